@@ -12,6 +12,8 @@ import (
 
 	"github.com/orbs-network/membuffers/go"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/client"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -108,6 +110,11 @@ func (s *server) createRouter() http.Handler {
 	router.Handle("/api/v1/send-transaction", http.HandlerFunc(s.sendTransactionHandler))
 	router.Handle("/api/v1/call-method", http.HandlerFunc(s.callMethodHandler))
 	router.Handle("/api/v1/get-transaction-status", http.HandlerFunc(s.getTransactionStatusHandler))
+	router.Handle("/api/v1/trace-call-method", http.HandlerFunc(s.traceCallMethodHandler))
+	router.Handle("/api/v1/trace-transaction", http.HandlerFunc(s.traceTransactionHandler))
+	router.Handle("/api/v1/dry-run-transaction", http.HandlerFunc(s.dryRunTransactionHandler))
+	router.Handle("/api/v1/get-contract-abi", http.HandlerFunc(s.getContractAbiHandler))
+	router.Handle("/api/v1/cancel-transaction", http.HandlerFunc(s.cancelTransactionHandler))
 	router.Handle("/metrics", http.HandlerFunc(s.dumpMetrics))
 	return router
 }
@@ -226,6 +233,149 @@ func translateStatusToHttpCode(responseCode protocol.RequestStatus) int {
 	return http.StatusNotImplemented
 }
 
+func (s *server) traceCallMethodHandler(w http.ResponseWriter, r *http.Request) {
+	tracer, ok := s.publicApi.(publicapi.Tracer)
+	if !ok {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusNotImplemented, nil, "tracing is not supported by this node"})
+		return
+	}
+
+	bytes, e := readInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	clientRequest := client.CallMethodRequestReader(bytes)
+	if e := validate(clientRequest); e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http server received trace-call-method", log.Stringable("request", clientRequest))
+	result, err := tracer.TraceCallMethod(r.Context(), &publicapi.TraceCallMethodInput{ClientRequest: clientRequest})
+	if result != nil && result.ClientResponse != nil {
+		s.writeJsonResponse(w, result.ClientResponse, translateStatusToHttpCode(result.RequestStatus), result.ClientResponse.CallMethodResult)
+	} else {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), err.Error()})
+	}
+}
+
+func (s *server) traceTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	tracer, ok := s.publicApi.(publicapi.Tracer)
+	if !ok {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusNotImplemented, nil, "tracing is not supported by this node"})
+		return
+	}
+
+	bytes, e := readInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	clientRequest := client.GetTransactionStatusRequestReader(bytes)
+	if e := validate(clientRequest); e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http server received trace-transaction", log.Stringable("request", clientRequest))
+	result, err := tracer.TraceTransaction(r.Context(), &publicapi.TraceTransactionInput{ClientRequest: clientRequest})
+	if result != nil && result.ClientResponse != nil {
+		s.writeJsonResponse(w, result.ClientResponse, translateStatusToHttpCode(result.RequestStatus), result.ClientResponse.TransactionStatus)
+	} else {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), err.Error()})
+	}
+}
+
+func (s *server) dryRunTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	dryRunner, ok := s.publicApi.(publicapi.DryRunner)
+	if !ok {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusNotImplemented, nil, "dry runs are not supported by this node"})
+		return
+	}
+
+	bytes, e := readInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	clientRequest := &publicapi.DryRunTransactionRequest{}
+	if err := json.Unmarshal(bytes, clientRequest); err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), "http request is not a valid dry run transaction request"})
+		return
+	}
+
+	s.logger.Info("http server received dry-run-transaction", log.Uint64("block-height", clientRequest.BlockHeight))
+	result, err := dryRunner.DryRunTransaction(r.Context(), &publicapi.DryRunTransactionInput{ClientRequest: clientRequest})
+	if result != nil && result.ClientResponse != nil {
+		s.writeJsonResponse(w, result.ClientResponse, translateStatusToHttpCode(result.RequestStatus), result.ClientResponse.RequestStatus)
+	} else {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), err.Error()})
+	}
+}
+
+func (s *server) getContractAbiHandler(w http.ResponseWriter, r *http.Request) {
+	abiReader, ok := s.publicApi.(publicapi.ContractAbiReader)
+	if !ok {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusNotImplemented, nil, "contract abis are not supported by this node"})
+		return
+	}
+
+	bytes, e := readInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	clientRequest := &publicapi.GetContractAbiRequest{}
+	if err := json.Unmarshal(bytes, clientRequest); err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), "http request is not a valid get contract abi request"})
+		return
+	}
+
+	s.logger.Info("http server received get-contract-abi", log.String("contract", clientRequest.ContractName))
+	result, err := abiReader.GetContractAbi(r.Context(), &publicapi.GetContractAbiInput{ClientRequest: clientRequest})
+	if result != nil && result.ClientResponse != nil {
+		s.writeJsonResponse(w, result.ClientResponse, translateStatusToHttpCode(result.RequestStatus), result.ClientResponse.RequestStatus)
+	} else {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), err.Error()})
+	}
+}
+
+func (s *server) cancelTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	canceller, ok := s.publicApi.(transactionpool.TransactionCanceller)
+	if !ok {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusNotImplemented, nil, "cancellations are not supported by this node"})
+		return
+	}
+
+	bytes, e := readInput(r)
+	if e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	clientRequest := &publicapi.CancelTransactionRequest{}
+	if err := json.Unmarshal(bytes, clientRequest); err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusBadRequest, log.Error(err), "http request is not a valid cancel transaction request"})
+		return
+	}
+
+	cancellation := protocol.SignedTransactionReader(clientRequest.SignedTransaction)
+	if e := validate(cancellation); e != nil {
+		s.writeErrorResponseAndLog(w, e)
+		return
+	}
+
+	s.logger.Info("http server received cancel-transaction", log.Stringable("request", cancellation))
+	result, err := canceller.CancelTransaction(r.Context(), &transactionpool.CancelTransactionInput{SignedTransaction: cancellation})
+	requestStatus, response := publicapi.ToCancelTransactionResponse(result, err)
+	s.writeJsonResponse(w, response, translateStatusToHttpCode(requestStatus), response.RequestStatus)
+}
+
 func (s *server) writeMembuffResponse(w http.ResponseWriter, message membuffers.Message, httpCode int, orbsText string) {
 	w.Header().Set("Content-Type", "application/vnd.membuffers")
 	w.WriteHeader(httpCode)
@@ -236,6 +386,21 @@ func (s *server) writeMembuffResponse(w http.ResponseWriter, message membuffers.
 	}
 }
 
+func (s *server) writeJsonResponse(w http.ResponseWriter, message interface{}, httpCode int, orbsText string) {
+	bytes, err := json.Marshal(message)
+	if err != nil {
+		s.writeErrorResponseAndLog(w, &httpErr{http.StatusInternalServerError, log.Error(err), "failed to encode response"})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("X-ORBS-CODE-NAME", orbsText)
+	w.WriteHeader(httpCode)
+	_, err = w.Write(bytes)
+	if err != nil {
+		s.logger.Info("error writing response", log.Error(err))
+	}
+}
+
 func (s *server) writeErrorResponseAndLog(w http.ResponseWriter, m *httpErr) {
 	if m.logField == nil {
 		s.logger.Info(m.message)
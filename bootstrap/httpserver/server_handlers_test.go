@@ -2,9 +2,13 @@ package httpserver
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
@@ -15,6 +19,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -149,3 +154,216 @@ func TestHttpServerGetTx_Error(t *testing.T) {
 
 	require.Equal(t, http.StatusInternalServerError, rec.Code, "should fail with 500")
 }
+
+type cancellerPublicApiMock struct {
+	services.MockPublicApi
+}
+
+func (m *cancellerPublicApiMock) CancelTransaction(ctx context.Context, input *transactionpool.CancelTransactionInput) (*transactionpool.CancelTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*transactionpool.CancelTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func TestHttpServerCancelTransaction_Basic(t *testing.T) {
+	papiMock := &cancellerPublicApiMock{}
+	papiMock.When("CancelTransaction", mock.Any, mock.Any).Times(1).Return(&transactionpool.CancelTransactionOutput{CancellationStatus: transactionpool.CANCELLATION_STATUS_CANCELLED}, nil)
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	request, _ := json.Marshal(&publicapi.CancelTransactionRequest{SignedTransaction: builders.TransferTransaction().Build().Raw()})
+	req, _ := http.NewRequest("POST", "", bytes.NewReader(request))
+	rec := httptest.NewRecorder()
+	s.(*server).cancelTransactionHandler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+	require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"), "cancellation should be returned as json")
+	require.Contains(t, rec.Body.String(), "CANCELLATION_STATUS_CANCELLED", "cancellation status should be returned")
+}
+
+func TestHttpServerCancelTransaction_Rejected(t *testing.T) {
+	papiMock := &cancellerPublicApiMock{}
+	papiMock.When("CancelTransaction", mock.Any, mock.Any).Times(1).Return(nil, &transactionpool.ErrTransactionRejected{
+		TransactionStatus: protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH,
+		Expected:          log.String("signer", "original"),
+		Actual:            log.String("signer", "other"),
+	})
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	request, _ := json.Marshal(&publicapi.CancelTransactionRequest{SignedTransaction: builders.TransferTransaction().Build().Raw()})
+	req, _ := http.NewRequest("POST", "", bytes.NewReader(request))
+	rec := httptest.NewRecorder()
+	s.(*server).cancelTransactionHandler(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code, "should be rejected")
+	require.Contains(t, rec.Body.String(), protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH.String(), "rejection status should be returned")
+}
+
+type tracingPublicApiMock struct {
+	services.MockPublicApi
+}
+
+func (m *tracingPublicApiMock) TraceCallMethod(ctx context.Context, input *publicapi.TraceCallMethodInput) (*publicapi.TraceCallMethodOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.TraceCallMethodOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func (m *tracingPublicApiMock) TraceTransaction(ctx context.Context, input *publicapi.TraceTransactionInput) (*publicapi.TraceTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.TraceTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func TestHttpServerTraceCallMethod_Basic(t *testing.T) {
+	papiMock := &tracingPublicApiMock{}
+	response := &publicapi.TraceCallMethodResponse{
+		RequestStatus:    protocol.REQUEST_STATUS_COMPLETED.String(),
+		CallMethodResult: protocol.EXECUTION_RESULT_SUCCESS.String(),
+		Trace:            []byte("{}"),
+	}
+
+	papiMock.When("TraceCallMethod", mock.Any, mock.Any).Times(1).Return(&publicapi.TraceCallMethodOutput{RequestStatus: protocol.REQUEST_STATUS_COMPLETED, ClientResponse: response})
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	request := (&client.CallMethodRequestBuilder{
+		Transaction: &protocol.TransactionBuilder{},
+	}).Build()
+
+	req, _ := http.NewRequest("POST", "", bytes.NewReader(request.Raw()))
+	rec := httptest.NewRecorder()
+	s.(*server).traceCallMethodHandler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+	require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"), "trace should be returned as json")
+}
+
+func TestHttpServerTraceTransaction_Congestion(t *testing.T) {
+	papiMock := &tracingPublicApiMock{}
+	response := &publicapi.TraceTransactionResponse{
+		RequestStatus:     protocol.REQUEST_STATUS_CONGESTION.String(),
+		TransactionStatus: protocol.TRANSACTION_STATUS_RESERVED.String(),
+	}
+
+	papiMock.When("TraceTransaction", mock.Any, mock.Any).Times(1).Return(&publicapi.TraceTransactionOutput{RequestStatus: protocol.REQUEST_STATUS_CONGESTION, ClientResponse: response}, errors.New("too many traces"))
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	request := (&client.GetTransactionStatusRequestBuilder{}).Build()
+
+	req, _ := http.NewRequest("POST", "", bytes.NewReader(request.Raw()))
+	rec := httptest.NewRecorder()
+	s.(*server).traceTransactionHandler(rec, req)
+
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code, "should fail with 503")
+}
+
+func TestHttpServerTraceCallMethod_NotSupported(t *testing.T) {
+	papiMock := &services.MockPublicApi{}
+	s := makeServer(papiMock)
+
+	request := (&client.CallMethodRequestBuilder{
+		Transaction: &protocol.TransactionBuilder{},
+	}).Build()
+
+	req, _ := http.NewRequest("POST", "", bytes.NewReader(request.Raw()))
+	rec := httptest.NewRecorder()
+	s.(*server).traceCallMethodHandler(rec, req)
+
+	require.Equal(t, http.StatusNotImplemented, rec.Code, "should fail with 501")
+}
+
+type dryRunPublicApiMock struct {
+	services.MockPublicApi
+}
+
+func (m *dryRunPublicApiMock) DryRunTransaction(ctx context.Context, input *publicapi.DryRunTransactionInput) (*publicapi.DryRunTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.DryRunTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func TestHttpServerDryRunTransaction_Basic(t *testing.T) {
+	papiMock := &dryRunPublicApiMock{}
+	response := &publicapi.DryRunTransactionResponse{
+		RequestStatus:      protocol.REQUEST_STATUS_COMPLETED.String(),
+		ExecutionResult:    protocol.EXECUTION_RESULT_SUCCESS.String(),
+		ContractStateDiffs: []*publicapi.ContractStateDiff{},
+		BlockHeight:        10,
+	}
+
+	papiMock.When("DryRunTransaction", mock.Any, mock.Any).Times(1).Return(&publicapi.DryRunTransactionOutput{RequestStatus: protocol.REQUEST_STATUS_COMPLETED, ClientResponse: response})
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"transaction":"","blockHeight":10,"stateOverrides":[]}`))
+	rec := httptest.NewRecorder()
+	s.(*server).dryRunTransactionHandler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+	require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"), "dry run should be returned as json")
+}
+
+type contractAbiPublicApiMock struct {
+	services.MockPublicApi
+}
+
+func (m *contractAbiPublicApiMock) GetContractAbi(ctx context.Context, input *publicapi.GetContractAbiInput) (*publicapi.GetContractAbiOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*publicapi.GetContractAbiOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func TestHttpServerGetContractAbi_Basic(t *testing.T) {
+	papiMock := &contractAbiPublicApiMock{}
+	response := &publicapi.GetContractAbiResponse{
+		RequestStatus:   protocol.REQUEST_STATUS_COMPLETED.String(),
+		ContractName:    "BenchmarkContract",
+		PermissionScope: protocol.PERMISSION_SCOPE_SERVICE.String(),
+		Described:       true,
+		Methods: []*publicapi.ContractAbiMethod{
+			{Name: "add", Access: "read-only", InputTypes: []string{"uint64", "uint64"}, OutputTypes: []string{"uint64"}},
+		},
+	}
+
+	papiMock.When("GetContractAbi", mock.Any, mock.Any).Times(1).Return(&publicapi.GetContractAbiOutput{RequestStatus: protocol.REQUEST_STATUS_COMPLETED, ClientResponse: response})
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	req, _ := http.NewRequest("POST", "", strings.NewReader(`{"virtualChainId":42,"contractName":"BenchmarkContract"}`))
+	rec := httptest.NewRecorder()
+	s.(*server).getContractAbiHandler(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code, "should succeed")
+	require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"), "abi should be returned as json")
+}
+
+func TestHttpServerGetContractAbi_RejectsInvalidJson(t *testing.T) {
+	papiMock := &contractAbiPublicApiMock{}
+	papiMock.Never("GetContractAbi", mock.Any, mock.Any)
+
+	s := NewHttpServer("", log.GetLogger(), papiMock, metric.NewRegistry())
+
+	req, _ := http.NewRequest("POST", "", strings.NewReader("not json"))
+	rec := httptest.NewRecorder()
+	s.(*server).getContractAbiHandler(rec, req)
+
+	require.Equal(t, http.StatusBadRequest, rec.Code, "should be rejected")
+}
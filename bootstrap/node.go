@@ -35,7 +35,7 @@ func NewNode(nodeConfig config.NodeConfig, logger log.BasicLogger, httpAddress s
 	transport := gossipAdapter.NewDirectTransport(ctx, nodeConfig, nodeLogger)
 	blockPersistence := blockStorageAdapter.NewInMemoryBlockPersistence()
 	statePersistence := stateStorageAdapter.NewInMemoryStatePersistence(metricRegistry)
-	nativeCompiler := nativeProcessorAdapter.NewNativeCompiler(nodeConfig, nodeLogger)
+	nativeCompiler := newNativeCompiler(nodeConfig, nodeLogger)
 	nodeLogic := NewNodeLogic(ctx, transport, blockPersistence, statePersistence, nativeCompiler, nodeLogger, metricRegistry, nodeConfig)
 	httpServer := httpserver.NewHttpServer(httpAddress, nodeLogger, nodeLogic.PublicApi(), metricRegistry)
 
@@ -47,6 +47,14 @@ func NewNode(nodeConfig config.NodeConfig, logger log.BasicLogger, httpAddress s
 	}
 }
 
+// deployed contracts run in a supervised executor process if one is configured and are loaded as plugins otherwise
+func newNativeCompiler(nodeConfig config.NodeConfig, logger log.BasicLogger) nativeProcessorAdapter.Compiler {
+	if nodeConfig.ProcessorNativeExecutorPath() != "" {
+		return nativeProcessorAdapter.NewExecutorCompiler(nodeConfig, logger)
+	}
+	return nativeProcessorAdapter.NewNativeCompiler(nodeConfig, logger)
+}
+
 func (n *node) GracefulShutdown(timeout time.Duration) {
 	n.ctxCancel()
 	n.httpServer.GracefulShutdown(timeout)
@@ -13,8 +13,10 @@ import (
 	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
 	"github.com/orbs-network/orbs-network-go/services/gossip"
 	gossipAdapter "github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-network-go/services/processor/javascript"
 	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-network-go/services/processor/wasm"
 	"github.com/orbs-network/orbs-network-go/services/publicapi"
 	"github.com/orbs-network/orbs-network-go/services/statestorage"
 	stateStorageAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
@@ -47,27 +49,28 @@ func NewNodeLogic(
 
 	processors := make(map[protocol.ProcessorType]services.Processor)
 	processors[protocol.PROCESSOR_TYPE_NATIVE] = native.NewNativeProcessor(nativeCompiler, logger, metricRegistry)
+	if nodeConfig.ProcessorJavaScriptEnabled() {
+		processors[protocol.PROCESSOR_TYPE_JAVASCRIPT] = javascript.NewJavaScriptProcessor(logger)
+	}
+	processors[wasm.PROCESSOR_TYPE_WASM] = wasm.NewWasmProcessor(logger)
 
+	ethereumConnector := ethereum.NewEthereumCrosschainConnector(nodeConfig, logger)
 	crosschainConnectors := make(map[protocol.CrosschainConnectorType]services.CrosschainConnector)
-	crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM] = ethereum.NewEthereumCrosschainConnector()
+	crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM] = ethereumConnector
 
 	gossipService := gossip.NewGossip(gossipTransport, nodeConfig, logger)
 	stateStorageService := statestorage.NewStateStorage(nodeConfig, statePersistence, logger, metricRegistry)
-	virtualMachineService := virtualmachine.NewVirtualMachine(stateStorageService, processors, crosschainConnectors, logger)
+	virtualMachineService := virtualmachine.NewVirtualMachine(ctx, stateStorageService, processors, crosschainConnectors, ethereumConnector, nodeConfig, logger, metricRegistry)
 	transactionPoolService := transactionpool.NewTransactionPool(ctx, gossipService, virtualMachineService, nodeConfig, logger, metricRegistry)
 	blockStorageService := blockstorage.NewBlockStorage(ctx, nodeConfig, blockPersistence, stateStorageService, gossipService, transactionPoolService, logger, metricRegistry)
 	publicApiService := publicapi.NewPublicApi(nodeConfig, transactionPoolService, virtualMachineService, blockStorageService, logger, metricRegistry)
-	consensusContextService := consensuscontext.NewConsensusContext(transactionPoolService, virtualMachineService, nil, nodeConfig, logger, metricRegistry)
+	consensusContextService := consensuscontext.NewConsensusContext(transactionPoolService, virtualMachineService, stateStorageService, nodeConfig, logger, metricRegistry)
 
-	// TODO Uncomment and append to consensusAlgo when you want to integrate Lean Helix.
-	// TODO For now, NewLeanHelixConsensusAlgo() is executed to ensure compilation
-	/*leanHelixAlgo := */
-	leanhelixconsensus.NewLeanHelixConsensusAlgo(ctx, gossipService, blockStorageService, consensusContextService, logger, nodeConfig, metricRegistry)
+	leanHelixAlgo := leanhelixconsensus.NewLeanHelixConsensusAlgo(ctx, gossipService, blockStorageService, consensusContextService, logger, nodeConfig, metricRegistry)
 	benchmarkConsensusAlgo := benchmarkconsensus.NewBenchmarkConsensusAlgo(ctx, gossipService, blockStorageService, consensusContextService, logger, nodeConfig, metricRegistry)
 
-	// TODO: Restore this when lean-helix-go submodule is integrated
 	consensusAlgos := make([]services.ConsensusAlgo, 0)
-	//consensusAlgos = append(consensusAlgos, leanHelixAlgo)
+	consensusAlgos = append(consensusAlgos, leanHelixAlgo)
 	consensusAlgos = append(consensusAlgos, benchmarkConsensusAlgo)
 
 	runtimeReporter := metric.NewRuntimeReporter(ctx, metricRegistry, logger)
@@ -4,6 +4,8 @@ rm -rf _bin
 
 time go build -o _bin/orbs-node -a main.go
 
+time go build -o _bin/orbs-contract-executor -a services/processor/native/executor/main/main.go
+
 time go test -o _bin/e2e.test -a -c ./test/e2e
 
 if [ "$SKIP_DEVTOOLS" == "" ]; then
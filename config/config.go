@@ -26,6 +26,8 @@ type NodeConfig interface {
 
 	// benchmark consensus
 	BenchmarkConsensusRetryInterval() time.Duration
+	BenchmarkConsensusLeaderFailoverEnabled() bool
+	BenchmarkConsensusLeaderFailoverTimeout() time.Duration
 
 	// block storage
 	BlockSyncBatchSize() uint32
@@ -47,9 +49,28 @@ type NodeConfig interface {
 	ConsensusContextMinimalBlockTime() time.Duration
 	ConsensusContextMinimumTransactionsInBlock() uint32
 	ConsensusContextMaximumTransactionsInBlock() uint32
+	ConsensusContextSystemTimestampAllowedJitter() time.Duration
+
+	// virtual machine
+	VirtualMachineTransactionExecutionBudget() uint32
+	VirtualMachineBlockExecutionBudget() uint32
+	VirtualMachineExecutionConcurrency() uint32
+	VirtualMachinePreloadDeployedContractsTimeout() time.Duration
+	VirtualMachineSubscriptionEnforced() bool
+	VirtualMachineSubscriptionGenesisAdmin() primitives.Ripmd160Sha256
+	VirtualMachineSubscriptionGenesisPlan() string
+	VirtualMachineSubscriptionGenesisExpiry() primitives.TimestampNano
+	VirtualMachineSubscriptionGenesisTransactionsPerBlock() uint32
+	VirtualMachineMaximumCallDepth() uint32
+	VirtualMachineTransactionExecutionTimeout() time.Duration
+	VirtualMachineCallExecutionTimeout() time.Duration
+	VirtualMachineBlockCallExecutionTimeout() time.Duration
+	VirtualMachineDeterminismSelfCheckEnabled() bool
+	VirtualMachineDeterminismSelfCheckBlacklist() bool
 
 	// transaction pool
 	TransactionPoolPendingPoolSizeInBytes() uint32
+	TransactionPoolCancelledPoolSize() uint32
 	TransactionPoolTransactionExpirationWindow() time.Duration
 	TransactionPoolFutureTimestampGraceTimeout() time.Duration
 	TransactionPoolPendingPoolClearExpiredInterval() time.Duration
@@ -64,9 +85,21 @@ type NodeConfig interface {
 
 	// public api
 	SendTransactionTimeout() time.Duration
+	PublicApiTracingEnabled() bool
+	PublicApiTracingConcurrency() uint32
 
 	// processor
 	ProcessorArtifactPath() string
+	ProcessorJavaScriptEnabled() bool
+	ProcessorNativeExecutorPath() string
+	ProcessorNativeExecutorCallMemoryLimitInBytes() uint32
+	ProcessorNativeExecutorCallCpuLimitInSeconds() uint32
+
+	// ethereum connector
+	EthereumEndpoint() string
+	EthereumCallTimeout() time.Duration
+	EthereumCallCacheSize() uint32
+	EthereumMinConfirmations() uint32
 
 	// metrics
 	MetricsReportInterval() time.Duration
@@ -78,6 +111,7 @@ type mutableNodeConfig interface {
 	SetDuration(key string, value time.Duration) mutableNodeConfig
 	SetUint32(key string, value uint32) mutableNodeConfig
 	SetString(key string, value string) mutableNodeConfig
+	SetBool(key string, value bool) mutableNodeConfig
 	SetFederationNodes(nodes map[string]FederationNode) mutableNodeConfig
 	SetGossipPeers(peers map[string]GossipPeer) mutableNodeConfig
 	SetNodePublicKey(key primitives.Ed25519PublicKey) mutableNodeConfig
@@ -120,6 +154,7 @@ type ConsensusContextConfig interface {
 	ConsensusContextMaximumTransactionsInBlock() uint32
 	ConsensusContextMinimumTransactionsInBlock() uint32
 	ConsensusContextMinimalBlockTime() time.Duration
+	ConsensusContextSystemTimestampAllowedJitter() time.Duration
 	FederationNodes(asOfBlock uint64) map[string]FederationNode
 	ConsensusMinimumCommitteeSize() uint32
 }
@@ -127,6 +162,36 @@ type ConsensusContextConfig interface {
 type PublicApiConfig interface {
 	SendTransactionTimeout() time.Duration
 	VirtualChainId() primitives.VirtualChainId
+	PublicApiTracingEnabled() bool
+	PublicApiTracingConcurrency() uint32
+	StateStorageHistorySnapshotNum() uint32
+}
+
+type VirtualMachineConfig interface {
+	VirtualChainId() primitives.VirtualChainId
+	VirtualMachineTransactionExecutionBudget() uint32
+	VirtualMachineBlockExecutionBudget() uint32
+	VirtualMachineExecutionConcurrency() uint32
+	VirtualMachinePreloadDeployedContractsTimeout() time.Duration
+	VirtualMachineSubscriptionEnforced() bool
+	VirtualMachineSubscriptionGenesisAdmin() primitives.Ripmd160Sha256
+	VirtualMachineSubscriptionGenesisPlan() string
+	VirtualMachineSubscriptionGenesisExpiry() primitives.TimestampNano
+	VirtualMachineSubscriptionGenesisTransactionsPerBlock() uint32
+	VirtualMachineMaximumCallDepth() uint32
+	VirtualMachineTransactionExecutionTimeout() time.Duration
+	VirtualMachineCallExecutionTimeout() time.Duration
+	VirtualMachineBlockCallExecutionTimeout() time.Duration
+	VirtualMachineDeterminismSelfCheckEnabled() bool
+	VirtualMachineDeterminismSelfCheckBlacklist() bool
+	StateStorageHistorySnapshotNum() uint32
+}
+
+type EthereumCrosschainConnectorConfig interface {
+	EthereumEndpoint() string
+	EthereumCallTimeout() time.Duration
+	EthereumCallCacheSize() uint32
+	EthereumMinConfirmations() uint32
 }
 
 type StateStorageConfig interface {
@@ -142,6 +207,7 @@ type TransactionPoolConfig interface {
 	BlockTrackerGraceDistance() uint32
 	BlockTrackerGraceTimeout() time.Duration
 	TransactionPoolPendingPoolSizeInBytes() uint32
+	TransactionPoolCancelledPoolSize() uint32
 	TransactionPoolTransactionExpirationWindow() time.Duration
 	TransactionPoolFutureTimestampGraceTimeout() time.Duration
 	TransactionPoolPendingPoolClearExpiredInterval() time.Duration
@@ -28,6 +28,15 @@ func newFileConfig(parent mutableNodeConfig, source string) (mutableNodeConfig,
 	return parent, nil
 }
 
+// values of these keys are kept as they are, any other string value is a duration
+var stringKeys = map[string]bool{
+	PROCESSOR_ARTIFACT_PATH:                    true,
+	PROCESSOR_NATIVE_EXECUTOR_PATH:             true,
+	ETHEREUM_ENDPOINT:                          true,
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_ADMIN: true,
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_PLAN:  true,
+}
+
 func convertKeyName(key string) string {
 	return strings.ToUpper(strings.Replace(key, "-", "_", -1))
 }
@@ -76,7 +85,7 @@ func parseNodesAndPeers(value interface{}) (nodes map[string]FederationNode, pee
 	return nodes, peers, nil
 }
 
-func populateConfig(cfg mutableNodeConfig, data map[string]interface{}) (error) {
+func populateConfig(cfg mutableNodeConfig, data map[string]interface{}) error {
 	for key, value := range data {
 		var duration time.Duration
 		var numericValue uint32
@@ -87,7 +96,13 @@ func populateConfig(cfg mutableNodeConfig, data map[string]interface{}) (error)
 		case float64:
 			numericValue, err = parseUint32(value.(float64))
 		case string:
-			duration, err = time.ParseDuration(value.(string))
+			if stringKeys[convertKeyName(key)] {
+				cfg.SetString(convertKeyName(key), value.(string))
+			} else {
+				duration, err = time.ParseDuration(value.(string))
+			}
+		case bool:
+			cfg.SetBool(convertKeyName(key), value.(bool))
 		}
 
 		if numericValue != 0 {
@@ -109,6 +124,10 @@ func populateConfig(cfg mutableNodeConfig, data map[string]interface{}) (error)
 			cfg.SetActiveConsensusAlgo(consensus.ConsensusAlgoType(i))
 		}
 
+		if key == "virtual-machine-subscription-genesis-admin" {
+			_, err = hex.DecodeString(value.(string))
+		}
+
 		if key == "node-public-key" {
 			publicKey, err = hex.DecodeString(value.(string))
 			cfg.SetNodePublicKey(primitives.Ed25519PublicKey(publicKey))
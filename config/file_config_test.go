@@ -31,6 +31,63 @@ func TestFileConfigSetDuration(t *testing.T) {
 	require.EqualValues(t, 10*time.Minute, cfg.BlockSyncCollectResponseTimeout())
 }
 
+func TestFileConfigSetBool(t *testing.T) {
+	cfg, err := newEmptyFileConfig(`{"public-api-tracing-enabled": true}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.True(t, cfg.PublicApiTracingEnabled())
+}
+
+func TestFileConfigEnablesJavaScriptProcessor(t *testing.T) {
+	cfg, err := newEmptyFileConfig(`{"processor-javascript-enabled": true}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.True(t, cfg.ProcessorJavaScriptEnabled())
+}
+
+func TestFileConfigTurnsOnSubscriptionEnforcedInProduction(t *testing.T) {
+	cfg, err := ForProduction("").MergeWithFileConfig(`{"virtual-machine-subscription-enforced": true}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.True(t, cfg.VirtualMachineSubscriptionEnforced())
+}
+
+func TestFileConfigSetEthereumEndpoint(t *testing.T) {
+	cfg, err := newEmptyFileConfig(`{"ethereum-endpoint": "http://localhost:8545"}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "http://localhost:8545", cfg.EthereumEndpoint())
+}
+
+func TestFileConfigSetString(t *testing.T) {
+	cfg, err := newEmptyFileConfig(`{"processor-native-executor-path": "/opt/orbs/orbs-contract-executor"}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.Equal(t, "/opt/orbs/orbs-contract-executor", cfg.ProcessorNativeExecutorPath())
+}
+
+func TestSetVirtualMachineSubscriptionGenesis(t *testing.T) {
+	cfg, err := newEmptyFileConfig(`{"virtual-machine-subscription-genesis-admin": "a328846cd5b4979d68a8c58a9bdfeee657b34de7", "virtual-machine-subscription-genesis-plan": "B0", "virtual-machine-subscription-genesis-expiry-in-seconds": 1600000000, "virtual-machine-subscription-genesis-transactions-per-block": 100}`)
+
+	require.NotNil(t, cfg)
+	require.NoError(t, err)
+	require.EqualValues(t, []byte{0xa3, 0x28, 0x84, 0x6c, 0xd5, 0xb4, 0x97, 0x9d, 0x68, 0xa8, 0xc5, 0x8a, 0x9b, 0xdf, 0xee, 0xe6, 0x57, 0xb3, 0x4d, 0xe7}, cfg.VirtualMachineSubscriptionGenesisAdmin())
+	require.Equal(t, "B0", cfg.VirtualMachineSubscriptionGenesisPlan())
+	require.EqualValues(t, 1600000000*time.Second, cfg.VirtualMachineSubscriptionGenesisExpiry())
+	require.EqualValues(t, 100, cfg.VirtualMachineSubscriptionGenesisTransactionsPerBlock())
+}
+
+func TestSetVirtualMachineSubscriptionGenesisAdminRejectsInvalidHex(t *testing.T) {
+	_, err := newEmptyFileConfig(`{"virtual-machine-subscription-genesis-admin": "not hex"}`)
+
+	require.Error(t, err)
+}
+
 func TestSetNodePublicKey(t *testing.T) {
 	cfg, err := newEmptyFileConfig(`{"node-public-key": "dfc06c5be24a67adee80b35ab4f147bb1a35c55ff85eda69f40ef827bddec173"}`)
 
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/hex"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"time"
@@ -19,6 +20,7 @@ type NodeConfigValue struct {
 	Uint32Value   uint32
 	DurationValue time.Duration
 	StringValue   string
+	BoolValue     bool
 }
 
 type config struct {
@@ -38,6 +40,9 @@ const (
 	CONSENSUS_REQUIRED_QUORUM_PERCENTAGE = "CONSENSUS_REQUIRED_QUORUM_PERCENTAGE"
 	CONSENSUS_MINIMUM_COMMITTEE_SIZE     = "CONSENSUS_MINIMUM_COMMITTEE_SIZE"
 
+	BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED = "BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED"
+	BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT = "BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT"
+
 	BLOCK_SYNC_BATCH_SIZE               = "BLOCK_SYNC_BATCH_SIZE"
 	BLOCK_SYNC_INTERVAL                 = "BLOCK_SYNC_INTERVAL"
 	BLOCK_SYNC_COLLECT_RESPONSE_TIMEOUT = "BLOCK_SYNC_COLLECT_RESPONSE_TIMEOUT"
@@ -47,16 +52,34 @@ const (
 	BLOCK_TRANSACTION_RECEIPT_QUERY_GRACE_END         = "BLOCK_TRANSACTION_RECEIPT_QUERY_GRACE_END"
 	BLOCK_TRANSACTION_RECEIPT_QUERY_EXPIRATION_WINDOW = "BLOCK_TRANSACTION_RECEIPT_QUERY_EXPIRATION_WINDOW"
 
-	CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME            = "CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME"
-	CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK = "CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK"
-	CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK = "CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK"
+	CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME              = "CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME"
+	CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK   = "CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK"
+	CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK   = "CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK"
+	CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER = "CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER"
 
 	STATE_STORAGE_HISTORY_SNAPSHOT_NUM = "STATE_STORAGE_HISTORY_SNAPSHOT_NUM"
 
 	BLOCK_TRACKER_GRACE_DISTANCE = "BLOCK_TRACKER_GRACE_DISTANCE"
 	BLOCK_TRACKER_GRACE_TIMEOUT  = "BLOCK_TRACKER_GRACE_TIMEOUT"
 
+	VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET                = "VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET"
+	VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET                      = "VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET"
+	VIRTUAL_MACHINE_EXECUTION_CONCURRENCY                       = "VIRTUAL_MACHINE_EXECUTION_CONCURRENCY"
+	VIRTUAL_MACHINE_PRELOAD_DEPLOYED_CONTRACTS_TIMEOUT          = "VIRTUAL_MACHINE_PRELOAD_DEPLOYED_CONTRACTS_TIMEOUT"
+	VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED                       = "VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED"
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_ADMIN                  = "VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_ADMIN"
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_PLAN                   = "VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_PLAN"
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_EXPIRY_IN_SECONDS      = "VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_EXPIRY_IN_SECONDS"
+	VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_TRANSACTIONS_PER_BLOCK = "VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_TRANSACTIONS_PER_BLOCK"
+	VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH                          = "VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH"
+	VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT               = "VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT"
+	VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT                      = "VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT"
+	VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT                = "VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT"
+	VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED              = "VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED"
+	VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST            = "VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST"
+
 	TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES            = "TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES"
+	TRANSACTION_POOL_CANCELLED_POOL_SIZE                   = "TRANSACTION_POOL_CANCELLED_POOL_SIZE"
 	TRANSACTION_POOL_TRANSACTION_EXPIRATION_WINDOW         = "TRANSACTION_POOL_TRANSACTION_EXPIRATION_WINDOW"
 	TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT        = "TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT"
 	TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL   = "TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL"
@@ -69,8 +92,19 @@ const (
 	GOSSIP_NETWORK_TIMEOUT                = "GOSSIP_NETWORK_TIMEOUT"
 
 	PUBLIC_API_SEND_TRANSACTION_TIMEOUT = "PUBLIC_API_SEND_TRANSACTION_TIMEOUT"
+	PUBLIC_API_TRACING_ENABLED          = "PUBLIC_API_TRACING_ENABLED"
+	PUBLIC_API_TRACING_CONCURRENCY      = "PUBLIC_API_TRACING_CONCURRENCY"
+
+	PROCESSOR_ARTIFACT_PATH                              = "PROCESSOR_ARTIFACT_PATH"
+	PROCESSOR_JAVASCRIPT_ENABLED                         = "PROCESSOR_JAVASCRIPT_ENABLED"
+	PROCESSOR_NATIVE_EXECUTOR_PATH                       = "PROCESSOR_NATIVE_EXECUTOR_PATH"
+	PROCESSOR_NATIVE_EXECUTOR_CALL_MEMORY_LIMIT_IN_BYTES = "PROCESSOR_NATIVE_EXECUTOR_CALL_MEMORY_LIMIT_IN_BYTES"
+	PROCESSOR_NATIVE_EXECUTOR_CALL_CPU_LIMIT_IN_SECONDS  = "PROCESSOR_NATIVE_EXECUTOR_CALL_CPU_LIMIT_IN_SECONDS"
 
-	PROCESSOR_ARTIFACT_PATH = "PROCESSOR_ARTIFACT_PATH"
+	ETHEREUM_ENDPOINT          = "ETHEREUM_ENDPOINT"
+	ETHEREUM_CALL_TIMEOUT      = "ETHEREUM_CALL_TIMEOUT"
+	ETHEREUM_CALL_CACHE_SIZE   = "ETHEREUM_CALL_CACHE_SIZE"
+	ETHEREUM_MIN_CONFIRMATIONS = "ETHEREUM_MIN_CONFIRMATIONS"
 
 	METRICS_REPORT_INTERVAL = "METRICS_REPORT_INTERVAL"
 )
@@ -108,6 +142,11 @@ func (c *config) SetString(key string, value string) mutableNodeConfig {
 	return c
 }
 
+func (c *config) SetBool(key string, value bool) mutableNodeConfig {
+	c.kv[key] = NodeConfigValue{BoolValue: value}
+	return c
+}
+
 func (c *config) SetNodePublicKey(key primitives.Ed25519PublicKey) mutableNodeConfig {
 	c.nodePublicKey = key
 	return c
@@ -186,6 +225,14 @@ func (c *config) BenchmarkConsensusRetryInterval() time.Duration {
 	return c.kv[BENCHMARK_CONSENSUS_RETRY_INTERVAL].DurationValue
 }
 
+func (c *config) BenchmarkConsensusLeaderFailoverEnabled() bool {
+	return c.kv[BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED].BoolValue
+}
+
+func (c *config) BenchmarkConsensusLeaderFailoverTimeout() time.Duration {
+	return c.kv[BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT].DurationValue
+}
+
 func (c *config) LeanHelixConsensusRoundTimeoutInterval() time.Duration {
 	return c.kv[LEAN_HELIX_CONSENSUS_RETRY_INTERVAL].DurationValue
 }
@@ -226,6 +273,10 @@ func (c *config) ConsensusContextMaximumTransactionsInBlock() uint32 {
 	return c.kv[CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK].Uint32Value
 }
 
+func (c *config) ConsensusContextSystemTimestampAllowedJitter() time.Duration {
+	return c.kv[CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER].DurationValue
+}
+
 func (c *config) StateStorageHistorySnapshotNum() uint32 {
 	return c.kv[STATE_STORAGE_HISTORY_SNAPSHOT_NUM].Uint32Value
 }
@@ -238,10 +289,79 @@ func (c *config) BlockTrackerGraceTimeout() time.Duration {
 	return c.kv[BLOCK_TRACKER_GRACE_TIMEOUT].DurationValue
 }
 
+func (c *config) VirtualMachineTransactionExecutionBudget() uint32 {
+	return c.kv[VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET].Uint32Value
+}
+
+func (c *config) VirtualMachineBlockExecutionBudget() uint32 {
+	return c.kv[VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET].Uint32Value
+}
+
+func (c *config) VirtualMachineExecutionConcurrency() uint32 {
+	return c.kv[VIRTUAL_MACHINE_EXECUTION_CONCURRENCY].Uint32Value
+}
+
+func (c *config) VirtualMachinePreloadDeployedContractsTimeout() time.Duration {
+	return c.kv[VIRTUAL_MACHINE_PRELOAD_DEPLOYED_CONTRACTS_TIMEOUT].DurationValue
+}
+
+func (c *config) VirtualMachineSubscriptionEnforced() bool {
+	return c.kv[VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED].BoolValue
+}
+
+// hex encoded (validated when read from a file), a chain without a genesis admin can never change its subscription
+func (c *config) VirtualMachineSubscriptionGenesisAdmin() primitives.Ripmd160Sha256 {
+	admin, err := hex.DecodeString(c.kv[VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_ADMIN].StringValue)
+	if err != nil {
+		return nil
+	}
+	return admin
+}
+
+func (c *config) VirtualMachineSubscriptionGenesisPlan() string {
+	return c.kv[VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_PLAN].StringValue
+}
+
+func (c *config) VirtualMachineSubscriptionGenesisExpiry() primitives.TimestampNano {
+	return primitives.TimestampNano(uint64(c.kv[VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_EXPIRY_IN_SECONDS].Uint32Value) * uint64(time.Second))
+}
+
+func (c *config) VirtualMachineSubscriptionGenesisTransactionsPerBlock() uint32 {
+	return c.kv[VIRTUAL_MACHINE_SUBSCRIPTION_GENESIS_TRANSACTIONS_PER_BLOCK].Uint32Value
+}
+
+func (c *config) VirtualMachineMaximumCallDepth() uint32 {
+	return c.kv[VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH].Uint32Value
+}
+
+func (c *config) VirtualMachineTransactionExecutionTimeout() time.Duration {
+	return c.kv[VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT].DurationValue
+}
+
+func (c *config) VirtualMachineCallExecutionTimeout() time.Duration {
+	return c.kv[VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT].DurationValue
+}
+
+func (c *config) VirtualMachineBlockCallExecutionTimeout() time.Duration {
+	return c.kv[VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT].DurationValue
+}
+
+func (c *config) VirtualMachineDeterminismSelfCheckEnabled() bool {
+	return c.kv[VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED].BoolValue
+}
+
+func (c *config) VirtualMachineDeterminismSelfCheckBlacklist() bool {
+	return c.kv[VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST].BoolValue
+}
+
 func (c *config) TransactionPoolPendingPoolSizeInBytes() uint32 {
 	return c.kv[TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES].Uint32Value
 }
 
+func (c *config) TransactionPoolCancelledPoolSize() uint32 {
+	return c.kv[TRANSACTION_POOL_CANCELLED_POOL_SIZE].Uint32Value
+}
+
 func (c *config) TransactionPoolTransactionExpirationWindow() time.Duration {
 	return c.kv[TRANSACTION_POOL_TRANSACTION_EXPIRATION_WINDOW].DurationValue
 }
@@ -270,6 +390,14 @@ func (c *config) SendTransactionTimeout() time.Duration {
 	return c.kv[PUBLIC_API_SEND_TRANSACTION_TIMEOUT].DurationValue
 }
 
+func (c *config) PublicApiTracingEnabled() bool {
+	return c.kv[PUBLIC_API_TRACING_ENABLED].BoolValue
+}
+
+func (c *config) PublicApiTracingConcurrency() uint32 {
+	return c.kv[PUBLIC_API_TRACING_CONCURRENCY].Uint32Value
+}
+
 func (c *config) BlockSyncCollectChunksTimeout() time.Duration {
 	return c.kv[BLOCK_SYNC_COLLECT_CHUNKS_TIMEOUT].DurationValue
 }
@@ -278,6 +406,38 @@ func (c *config) ProcessorArtifactPath() string {
 	return c.kv[PROCESSOR_ARTIFACT_PATH].StringValue
 }
 
+func (c *config) ProcessorJavaScriptEnabled() bool {
+	return c.kv[PROCESSOR_JAVASCRIPT_ENABLED].BoolValue
+}
+
+func (c *config) ProcessorNativeExecutorPath() string {
+	return c.kv[PROCESSOR_NATIVE_EXECUTOR_PATH].StringValue
+}
+
+func (c *config) ProcessorNativeExecutorCallMemoryLimitInBytes() uint32 {
+	return c.kv[PROCESSOR_NATIVE_EXECUTOR_CALL_MEMORY_LIMIT_IN_BYTES].Uint32Value
+}
+
+func (c *config) ProcessorNativeExecutorCallCpuLimitInSeconds() uint32 {
+	return c.kv[PROCESSOR_NATIVE_EXECUTOR_CALL_CPU_LIMIT_IN_SECONDS].Uint32Value
+}
+
+func (c *config) EthereumEndpoint() string {
+	return c.kv[ETHEREUM_ENDPOINT].StringValue
+}
+
+func (c *config) EthereumCallTimeout() time.Duration {
+	return c.kv[ETHEREUM_CALL_TIMEOUT].DurationValue
+}
+
+func (c *config) EthereumCallCacheSize() uint32 {
+	return c.kv[ETHEREUM_CALL_CACHE_SIZE].Uint32Value
+}
+
+func (c *config) EthereumMinConfirmations() uint32 {
+	return c.kv[ETHEREUM_MIN_CONFIRMATIONS].Uint32Value
+}
+
 func (c *config) GossipListenPort() uint16 {
 	return uint16(c.kv[GOSSIP_LISTEN_PORT].Uint32Value)
 }
@@ -33,6 +33,8 @@ func ForConsensusContextTests(federationNodes map[string]FederationNode) Consens
 
 	cfg.SetDuration(CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME, 1*time.Millisecond)
 	cfg.SetUint32(CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK, 2)
+	cfg.SetUint32(CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK, 100)
+	cfg.SetDuration(CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER, 2*time.Second)
 	cfg.SetUint32(CONSENSUS_MINIMUM_COMMITTEE_SIZE, 4)
 	if federationNodes != nil {
 		cfg.SetFederationNodes(federationNodes)
@@ -40,11 +42,111 @@ func ForConsensusContextTests(federationNodes map[string]FederationNode) Consens
 	return cfg
 }
 
+func ForVirtualMachineTests(transactionExecutionBudget uint32, blockExecutionBudget uint32, executionConcurrency uint32) VirtualMachineConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(VIRTUAL_CHAIN_ID, 42)
+
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, transactionExecutionBudget)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, blockExecutionBudget)
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, executionConcurrency)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, true)
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, 64)
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, 5*time.Second)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, false)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, false)
+	cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, 5)
+	return cfg
+}
+
+func ForVirtualMachineCallDepthTests(maximumCallDepth uint32) VirtualMachineConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(VIRTUAL_CHAIN_ID, 42)
+
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, 1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, 100*1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, 1)
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, maximumCallDepth)
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, 5*time.Second)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, false)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, false)
+	return cfg
+}
+
+func ForVirtualMachineTimeoutTests(transactionExecutionTimeout time.Duration, callExecutionTimeout time.Duration, blockCallExecutionTimeout time.Duration) VirtualMachineConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(VIRTUAL_CHAIN_ID, 42)
+
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, 1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, 100*1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, 1)
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, 64)
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, transactionExecutionTimeout)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, callExecutionTimeout)
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, blockCallExecutionTimeout)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, false)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, false)
+	return cfg
+}
+
+func ForVirtualMachineDeterminismSelfCheckTests(transactionExecutionBudget uint32, blockExecutionBudget uint32, blacklist bool) VirtualMachineConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(VIRTUAL_CHAIN_ID, 42)
+
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, transactionExecutionBudget)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, blockExecutionBudget)
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, 1)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, false)
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, 64)
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, 5*time.Second)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, true)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, blacklist)
+	return cfg
+}
+
+func ForVirtualMachinePreloadTests(preloadDeployedContractsTimeout time.Duration) VirtualMachineConfig {
+	cfg := emptyConfig()
+
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, 1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, 100*1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, 1)
+	cfg.SetDuration(VIRTUAL_MACHINE_PRELOAD_DEPLOYED_CONTRACTS_TIMEOUT, preloadDeployedContractsTimeout)
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, 64)
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, 5*time.Second)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, false)
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, false)
+	return cfg
+}
+
+func ForEthereumCrosschainConnectorTests(endpoint string, callTimeout time.Duration, callCacheSize uint32, minConfirmations uint32) EthereumCrosschainConnectorConfig {
+	cfg := emptyConfig()
+
+	cfg.SetString(ETHEREUM_ENDPOINT, endpoint)
+	cfg.SetDuration(ETHEREUM_CALL_TIMEOUT, callTimeout)
+	cfg.SetUint32(ETHEREUM_CALL_CACHE_SIZE, callCacheSize)
+	cfg.SetUint32(ETHEREUM_MIN_CONFIRMATIONS, minConfirmations)
+	return cfg
+}
+
 func ForPublicApiTests(virtualChain uint32, txTimeout time.Duration) PublicApiConfig {
 	cfg := emptyConfig()
 
 	cfg.SetUint32(VIRTUAL_CHAIN_ID, virtualChain)
 	cfg.SetDuration(PUBLIC_API_SEND_TRANSACTION_TIMEOUT, txTimeout)
+	cfg.SetBool(PUBLIC_API_TRACING_ENABLED, true)
+	cfg.SetUint32(PUBLIC_API_TRACING_CONCURRENCY, 1)
+	cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, 5)
 	return cfg
 }
 
@@ -66,6 +168,7 @@ func ForTransactionPoolTests(sizeLimit uint32, keyPair *keys.Ed25519KeyPair) Tra
 	cfg.SetDuration(BLOCK_TRACKER_GRACE_TIMEOUT, 100*time.Millisecond)
 	cfg.SetUint32(BLOCK_TRACKER_GRACE_DISTANCE, 5)
 	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES, sizeLimit)
+	cfg.SetUint32(TRANSACTION_POOL_CANCELLED_POOL_SIZE, 10)
 	cfg.SetDuration(TRANSACTION_POOL_TRANSACTION_EXPIRATION_WINDOW, 30*time.Minute)
 	cfg.SetDuration(TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT, 3*time.Minute)
 	cfg.SetDuration(TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL, 10*time.Millisecond)
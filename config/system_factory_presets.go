@@ -15,10 +15,14 @@ func defaultProductionConfig() mutableNodeConfig {
 	cfg.SetUint32(GOSSIP_LISTEN_PORT, 4400)
 	cfg.SetActiveConsensusAlgo(consensus.CONSENSUS_ALGO_TYPE_BENCHMARK_CONSENSUS)
 	cfg.SetDuration(BENCHMARK_CONSENSUS_RETRY_INTERVAL, 2*time.Second)
+	cfg.SetBool(BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED, false) // a single constant leader unless a test or staging network turns it on
+	cfg.SetDuration(BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT, 30*time.Second)
+	cfg.SetDuration(LEAN_HELIX_CONSENSUS_RETRY_INTERVAL, 4*time.Second)  // a leader that does not commit a block in this round time is replaced
 	cfg.SetDuration(CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME, 1*time.Second) // this is the time between empty blocks when no transactions, need to be large so we don't close infinite blocks on idle
 	cfg.SetUint32(CONSENSUS_REQUIRED_QUORUM_PERCENTAGE, 66)
 	cfg.SetUint32(CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK, 10)
 	cfg.SetUint32(CONSENSUS_CONTEXT_MAXIMUM_TRANSACTIONS_IN_BLOCK, 100)
+	cfg.SetDuration(CONSENSUS_CONTEXT_SYSTEM_TIMESTAMP_ALLOWED_JITTER, 2*time.Second) // how far the timestamp of a proposed block may be from our clock
 	cfg.SetUint32(CONSENSUS_MINIMUM_COMMITTEE_SIZE, 4)
 	cfg.SetUint32(BLOCK_TRACKER_GRACE_DISTANCE, 3)
 	cfg.SetDuration(BLOCK_TRACKER_GRACE_TIMEOUT, 100*time.Millisecond)
@@ -27,11 +31,25 @@ func defaultProductionConfig() mutableNodeConfig {
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_RESPONSE_TIMEOUT, 3*time.Second)
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_CHUNKS_TIMEOUT, 5*time.Second)
 	cfg.SetDuration(PUBLIC_API_SEND_TRANSACTION_TIMEOUT, 30*time.Second)
+	cfg.SetBool(PUBLIC_API_TRACING_ENABLED, false) // re-executes blocks on demand, only for nodes that serve developers
+	cfg.SetUint32(PUBLIC_API_TRACING_CONCURRENCY, 1)
 	cfg.SetDuration(BLOCK_TRANSACTION_RECEIPT_QUERY_GRACE_START, 5*time.Second)
 	cfg.SetDuration(BLOCK_TRANSACTION_RECEIPT_QUERY_GRACE_END, 5*time.Second)
 	cfg.SetDuration(BLOCK_TRANSACTION_RECEIPT_QUERY_EXPIRATION_WINDOW, 3*time.Minute)
 	cfg.SetUint32(STATE_STORAGE_HISTORY_SNAPSHOT_NUM, 5)
+	cfg.SetUint32(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_BUDGET, 1000000)
+	cfg.SetUint32(VIRTUAL_MACHINE_BLOCK_EXECUTION_BUDGET, 100*1000000) // room for a full block of transactions that exhaust their budget
+	cfg.SetUint32(VIRTUAL_MACHINE_EXECUTION_CONCURRENCY, 1)            // transactions of a block are executed serially
+	cfg.SetDuration(VIRTUAL_MACHINE_PRELOAD_DEPLOYED_CONTRACTS_TIMEOUT, 5*time.Minute)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, false) // existing chains have no subscription in _GlobalPreOrder
+	cfg.SetUint32(VIRTUAL_MACHINE_MAXIMUM_CALL_DEPTH, 64)     // nested contract calls, bounded so recursion can't exhaust the goroutine stack
+	cfg.SetDuration(VIRTUAL_MACHINE_TRANSACTION_EXECUTION_TIMEOUT, 1*time.Second)
+	cfg.SetDuration(VIRTUAL_MACHINE_CALL_EXECUTION_TIMEOUT, 500*time.Millisecond) // a single contract call can't use up the whole transaction
+	cfg.SetDuration(VIRTUAL_MACHINE_BLOCK_CALL_EXECUTION_TIMEOUT, 30*time.Second) // a block call running longer is a fault of the node, well above the executor cpu limit
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_ENABLED, false)            // doubles the cost of processing blocks, meant for staging
+	cfg.SetBool(VIRTUAL_MACHINE_DETERMINISM_SELF_CHECK_BLACKLIST, false)
 	cfg.SetUint32(TRANSACTION_POOL_PENDING_POOL_SIZE_IN_BYTES, 20*1024*1024)
+	cfg.SetUint32(TRANSACTION_POOL_CANCELLED_POOL_SIZE, 10000) // cancellations of transactions not seen yet, a cancelled txhash needn't exist
 	cfg.SetDuration(TRANSACTION_POOL_TRANSACTION_EXPIRATION_WINDOW, 30*time.Minute)
 	cfg.SetDuration(TRANSACTION_POOL_FUTURE_TIMESTAMP_GRACE_TIMEOUT, 5*time.Second)
 	cfg.SetDuration(TRANSACTION_POOL_PENDING_POOL_CLEAR_EXPIRED_INTERVAL, 10*time.Second)
@@ -42,6 +60,14 @@ func defaultProductionConfig() mutableNodeConfig {
 	cfg.SetDuration(GOSSIP_NETWORK_TIMEOUT, 30*time.Second)
 	cfg.SetDuration(METRICS_REPORT_INTERVAL, 30*time.Second)
 	cfg.SetString(PROCESSOR_ARTIFACT_PATH, filepath.Join(GetProjectSourceTmpPath(), "processor-artifacts"))
+	cfg.SetBool(PROCESSOR_JAVASCRIPT_ENABLED, false)  // experimental, decides which contracts run so every node of a chain must agree on it
+	cfg.SetString(PROCESSOR_NATIVE_EXECUTOR_PATH, "") // deployed contracts are loaded as plugins into the node process
+	cfg.SetUint32(PROCESSOR_NATIVE_EXECUTOR_CALL_MEMORY_LIMIT_IN_BYTES, 256*1024*1024)
+	cfg.SetUint32(PROCESSOR_NATIVE_EXECUTOR_CALL_CPU_LIMIT_IN_SECONDS, 5)
+	cfg.SetString(ETHEREUM_ENDPOINT, "") // ethereum calls fail until a node is given a json rpc endpoint
+	cfg.SetDuration(ETHEREUM_CALL_TIMEOUT, 10*time.Second)
+	cfg.SetUint32(ETHEREUM_CALL_CACHE_SIZE, 1000)
+	cfg.SetUint32(ETHEREUM_MIN_CONFIRMATIONS, 12) // must be the same across the federation so all nodes reach the same verdict
 	return cfg
 }
 
@@ -60,6 +86,7 @@ func ForE2E(processorArtifactPath string) mutableNodeConfig {
 	cfg := defaultProductionConfig()
 
 	cfg.SetDuration(BENCHMARK_CONSENSUS_RETRY_INTERVAL, 250*time.Millisecond)
+	cfg.SetDuration(LEAN_HELIX_CONSENSUS_RETRY_INTERVAL, 1000*time.Millisecond)
 	cfg.SetDuration(CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME, 100*time.Millisecond) // this is the time between empty blocks when no transactions, need to be large so we don't close infinite blocks on idle
 	cfg.SetDuration(PUBLIC_API_SEND_TRANSACTION_TIMEOUT, 10*time.Second)
 	cfg.SetUint32(CONSENSUS_CONTEXT_MINIMUM_TRANSACTIONS_IN_BLOCK, 1)
@@ -67,6 +94,8 @@ func ForE2E(processorArtifactPath string) mutableNodeConfig {
 	cfg.SetUint32(TRANSACTION_POOL_PROPAGATION_BATCH_SIZE, 100)
 	cfg.SetDuration(TRANSACTION_POOL_PROPAGATION_BATCHING_TIMEOUT, 50*time.Millisecond)
 	cfg.SetDuration(BLOCK_SYNC_INTERVAL, 1000*time.Millisecond)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, false) // test chains start without a subscription
+	cfg.SetBool(PROCESSOR_JAVASCRIPT_ENABLED, true)
 
 	if processorArtifactPath != "" {
 		cfg.SetString(PROCESSOR_ARTIFACT_PATH, processorArtifactPath)
@@ -88,6 +117,7 @@ func ForAcceptanceTests(
 	cfg.OverrideNodeSpecificValues(federationNodes, gossipPeers, 0, nodePublicKey, nodePrivateKey, constantConsensusLeader, activeConsensusAlgo)
 
 	cfg.SetDuration(BENCHMARK_CONSENSUS_RETRY_INTERVAL, 1*time.Millisecond)
+	cfg.SetDuration(LEAN_HELIX_CONSENSUS_RETRY_INTERVAL, 200*time.Millisecond)
 	cfg.SetDuration(CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME, 10*time.Millisecond)
 	cfg.SetUint32(CONSENSUS_REQUIRED_QUORUM_PERCENTAGE, 100)
 	cfg.SetDuration(BLOCK_TRACKER_GRACE_TIMEOUT, 50*time.Millisecond)
@@ -100,6 +130,8 @@ func ForAcceptanceTests(
 	cfg.SetDuration(BLOCK_SYNC_INTERVAL, 100*time.Millisecond)
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_RESPONSE_TIMEOUT, 15*time.Millisecond)
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_CHUNKS_TIMEOUT, 15*time.Millisecond)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, false) // test chains start without a subscription
+	cfg.SetBool(PROCESSOR_JAVASCRIPT_ENABLED, true)
 	return cfg
 }
 
@@ -116,6 +148,7 @@ func ForGamma(
 	cfg.OverrideNodeSpecificValues(federationNodes, gossipPeers, 0, nodePublicKey, nodePrivateKey, constantConsensusLeader, activeConsensusAlgo)
 
 	cfg.SetDuration(BENCHMARK_CONSENSUS_RETRY_INTERVAL, 1000*time.Millisecond)
+	cfg.SetDuration(LEAN_HELIX_CONSENSUS_RETRY_INTERVAL, 1000*time.Millisecond)
 	cfg.SetDuration(CONSENSUS_CONTEXT_MINIMAL_BLOCK_TIME, 500*time.Millisecond) // this is the time between empty blocks when no transactions, need to be large so we don't close infinite blocks on idle
 	cfg.SetUint32(CONSENSUS_REQUIRED_QUORUM_PERCENTAGE, 100)
 	cfg.SetDuration(BLOCK_TRACKER_GRACE_TIMEOUT, 100*time.Millisecond)
@@ -128,5 +161,9 @@ func ForGamma(
 	cfg.SetDuration(BLOCK_SYNC_INTERVAL, 2500*time.Millisecond)
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_RESPONSE_TIMEOUT, 15*time.Millisecond)
 	cfg.SetDuration(BLOCK_SYNC_COLLECT_CHUNKS_TIMEOUT, 15*time.Millisecond)
+	cfg.SetBool(VIRTUAL_MACHINE_SUBSCRIPTION_ENFORCED, false) // test chains start without a subscription
+	cfg.SetBool(PROCESSOR_JAVASCRIPT_ENABLED, true)
+	cfg.SetBool(PUBLIC_API_TRACING_ENABLED, true)
+	cfg.SetUint32(PUBLIC_API_TRACING_CONCURRENCY, 4)
 	return cfg
 }
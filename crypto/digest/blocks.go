@@ -1,9 +1,12 @@
 package digest
 
 import (
+	"bytes"
+	"github.com/orbs-network/membuffers/go"
 	"github.com/orbs-network/orbs-network-go/crypto/hash"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"sort"
 )
 
 func CalcTransactionsBlockHash(transactionsBlock *protocol.TransactionsBlockContainer) primitives.Sha256 {
@@ -13,3 +16,53 @@ func CalcTransactionsBlockHash(transactionsBlock *protocol.TransactionsBlockCont
 func CalcResultsBlockHash(resultsBlock *protocol.ResultsBlockContainer) primitives.Sha256 {
 	return hash.CalcSha256(resultsBlock.Header.Raw())
 }
+
+// block hashes only cover the headers, so the headers commit to the content of the blocks through these,
+// the roots are a hash of the ordered list rather than a merkle tree (there are no proofs of single entries yet)
+func CalcTransactionsRootHash(signedTransactions []*protocol.SignedTransaction) primitives.MerkleSha256 {
+	data := make([]byte, 0, len(signedTransactions)*hash.SHA256_HASH_SIZE_BYTES)
+	for _, signedTransaction := range signedTransactions {
+		data = append(data, hash.CalcSha256(signedTransaction.Raw())...)
+	}
+	return primitives.MerkleSha256(hash.CalcSha256(data))
+}
+
+func CalcReceiptsRootHash(receipts []*protocol.TransactionReceipt) primitives.MerkleSha256 {
+	data := make([]byte, 0, len(receipts)*hash.SHA256_HASH_SIZE_BYTES)
+	for _, receipt := range receipts {
+		data = append(data, hash.CalcSha256(receipt.Raw())...)
+	}
+	return primitives.MerkleSha256(hash.CalcSha256(data))
+}
+
+// the virtual machine does not order the state diff, so records are hashed sorted by contract and key
+func CalcStateDiffHash(contractStateDiffs []*protocol.ContractStateDiff) primitives.Sha256 {
+	var records [][]byte
+	for _, contractStateDiff := range contractStateDiffs {
+		for i := contractStateDiff.StateDiffsIterator(); i.HasNext(); {
+			record := i.NextStateDiffs()
+			records = append(records, stateDiffRecordBytes(contractStateDiff.ContractName(), record.Key(), record.Value()))
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		return bytes.Compare(records[i], records[j]) < 0
+	})
+
+	var data []byte
+	for _, record := range records {
+		data = append(data, record...)
+	}
+	return hash.CalcSha256(data)
+}
+
+// every part is length prefixed so different records can't encode the same
+func stateDiffRecordBytes(contractName primitives.ContractName, key []byte, value []byte) []byte {
+	res := make([]byte, 0, 12+len(contractName)+len(key)+len(value))
+	for _, part := range [][]byte{[]byte(contractName), key, value} {
+		length := make([]byte, 4)
+		membuffers.WriteUint32(length, uint32(len(part)))
+		res = append(res, length...)
+		res = append(res, part...)
+	}
+	return res
+}
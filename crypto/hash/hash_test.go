@@ -1,6 +1,7 @@
 package hash_test
 
 import (
+	"encoding/hex"
 	"github.com/orbs-network/orbs-network-go/crypto/hash"
 	"testing"
 )
@@ -10,6 +11,7 @@ var someData = []byte("testing")
 const (
 	ExpectedSha256         = "cf80cd8aed482d5d1527d7dc72fceff84e6326592848447d2dc0b0e87dfc9a90"
 	ExpectedSha256Ripmd160 = "1acb19a469206161ed7e5ed9feb996a6e24be441"
+	ExpectedKeccak256      = "5f16f4c7f149ac4f9510d9cf8cf384038ad348b3bcdc01915f95de12df9d1b02"
 )
 
 func TestCalcSha256(t *testing.T) {
@@ -26,6 +28,13 @@ func TestCalcRipmd160Sha256(t *testing.T) {
 	}
 }
 
+func TestCalcKeccak256(t *testing.T) {
+	h := hash.CalcKeccak256(someData)
+	if hex.EncodeToString(h) != ExpectedKeccak256 {
+		t.Errorf("keccak256 failed expected %s got %x", ExpectedKeccak256, h)
+	}
+}
+
 func BenchmarkCalcSha256(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		hash.CalcSha256(someData)
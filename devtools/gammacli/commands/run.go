@@ -83,7 +83,7 @@ func (r *CommandRunner) HandleRunCommand(args []string) (string, error) {
 	hostPtr := flagSet.String("host", "http://localhost:8080", "<http://..../api>")
 
 	if err := flagSet.Parse(args[2:]); err != nil {
-		return "", errors.Wrapf(err , "flag issues")
+		return "", errors.Wrapf(err, "flag issues")
 	}
 
 	runType := args[0]
@@ -100,6 +100,12 @@ func (r *CommandRunner) HandleRunCommand(args []string) (string, error) {
 		return "", err
 	}
 
+	if runType == "send" || runType == "call" {
+		if err := validateAgainstContractAbi(tx, *hostPtr); err != nil {
+			return "", err
+		}
+	}
+
 	switch runType {
 	case "send":
 		keyPair, err := readKeyPair(*publicKeyPtr, *privateKeyPtr)
@@ -125,6 +131,15 @@ func (r *CommandRunner) HandleRunCommand(args []string) (string, error) {
 	}
 }
 
+// nodes that can't describe the contract (or don't serve abis at all) leave the transaction to be checked on execution
+func validateAgainstContractAbi(tx *gammacli.JSONTransaction, host string) error {
+	abi, err := gammacli.GetContractAbi(tx.ContractName, host, false)
+	if err != nil {
+		return nil
+	}
+	return gammacli.ValidateJSONTransaction(tx, abi)
+}
+
 func readKeyPair(publicKey, privateKey string) (*keys.Ed25519KeyPair, error) {
 	if publicKey != "" && privateKey != "" {
 		if keyPair, err := getKeypairFromFlags(publicKey, privateKey); err != nil {
@@ -3,6 +3,7 @@ package gammacli
 import (
 	"bytes"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/crypto/keys"
@@ -160,6 +161,28 @@ func convertMethodArgument(arg *protocol.MethodArgument) JSONMethodArgument {
 	return methodArg
 }
 
+// checks the transaction against the contract abi so a mistyped call is caught before it is sent
+func ValidateJSONTransaction(tx *JSONTransaction, abi *GetContractAbiOutput) error {
+	if !abi.Described {
+		return nil
+	}
+	for _, method := range abi.Methods {
+		if method.Name != tx.MethodName {
+			continue
+		}
+		if len(tx.Arguments) != len(method.InputTypes) {
+			return errors.Errorf("method '%s' takes %d args but the transaction has %d", method.Name, len(method.InputTypes), len(tx.Arguments))
+		}
+		for i, arg := range tx.Arguments {
+			if arg.Type != method.InputTypes[i] {
+				return errors.Errorf("method '%s' expects arg %d to be %s but it has %s", method.Name, i, method.InputTypes[i], arg.Type)
+			}
+		}
+		return nil
+	}
+	return errors.Errorf("contract '%s' has no external method '%s'", abi.ContractName, tx.MethodName)
+}
+
 func SendTransaction(transferJson *JSONTransaction, keyPair *keys.Ed25519KeyPair, serverUrl string, logVerbose bool) (*SendTransactionOutput, error) {
 	tx, err := ConvertAndSignTransaction(transferJson, keyPair)
 	if err != nil {
@@ -219,3 +242,44 @@ func CallMethod(transferJson *JSONTransaction, serverUrl string, logVerbose bool
 
 	return ConvertCallMethodOutput(client.CallMethodResponseReader(readBytes)), err
 }
+
+func GetContractAbi(contractName string, serverUrl string, logVerbose bool) (*GetContractAbiOutput, error) {
+	request, err := json.Marshal(&getContractAbiRequest{
+		VirtualChainId: uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID), //TODO move to JSONTransaction
+		ContractName:   contractName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if logVerbose {
+		log.GetLogger().Info("getting contract abi", log.String("contract", contractName))
+	}
+
+	res, err := http.Post(serverUrl+"/api/v1/get-contract-abi", "application/json", bytes.NewReader(request))
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("got unexpected http status code %d", res.StatusCode)
+	}
+
+	readBytes, err := ioutil.ReadAll(res.Body)
+	defer res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	output := &GetContractAbiOutput{}
+	err = json.Unmarshal(readBytes, output)
+	if err != nil {
+		return nil, errors.Wrap(err, "got invalid contract abi response")
+	}
+	return output, nil
+}
+
+type getContractAbiRequest struct {
+	VirtualChainId uint32 `json:"virtualChainId"`
+	ContractName   string `json:"contractName"`
+}
@@ -251,7 +251,30 @@ func TestConvertCallMethodOutput(t *testing.T) {
 	require.EqualValues(t, "bar", actualArg.Value, "argument value mismatched")
 }
 
-//TODO dedup from virtual machine (extract to crypto package?)
+func TestValidateJSONTransaction(t *testing.T) {
+	abi := &GetContractAbiOutput{
+		ContractName: "BenchmarkContract",
+		Described:    true,
+		Methods: []JSONContractMethod{
+			{Name: "add", Access: "read-only", InputTypes: []string{"uint64", "uint64"}, OutputTypes: []string{"uint64"}},
+		},
+	}
+	tx := func(methodName string, argTypes ...string) *JSONTransaction {
+		res := &JSONTransaction{ContractName: "BenchmarkContract", MethodName: methodName}
+		for _, argType := range argTypes {
+			res.Arguments = append(res.Arguments, JSONMethodArgument{Type: argType})
+		}
+		return res
+	}
+
+	require.NoError(t, ValidateJSONTransaction(tx("add", "uint64", "uint64"), abi), "matching call should be valid")
+	require.Error(t, ValidateJSONTransaction(tx("sub", "uint64", "uint64"), abi), "unknown method should be invalid")
+	require.Error(t, ValidateJSONTransaction(tx("add", "uint64"), abi), "missing arg should be invalid")
+	require.Error(t, ValidateJSONTransaction(tx("add", "uint64", "string"), abi), "mistyped arg should be invalid")
+	require.NoError(t, ValidateJSONTransaction(tx("sub", "string"), &GetContractAbiOutput{ContractName: "BenchmarkContract"}), "contract without a described abi should not be validated")
+}
+
+// TODO dedup from virtual machine (extract to crypto package?)
 func verifyEd25519Signer(signedTransaction *protocol.SignedTransaction) bool {
 	signerPublicKey := signedTransaction.Transaction().Signer().Eddsa().SignerPublicKey()
 	txHash := digest.CalcTxHash(signedTransaction.Transaction())
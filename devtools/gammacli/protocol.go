@@ -39,6 +39,25 @@ type CallMethodOutput struct {
 	BlockTimestamp  primitives.TimestampNano
 }
 
+// types are named like JSONMethodArgument types
+type JSONContractMethod struct {
+	Name        string   `json:"name"`
+	Access      string   `json:"access"`
+	InputTypes  []string `json:"inputTypes"`
+	OutputTypes []string `json:"outputTypes"`
+}
+
+// contracts that are not described (like untyped javascript contracts) have no methods to validate against
+type GetContractAbiOutput struct {
+	RequestStatus   string               `json:"requestStatus"`
+	ContractName    string               `json:"contractName"`
+	PermissionScope string               `json:"permissionScope"`
+	Described       bool                 `json:"described"`
+	Methods         []JSONContractMethod `json:"methods"`
+	BlockHeight     uint64               `json:"blockHeight"`
+	BlockTimestamp  uint64               `json:"blockTimestamp"`
+}
+
 func (ma *JSONMethodArgument) String() string {
 	var argumentValue string
 	switch ma.Type {
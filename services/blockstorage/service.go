@@ -186,6 +186,40 @@ func (s *service) GetResultsBlockHeader(ctx context.Context, input *services.Get
 	return nil, err
 }
 
+// reading a whole block pair is not part of the pinned spec, tracing reaches it on the block storage through this interface
+type BlockPairReader interface {
+	GetBlockPair(ctx context.Context, input *GetBlockPairInput) (*GetBlockPairOutput, error)
+}
+
+type GetBlockPairInput struct {
+	BlockHeight primitives.BlockHeight
+}
+
+type GetBlockPairOutput struct {
+	BlockPair *protocol.BlockPairContainer
+}
+
+func (s *service) GetBlockPair(ctx context.Context, input *GetBlockPairInput) (*GetBlockPairOutput, error) {
+	err := s.persistence.GetBlockTracker().WaitForBlock(ctx, input.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	txBlock, err := s.persistence.GetTransactionsBlock(input.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	rxBlock, err := s.persistence.GetResultsBlock(input.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+	return &GetBlockPairOutput{
+		BlockPair: &protocol.BlockPairContainer{
+			TransactionsBlock: txBlock,
+			ResultsBlock:      rxBlock,
+		},
+	}, nil
+}
+
 func (s *service) createEmptyTransactionReceiptResult(ctx context.Context) (*services.GetTransactionReceiptOutput, error) {
 	out, err := s.GetLastCommittedBlockHeight(ctx, &services.GetLastCommittedBlockHeightInput{})
 	if err != nil {
@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -169,3 +170,22 @@ func TestReturnResultsBlockHeaderFromNearFutureFailsWhenContextEnds(t *testing.T
 		require.EqualError(t, err, "aborted while waiting for block at height 5: context canceled", "expect a timeout as the requested block height never reached")
 	})
 }
+
+func TestReturnBlockPair(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		harness := newBlockStorageHarness().
+			withSyncBroadcast(1).
+			withCommitStateDiff(1).
+			withValidateConsensusAlgos(1).
+			start(ctx)
+
+		block := builders.BlockPair().WithTransactions(3).Build()
+		harness.commitBlock(ctx, block)
+
+		output, err := harness.blockStorage.(blockstorage.BlockPairReader).GetBlockPair(ctx, &blockstorage.GetBlockPairInput{BlockHeight: 1})
+
+		require.NoError(t, err, "this is a happy flow test")
+		require.EqualValues(t, block.TransactionsBlock.SignedTransactions, output.BlockPair.TransactionsBlock.SignedTransactions, "block transactions should be as committed")
+		require.EqualValues(t, block.ResultsBlock.TransactionReceipts, output.BlockPair.ResultsBlock.TransactionReceipts, "block receipts should be as committed")
+	})
+}
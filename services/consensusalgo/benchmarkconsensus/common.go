@@ -79,7 +79,7 @@ func (s *service) validateBlockConsensus(blockPair *protocol.BlockPairContainer,
 
 	// block proof
 	blockProof := blockPair.ResultsBlock.BlockProof.BenchmarkConsensus()
-	if !blockProof.Sender().SenderPublicKey().Equal(s.config.ConstantConsensusLeader()) {
+	if !s.isValidBlockSigner(blockPair, prevCommittedBlockPair) {
 		return errors.Errorf("block proof not from leader: %s", blockProof.Sender().SenderPublicKey())
 	}
 	signedData := s.signedDataForBlockProof(blockPair)
@@ -0,0 +1,167 @@
+package benchmarkconsensus
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"sort"
+	"time"
+)
+
+func (s *service) designatedLeader() primitives.Ed25519PublicKey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.leaderUnderMutex
+}
+
+func (s *service) isLeader() bool {
+	return s.designatedLeader().Equal(s.config.NodePublicKey())
+}
+
+func (s *service) setDesignatedLeader(leader primitives.Ed25519PublicKey) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.leaderUnderMutex = leader
+}
+
+// without failover only the constant leader closes blocks, with it a block is closed in some view of its height by the
+// leader designated for that view, which happens no sooner than that many failover timeouts after the previous block
+func (s *service) isValidBlockSigner(blockPair *protocol.BlockPairContainer, prevCommittedBlockPair *protocol.BlockPairContainer) bool {
+	signer := blockPair.ResultsBlock.BlockProof.BenchmarkConsensus().Sender().SenderPublicKey()
+	if !s.config.BenchmarkConsensusLeaderFailoverEnabled() {
+		return signer.Equal(s.config.ConstantConsensusLeader())
+	}
+
+	// without the previous block the view is unknown, so only the leader we follow is accepted
+	if prevCommittedBlockPair == nil {
+		return signer.Equal(s.designatedLeader())
+	}
+
+	prevLeader := prevCommittedBlockPair.ResultsBlock.BlockProof.BenchmarkConsensus().Sender().SenderPublicKey()
+	view, found := s.viewOfLeader(prevLeader, signer)
+	if !found {
+		return false
+	}
+	return view <= s.viewsReachedSince(prevCommittedBlockPair.TransactionsBlock.Header.Timestamp(), blockPair.TransactionsBlock.Header.Timestamp())
+}
+
+// every node waits for commits from the designated leader, if none arrive in time it moves on to the next node in sorted federation order,
+// nodes don't agree on the view change so failover is only meant for test and staging networks: a leader which comes back after
+// the network failed over (from a partition or a long pause) already closed a block of that height on its own, it keeps it and
+// stays forked until it is resynced, and a node which missed the block of the new leader may take the one of the old leader instead
+// (view 0 is always valid), see TestBenchmarkConsensusNetworkKeepsFollowingNewLeaderWhenOldLeaderComesBackMidHeight
+func (s *service) leaderFailoverRunLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("leader failover run loop terminating with context")
+			return
+		case <-s.commitsFromLeader:
+			continue
+		case <-time.After(s.config.BenchmarkConsensusLeaderFailoverTimeout()):
+			if s.isLeader() {
+				continue
+			}
+			failedLeader := s.designatedLeader()
+			leader := s.leaderForView(failedLeader, 1)
+			s.setDesignatedLeader(leader)
+			s.logger.Info("leader did not commit in time, failing over to next leader", log.Stringable("failed-leader", failedLeader), log.Stringable("leader", leader))
+		}
+	}
+}
+
+func (s *service) sortedFederationPublicKeys() []primitives.Ed25519PublicKey {
+	federationNodes := s.config.FederationNodes(0)
+	publicKeys := make([]primitives.Ed25519PublicKey, 0, len(federationNodes))
+	for _, federationNode := range federationNodes {
+		publicKeys = append(publicKeys, federationNode.NodePublicKey())
+	}
+	sort.Slice(publicKeys, func(i, j int) bool {
+		return bytes.Compare(publicKeys[i], publicKeys[j]) < 0
+	})
+	return publicKeys
+}
+
+// views of a height count in sorted federation order from the leader which closed the previous block, view 0 is that leader itself
+func (s *service) leaderForView(prevLeader primitives.Ed25519PublicKey, view int) primitives.Ed25519PublicKey {
+	publicKeys := s.sortedFederationPublicKeys()
+	for i, publicKey := range publicKeys {
+		if publicKey.Equal(prevLeader) {
+			return publicKeys[(i+view)%len(publicKeys)]
+		}
+	}
+	return publicKeys[0]
+}
+
+func (s *service) viewOfLeader(prevLeader primitives.Ed25519PublicKey, leader primitives.Ed25519PublicKey) (int, bool) {
+	publicKeys := s.sortedFederationPublicKeys()
+	prevIndex, index := -1, -1
+	for i, publicKey := range publicKeys {
+		if publicKey.Equal(prevLeader) {
+			prevIndex = i
+		}
+		if publicKey.Equal(leader) {
+			index = i
+		}
+	}
+	if prevIndex == -1 || index == -1 {
+		return 0, false
+	}
+	return (index - prevIndex + len(publicKeys)) % len(publicKeys), true
+}
+
+// every failover timeout that passed between the previous block and this one moves the height to its next view
+func (s *service) viewsReachedSince(prevTimestamp primitives.TimestampNano, timestamp primitives.TimestampNano) int {
+	if timestamp <= prevTimestamp {
+		return 0
+	}
+	return int(time.Duration(timestamp-prevTimestamp) / s.config.BenchmarkConsensusLeaderFailoverTimeout())
+}
+
+// commits are taken from the designated leader, or from the leader designated for the view of the block following our
+// last committed block, which means we missed a failover (for example while restarting), in that case we follow it from now on
+func (s *service) nonLeaderFollowLeaderOfCommit(ctx context.Context, blockPair *protocol.BlockPairContainer, lastCommittedBlockHeight primitives.BlockHeight, lastCommittedBlock *protocol.BlockPairContainer) error {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	var prevCommittedBlockPair *protocol.BlockPairContainer = nil
+	if blockPair.TransactionsBlock.Header.BlockHeight() == lastCommittedBlockHeight+1 {
+		prevCommittedBlockPair = lastCommittedBlock
+	}
+	err := s.validateBlockConsensus(blockPair, prevCommittedBlockPair)
+	if err != nil {
+		return err
+	}
+
+	signer := blockPair.ResultsBlock.BlockProof.BenchmarkConsensus().Sender().SenderPublicKey()
+	if !signer.Equal(s.designatedLeader()) {
+		s.setDesignatedLeader(signer)
+		logger.Info("following leader designated for the view of the block", log.Stringable("leader", signer), log.BlockHeight(blockPair.TransactionsBlock.Header.BlockHeight()))
+	}
+
+	select {
+	case s.commitsFromLeader <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// a node that becomes the leader continues from its own last committed block, which was already voted on under the
+// failed leader, so it proposes the next block right away
+func (s *service) leaderTakeOver(ctx context.Context) error {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
+	if lastCommittedBlock == nil {
+		logger.Info("taking over as leader from genesis")
+		return s.setLastCommittedBlock(s.leaderGenerateGenesisBlock(), nil)
+	}
+
+	logger.Info("taking over as leader", log.BlockHeight(lastCommittedBlockHeight))
+	s.lastSuccessfullyVotedBlock = lastCommittedBlockHeight
+	return nil
+}
@@ -18,12 +18,36 @@ import (
 )
 
 func (s *service) leaderConsensusRoundRunLoop(parent context.Context) {
-	s.lastCommittedBlockUnderMutex = s.leaderGenerateGenesisBlock()
+	wasLeader := s.isLeader()
+	if wasLeader {
+		s.lastCommittedBlockUnderMutex = s.leaderGenerateGenesisBlock()
+	}
 	for {
 		start := time.Now()
 		ctx := trace.NewContext(parent, "BenchmarkConsensus.Tick")
 		logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
 
+		// only with failover, a node which is not the leader waits until it's designated
+		isLeader := s.isLeader()
+		if !isLeader {
+			wasLeader = false
+			select {
+			case <-ctx.Done():
+				logger.Info("consensus round run loop terminating with context")
+				return
+			case <-s.successfullyVotedBlocks: // a vote counted just before we stopped being the leader
+				continue
+			case <-time.After(s.config.BenchmarkConsensusRetryInterval()):
+				continue
+			}
+		}
+		if !wasLeader {
+			wasLeader = true
+			if err := s.leaderTakeOver(ctx); err != nil {
+				logger.Error("leader failed to take over", log.Error(err))
+			}
+		}
+
 		err := s.leaderConsensusRoundTick(ctx)
 		if err != nil {
 			logger.Info("consensus round tick failed", log.Error(err))
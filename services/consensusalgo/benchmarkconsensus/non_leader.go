@@ -16,6 +16,13 @@ import (
 func (s *service) nonLeaderHandleCommit(ctx context.Context, blockPair *protocol.BlockPairContainer) error {
 	lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
 
+	if s.config.BenchmarkConsensusLeaderFailoverEnabled() {
+		err := s.nonLeaderFollowLeaderOfCommit(ctx, blockPair, lastCommittedBlockHeight, lastCommittedBlock)
+		if err != nil {
+			return err
+		}
+	}
+
 	err := s.nonLeaderValidateBlock(blockPair, lastCommittedBlockHeight, lastCommittedBlock)
 	if err != nil {
 		return err
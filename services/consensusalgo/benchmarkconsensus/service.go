@@ -29,6 +29,8 @@ type Config interface {
 	ConstantConsensusLeader() primitives.Ed25519PublicKey
 	ActiveConsensusAlgo() consensus.ConsensusAlgoType
 	BenchmarkConsensusRetryInterval() time.Duration
+	BenchmarkConsensusLeaderFailoverEnabled() bool
+	BenchmarkConsensusLeaderFailoverTimeout() time.Duration
 	ConsensusRequiredQuorumPercentage() uint32
 }
 
@@ -39,10 +41,11 @@ type service struct {
 	logger           log.BasicLogger
 	config           Config
 
-	isLeader                bool
 	successfullyVotedBlocks chan primitives.BlockHeight // leader only
+	commitsFromLeader       chan struct{}               // non-leader only, resets the failover timeout
 
 	mutex                                           *sync.RWMutex
+	leaderUnderMutex                                primitives.Ed25519PublicKey
 	lastCommittedBlockUnderMutex                    *protocol.BlockPairContainer
 	lastSuccessfullyVotedBlock                      primitives.BlockHeight // leader only
 	lastCommittedBlockVotersUnderMutex              map[string]bool        // leader only
@@ -86,13 +89,14 @@ func NewBenchmarkConsensusAlgo(
 		logger:           logger,
 		config:           config,
 
-		isLeader:                   config.ConstantConsensusLeader().Equal(config.NodePublicKey()),
 		successfullyVotedBlocks:    make(chan primitives.BlockHeight), // leader only
+		commitsFromLeader:          make(chan struct{}, 1),            // non-leader only
 		lastSuccessfullyVotedBlock: blockHeightNone,                   // leader only
 
-		mutex: &sync.RWMutex{},
-		lastCommittedBlockVotersUnderMutex:              make(map[string]bool), // leader only
-		lastCommittedBlockVotersReachedQuorumUnderMutex: false,                 // leader only
+		mutex:                              &sync.RWMutex{},
+		leaderUnderMutex:                   config.ConstantConsensusLeader(),
+		lastCommittedBlockVotersUnderMutex: make(map[string]bool), // leader only
+		lastCommittedBlockVotersReachedQuorumUnderMutex: false, // leader only
 
 		metrics: newMetrics(metricFactory, config.BenchmarkConsensusRetryInterval(), config.BenchmarkConsensusRetryInterval()),
 	}
@@ -100,10 +104,18 @@ func NewBenchmarkConsensusAlgo(
 	gossip.RegisterBenchmarkConsensusHandler(s)
 	blockStorage.RegisterConsensusBlocksHandler(s)
 
-	if config.ActiveConsensusAlgo() == consensus.CONSENSUS_ALGO_TYPE_BENCHMARK_CONSENSUS && s.isLeader {
-		supervised.GoForever(ctx, logger, func() {
-			s.leaderConsensusRoundRunLoop(ctx)
-		})
+	if config.ActiveConsensusAlgo() == consensus.CONSENSUS_ALGO_TYPE_BENCHMARK_CONSENSUS {
+		// with failover every node may become the leader, so the run loop idles on non-leaders
+		if s.isLeader() || config.BenchmarkConsensusLeaderFailoverEnabled() {
+			supervised.GoForever(ctx, logger, func() {
+				s.leaderConsensusRoundRunLoop(ctx)
+			})
+		}
+		if config.BenchmarkConsensusLeaderFailoverEnabled() {
+			supervised.GoForever(ctx, logger, func() {
+				s.leaderFailoverRunLoop(ctx)
+			})
+		}
 	}
 
 	return s
@@ -114,14 +126,15 @@ func (s *service) HandleBlockConsensus(ctx context.Context, input *handlers.Hand
 }
 
 func (s *service) HandleBenchmarkConsensusCommit(ctx context.Context, input *gossiptopics.BenchmarkConsensusCommitInput) (*gossiptopics.EmptyOutput, error) {
-	if !s.isLeader {
+	// with failover a leader may receive commits from the node that replaced it while it was down
+	if !s.isLeader() || s.config.BenchmarkConsensusLeaderFailoverEnabled() {
 		return nil, s.nonLeaderHandleCommit(ctx, input.Message.BlockPair)
 	}
 	return nil, nil
 }
 
 func (s *service) HandleBenchmarkConsensusCommitted(ctx context.Context, input *gossiptopics.BenchmarkConsensusCommittedInput) (*gossiptopics.EmptyOutput, error) {
-	if s.isLeader {
+	if s.isLeader() {
 		return nil, s.leaderHandleCommittedVote(ctx, input.Message.Sender, input.Message.Status)
 	}
 	return nil, nil
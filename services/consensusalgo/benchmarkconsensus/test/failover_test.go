@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"testing"
+	"time"
+)
+
+// a node which is neither the leader nor one of the two to take over from it
+func followerKeyPair() *keys.Ed25519KeyPair {
+	for i := 1; i < NETWORK_SIZE; i++ {
+		keyPair := testKeys.Ed25519KeyPairForTests(i)
+		if !keyPair.PublicKey().Equal(leaderKeyPairForView(1).PublicKey()) && !keyPair.PublicKey().Equal(leaderKeyPairForView(2).PublicKey()) {
+			return keyPair
+		}
+	}
+	return nil
+}
+
+func TestNonLeaderWithLeaderFailoverTakesOverFromLastCommittedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessForNode(nextLeaderKeyPair(), 50*time.Millisecond)
+		b1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPair()).WithHeight(1).Build()
+
+		t.Log("Leader fails after committing height 1, next leader commits height 2")
+
+		h.expectNewBlockProposalRequestedAndSaved(2)
+		h.expectCommitBroadcastViaGossip(2, h.config.NodePublicKey())
+
+		h.createService(ctx)
+		err := h.handleBlockConsensus(ctx, handlers.HANDLE_BLOCK_CONSENSUS_MODE_UPDATE_ONLY, b1, nil)
+		if err != nil {
+			t.Fatal("handle block consensus (update only mode) should not fail:", err)
+		}
+
+		h.verifyNewBlockProposalRequestedAndSaved(t)
+		h.verifyCommitBroadcastViaGossip(t)
+	})
+}
+
+func TestNonLeaderWithLeaderFailoverFollowsNewLeaderWhichIsAhead(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessForNode(followerKeyPair(), 1*time.Minute)
+		h.createService(ctx)
+
+		t.Log("Leader commits height 1, confirm height 1")
+
+		created := time.Now()
+		b1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPair()).WithHeight(1).WithBlockCreated(created).Build()
+		h.expectCommitSaveAndReply(b1, 1, leaderKeyPair().PublicKey(), h.config.NodePublicKey())
+
+		h.receivedCommitViaGossip(ctx, b1)
+		h.verifyCommitSaveAndReply(t)
+
+		t.Log("Next leader took over after the failover timeout and commits height 2, confirm height 2 to next leader")
+
+		b2 := builders.BlockPair().WithBenchmarkConsensusBlockProof(nextLeaderKeyPair()).WithHeight(2).WithPrevBlockHash(b1).WithBlockCreated(created.Add(1 * time.Minute)).Build()
+		h.expectCommitSaveAndReply(b2, 2, nextLeaderKeyPair().PublicKey(), h.config.NodePublicKey())
+
+		h.receivedCommitViaGossip(ctx, b2)
+		h.verifyCommitSaveAndReply(t)
+	})
+}
+
+func TestNonLeaderWithLeaderFailoverIgnoresNextLeaderCommitBeforeFailoverTimeout(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessForNode(followerKeyPair(), 1*time.Minute)
+		h.createService(ctx)
+
+		t.Log("Leader commits height 1, confirm height 1")
+
+		created := time.Now()
+		b1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPair()).WithHeight(1).WithBlockCreated(created).Build()
+		h.expectCommitSaveAndReply(b1, 1, leaderKeyPair().PublicKey(), h.config.NodePublicKey())
+
+		h.receivedCommitViaGossip(ctx, b1)
+		h.verifyCommitSaveAndReply(t)
+
+		t.Log("Next leader commits height 2 before the failover timeout passed, ignore")
+
+		b2 := builders.BlockPair().WithBenchmarkConsensusBlockProof(nextLeaderKeyPair()).WithHeight(2).WithPrevBlockHash(b1).WithBlockCreated(created.Add(30 * time.Second)).Build()
+		h.expectCommitIgnored()
+
+		h.receivedCommitViaGossip(ctx, b2)
+		h.verifyCommitIgnored(t)
+	})
+}
+
+func TestNonLeaderWithLeaderFailoverIgnoresCommitFromLeaderNotDesignatedForTheView(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessForNode(followerKeyPair(), 1*time.Minute)
+		h.createService(ctx)
+
+		t.Log("Leader commits height 1, confirm height 1")
+
+		created := time.Now()
+		b1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPair()).WithHeight(1).WithBlockCreated(created).Build()
+		h.expectCommitSaveAndReply(b1, 1, leaderKeyPair().PublicKey(), h.config.NodePublicKey())
+
+		h.receivedCommitViaGossip(ctx, b1)
+		h.verifyCommitSaveAndReply(t)
+
+		t.Log("Leader of the second view commits height 2 after a single failover timeout, ignore")
+
+		b2 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPairForView(2)).WithHeight(2).WithPrevBlockHash(b1).WithBlockCreated(created.Add(1 * time.Minute)).Build()
+		h.expectCommitIgnored()
+
+		h.receivedCommitViaGossip(ctx, b2)
+		h.verifyCommitIgnored(t)
+	})
+}
+
+func TestNonLeaderWithLeaderFailoverIgnoresOldBlockCommitFromNonDesignatedLeader(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessForNode(followerKeyPair(), 1*time.Minute)
+		h.createService(ctx)
+
+		t.Log("Leader commits height 1, confirm height 1")
+
+		b1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(leaderKeyPair()).WithHeight(1).Build()
+		h.expectCommitSaveAndReply(b1, 1, leaderKeyPair().PublicKey(), h.config.NodePublicKey())
+
+		h.receivedCommitViaGossip(ctx, b1)
+		h.verifyCommitSaveAndReply(t)
+
+		t.Log("Next leader commits height 1 although the leader did not fail, ignore")
+
+		otherB1 := builders.BlockPair().WithBenchmarkConsensusBlockProof(nextLeaderKeyPair()).WithHeight(1).Build()
+		h.expectCommitIgnored()
+
+		h.receivedCommitViaGossip(ctx, otherB1)
+		h.verifyCommitIgnored(t)
+	})
+}
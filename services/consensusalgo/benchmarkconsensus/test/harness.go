@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"context"
 	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/config"
@@ -15,6 +16,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
 	"os"
+	"sort"
 	"testing"
 	"time"
 )
@@ -43,21 +45,52 @@ func otherNonLeaderKeyPair() *keys.Ed25519KeyPair {
 	return testKeys.Ed25519KeyPairForTests(2)
 }
 
+// the node which takes over when the leader fails, next to it in sorted federation order
+func nextLeaderKeyPair() *keys.Ed25519KeyPair {
+	return leaderKeyPairForView(1)
+}
+
+// the node designated to close the block following one closed by the leader, after view failovers
+func leaderKeyPairForView(view int) *keys.Ed25519KeyPair {
+	keyPairs := make([]*keys.Ed25519KeyPair, 0, NETWORK_SIZE)
+	for i := 0; i < NETWORK_SIZE; i++ {
+		keyPairs = append(keyPairs, testKeys.Ed25519KeyPairForTests(i))
+	}
+	sort.Slice(keyPairs, func(i, j int) bool {
+		return bytes.Compare(keyPairs[i].PublicKey(), keyPairs[j].PublicKey()) < 0
+	})
+	for i, keyPair := range keyPairs {
+		if keyPair.PublicKey().Equal(leaderKeyPair().PublicKey()) {
+			return keyPairs[(i+view)%NETWORK_SIZE]
+		}
+	}
+	return nil
+}
+
 func newHarness(
 	isLeader bool,
 ) *harness {
 
+	nodeKeyPair := leaderKeyPair()
+	if !isLeader {
+		nodeKeyPair = nonLeaderKeyPair()
+	}
+
+	return newHarnessForNode(nodeKeyPair, 0)
+}
+
+// a zero leader failover timeout keeps the leader constant
+func newHarnessForNode(
+	nodeKeyPair *keys.Ed25519KeyPair,
+	leaderFailoverTimeout time.Duration,
+) *harness {
+
 	federationNodes := make(map[string]config.FederationNode)
 	for i := 0; i < NETWORK_SIZE; i++ {
 		publicKey := testKeys.Ed25519KeyPairForTests(i).PublicKey()
 		federationNodes[publicKey.KeyForMap()] = config.NewHardCodedFederationNode(publicKey)
 	}
 
-	nodeKeyPair := leaderKeyPair()
-	if !isLeader {
-		nodeKeyPair = nonLeaderKeyPair()
-	}
-
 	cfg := config.ForAcceptanceTests(
 		federationNodes,
 		make(map[string]config.GossipPeer),
@@ -70,6 +103,10 @@ func newHarness(
 
 	cfg.SetDuration(config.BENCHMARK_CONSENSUS_RETRY_INTERVAL, 5*time.Millisecond)
 	cfg.SetUint32(config.CONSENSUS_REQUIRED_QUORUM_PERCENTAGE, 66)
+	if leaderFailoverTimeout > 0 {
+		cfg.SetBool(config.BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED, true)
+		cfg.SetDuration(config.BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT, leaderFailoverTimeout)
+	}
 
 	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
 
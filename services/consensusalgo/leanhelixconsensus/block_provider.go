@@ -6,8 +6,8 @@ import (
 	lhprimitives "github.com/orbs-network/lean-helix-go/primitives"
 	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/crypto/logic"
-	"github.com/orbs-network/orbs-network-go/crypto/signature"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
@@ -26,6 +26,11 @@ func (s *service) HandleBlockConsensus(ctx context.Context, input *handlers.Hand
 		return nil, errors.Errorf("handler received unsupported block type %s", blockType)
 	}
 
+	// block storage moves on to the next algo only if we refuse, so blocks closed by other algos are refused in every mode
+	if !blockPair.ResultsBlock.BlockProof.IsTypeLeanHelix() {
+		return nil, errors.Errorf("incorrect block proof type: %s", blockPair.ResultsBlock.BlockProof.Type())
+	}
+
 	// validate the block consensus
 	if mode == handlers.HANDLE_BLOCK_CONSENSUS_MODE_VERIFY_AND_UPDATE || mode == handlers.HANDLE_BLOCK_CONSENSUS_MODE_VERIFY_ONLY {
 		err := s.validateBlockConsensus(blockPair, prevCommittedBlockPair)
@@ -35,30 +40,22 @@ func (s *service) HandleBlockConsensus(ctx context.Context, input *handlers.Hand
 	}
 
 	// update lastCommitted to reflect this if newer
-	// TODO: Gad - how to update internal block height and continue from there
 	if mode == handlers.HANDLE_BLOCK_CONSENSUS_MODE_VERIFY_AND_UPDATE || mode == handlers.HANDLE_BLOCK_CONSENSUS_MODE_UPDATE_ONLY {
-		//lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
-		//
-		//
-		//if blockPair.TransactionsBlock.Header.BlockHeight() > lastCommittedBlockHeight {
-		//
-		//	// TODO Set last committed block here?
-		//	//err := s.setLastCommittedBlock(blockPair, lastCommittedBlock)
-		//	//if err != nil {
-		//	//	return err
-		//	//}
-		//	// don't forget to update internal vars too since they may be used later on in the function
-		//	lastCommittedBlock = blockPair
-		//	lastCommittedBlockHeight = lastCommittedBlock.TransactionsBlock.Header.BlockHeight()
-		//}
+		lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
+
+		if blockPair.TransactionsBlock.Header.BlockHeight() > lastCommittedBlockHeight {
+			err := s.setLastCommittedBlock(blockPair, lastCommittedBlock)
+			if err != nil {
+				return nil, err
+			}
+			// the block was committed without us (block sync), the lib needs to move on to the next height
+			s.leanHelix.UpdateState(ctx, NewBlockPairWrapper(blockPair))
+		}
 	}
 
 	return nil, nil
 }
 
-func (s *service) updateLastCommit(mode handlers.HandleBlockConsensusMode, blockPair *protocol.BlockPairContainer) {
-}
-
 func (s *service) validateBlockConsensus(blockPair *protocol.BlockPairContainer, prevCommittedBlockPair *protocol.BlockPairContainer) error {
 	// correct block type
 	if !blockPair.TransactionsBlock.BlockProof.IsTypeLeanHelix() {
@@ -68,9 +65,6 @@ func (s *service) validateBlockConsensus(blockPair *protocol.BlockPairContainer,
 		return errors.Errorf("incorrect block proof type: %s", blockPair.ResultsBlock.BlockProof.Type())
 	}
 
-	panic("should not have reached here - not supposed to have generated any Lean Helix blocks!!")
-
-	// TODO Verify with Gad - do this here?
 	// prev block hash ptr (if given)
 	if prevCommittedBlockPair != nil {
 		prevTxHash := digest.CalcTransactionsBlockHash(prevCommittedBlockPair.TransactionsBlock)
@@ -83,19 +77,37 @@ func (s *service) validateBlockConsensus(blockPair *protocol.BlockPairContainer,
 		}
 	}
 
-	// TODO block proof
-	//blockProof := blockPair.ResultsBlock.BlockProof.LeanHelix()
-	//if !blockProof.Sender().SenderPublicKey().Equal(s.config.ConstantConsensusLeader()) {
-	//	return errors.Errorf("block proof not from leader: %s", blockProof.Sender().SenderPublicKey())
-	//}
-	//signedData := s.signedDataForBlockProof(blockPair)
-	//if !signature.VerifyEd25519(blockProof.Sender().SenderPublicKey(), signedData, blockProof.Sender().Signature()) {
-	//	return errors.Errorf("block proof signature is invalid: %s", blockProof.Sender().Signature())
-	//}
+	// block proof, a quorum of distinct federation members must have signed the commit message of this block, the lib signs
+	// its header (message type, height, view and block hash) so we rebuild it from the block and the view kept in the proof
+	blockProof := blockPair.ResultsBlock.BlockProof.LeanHelix()
+	federationNodes := s.config.FederationNodes(uint64(blockPair.TransactionsBlock.Header.BlockHeight()))
+	signedHeader := commitSignedHeader(blockPair, lhprimitives.View(blockProof.View()))
+	signers := make(map[string]bool)
+	for i := blockProof.NodesIterator(); i.HasNext(); {
+		sender := i.NextNodes()
+		if _, found := federationNodes[sender.SenderPublicKey().KeyForMap()]; !found {
+			return errors.Errorf("block proof signer with public key %s is not a valid federation member", sender.SenderPublicKey())
+		}
+		if !s.Verify(signedHeader.Raw(), (&leanhelix.SenderSignatureBuilder{
+			SenderPublicKey: lhprimitives.Ed25519PublicKey(sender.SenderPublicKey()),
+			Signature:       lhprimitives.Ed25519Sig(sender.Signature()),
+		}).Build()) {
+			return errors.Errorf("block proof signature is invalid: %s", sender.Signature())
+		}
+		signers[sender.SenderPublicKey().KeyForMap()] = true
+	}
+	if len(signers) < requiredQuorumSize(len(federationNodes)) {
+		return errors.Errorf("block proof has %d signers, quorum is %d", len(signers), requiredQuorumSize(len(federationNodes)))
+	}
 
 	return nil
 }
 
+// byzantine quorum, tolerates f faulty nodes out of 3f+1
+func requiredQuorumSize(committeeSize int) int {
+	return committeeSize - (committeeSize-1)/3
+}
+
 func (s *service) getLastCommittedBlock() (primitives.BlockHeight, *protocol.BlockPairContainer) {
 	s.lastCommittedBlock.RLock()
 	defer s.lastCommittedBlock.RUnlock()
@@ -106,94 +118,142 @@ func (s *service) getLastCommittedBlock() (primitives.BlockHeight, *protocol.Blo
 	return s.lastCommittedBlock.block.TransactionsBlock.Header.BlockHeight(), s.lastCommittedBlock.block
 }
 
+func (s *service) setLastCommittedBlock(blockPair *protocol.BlockPairContainer, expectedLastCommittedBlockBefore *protocol.BlockPairContainer) error {
+	s.lastCommittedBlock.Lock()
+	defer s.lastCommittedBlock.Unlock()
+
+	if s.lastCommittedBlock.block != expectedLastCommittedBlockBefore {
+		return errors.New("aborting shared state update due to inconsistency")
+	}
+	s.lastCommittedBlock.block = blockPair
+	return nil
+}
+
+// the lib calls this once a quorum of the committee sent commit messages for the block, all of them in the same view
+func (s *service) onCommit(ctx context.Context, block leanhelix.Block, commits []*leanhelix.CommitMessage) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	proposedBlockPair := block.(*BlockPairWrapper).blockPair
+	blockPair := blockPairWithLeanHelixProof(proposedBlockPair.TransactionsBlock, proposedBlockPair.ResultsBlock, commits)
+	blockHeight := blockPair.TransactionsBlock.Header.BlockHeight()
+
+	logger.Info("saving block to storage", log.BlockHeight(blockHeight))
+	_, err := s.blockStorage.CommitBlock(ctx, &services.CommitBlockInput{
+		BlockPair: blockPair,
+	})
+	if err != nil {
+		logger.Error("failed to commit block to storage", log.Error(err), log.BlockHeight(blockHeight))
+		return
+	}
+
+	lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
+	if blockHeight > lastCommittedBlockHeight {
+		err = s.setLastCommittedBlock(blockPair, lastCommittedBlock)
+		if err != nil {
+			logger.Error("failed to update last committed block", log.Error(err), log.BlockHeight(blockHeight))
+		}
+	}
+}
+
 func (s *service) RequestNewBlock(parentCtx context.Context, blockHeight lhprimitives.BlockHeight) leanhelix.Block {
 
 	// TODO Is this the right timeout here - probably should be a little smaller
 	ctxWithTimeout, cancel := context.WithTimeout(parentCtx, s.config.LeanHelixConsensusRoundTimeoutInterval())
 	defer cancel()
+	logger := s.logger.WithTags(trace.LogFieldFrom(parentCtx))
 
 	lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
-	s.logger.Info("generating new proposed block", log.BlockHeight(lastCommittedBlockHeight+1))
+	logger.Info("generating new proposed block", log.BlockHeight(lastCommittedBlockHeight+1))
+	prevTxHash, prevRxHash := prevBlockHashes(lastCommittedBlock)
 
 	// get tx
 	txOutput, err := s.consensusContext.RequestNewTransactionsBlock(ctxWithTimeout, &services.RequestNewTransactionsBlockInput{
 		BlockHeight:   lastCommittedBlockHeight + 1,
-		PrevBlockHash: digest.CalcTransactionsBlockHash(lastCommittedBlock.TransactionsBlock),
+		PrevBlockHash: prevTxHash,
 	})
 	if err != nil {
+		logger.Info("failed to generate transactions block", log.Error(err), log.BlockHeight(lastCommittedBlockHeight+1))
 		return nil
 	}
 
 	// get rx
 	rxOutput, err := s.consensusContext.RequestNewResultsBlock(ctxWithTimeout, &services.RequestNewResultsBlockInput{
 		BlockHeight:       lastCommittedBlockHeight + 1,
-		PrevBlockHash:     digest.CalcResultsBlockHash(lastCommittedBlock.ResultsBlock),
+		PrevBlockHash:     prevRxHash,
 		TransactionsBlock: txOutput.TransactionsBlock,
 	})
 	if err != nil {
+		logger.Info("failed to generate results block", log.Error(err), log.BlockHeight(lastCommittedBlockHeight+1))
 		return nil
 	}
 
-	// generate signed block
-	// TODO what to do in case of error - similar to handling timeout
-	pair, _ := s.signBlockProposal(txOutput.TransactionsBlock, rxOutput.ResultsBlock)
-	blockPairWrapper := NewBlockPairWrapper(&protocol.BlockPairContainer{
-		TransactionsBlock: pair.TransactionsBlock,
-		ResultsBlock:      pair.ResultsBlock,
-	})
-
-	return blockPairWrapper
-
+	// the proof is only known after the commit, the proposal carries an empty one
+	return NewBlockPairWrapper(blockPairWithLeanHelixProof(txOutput.TransactionsBlock, rxOutput.ResultsBlock, nil))
 }
 
-func (s *service) signBlockProposal(transactionsBlock *protocol.TransactionsBlockContainer, resultsBlock *protocol.ResultsBlockContainer) (*protocol.BlockPairContainer, error) {
-	blockPair := &protocol.BlockPairContainer{
-		TransactionsBlock: transactionsBlock,
-		ResultsBlock:      resultsBlock,
+// the first block has no previous block to point to
+func prevBlockHashes(lastCommittedBlock *protocol.BlockPairContainer) (primitives.Sha256, primitives.Sha256) {
+	if lastCommittedBlock == nil {
+		return nil, nil
 	}
+	return digest.CalcTransactionsBlockHash(lastCommittedBlock.TransactionsBlock), digest.CalcResultsBlockHash(lastCommittedBlock.ResultsBlock)
+}
 
-	// prepare signature over the block headers
-	signedData := s.signedDataForBlockProof(blockPair)
-	sig, err := signature.SignEd25519(s.config.NodePrivateKey(), signedData)
-	if err != nil {
-		return nil, err
+func blockPairWithLeanHelixProof(transactionsBlock *protocol.TransactionsBlockContainer, resultsBlock *protocol.ResultsBlockContainer, commits []*leanhelix.CommitMessage) *protocol.BlockPairContainer {
+	var view lhprimitives.View
+	nodes := make([]*consensus.LeanHelixSenderSignatureBuilder, 0, len(commits))
+	for _, commit := range commits {
+		view = commit.Content().SignedHeader().View()
+		sender := commit.Content().Sender()
+		nodes = append(nodes, &consensus.LeanHelixSenderSignatureBuilder{
+			SenderPublicKey: primitives.Ed25519PublicKey(sender.SenderPublicKey()),
+			Signature:       primitives.Ed25519Sig(sender.Signature()),
+		})
 	}
 
-	// generate tx block proof
-	blockPair.TransactionsBlock.BlockProof = (&protocol.TransactionsBlockProofBuilder{
-		Type:               protocol.TRANSACTIONS_BLOCK_PROOF_TYPE_BENCHMARK_CONSENSUS,
-		BenchmarkConsensus: &consensus.BenchmarkConsensusBlockProofBuilder{},
-	}).Build()
-
-	// generate rx block proof
-	blockPair.ResultsBlock.BlockProof = (&protocol.ResultsBlockProofBuilder{
-		Type: protocol.RESULTS_BLOCK_PROOF_TYPE_BENCHMARK_CONSENSUS,
-		BenchmarkConsensus: &consensus.BenchmarkConsensusBlockProofBuilder{
-			Sender: &consensus.BenchmarkConsensusSenderSignatureBuilder{
-				SenderPublicKey: s.config.NodePublicKey(),
-				Signature:       sig,
-			},
+	return &protocol.BlockPairContainer{
+		TransactionsBlock: &protocol.TransactionsBlockContainer{
+			Header:             transactionsBlock.Header,
+			Metadata:           transactionsBlock.Metadata,
+			SignedTransactions: transactionsBlock.SignedTransactions,
+			BlockProof: (&protocol.TransactionsBlockProofBuilder{
+				Type:      protocol.TRANSACTIONS_BLOCK_PROOF_TYPE_LEAN_HELIX,
+				LeanHelix: &consensus.LeanHelixBlockProofBuilder{},
+			}).Build(),
 		},
-	}).Build()
-	return blockPair, nil
+		ResultsBlock: &protocol.ResultsBlockContainer{
+			Header:              resultsBlock.Header,
+			TransactionReceipts: resultsBlock.TransactionReceipts,
+			ContractStateDiffs:  resultsBlock.ContractStateDiffs,
+			BlockProof: (&protocol.ResultsBlockProofBuilder{
+				Type: protocol.RESULTS_BLOCK_PROOF_TYPE_LEAN_HELIX,
+				LeanHelix: &consensus.LeanHelixBlockProofBuilder{
+					View:  primitives.View(view),
+					Nodes: nodes,
+				},
+			}).Build(),
+		},
+	}
 }
 
-func (s *service) hash(txBlock *protocol.TransactionsBlockContainer, rxBlock *protocol.ResultsBlockContainer) []byte {
-	txHash := digest.CalcTransactionsBlockHash(txBlock)
-	rxHash := digest.CalcResultsBlockHash(rxBlock)
+// the proofs are not part of the hash since it is signed before they exist
+func calcBlockHash(blockPair *protocol.BlockPairContainer) []byte {
+	txHash := digest.CalcTransactionsBlockHash(blockPair.TransactionsBlock)
+	rxHash := digest.CalcResultsBlockHash(blockPair.ResultsBlock)
 	xorHash := logic.CalcXor(txHash, rxHash)
 	return xorHash
 }
 
-func (s *service) signedDataForBlockProof(blockPair *protocol.BlockPairContainer) []byte {
-	return s.hash(blockPair.TransactionsBlock, blockPair.ResultsBlock)
-}
-
-func (s *service) signedDataForBlockProofWrapper(blockPairWrapper *BlockPairWrapper) []byte {
-	return s.hash(blockPairWrapper.blockPair.TransactionsBlock, blockPairWrapper.blockPair.ResultsBlock)
+// the header every commit message of the block signs, as the lib builds it
+func commitSignedHeader(blockPair *protocol.BlockPairContainer, view lhprimitives.View) *leanhelix.BlockRef {
+	return (&leanhelix.BlockRefBuilder{
+		MessageType: leanhelix.LEAN_HELIX_COMMIT,
+		BlockHeight: lhprimitives.BlockHeight(blockPair.TransactionsBlock.Header.BlockHeight()),
+		View:        view,
+		BlockHash:   lhprimitives.Uint256(calcBlockHash(blockPair)),
+	}).Build()
 }
 
 func (s *service) CalculateBlockHash(block leanhelix.Block) lhprimitives.Uint256 {
-
-	return s.signedDataForBlockProofWrapper(block.(*BlockPairWrapper))
+	return lhprimitives.Uint256(calcBlockHash(block.(*BlockPairWrapper).blockPair))
 }
@@ -0,0 +1,77 @@
+package leanhelixconsensus
+
+import (
+	"context"
+	"github.com/orbs-network/lean-helix-go"
+	lhprimitives "github.com/orbs-network/lean-helix-go/primitives"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+// every node of the federation is a service of its own so the commit messages are signed by the lib through each one's KeyManager
+func newFederationForTests(ctx context.Context, size int) []*service {
+	federationNodes := make(map[string]config.FederationNode)
+	for i := 0; i < size; i++ {
+		publicKey := keys.Ed25519KeyPairForTests(i).PublicKey()
+		federationNodes[publicKey.KeyForMap()] = config.NewHardCodedFederationNode(publicKey)
+	}
+
+	nodes := make([]*service, 0, size)
+	for i := 0; i < size; i++ {
+		nodes = append(nodes, newServiceForTests(ctx, &testConfig{
+			timeout:         1 * time.Millisecond,
+			federationNodes: federationNodes,
+			nodePublicKey:   keys.Ed25519KeyPairForTests(i).PublicKey(),
+			nodePrivateKey:  keys.Ed25519KeyPairForTests(i).PrivateKey(),
+		}))
+	}
+	return nodes
+}
+
+func commitMessagesFor(committers []*service, blockPair *protocol.BlockPairContainer, view lhprimitives.View) []*leanhelix.CommitMessage {
+	blockHeight := lhprimitives.BlockHeight(blockPair.TransactionsBlock.Header.BlockHeight())
+	commits := make([]*leanhelix.CommitMessage, 0, len(committers))
+	for _, committer := range committers {
+		commits = append(commits, leanhelix.NewMessageFactory(committer).CreateCommitMessage(blockHeight, view, lhprimitives.Uint256(calcBlockHash(blockPair))))
+	}
+	return commits
+}
+
+func TestValidateBlockConsensusAcceptsProofOfQuorumOfCommits(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+	nodes := newFederationForTests(ctx, 4)
+
+	block := builders.LeanHelixBlockPair().WithHeight(1).Build()
+	blockPair := blockPairWithLeanHelixProof(block.TransactionsBlock, block.ResultsBlock, commitMessagesFor(nodes[1:], block, 3))
+
+	require.NoError(t, nodes[0].validateBlockConsensus(blockPair, nil), "block committed by a quorum should pass consensus validation")
+}
+
+func TestValidateBlockConsensusRejectsProofWithoutQuorum(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+	nodes := newFederationForTests(ctx, 4)
+
+	block := builders.LeanHelixBlockPair().WithHeight(1).Build()
+	blockPair := blockPairWithLeanHelixProof(block.TransactionsBlock, block.ResultsBlock, commitMessagesFor(nodes[2:], block, 3))
+
+	require.Error(t, nodes[0].validateBlockConsensus(blockPair, nil), "block committed by less than a quorum should fail consensus validation")
+}
+
+func TestValidateBlockConsensusRejectsProofOfCommitsOfAnotherBlock(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+	nodes := newFederationForTests(ctx, 4)
+
+	block := builders.LeanHelixBlockPair().WithHeight(1).Build()
+	otherBlock := builders.LeanHelixBlockPair().WithHeight(1).WithTransactions(1).Build()
+	blockPair := blockPairWithLeanHelixProof(block.TransactionsBlock, block.ResultsBlock, commitMessagesFor(nodes[1:], otherBlock, 3))
+
+	require.Error(t, nodes[0].validateBlockConsensus(blockPair, nil), "commits of another block should fail consensus validation")
+}
@@ -1,10 +1,53 @@
 package leanhelixconsensus
 
 import (
+	"context"
 	"github.com/orbs-network/lean-helix-go"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
 )
 
-func (s *service) ValidateBlock(block leanhelix.Block) bool {
-	// TODO implement me
+func (s *service) ValidateBlock(parentCtx context.Context, block leanhelix.Block) bool {
+	ctx, cancel := context.WithTimeout(parentCtx, s.config.LeanHelixConsensusRoundTimeoutInterval())
+	defer cancel()
+
+	blockPair := block.(*BlockPairWrapper).blockPair
+	err := s.validateProposedBlock(ctx, blockPair)
+	if err != nil {
+		s.logger.WithTags(trace.LogFieldFrom(parentCtx)).Info("proposed block is invalid", log.Error(err), log.BlockHeight(blockPair.TransactionsBlock.Header.BlockHeight()))
+		return false
+	}
 	return true
 }
+
+// a proposal is valid if it continues our last committed block and consensus context approves both of its blocks
+func (s *service) validateProposedBlock(ctx context.Context, blockPair *protocol.BlockPairContainer) error {
+	lastCommittedBlockHeight, lastCommittedBlock := s.getLastCommittedBlock()
+
+	blockHeight := blockPair.TransactionsBlock.Header.BlockHeight()
+	if blockHeight != blockPair.ResultsBlock.Header.BlockHeight() {
+		return errors.Errorf("invalid block: block height of tx %s is not equal rx %s", blockHeight, blockPair.ResultsBlock.Header.BlockHeight())
+	}
+	if blockHeight != lastCommittedBlockHeight+1 {
+		return errors.Errorf("invalid block: block height %s does not follow last committed block %s", blockHeight, lastCommittedBlockHeight)
+	}
+
+	prevTxHash, prevRxHash := prevBlockHashes(lastCommittedBlock)
+	_, err := s.consensusContext.ValidateTransactionsBlock(ctx, &services.ValidateTransactionsBlockInput{
+		TransactionsBlock: blockPair.TransactionsBlock,
+		PrevBlockHash:     prevTxHash,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.consensusContext.ValidateResultsBlock(ctx, &services.ValidateResultsBlockInput{
+		ResultsBlock:      blockPair.ResultsBlock,
+		PrevBlockHash:     prevRxHash,
+		TransactionsBlock: blockPair.TransactionsBlock,
+	})
+	return err
+}
@@ -1,6 +1,7 @@
 package leanhelixconsensus
 
 import (
+	"bytes"
 	"context"
 	"github.com/orbs-network/lean-helix-go"
 	lhprimitives "github.com/orbs-network/lean-helix-go/primitives"
@@ -8,6 +9,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
+	"sort"
 )
 
 func (s *service) HandleLeanHelixMessage(ctx context.Context, input *gossiptopics.LeanHelixInput) (*gossiptopics.EmptyOutput, error) {
@@ -26,12 +28,29 @@ func (s *service) HandleLeanHelixMessage(ctx context.Context, input *gossiptopic
 	return nil, nil
 }
 
+// the committee is the whole federation, the seed rotates it so every member gets to be the leader
 func (s *service) RequestOrderedCommittee(seed uint64) []lhprimitives.Ed25519PublicKey {
-	panic("implement me")
+	federationNodes := s.config.FederationNodes(0)
+	publicKeys := make([]lhprimitives.Ed25519PublicKey, 0, len(federationNodes))
+	for _, federationNode := range federationNodes {
+		publicKeys = append(publicKeys, lhprimitives.Ed25519PublicKey(federationNode.NodePublicKey()))
+	}
+	sort.Slice(publicKeys, func(i, j int) bool {
+		return bytes.Compare(publicKeys[i], publicKeys[j]) < 0
+	})
+
+	if len(publicKeys) == 0 {
+		return publicKeys
+	}
+	offset := int(seed % uint64(len(publicKeys)))
+	committee := make([]lhprimitives.Ed25519PublicKey, 0, len(publicKeys))
+	committee = append(committee, publicKeys[offset:]...)
+	return append(committee, publicKeys[:offset]...)
 }
 
 func (s *service) IsMember(pk lhprimitives.Ed25519PublicKey) bool {
-	panic("implement me")
+	_, found := s.config.FederationNodes(0)[primitives.Ed25519PublicKey(pk).KeyForMap()]
+	return found
 }
 
 // Lib calls this method to register itself for incoming messages, and supplies the callback
@@ -54,8 +73,8 @@ func (s *service) CountRegisteredOnMessage() int {
 func (s *service) SendMessage(ctx context.Context, lhtargets []lhprimitives.Ed25519PublicKey, consensusRawMessage leanhelix.ConsensusRawMessage) {
 
 	targets := make([]primitives.Ed25519PublicKey, 0, len(lhtargets))
-	for i, lhtarget := range lhtargets {
-		targets[i] = primitives.Ed25519PublicKey(lhtarget)
+	for _, lhtarget := range lhtargets {
+		targets = append(targets, primitives.Ed25519PublicKey(lhtarget))
 	}
 
 	blockPairWrapper := consensusRawMessage.Block().(*BlockPairWrapper)
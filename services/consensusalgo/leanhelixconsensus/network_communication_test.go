@@ -2,11 +2,16 @@ package leanhelixconsensus
 
 import (
 	"context"
+	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/lean-helix-go"
+	lhprimitives "github.com/orbs-network/lean-helix-go/primitives"
+	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
+	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/gossiptopics"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -15,23 +20,31 @@ import (
 )
 
 type testConfig struct {
-	timeout time.Duration
+	timeout         time.Duration
+	federationNodes map[string]config.FederationNode
+	nodePublicKey   primitives.Ed25519PublicKey
+	nodePrivateKey  primitives.Ed25519PrivateKey
 }
 
 func (c *testConfig) NodePublicKey() primitives.Ed25519PublicKey {
-	panic("implement me")
+	return c.nodePublicKey
 }
 
 func (c *testConfig) NodePrivateKey() primitives.Ed25519PrivateKey {
-	panic("implement me")
+	return c.nodePrivateKey
+}
+
+func (c *testConfig) FederationNodes(asOfBlock uint64) map[string]config.FederationNode {
+	return c.federationNodes
 }
 
 func (c *testConfig) LeanHelixConsensusRoundTimeoutInterval() time.Duration {
 	return c.timeout
 }
 
+// lean helix is not started, the tests call the service directly
 func (c *testConfig) ActiveConsensusAlgo() consensus.ConsensusAlgoType {
-	return consensus.CONSENSUS_ALGO_TYPE_LEAN_HELIX
+	return consensus.CONSENSUS_ALGO_TYPE_BENCHMARK_CONSENSUS
 }
 
 type testGossip struct{}
@@ -45,22 +58,28 @@ func (g *testGossip) RegisterLeanHelixHandler(handler gossiptopics.LeanHelixHand
 }
 
 // TODO Extract a harness out of this mess after 3+ tests are written
-func TestMessageRegistration(t *testing.T) {
+func newServiceForTests(ctx context.Context, cfg *testConfig) *service {
 	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
-	timeout := 1 * time.Millisecond
 	metricFactory := metric.NewRegistry()
-	ctx, ctxCancel := context.WithCancel(context.Background())
-	defer ctxCancel()
+	blockStorage := &services.MockBlockStorage{}
+	blockStorage.When("RegisterConsensusBlocksHandler", mock.Any).Return().Times(1)
 	res := NewLeanHelixConsensusAlgo(
 		ctx,
 		&testGossip{},
-		nil,
+		blockStorage,
 		nil,
 		log,
-		&testConfig{timeout: timeout},
+		cfg,
 		metricFactory,
 	)
-	s := res.(*service)
+	return res.(*service)
+}
+
+func TestMessageRegistration(t *testing.T) {
+	timeout := 1 * time.Millisecond
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+	s := newServiceForTests(ctx, &testConfig{timeout: timeout})
 
 	f := func(ctx context.Context, message leanhelix.ConsensusRawMessage) {}
 	g := func(ctx context.Context, message leanhelix.ConsensusRawMessage) {}
@@ -80,3 +99,24 @@ func TestMessageRegistration(t *testing.T) {
 	countRegistered = s.CountRegisteredOnMessage()
 	require.Equal(t, 0, s.CountRegisteredOnMessage(), "no registered functions should have remained but actually %d are still registered", countRegistered)
 }
+
+func TestRequestOrderedCommitteeRotatesFederationBySeed(t *testing.T) {
+	ctx, ctxCancel := context.WithCancel(context.Background())
+	defer ctxCancel()
+	federationNodes := make(map[string]config.FederationNode)
+	for i := 0; i < 4; i++ {
+		publicKey := keys.Ed25519KeyPairForTests(i).PublicKey()
+		federationNodes[publicKey.KeyForMap()] = config.NewHardCodedFederationNode(publicKey)
+	}
+	s := newServiceForTests(ctx, &testConfig{timeout: 1 * time.Millisecond, federationNodes: federationNodes})
+
+	committee := s.RequestOrderedCommittee(0)
+	require.Len(t, committee, 4, "committee should include the whole federation")
+
+	rotatedCommittee := s.RequestOrderedCommittee(1)
+	require.Equal(t, append(committee[1:], committee[0]), rotatedCommittee, "next seed should rotate the leader to the next member")
+	require.Equal(t, committee, s.RequestOrderedCommittee(4), "seed should wrap around the committee size")
+
+	require.True(t, s.IsMember(committee[0]), "federation node should be a member")
+	require.False(t, s.IsMember(lhprimitives.Ed25519PublicKey(keys.Ed25519KeyPairForTests(5).PublicKey())), "node outside the federation should not be a member")
+}
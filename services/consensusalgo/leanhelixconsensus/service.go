@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/orbs-network/lean-helix-go"
 	lhprimitives "github.com/orbs-network/lean-helix-go/primitives"
+	"github.com/orbs-network/orbs-network-go/config"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
@@ -45,6 +47,7 @@ type metrics struct {
 type Config interface {
 	NodePublicKey() primitives.Ed25519PublicKey
 	NodePrivateKey() primitives.Ed25519PrivateKey
+	FederationNodes(asOfBlock uint64) map[string]config.FederationNode
 
 	LeanHelixConsensusRoundTimeoutInterval() time.Duration
 	ActiveConsensusAlgo() consensus.ConsensusAlgoType
@@ -67,8 +70,7 @@ func (b *BlockPairWrapper) Height() lhprimitives.BlockHeight {
 }
 
 func (b *BlockPairWrapper) BlockHash() lhprimitives.Uint256 {
-	// TODO This is surely incorrect, fix to use the right hash
-	return lhprimitives.Uint256(b.blockPair.TransactionsBlock.Header.MetadataHash())
+	return lhprimitives.Uint256(calcBlockHash(b.blockPair))
 }
 
 func NewBlockPairWrapper(blockPair *protocol.BlockPairContainer) *BlockPairWrapper {
@@ -98,6 +100,7 @@ func NewLeanHelixConsensusAlgo(
 		config:                  config,
 		metrics:                 newMetrics(metricFactory, config.LeanHelixConsensusRoundTimeoutInterval()),
 		leanHelix:               nil,
+		lastCommittedBlock:      &lastCommittedBlock{},
 		messageReceivers:        make(map[int]func(ctx context.Context, message leanhelix.ConsensusRawMessage)),
 		messageReceiversCounter: 0,
 	}
@@ -111,15 +114,20 @@ func NewLeanHelixConsensusAlgo(
 
 	leanHelix := leanhelix.NewLeanHelix(leanHelixConfig)
 
+	leanHelix.RegisterOnCommitted(s.onCommit)
+
 	s.leanHelix = leanHelix
 
 	gossip.RegisterLeanHelixHandler(s)
 
-	// TODO uncomment after BlockStorage mutex issues (s.lastBlockLock) are fixed
-	//blockStorage.RegisterConsensusBlocksHandler(s)
+	// block storage updates us synchronously with its last committed block, this is where we resume from after a restart
+	blockStorage.RegisterConsensusBlocksHandler(s)
 
 	if config.ActiveConsensusAlgo() == consensus.CONSENSUS_ALGO_TYPE_LEAN_HELIX {
-		go s.leanHelix.Start(ctx, 1) // TODO Get the block height from someplace
+		lastCommittedBlockHeight, _ := s.getLastCommittedBlock()
+		supervised.GoOnce(s.logger, func() {
+			s.leanHelix.Start(ctx, lhprimitives.BlockHeight(lastCommittedBlockHeight+1))
+		})
 	}
 
 	return s
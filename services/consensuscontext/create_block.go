@@ -6,6 +6,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"sync"
 	"time"
 )
 
@@ -17,20 +18,29 @@ func (s *service) createTransactionsBlock(ctx context.Context, blockHeight primi
 	if err != nil {
 		return nil, err
 	}
-	txCount := len(proposedTransactions.SignedTransactions)
+	timestamp := primitives.TimestampNano(time.Now().UnixNano()) // contracts see it through Sdk.Env
+
+	// the virtual machine stops at the block execution budget, transactions it gave no receipt stay in the pool
+	executed, signedTransactions, err := s.executeProposedTransactions(ctx, blockHeight, timestamp, proposedTransactions.SignedTransactions)
+	if err != nil {
+		return nil, err
+	}
+	txCount := len(signedTransactions)
 
 	txBlock := &protocol.TransactionsBlockContainer{
 		Header: (&protocol.TransactionsBlockHeaderBuilder{
-			ProtocolVersion: primitives.ProtocolVersion(1), // TODO: fix
-			BlockHeight:     blockHeight,
-			//Timestamp: 			   primitives.TimestampNano(time.Now().UnixNano()),
+			ProtocolVersion:       primitives.ProtocolVersion(1), // TODO: fix
+			BlockHeight:           blockHeight,
+			Timestamp:             timestamp,
 			PrevBlockHashPtr:      prevBlockHash,
+			TransactionsRootHash:  digest.CalcTransactionsRootHash(signedTransactions),
 			NumSignedTransactions: uint32(txCount),
 		}).Build(),
 		Metadata:           (&protocol.TransactionsBlockMetadataBuilder{}).Build(),
-		SignedTransactions: proposedTransactions.SignedTransactions,
+		SignedTransactions: signedTransactions,
 		BlockProof:         nil,
 	}
+	s.proposedExecution.set(txBlock, executed)
 	return txBlock, nil
 }
 
@@ -38,12 +48,17 @@ func (s *service) createResultsBlock(ctx context.Context, blockHeight primitives
 	start := time.Now()
 	defer s.metrics.createResultsBlockTime.RecordSince(start)
 
-	output, err := s.virtualMachine.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
-		BlockHeight:        blockHeight,
-		SignedTransactions: transactionsBlock.SignedTransactions,
-	})
-	if err != nil {
-		return nil, err
+	output := s.proposedExecution.takeFor(transactionsBlock)
+	if output == nil {
+		var err error
+		output, err = s.virtualMachine.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+			BlockHeight:        blockHeight,
+			BlockTimestamp:     transactionsBlock.Header.Timestamp(),
+			SignedTransactions: transactionsBlock.SignedTransactions,
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	rxBlock := &protocol.ResultsBlockContainer{
@@ -52,6 +67,8 @@ func (s *service) createResultsBlock(ctx context.Context, blockHeight primitives
 			BlockHeight:              blockHeight,
 			PrevBlockHashPtr:         prevBlockHash,
 			TransactionsBlockHashPtr: digest.CalcTransactionsBlockHash(transactionsBlock),
+			ReceiptsRootHash:         digest.CalcReceiptsRootHash(output.TransactionReceipts),
+			StateDiffHash:            digest.CalcStateDiffHash(output.ContractStateDiffs),
 			NumTransactionReceipts:   uint32(len(output.TransactionReceipts)),
 			NumContractStateDiffs:    uint32(len(output.ContractStateDiffs)),
 		}).Build(),
@@ -61,3 +78,39 @@ func (s *service) createResultsBlock(ctx context.Context, blockHeight primitives
 	}
 	return rxBlock, nil
 }
+
+// the execution of the transactions block this node proposed last, its results block is made from it instead of
+// executing the same transactions again (the state they run on doesn't change until a block at this height is committed)
+type proposedExecution struct {
+	mutex                 *sync.Mutex
+	transactionsBlockHash primitives.Sha256
+	output                *services.ProcessTransactionSetOutput
+}
+
+func newProposedExecution() *proposedExecution {
+	return &proposedExecution{
+		mutex: &sync.Mutex{},
+	}
+}
+
+func (p *proposedExecution) set(transactionsBlock *protocol.TransactionsBlockContainer, output *services.ProcessTransactionSetOutput) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.transactionsBlockHash = digest.CalcTransactionsBlockHash(transactionsBlock)
+	p.output = output
+}
+
+// returns nil unless the transactions block is the one proposed last, which is then forgotten
+func (p *proposedExecution) takeFor(transactionsBlock *protocol.TransactionsBlockContainer) *services.ProcessTransactionSetOutput {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.output == nil || !p.transactionsBlockHash.Equal(digest.CalcTransactionsBlockHash(transactionsBlock)) {
+		return nil
+	}
+	output := p.output
+	p.output = nil
+	p.transactionsBlockHash = nil
+	return output
+}
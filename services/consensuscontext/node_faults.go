@@ -0,0 +1,91 @@
+package consensuscontext
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"sync"
+)
+
+// a transaction this node failed to execute in this many proposals is evicted from the transaction pool
+const MAX_NODE_FAULTS_PER_TRANSACTION = 3
+
+type nodeFaults struct {
+	sync.Mutex
+	countByTxHash map[string]uint32
+}
+
+func newNodeFaults() *nodeFaults {
+	return &nodeFaults{countByTxHash: make(map[string]uint32)}
+}
+
+// returns true once the transaction reached the maximum number of faults (its count is then reset)
+func (f *nodeFaults) add(txHash primitives.Sha256) bool {
+	f.Lock()
+	defer f.Unlock()
+	f.countByTxHash[txHash.KeyForMap()]++
+	if f.countByTxHash[txHash.KeyForMap()] < MAX_NODE_FAULTS_PER_TRANSACTION {
+		return false
+	}
+	delete(f.countByTxHash, txHash.KeyForMap())
+	return true
+}
+
+func (f *nodeFaults) clear(transactions []*protocol.SignedTransaction) {
+	f.Lock()
+	defer f.Unlock()
+	if len(f.countByTxHash) == 0 {
+		return
+	}
+	for _, tx := range transactions {
+		delete(f.countByTxHash, digest.CalcTxHash(tx.Transaction()).KeyForMap())
+	}
+}
+
+// a transaction this node can't execute (e.g. its ethereum endpoint is down) is left out of the proposal instead of failing it,
+// so block production goes on without it, and it is evicted from the pool if it keeps failing so it does not slow down every block
+func (s *service) executeProposedTransactions(ctx context.Context, blockHeight primitives.BlockHeight, timestamp primitives.TimestampNano, transactions []*protocol.SignedTransaction) (*services.ProcessTransactionSetOutput, []*protocol.SignedTransaction, error) {
+	for {
+		executed, err := s.virtualMachine.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+			BlockHeight:        blockHeight,
+			BlockTimestamp:     timestamp,
+			SignedTransactions: transactions,
+		})
+		if err == nil {
+			executedTransactions := transactions[:len(executed.TransactionReceipts)]
+			s.nodeFaults.clear(executedTransactions)
+			return executed, executedTransactions, nil
+		}
+
+		nodeFault, ok := err.(*virtualmachine.TransactionSetNodeFault)
+		if !ok || nodeFault.Index >= len(transactions) {
+			return nil, nil, err
+		}
+
+		s.onTransactionNodeFault(ctx, transactions[nodeFault.Index], nodeFault)
+		remaining := make([]*protocol.SignedTransaction, 0, len(transactions)-1)
+		remaining = append(remaining, transactions[:nodeFault.Index]...)
+		transactions = append(remaining, transactions[nodeFault.Index+1:]...)
+	}
+}
+
+func (s *service) onTransactionNodeFault(ctx context.Context, transaction *protocol.SignedTransaction, nodeFault *virtualmachine.TransactionSetNodeFault) {
+	txHash := digest.CalcTxHash(transaction.Transaction())
+	s.logger.Info("leaving out of the proposal a transaction this node failed to execute", log.Error(nodeFault.Cause), log.Transaction(txHash))
+
+	if !s.nodeFaults.add(txHash) {
+		return
+	}
+	evictor, ok := s.transactionPool.(transactionpool.TransactionEvictor)
+	if !ok {
+		return
+	}
+	if _, err := evictor.EvictTransaction(ctx, &transactionpool.EvictTransactionInput{Txhash: txHash}); err != nil {
+		s.logger.Info("failed to evict transaction this node failed to execute", log.Error(err), log.Transaction(txHash))
+	}
+}
@@ -34,6 +34,9 @@ type service struct {
 	config          config.ConsensusContextConfig
 	logger          log.BasicLogger
 
+	proposedExecution *proposedExecution
+	nodeFaults        *nodeFaults
+
 	metrics *metrics
 }
 
@@ -52,7 +55,11 @@ func NewConsensusContext(
 		stateStorage:    stateStorage,
 		config:          config,
 		logger:          logger.WithTags(LogTag),
-		metrics:         newMetrics(metricFactory),
+
+		proposedExecution: newProposedExecution(),
+		nodeFaults:        newNodeFaults(),
+
+		metrics: newMetrics(metricFactory),
 	}
 }
 
@@ -93,9 +100,25 @@ func (s *service) RequestNewResultsBlock(ctx context.Context, input *services.Re
 }
 
 func (s *service) ValidateTransactionsBlock(ctx context.Context, input *services.ValidateTransactionsBlockInput) (*services.ValidateTransactionsBlockOutput, error) {
-	panic("Not implemented")
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	err := s.validateTransactionsBlock(ctx, input.TransactionsBlock, input.PrevBlockHash)
+	if err != nil {
+		logger.Info("transactions block is invalid", log.Error(err), log.BlockHeight(input.TransactionsBlock.Header.BlockHeight()))
+		return nil, err
+	}
+
+	return &services.ValidateTransactionsBlockOutput{}, nil
 }
 
 func (s *service) ValidateResultsBlock(ctx context.Context, input *services.ValidateResultsBlockInput) (*services.ValidateResultsBlockOutput, error) {
-	panic("Not implemented")
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	err := s.validateResultsBlock(ctx, input.ResultsBlock, input.PrevBlockHash, input.TransactionsBlock)
+	if err != nil {
+		logger.Info("results block is invalid", log.Error(err), log.BlockHeight(input.ResultsBlock.Header.BlockHeight()))
+		return nil, err
+	}
+
+	return &services.ValidateResultsBlockOutput{}, nil
 }
@@ -2,7 +2,12 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/consensuscontext"
 	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -13,6 +18,7 @@ func TestReturnAllAvailableTransactionsFromTransactionPool(t *testing.T) {
 		txCount := h.config.ConsensusContextMinimumTransactionsInBlock() + 1
 
 		h.expectTransactionsRequestedFromTransactionPool(txCount)
+		h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(int(txCount))
 
 		txBlock, err := h.requestTransactionsBlock(ctx)
 		if err != nil {
@@ -38,6 +44,7 @@ func TestRetryWhenNotEnoughTransactionsPendingOnTransactionPool(t *testing.T) {
 
 		h.expectTransactionsRequestedFromTransactionPool(0)
 		h.expectTransactionsRequestedFromTransactionPool(txCount)
+		h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(int(txCount))
 
 		txBlock, err := h.requestTransactionsBlock(ctx)
 		require.NoError(t, err, "request transactions block failed:", err)
@@ -49,3 +56,81 @@ func TestRetryWhenNotEnoughTransactionsPendingOnTransactionPool(t *testing.T) {
 		h.verifyTransactionsRequestedFromTransactionPool(t)
 	})
 }
+
+func TestTransactionsInBlockAreLimitedByBlockExecutionBudget(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		txCount := h.config.ConsensusContextMinimumTransactionsInBlock() + 2
+
+		h.expectTransactionsRequestedFromTransactionPool(txCount)
+		h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(int(txCount) - 1)
+
+		txBlock, err := h.requestTransactionsBlock(ctx)
+		require.NoError(t, err, "request transactions block failed:", err)
+		require.EqualValues(t, txCount-1, len(txBlock.SignedTransactions), "block should only hold the transactions that fit in its execution budget")
+		require.EqualValues(t, txCount-1, txBlock.Header.NumSignedTransactions(), "block header should count the transactions that fit in its execution budget")
+
+		h.verifyMocks(t)
+	})
+}
+
+func TestResultsBlockOfProposedTransactionsBlockReusesItsExecution(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		txCount := h.config.ConsensusContextMinimumTransactionsInBlock() + 1
+
+		h.expectTransactionsRequestedFromTransactionPool(txCount)
+		h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(int(txCount))
+
+		txBlock, err := h.requestTransactionsBlock(ctx)
+		require.NoError(t, err, "request transactions block failed:", err)
+
+		rxBlock, err := h.requestResultsBlock(ctx, txBlock)
+		require.NoError(t, err, "request results block failed:", err)
+		require.EqualValues(t, txCount, len(rxBlock.TransactionReceipts), "results block should hold the receipts of the proposed execution")
+
+		h.verifyMocks(t)
+	})
+}
+
+func TestTransactionFailingAsNodeFaultIsLeftOutOfTheBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		txCount := h.config.ConsensusContextMinimumTransactionsInBlock() + 1
+
+		h.expectTransactionsRequestedFromTransactionPool(txCount)
+		h.expectTransactionSetFailingWithNodeFaultByVirtualMachine(1)
+		h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(int(txCount))
+
+		txBlock, err := h.requestTransactionsBlock(ctx)
+		require.NoError(t, err, "request transactions block failed when this node could not execute one of the transactions")
+		require.Len(t, txBlock.SignedTransactions, int(txCount)-1, "the transaction this node failed to execute should be left out of the block")
+
+		h.verifyMocks(t)
+	})
+}
+
+func TestTransactionRepeatedlyFailingAsNodeFaultIsEvictedFromTransactionPool(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		faultyTx := builders.TransferTransaction().WithAmountAndTargetAddress(10, builders.AddressForEd25519SignerForTests(2)).Build()
+		otherTx := builders.TransferTransaction().WithAmountAndTargetAddress(20, builders.AddressForEd25519SignerForTests(2)).Build()
+
+		h.transactionPool.When("GetTransactionsForOrdering", mock.Any, mock.Any).Return(&services.GetTransactionsForOrderingOutput{
+			SignedTransactions: []*protocol.SignedTransaction{faultyTx, otherTx},
+		}, nil).Times(consensuscontext.MAX_NODE_FAULTS_PER_TRANSACTION)
+		for i := 0; i < consensuscontext.MAX_NODE_FAULTS_PER_TRANSACTION; i++ {
+			h.expectTransactionSetFailingWithNodeFaultByVirtualMachine(0)
+			h.expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(1)
+		}
+		h.expectTransactionEvictedFromTransactionPool(faultyTx)
+
+		for i := 0; i < consensuscontext.MAX_NODE_FAULTS_PER_TRANSACTION; i++ {
+			txBlock, err := h.requestTransactionsBlock(ctx)
+			require.NoError(t, err, "request transactions block failed when this node could not execute one of the transactions")
+			require.Equal(t, []*protocol.SignedTransaction{otherTx}, txBlock.SignedTransactions, "the transaction this node failed to execute should be left out of the block")
+		}
+
+		h.verifyMocks(t)
+	})
+}
@@ -4,14 +4,19 @@ import (
 	"context"
 	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/crypto/hash"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/services/consensuscontext"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"os"
 	"testing"
@@ -30,8 +35,23 @@ var federationNodePublicKeysForTest = []primitives.Ed25519PublicKey{
 	primitives.Ed25519PublicKey("70d92324eb8d24b7c7ed646e1996f94dcd52934a031935b9ac2d0e5bbcfa357c"),
 }
 
+type transactionPoolMock struct {
+	services.MockTransactionPool
+}
+
+func (m *transactionPoolMock) EvictTransaction(ctx context.Context, input *transactionpool.EvictTransactionInput) (*transactionpool.EvictTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*transactionpool.EvictTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
 type harness struct {
-	transactionPool *services.MockTransactionPool
+	transactionPool *transactionPoolMock
+	virtualMachine  *services.MockVirtualMachine
+	stateStorage    *services.MockStateStorage
 	reporting       log.BasicLogger
 	service         services.ConsensusContext
 	config          config.ConsensusContextConfig
@@ -50,6 +70,18 @@ func (h *harness) requestTransactionsBlock(ctx context.Context) (*protocol.Trans
 	return output.TransactionsBlock, nil
 }
 
+func (h *harness) requestResultsBlock(ctx context.Context, transactionsBlock *protocol.TransactionsBlockContainer) (*protocol.ResultsBlockContainer, error) {
+	output, err := h.service.RequestNewResultsBlock(ctx, &services.RequestNewResultsBlockInput{
+		BlockHeight:       transactionsBlock.Header.BlockHeight(),
+		PrevBlockHash:     transactionsBlock.Header.PrevBlockHashPtr(),
+		TransactionsBlock: transactionsBlock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return output.ResultsBlock, nil
+}
+
 func (h *harness) expectTransactionsRequestedFromTransactionPool(numTransactionsToReturn uint32) {
 
 	output := &services.GetTransactionsForOrderingOutput{
@@ -64,6 +96,33 @@ func (h *harness) expectTransactionsRequestedFromTransactionPool(numTransactions
 	h.transactionPool.When("GetTransactionsForOrdering", mock.Any, mock.Any).Return(output, nil).Times(1)
 }
 
+// the virtual machine executes the first numTransactionsInBlock of the proposed transactions, the rest exceed the block budget
+func (h *harness) expectTransactionSetExecutedUpToBlockBudgetByVirtualMachine(numTransactionsInBlock int) {
+	h.virtualMachine.When("ProcessTransactionSet", mock.Any, mock.Any).Call(func(ctx context.Context, input *services.ProcessTransactionSetInput) (*services.ProcessTransactionSetOutput, error) {
+		if numTransactionsInBlock > len(input.SignedTransactions) {
+			numTransactionsInBlock = len(input.SignedTransactions)
+		}
+		receipts := make([]*protocol.TransactionReceipt, 0, numTransactionsInBlock)
+		for _, tx := range input.SignedTransactions[:numTransactionsInBlock] {
+			receipts = append(receipts, builders.TransactionReceipt().WithTransaction(tx.Transaction()).Build())
+		}
+		return &services.ProcessTransactionSetOutput{TransactionReceipts: receipts}, nil
+	}).Times(1)
+}
+
+// the virtual machine fails the set as a node fault on the transaction at the given index
+func (h *harness) expectTransactionSetFailingWithNodeFaultByVirtualMachine(faultyTransactionIndex int) {
+	nodeFault := &virtualmachine.TransactionSetNodeFault{Index: faultyTransactionIndex, Cause: &ethereum.EndpointFailure{Cause: errors.New("connection refused")}}
+	h.virtualMachine.When("ProcessTransactionSet", mock.Any, mock.Any).Return(nil, nodeFault).Times(1)
+}
+
+func (h *harness) expectTransactionEvictedFromTransactionPool(transaction *protocol.SignedTransaction) {
+	txHash := digest.CalcTxHash(transaction.Transaction())
+	h.transactionPool.When("EvictTransaction", mock.Any, mock.AnyIf("eviction of the given transaction", func(i interface{}) bool {
+		return i.(*transactionpool.EvictTransactionInput).Txhash.Equal(txHash)
+	})).Return(&transactionpool.EvictTransactionOutput{Evicted: true}, nil).Times(1)
+}
+
 func (h *harness) expectTransactionsNoLongerRequestedFromTransactionPool() {
 	h.transactionPool.When("GetTransactionsForOrdering", mock.Any, mock.Any).Return(nil, nil).Times(0)
 }
@@ -75,10 +134,76 @@ func (h *harness) verifyTransactionsRequestedFromTransactionPool(t *testing.T) {
 	require.True(t, ok)
 }
 
+func (h *harness) validateTransactionsBlock(ctx context.Context, transactionsBlock *protocol.TransactionsBlockContainer, prevBlockHash primitives.Sha256) error {
+	_, err := h.service.ValidateTransactionsBlock(ctx, &services.ValidateTransactionsBlockInput{
+		TransactionsBlock: transactionsBlock,
+		PrevBlockHash:     prevBlockHash,
+	})
+	return err
+}
+
+func (h *harness) validateResultsBlock(ctx context.Context, resultsBlock *protocol.ResultsBlockContainer, prevBlockHash primitives.Sha256, transactionsBlock *protocol.TransactionsBlockContainer) error {
+	_, err := h.service.ValidateResultsBlock(ctx, &services.ValidateResultsBlockInput{
+		ResultsBlock:      resultsBlock,
+		PrevBlockHash:     prevBlockHash,
+		TransactionsBlock: transactionsBlock,
+	})
+	return err
+}
+
+func (h *harness) expectLastCommittedBlock(prevBlock *protocol.BlockPairContainer) {
+	output := &services.GetStateStorageBlockHeightOutput{
+		LastCommittedBlockHeight:    prevBlock.TransactionsBlock.Header.BlockHeight(),
+		LastCommittedBlockTimestamp: prevBlock.TransactionsBlock.Header.Timestamp(),
+	}
+
+	h.stateStorage.When("GetStateStorageBlockHeight", mock.Any, mock.Any).Return(output, nil).Times(1)
+}
+
+func (h *harness) expectTransactionsValidatedByTransactionPool(returnError error) {
+	h.transactionPool.When("ValidateTransactionsForOrdering", mock.Any, mock.Any).Return(&services.ValidateTransactionsForOrderingOutput{}, returnError).Times(1)
+}
+
+func (h *harness) expectTransactionSetProcessedByVirtualMachine(receipts []*protocol.TransactionReceipt, contractStateDiffs []*protocol.ContractStateDiff) {
+	output := &services.ProcessTransactionSetOutput{
+		TransactionReceipts: receipts,
+		ContractStateDiffs:  contractStateDiffs,
+	}
+
+	h.virtualMachine.When("ProcessTransactionSet", mock.Any, mock.Any).Return(output, nil).Times(1)
+}
+
+func (h *harness) verifyMocks(t *testing.T) {
+	ok, err := h.transactionPool.Verify()
+	require.True(t, ok, "transaction pool mock was not called as expected: %v", err)
+	ok, err = h.virtualMachine.Verify()
+	require.True(t, ok, "virtual machine mock was not called as expected: %v", err)
+	ok, err = h.stateStorage.Verify()
+	require.True(t, ok, "state storage mock was not called as expected: %v", err)
+}
+
+// a results block executed on top of the given transactions block, pointing at it
+func resultsBlockFor(transactionsBlock *protocol.TransactionsBlockContainer, prevBlockHash primitives.Sha256, receipts []*protocol.TransactionReceipt, contractStateDiffs []*protocol.ContractStateDiff) *protocol.ResultsBlockContainer {
+	return &protocol.ResultsBlockContainer{
+		Header: (&protocol.ResultsBlockHeaderBuilder{
+			ProtocolVersion:          primitives.ProtocolVersion(1),
+			BlockHeight:              transactionsBlock.Header.BlockHeight(),
+			PrevBlockHashPtr:         prevBlockHash,
+			TransactionsBlockHashPtr: digest.CalcTransactionsBlockHash(transactionsBlock),
+			ReceiptsRootHash:         digest.CalcReceiptsRootHash(receipts),
+			StateDiffHash:            digest.CalcStateDiffHash(contractStateDiffs),
+			NumTransactionReceipts:   uint32(len(receipts)),
+			NumContractStateDiffs:    uint32(len(contractStateDiffs)),
+		}).Build(),
+		TransactionReceipts: receipts,
+		ContractStateDiffs:  contractStateDiffs,
+	}
+}
+
 func newHarness() *harness {
 	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
 
-	transactionPool := &services.MockTransactionPool{}
+	transactionPool := &transactionPoolMock{}
 	federationNodes := make(map[string]config.FederationNode)
 	for _, pk := range federationNodePublicKeysForTest {
 		federationNodes[pk.KeyForMap()] = config.NewHardCodedFederationNode(pk)
@@ -88,11 +213,17 @@ func newHarness() *harness {
 
 	metricFactory := metric.NewRegistry()
 
-	service := consensuscontext.NewConsensusContext(transactionPool, nil, nil,
+	virtualMachine := &services.MockVirtualMachine{}
+
+	stateStorage := &services.MockStateStorage{}
+
+	service := consensuscontext.NewConsensusContext(transactionPool, virtualMachine, stateStorage,
 		cfg, log, metricFactory)
 
 	return &harness{
 		transactionPool: transactionPool,
+		virtualMachine:  virtualMachine,
+		stateStorage:    stateStorage,
 		reporting:       log,
 		service:         service,
 		config:          cfg,
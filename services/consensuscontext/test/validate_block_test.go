@@ -0,0 +1,195 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestValidateTransactionsBlock_AcceptsBlockApprovedByTransactionPool(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now().Add(-1 * time.Second)).Build()
+		block := builders.BlockPair().WithHeight(2).WithPrevBlockHash(prevBlock).WithTransactions(2).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+		h.expectLastCommittedBlock(prevBlock)
+
+		h.expectTransactionsValidatedByTransactionPool(nil)
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.NoError(t, err, "valid transactions block should be accepted")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockWithWrongPrevBlockHash(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).Build()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(2).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.Error(t, err, "transactions block not pointing to the prev block should be rejected")
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockWithTransactionsNotMatchingItsHeader(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now().Add(-1 * time.Second)).Build()
+		block := builders.BlockPair().WithHeight(2).WithPrevBlockHash(prevBlock).WithTransactions(2).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+		h.expectLastCommittedBlock(prevBlock)
+		block.TransactionsBlock.SignedTransactions[1] = builders.TransferTransaction().WithAmountAndTargetAddress(1000, builders.AddressForEd25519SignerForTests(7)).Build()
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.Error(t, err, "transactions block with transactions replaced after signing its header should be rejected")
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockWithTransactionsRejectedByTransactionPool(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now().Add(-1 * time.Second)).Build()
+		block := builders.BlockPair().WithHeight(2).WithPrevBlockHash(prevBlock).WithTransactions(2).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+		h.expectLastCommittedBlock(prevBlock)
+
+		h.expectTransactionsValidatedByTransactionPool(errors.New("transaction already committed"))
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.Error(t, err, "transactions block with invalid transactions should be rejected")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockNotLaterThanPrevBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now()).Build()
+		block := builders.BlockPair().WithHeight(2).WithPrevBlockHash(prevBlock).WithTransactions(2).WithBlockCreated(time.Now().Add(-1 * time.Millisecond)).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+		h.expectLastCommittedBlock(prevBlock)
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.Error(t, err, "transactions block with a timestamp before the prev block should be rejected")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockWithTimestampTooFarFromLocalTime(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		allowedJitter := newHarness().config.ConsensusContextSystemTimestampAllowedJitter()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now().Add(-3 * allowedJitter)).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+
+		for _, blockCreated := range []time.Time{time.Now().Add(-2 * allowedJitter), time.Now().Add(2 * allowedJitter)} {
+			h := newHarness()
+			block := builders.BlockPair().WithHeight(2).WithPrevBlockHash(prevBlock).WithTransactions(2).WithBlockCreated(blockCreated).Build()
+			h.expectLastCommittedBlock(prevBlock)
+
+			err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+			require.Error(t, err, "transactions block with a timestamp %s away from local time should be rejected", time.Until(blockCreated))
+			h.verifyMocks(t)
+		}
+	})
+}
+
+func TestValidateTransactionsBlock_RejectsBlockNotOnTopOfLastCommittedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		prevBlock := builders.BlockPair().WithHeight(1).WithBlockCreated(time.Now().Add(-1 * time.Second)).Build()
+		block := builders.BlockPair().WithHeight(3).WithPrevBlockHash(prevBlock).WithTransactions(2).Build()
+		prevBlockHash := digest.CalcTransactionsBlockHash(prevBlock.TransactionsBlock)
+		h.expectLastCommittedBlock(prevBlock)
+
+		err := h.validateTransactionsBlock(ctx, block.TransactionsBlock, prevBlockHash)
+		require.Error(t, err, "transactions block whose timestamp cannot be compared with its prev block should be rejected")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateResultsBlock_AcceptsBlockMatchingExecution(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().WithStateDiffs(2).Build()
+		prevBlockHash := digest.CalcResultsBlockHash(builders.BlockPair().WithHeight(1).Build().ResultsBlock)
+		resultsBlock := resultsBlockFor(block.TransactionsBlock, prevBlockHash, block.ResultsBlock.TransactionReceipts, block.ResultsBlock.ContractStateDiffs)
+
+		h.expectTransactionSetProcessedByVirtualMachine(block.ResultsBlock.TransactionReceipts, block.ResultsBlock.ContractStateDiffs)
+
+		err := h.validateResultsBlock(ctx, resultsBlock, prevBlockHash, block.TransactionsBlock)
+		require.NoError(t, err, "results block matching the execution should be accepted")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateResultsBlock_RejectsBlockNotPointingToItsTransactionsBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().Build()
+		otherBlock := builders.BlockPair().WithHeight(2).WithTransactions(3).Build()
+		prevBlockHash := digest.CalcResultsBlockHash(builders.BlockPair().WithHeight(1).Build().ResultsBlock)
+		resultsBlock := resultsBlockFor(otherBlock.TransactionsBlock, prevBlockHash, block.ResultsBlock.TransactionReceipts, nil)
+
+		err := h.validateResultsBlock(ctx, resultsBlock, prevBlockHash, block.TransactionsBlock)
+		require.Error(t, err, "results block pointing to another transactions block should be rejected")
+	})
+}
+
+func TestValidateResultsBlock_RejectsBlockWithReceiptsNotMatchingItsHeader(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().Build()
+		otherBlock := builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().Build()
+		prevBlockHash := digest.CalcResultsBlockHash(builders.BlockPair().WithHeight(1).Build().ResultsBlock)
+		resultsBlock := resultsBlockFor(block.TransactionsBlock, prevBlockHash, otherBlock.ResultsBlock.TransactionReceipts, nil)
+		resultsBlock.TransactionReceipts = block.ResultsBlock.TransactionReceipts
+
+		h.expectTransactionSetProcessedByVirtualMachine(block.ResultsBlock.TransactionReceipts, nil)
+
+		err := h.validateResultsBlock(ctx, resultsBlock, prevBlockHash, block.TransactionsBlock)
+		require.Error(t, err, "results block with receipts replaced after signing its header should be rejected")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateResultsBlock_RejectsBlockWithStateDiffDifferentFromExecution(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(2).WithReceiptsForTransactions().Build()
+		prevBlockHash := digest.CalcResultsBlockHash(builders.BlockPair().WithHeight(1).Build().ResultsBlock)
+		claimedStateDiffs := []*protocol.ContractStateDiff{builders.ContractStateDiff().WithStringRecord("amount", "1000").Build()}
+		executedStateDiffs := []*protocol.ContractStateDiff{builders.ContractStateDiff().WithStringRecord("amount", "10").Build()}
+		resultsBlock := resultsBlockFor(block.TransactionsBlock, prevBlockHash, block.ResultsBlock.TransactionReceipts, claimedStateDiffs)
+
+		h.expectTransactionSetProcessedByVirtualMachine(block.ResultsBlock.TransactionReceipts, executedStateDiffs)
+
+		err := h.validateResultsBlock(ctx, resultsBlock, prevBlockHash, block.TransactionsBlock)
+		require.Error(t, err, "results block with a state diff not matching the execution should be rejected")
+		h.verifyMocks(t)
+	})
+}
+
+func TestValidateResultsBlock_RejectsBlockWithTransactionsBeyondTheBlockBudget(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		block := builders.BlockPair().WithHeight(2).WithTransactions(3).WithReceiptsForTransactions().Build()
+		prevBlockHash := digest.CalcResultsBlockHash(builders.BlockPair().WithHeight(1).Build().ResultsBlock)
+		executedReceipts := block.ResultsBlock.TransactionReceipts[:2] // the block budget ran out before the last transaction
+		resultsBlock := resultsBlockFor(block.TransactionsBlock, prevBlockHash, executedReceipts, nil)
+
+		h.expectTransactionSetProcessedByVirtualMachine(executedReceipts, nil)
+
+		err := h.validateResultsBlock(ctx, resultsBlock, prevBlockHash, block.TransactionsBlock)
+		require.Error(t, err, "results block leaving transactions without receipts should be rejected")
+		h.verifyMocks(t)
+	})
+}
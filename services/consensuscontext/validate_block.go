@@ -0,0 +1,135 @@
+package consensuscontext
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"time"
+)
+
+func (s *service) validateTransactionsBlock(ctx context.Context, transactionsBlock *protocol.TransactionsBlockContainer, prevBlockHash primitives.Sha256) error {
+	header := transactionsBlock.Header
+	if header.ProtocolVersion() != primitives.ProtocolVersion(1) {
+		return errors.Errorf("incorrect protocol version: %d", header.ProtocolVersion())
+	}
+	if !header.PrevBlockHashPtr().Equal(prevBlockHash) {
+		return errors.Errorf("transactions prev block hash does not match prev block: %s", prevBlockHash)
+	}
+	if err := s.validateBlockTimestamp(ctx, header.BlockHeight(), header.Timestamp()); err != nil {
+		return err
+	}
+	if header.NumSignedTransactions() != uint32(len(transactionsBlock.SignedTransactions)) {
+		return errors.Errorf("transactions block has %d transactions but its header says %d", len(transactionsBlock.SignedTransactions), header.NumSignedTransactions())
+	}
+	// the block hash only covers the header, so the header must commit to the transactions
+	if rootHash := digest.CalcTransactionsRootHash(transactionsBlock.SignedTransactions); !bytes.Equal(header.TransactionsRootHash(), rootHash) {
+		return errors.Errorf("transactions root hash of the header does not match the transactions: %s", rootHash)
+	}
+
+	_, err := s.transactionPool.ValidateTransactionsForOrdering(ctx, &services.ValidateTransactionsForOrderingInput{
+		BlockHeight:        header.BlockHeight() - 1, // the transactions are ordered on top of the previous block
+		SignedTransactions: transactionsBlock.SignedTransactions,
+	})
+	return err
+}
+
+// contracts see the block timestamp through Sdk.Env, so a proposer must not be able to move it back in time or far from the clocks of the validators
+func (s *service) validateBlockTimestamp(ctx context.Context, blockHeight primitives.BlockHeight, timestamp primitives.TimestampNano) error {
+	output, err := s.stateStorage.GetStateStorageBlockHeight(ctx, &services.GetStateStorageBlockHeightInput{})
+	if err != nil {
+		return err
+	}
+	if output.LastCommittedBlockHeight+1 != blockHeight {
+		return errors.Errorf("cannot validate timestamp of block %d on top of last committed block %d", blockHeight, output.LastCommittedBlockHeight)
+	}
+	if timestamp <= output.LastCommittedBlockTimestamp {
+		return errors.Errorf("block timestamp %d is not later than prev block timestamp %d", timestamp, output.LastCommittedBlockTimestamp)
+	}
+
+	now := time.Now().UnixNano()
+	allowedJitter := s.config.ConsensusContextSystemTimestampAllowedJitter().Nanoseconds()
+	if int64(timestamp) > now+allowedJitter || int64(timestamp) < now-allowedJitter {
+		return errors.Errorf("block timestamp %d is more than %s away from local time %d", timestamp, s.config.ConsensusContextSystemTimestampAllowedJitter(), now)
+	}
+	return nil
+}
+
+// the results are trusted only if executing the transactions here gives the same receipts and state diffs
+func (s *service) validateResultsBlock(ctx context.Context, resultsBlock *protocol.ResultsBlockContainer, prevBlockHash primitives.Sha256, transactionsBlock *protocol.TransactionsBlockContainer) error {
+	header := resultsBlock.Header
+	if header.BlockHeight() != transactionsBlock.Header.BlockHeight() {
+		return errors.Errorf("block height of results %d is not equal to transactions %d", header.BlockHeight(), transactionsBlock.Header.BlockHeight())
+	}
+	if !header.PrevBlockHashPtr().Equal(prevBlockHash) {
+		return errors.Errorf("results prev block hash does not match prev block: %s", prevBlockHash)
+	}
+	if txHash := digest.CalcTransactionsBlockHash(transactionsBlock); !header.TransactionsBlockHashPtr().Equal(txHash) {
+		return errors.Errorf("results block does not point to its transactions block: %s", txHash)
+	}
+
+	output, err := s.virtualMachine.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+		BlockHeight:        header.BlockHeight(),
+		BlockTimestamp:     transactionsBlock.Header.Timestamp(),
+		SignedTransactions: transactionsBlock.SignedTransactions,
+	})
+	if err != nil {
+		return err
+	}
+
+	// the virtual machine stops at the block budget, a block whose transactions don't all fit in it must not be committed
+	// with transactions that have no receipt
+	if len(output.TransactionReceipts) != len(transactionsBlock.SignedTransactions) || len(output.TransactionReceipts) != int(transactionsBlock.Header.NumSignedTransactions()) {
+		return errors.Errorf("execution produced %d receipts for %d transactions, the transactions block exceeds the block execution budget", len(output.TransactionReceipts), len(transactionsBlock.SignedTransactions))
+	}
+	if len(output.TransactionReceipts) != len(resultsBlock.TransactionReceipts) {
+		return errors.Errorf("results block has %d receipts but execution produced %d", len(resultsBlock.TransactionReceipts), len(output.TransactionReceipts))
+	}
+	for i, receipt := range output.TransactionReceipts {
+		if !bytes.Equal(receipt.Raw(), resultsBlock.TransactionReceipts[i].Raw()) {
+			return errors.Errorf("receipt of transaction %s does not match its execution", receipt.Txhash())
+		}
+	}
+	if !isSameStateDiff(output.ContractStateDiffs, resultsBlock.ContractStateDiffs) {
+		return errors.New("state diff of results block does not match execution")
+	}
+
+	// the block hash only covers the header, so the header must commit to the receipts and state diff
+	if rootHash := digest.CalcReceiptsRootHash(resultsBlock.TransactionReceipts); !bytes.Equal(header.ReceiptsRootHash(), rootHash) {
+		return errors.Errorf("receipts root hash of the header does not match the receipts: %s", rootHash)
+	}
+	if stateDiffHash := digest.CalcStateDiffHash(resultsBlock.ContractStateDiffs); !bytes.Equal(header.StateDiffHash(), stateDiffHash) {
+		return errors.Errorf("state diff hash of the header does not match the state diff: %s", stateDiffHash)
+	}
+	return nil
+}
+
+// the virtual machine does not order the state diff, so records are compared regardless of their order
+func isSameStateDiff(contractStateDiffs []*protocol.ContractStateDiff, otherContractStateDiffs []*protocol.ContractStateDiff) bool {
+	records := stateDiffRecords(contractStateDiffs)
+	otherRecords := stateDiffRecords(otherContractStateDiffs)
+	if len(records) != len(otherRecords) {
+		return false
+	}
+	for key, value := range records {
+		otherValue, found := otherRecords[key]
+		if !found || !bytes.Equal(value, otherValue) {
+			return false
+		}
+	}
+	return true
+}
+
+func stateDiffRecords(contractStateDiffs []*protocol.ContractStateDiff) map[string][]byte {
+	records := make(map[string][]byte)
+	for _, contractStateDiff := range contractStateDiffs {
+		for i := contractStateDiff.StateDiffsIterator(); i.HasNext(); {
+			record := i.NextStateDiffs()
+			records[string(contractStateDiff.ContractName())+"\x00"+string(record.Key())] = record.Value()
+		}
+	}
+	return records
+}
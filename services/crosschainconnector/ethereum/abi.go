@@ -0,0 +1,417 @@
+package ethereum
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"strconv"
+	"strings"
+)
+
+const ABI_WORD_SIZE = 32
+const ETHEREUM_ADDRESS_SIZE = 20
+
+type abiArgument struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Indexed bool   `json:"indexed"`
+}
+
+// events share the json abi entry format, their params are inputs
+type abiFunction struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Inputs    []abiArgument `json:"inputs"`
+	Outputs   []abiArgument `json:"outputs"`
+	Anonymous bool          `json:"anonymous"`
+}
+
+// only the elementary types have a natural mapping to method arguments, int and arrays are rejected
+type abiType struct {
+	name    string
+	kind    abiKind
+	size    int // bits for uint, bytes for fixed bytes
+	dynamic bool
+}
+
+type abiKind int
+
+const (
+	abiKindUint abiKind = iota
+	abiKindAddress
+	abiKindBool
+	abiKindFixedBytes
+	abiKindBytes
+	abiKindString
+)
+
+func findAbiFunction(jsonAbi string, functionName string, argCount int) (*abiFunction, error) {
+	var functions []*abiFunction
+	err := json.Unmarshal([]byte(jsonAbi), &functions)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse ethereum json abi")
+	}
+	for _, function := range functions {
+		if (function.Type == "function" || function.Type == "") && function.Name == functionName && len(function.Inputs) == argCount {
+			return function, nil
+		}
+	}
+	return nil, errors.Errorf("ethereum function '%s' with %d args not found in json abi", functionName, argCount)
+}
+
+func findAbiEvent(jsonAbi string, eventName string) (*abiFunction, error) {
+	var entries []*abiFunction
+	err := json.Unmarshal([]byte(jsonAbi), &entries)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse ethereum json abi")
+	}
+	for _, entry := range entries {
+		if entry.Type == "event" && entry.Name == eventName {
+			if entry.Anonymous {
+				return nil, errors.Errorf("ethereum event '%s' is anonymous and can't be identified by its topic", eventName)
+			}
+			return entry, nil
+		}
+	}
+	return nil, errors.Errorf("ethereum event '%s' not found in json abi", eventName)
+}
+
+func (f *abiFunction) signature() string {
+	types := make([]string, len(f.Inputs))
+	for i, input := range f.Inputs {
+		types[i] = canonicalAbiTypeName(input.Type)
+	}
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(types, ","))
+}
+
+func (f *abiFunction) selector() []byte {
+	return hash.CalcKeccak256([]byte(f.signature()))[:4]
+}
+
+// the first topic of a non anonymous event log
+func (f *abiFunction) eventTopic() []byte {
+	return hash.CalcKeccak256([]byte(f.signature()))
+}
+
+func canonicalAbiTypeName(typeName string) string {
+	if typeName == "uint" {
+		return "uint256"
+	}
+	return typeName
+}
+
+func parseAbiType(typeName string) (*abiType, error) {
+	typeName = canonicalAbiTypeName(typeName)
+	switch {
+	case typeName == "address":
+		return &abiType{name: typeName, kind: abiKindAddress}, nil
+	case typeName == "bool":
+		return &abiType{name: typeName, kind: abiKindBool}, nil
+	case typeName == "bytes":
+		return &abiType{name: typeName, kind: abiKindBytes, dynamic: true}, nil
+	case typeName == "string":
+		return &abiType{name: typeName, kind: abiKindString, dynamic: true}, nil
+	case strings.HasPrefix(typeName, "uint"):
+		bits, err := strconv.Atoi(strings.TrimPrefix(typeName, "uint"))
+		if err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+			return nil, errors.Errorf("invalid ethereum abi type '%s'", typeName)
+		}
+		return &abiType{name: typeName, kind: abiKindUint, size: bits}, nil
+	case strings.HasPrefix(typeName, "bytes"):
+		size, err := strconv.Atoi(strings.TrimPrefix(typeName, "bytes"))
+		if err != nil || size < 1 || size > ABI_WORD_SIZE {
+			return nil, errors.Errorf("invalid ethereum abi type '%s'", typeName)
+		}
+		return &abiType{name: typeName, kind: abiKindFixedBytes, size: size}, nil
+	}
+	return nil, errors.Errorf("unsupported ethereum abi type '%s'", typeName)
+}
+
+// calldata is the 4 byte selector followed by the args, dynamic args are placed after all the static heads
+func encodeCallData(function *abiFunction, args []*protocol.MethodArgument) ([]byte, error) {
+	if len(args) != len(function.Inputs) {
+		return nil, errors.Errorf("ethereum function '%s' takes %d args but %d were given", function.Name, len(function.Inputs), len(args))
+	}
+
+	var heads, tails []byte
+	tailOffset := len(args) * ABI_WORD_SIZE
+	for i, input := range function.Inputs {
+		typ, err := parseAbiType(input.Type)
+		if err != nil {
+			return nil, err
+		}
+		encoded, err := encodeAbiValue(typ, args[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not encode arg %d of ethereum function '%s'", i, function.Name)
+		}
+		if typ.dynamic {
+			heads = append(heads, uintWord(uint64(tailOffset+len(tails)))...)
+			tails = append(tails, encoded...)
+		} else {
+			heads = append(heads, encoded...)
+		}
+	}
+
+	res := append([]byte{}, function.selector()...)
+	res = append(res, heads...)
+	return append(res, tails...), nil
+}
+
+func encodeAbiValue(typ *abiType, arg *protocol.MethodArgument) ([]byte, error) {
+	switch typ.kind {
+	case abiKindUint:
+		var value []byte
+		switch {
+		case arg.IsTypeUint32Value():
+			value = uintWord(uint64(arg.Uint32Value()))
+		case arg.IsTypeUint64Value():
+			value = uintWord(arg.Uint64Value())
+		case arg.IsTypeBytesValue():
+			if len(arg.BytesValue()) > ABI_WORD_SIZE {
+				return nil, errors.Errorf("%s value is longer than %d bytes", typ.name, ABI_WORD_SIZE)
+			}
+			value = leftPad(arg.BytesValue())
+		default:
+			return nil, errors.Errorf("%s must be given as uint32, uint64 or big endian bytes", typ.name)
+		}
+		if !fitsInBits(value, typ.size) {
+			return nil, errors.Errorf("value overflows %s", typ.name)
+		}
+		return value, nil
+	case abiKindAddress:
+		address, err := addressFromArgument(arg)
+		if err != nil {
+			return nil, err
+		}
+		return leftPad(address), nil
+	case abiKindBool:
+		if !arg.IsTypeUint32Value() || arg.Uint32Value() > 1 {
+			return nil, errors.New("bool must be given as uint32 0 or 1")
+		}
+		return uintWord(uint64(arg.Uint32Value())), nil
+	case abiKindFixedBytes:
+		if !arg.IsTypeBytesValue() || len(arg.BytesValue()) != typ.size {
+			return nil, errors.Errorf("%s must be given as %d bytes", typ.name, typ.size)
+		}
+		return rightPad(arg.BytesValue()), nil
+	case abiKindBytes:
+		if !arg.IsTypeBytesValue() {
+			return nil, errors.New("bytes must be given as bytes")
+		}
+		return append(uintWord(uint64(len(arg.BytesValue()))), rightPad(arg.BytesValue())...), nil
+	case abiKindString:
+		if !arg.IsTypeStringValue() {
+			return nil, errors.New("string must be given as string")
+		}
+		return append(uintWord(uint64(len(arg.StringValue()))), rightPad([]byte(arg.StringValue()))...), nil
+	}
+	return nil, errors.Errorf("unsupported ethereum abi type '%s'", typ.name)
+}
+
+func decodeReturnData(function *abiFunction, data []byte) (*protocol.MethodArgumentArray, error) {
+	outputs, err := decodeArguments(function.Outputs, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode outputs of ethereum function '%s'", function.Name)
+	}
+	return (&protocol.MethodArgumentArrayBuilder{Arguments: outputs}).Build(), nil
+}
+
+// indexed params are taken from the topics (after the event topic) and the rest are decoded from the log data,
+// indexed dynamic values are only stored as their hash so the 32 byte topic is returned as is
+func decodeEventLog(event *abiFunction, topics [][]byte, data []byte) (*protocol.MethodArgumentArray, error) {
+	var indexed, nonIndexed []abiArgument
+	for _, input := range event.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		} else {
+			nonIndexed = append(nonIndexed, input)
+		}
+	}
+	if len(topics) != len(indexed)+1 {
+		return nil, errors.Errorf("ethereum event '%s' has %d indexed params but the log has %d topics", event.Name, len(indexed), len(topics))
+	}
+	decodedNonIndexed, err := decodeArguments(nonIndexed, data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not decode data of ethereum event '%s'", event.Name)
+	}
+
+	var args []*protocol.MethodArgumentBuilder
+	nextIndexed, nextNonIndexed := 1, 0
+	for _, input := range event.Inputs {
+		if !input.Indexed {
+			args = append(args, decodedNonIndexed[nextNonIndexed])
+			nextNonIndexed++
+			continue
+		}
+		typ, err := parseAbiType(input.Type)
+		if err != nil {
+			return nil, err
+		}
+		topic := topics[nextIndexed]
+		nextIndexed++
+		if len(topic) != ABI_WORD_SIZE {
+			return nil, errors.Errorf("topic of ethereum event '%s' is not %d bytes", event.Name, ABI_WORD_SIZE)
+		}
+		var argument *protocol.MethodArgumentBuilder
+		if typ.dynamic {
+			argument = &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: topic}
+		} else {
+			argument, err = decodeAbiValue(typ, topic)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not decode topic of ethereum event '%s'", event.Name)
+			}
+		}
+		args = append(args, namedArgument(argument, input, typ))
+	}
+	return (&protocol.MethodArgumentArrayBuilder{Arguments: args}).Build(), nil
+}
+
+func decodeArguments(arguments []abiArgument, data []byte) ([]*protocol.MethodArgumentBuilder, error) {
+	if len(data) < len(arguments)*ABI_WORD_SIZE {
+		return nil, errors.Errorf("%d bytes is too short for %d values", len(data), len(arguments))
+	}
+
+	var res []*protocol.MethodArgumentBuilder
+	for i, argument := range arguments {
+		typ, err := parseAbiType(argument.Type)
+		if err != nil {
+			return nil, err
+		}
+		word := data[i*ABI_WORD_SIZE : (i+1)*ABI_WORD_SIZE]
+		if typ.dynamic {
+			word, err = readDynamicValue(data, word)
+			if err != nil {
+				return nil, errors.Wrapf(err, "value %d", i)
+			}
+		}
+		decoded, err := decodeAbiValue(typ, word)
+		if err != nil {
+			return nil, errors.Wrapf(err, "value %d", i)
+		}
+		res = append(res, namedArgument(decoded, argument, typ))
+	}
+	return res, nil
+}
+
+func namedArgument(builder *protocol.MethodArgumentBuilder, argument abiArgument, typ *abiType) *protocol.MethodArgumentBuilder {
+	builder.Name = argument.Name
+	if builder.Name == "" {
+		builder.Name = typ.name
+	}
+	return builder
+}
+
+func readDynamicValue(data []byte, offsetWord []byte) ([]byte, error) {
+	offset, ok := wordToInt(offsetWord, len(data))
+	if !ok || offset+ABI_WORD_SIZE > len(data) {
+		return nil, errors.New("dynamic value offset is out of bounds")
+	}
+	length, ok := wordToInt(data[offset:offset+ABI_WORD_SIZE], len(data))
+	start := offset + ABI_WORD_SIZE
+	if !ok || start+length > len(data) {
+		return nil, errors.New("dynamic value length is out of bounds")
+	}
+	return data[start : start+length], nil
+}
+
+// uints up to 64 bits become uint32 or uint64, larger ones are returned as 32 big endian bytes
+func decodeAbiValue(typ *abiType, value []byte) (*protocol.MethodArgumentBuilder, error) {
+	switch typ.kind {
+	case abiKindUint:
+		if !fitsInBits(value, typ.size) {
+			return nil, errors.Errorf("value overflows %s", typ.name)
+		}
+		switch {
+		case typ.size <= 32:
+			return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, Uint32Value: binary.BigEndian.Uint32(value[ABI_WORD_SIZE-4:])}, nil
+		case typ.size <= 64:
+			return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: binary.BigEndian.Uint64(value[ABI_WORD_SIZE-8:])}, nil
+		default:
+			return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: value}, nil
+		}
+	case abiKindAddress:
+		if !fitsInBits(value, ETHEREUM_ADDRESS_SIZE*8) {
+			return nil, errors.New("address has non zero padding")
+		}
+		return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: value[ABI_WORD_SIZE-ETHEREUM_ADDRESS_SIZE:]}, nil
+	case abiKindBool:
+		if !fitsInBits(value, 1) {
+			return nil, errors.New("bool is neither 0 nor 1")
+		}
+		return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, Uint32Value: uint32(value[ABI_WORD_SIZE-1])}, nil
+	case abiKindFixedBytes:
+		return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: value[:typ.size]}, nil
+	case abiKindBytes:
+		return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: value}, nil
+	case abiKindString:
+		return &protocol.MethodArgumentBuilder{Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: string(value)}, nil
+	}
+	return nil, errors.Errorf("unsupported ethereum abi type '%s'", typ.name)
+}
+
+func addressFromArgument(arg *protocol.MethodArgument) ([]byte, error) {
+	switch {
+	case arg.IsTypeBytesValue() && len(arg.BytesValue()) == ETHEREUM_ADDRESS_SIZE:
+		return arg.BytesValue(), nil
+	case arg.IsTypeStringValue():
+		return parseAddress(arg.StringValue())
+	}
+	return nil, errors.Errorf("address must be given as %d bytes or a hex string", ETHEREUM_ADDRESS_SIZE)
+}
+
+func parseAddress(address string) ([]byte, error) {
+	res, err := hex.DecodeString(strings.TrimPrefix(address, "0x"))
+	if err != nil || len(res) != ETHEREUM_ADDRESS_SIZE {
+		return nil, errors.Errorf("invalid ethereum address '%s'", address)
+	}
+	return res, nil
+}
+
+func uintWord(value uint64) []byte {
+	res := make([]byte, ABI_WORD_SIZE)
+	binary.BigEndian.PutUint64(res[ABI_WORD_SIZE-8:], value)
+	return res
+}
+
+func wordToInt(word []byte, limit int) (int, bool) {
+	if !fitsInBits(word, 64) {
+		return 0, false
+	}
+	value := binary.BigEndian.Uint64(word[ABI_WORD_SIZE-8:])
+	if value > uint64(limit) {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// word is a full 32 byte big endian value
+func fitsInBits(word []byte, bits int) bool {
+	for i, b := range word {
+		bitsBelow := (ABI_WORD_SIZE - 1 - i) * 8
+		if bitsBelow >= bits {
+			if b != 0 {
+				return false
+			}
+		} else if bitsBelow+8 > bits && b>>uint(bits-bitsBelow) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func leftPad(value []byte) []byte {
+	res := make([]byte, ABI_WORD_SIZE-len(value), ABI_WORD_SIZE)
+	return append(res, value...)
+}
+
+func rightPad(value []byte) []byte {
+	res := append([]byte{}, value...)
+	if len(value)%ABI_WORD_SIZE != 0 {
+		res = append(res, make([]byte, ABI_WORD_SIZE-len(value)%ABI_WORD_SIZE)...)
+	}
+	return res
+}
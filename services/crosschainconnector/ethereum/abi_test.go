@@ -0,0 +1,108 @@
+package ethereum
+
+import (
+	"encoding/hex"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const ERC20_JSON_ABI = `[
+	{"type":"function","name":"balanceOf","constant":true,"inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]},
+	{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint"}],"outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"describe","inputs":[{"name":"id","type":"uint32"},{"name":"tag","type":"string"}],"outputs":[{"name":"name","type":"string"},{"name":"decimals","type":"uint8"},{"name":"data","type":"bytes"}]},
+	{"type":"event","name":"Transfer","inputs":[]}
+]`
+
+const TEST_ADDRESS = "0x00112233445566778899aabbccddeeff00112233"
+
+func TestEncodeCallData_StaticArgs(t *testing.T) {
+	function, err := findAbiFunction(ERC20_JSON_ABI, "transfer", 2)
+	require.NoError(t, err)
+	require.Equal(t, "transfer(address,uint256)", function.signature(), "uint should be canonicalized to uint256")
+
+	callData, err := encodeCallData(function, args(TEST_ADDRESS, uint64(1000)))
+	require.NoError(t, err)
+	require.Equal(t, "a9059cbb"+
+		"00000000000000000000000000112233445566778899aabbccddeeff00112233"+
+		"00000000000000000000000000000000000000000000000000000000000003e8", hex.EncodeToString(callData))
+}
+
+func TestEncodeCallData_DynamicArgsArePlacedAfterHeads(t *testing.T) {
+	function, err := findAbiFunction(ERC20_JSON_ABI, "describe", 2)
+	require.NoError(t, err)
+
+	callData, err := encodeCallData(function, args(uint32(7), "hello"))
+	require.NoError(t, err)
+	require.Equal(t, 4+4*ABI_WORD_SIZE, len(callData), "calldata should be selector, two heads, length and padded data")
+	require.Equal(t, strings.Join([]string{
+		"0000000000000000000000000000000000000000000000000000000000000007",
+		"0000000000000000000000000000000000000000000000000000000000000040",
+		"0000000000000000000000000000000000000000000000000000000000000005",
+		"68656c6c6f000000000000000000000000000000000000000000000000000000",
+	}, ""), hex.EncodeToString(callData[4:]))
+}
+
+func TestEncodeCallData_RejectsMismatchingArgs(t *testing.T) {
+	function, err := findAbiFunction(ERC20_JSON_ABI, "transfer", 2)
+	require.NoError(t, err)
+
+	_, err = encodeCallData(function, args("not an address", uint64(1)))
+	require.Error(t, err, "invalid address should be rejected")
+
+	_, err = encodeCallData(function, args(TEST_ADDRESS, "1000"))
+	require.Error(t, err, "string given for uint should be rejected")
+
+	function, err = findAbiFunction(ERC20_JSON_ABI, "describe", 2)
+	require.NoError(t, err)
+	_, err = encodeCallData(function, args(uint64(1)<<32, "hello"))
+	require.Error(t, err, "value that overflows uint32 should be rejected")
+
+	_, err = findAbiFunction(ERC20_JSON_ABI, "transfer", 1)
+	require.Error(t, err, "function should be matched by arg count")
+}
+
+func TestDecodeReturnData_MapsTypesToMethodArguments(t *testing.T) {
+	function, err := findAbiFunction(ERC20_JSON_ABI, "describe", 2)
+	require.NoError(t, err)
+	returnData, _ := hex.DecodeString(strings.Join([]string{
+		"0000000000000000000000000000000000000000000000000000000000000060",
+		"0000000000000000000000000000000000000000000000000000000000000012",
+		"00000000000000000000000000000000000000000000000000000000000000a0",
+		"0000000000000000000000000000000000000000000000000000000000000004",
+		"4f52425300000000000000000000000000000000000000000000000000000000",
+		"0000000000000000000000000000000000000000000000000000000000000002",
+		"beef000000000000000000000000000000000000000000000000000000000000",
+	}, ""))
+
+	outputArgs, err := decodeReturnData(function, returnData)
+	require.NoError(t, err)
+	outputs := methodArgumentArrayToSlice(outputArgs)
+	require.Len(t, outputs, 3)
+	require.Equal(t, "name", outputs[0].Name(), "output should be named from the abi")
+	require.Equal(t, "ORBS", outputs[0].StringValue())
+	require.Equal(t, uint32(18), outputs[1].Uint32Value(), "uint8 should be returned as uint32")
+	require.Equal(t, []byte{0xbe, 0xef}, outputs[2].BytesValue())
+}
+
+func TestDecodeReturnData_RejectsCorruptData(t *testing.T) {
+	function, err := findAbiFunction(ERC20_JSON_ABI, "transfer", 2)
+	require.NoError(t, err)
+
+	_, err = decodeReturnData(function, []byte{})
+	require.Error(t, err, "empty return data should be rejected")
+
+	_, err = decodeReturnData(function, uintWord(2))
+	require.Error(t, err, "bool other than 0 or 1 should be rejected")
+
+	function, err = findAbiFunction(ERC20_JSON_ABI, "describe", 2)
+	require.NoError(t, err)
+	_, err = decodeReturnData(function, append(append(uintWord(1000), uintWord(0)...), uintWord(0)...))
+	require.Error(t, err, "out of bounds offset should be rejected")
+}
+
+func args(values ...interface{}) []*protocol.MethodArgument {
+	return methodArgumentArrayToSlice(builders.MethodArgumentsArray(values...))
+}
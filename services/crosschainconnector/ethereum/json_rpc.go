@@ -0,0 +1,125 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/pkg/errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type jsonRpcRequest struct {
+	JsonRpc string        `json:"jsonrpc"`
+	Id      int           `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type jsonRpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *jsonRpcError   `json:"error"`
+}
+
+type jsonRpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// the json rpc error code of an ethereum call that reverted (older endpoints only say so in the message)
+const JSON_RPC_ERROR_CODE_EXECUTION_REVERTED = 3
+
+var errJsonRpcNoResult = errors.New("ethereum json rpc response has no result")
+
+// the endpoint could not give an answer (unreachable, timed out, behind the pinned block or answered garbage),
+// which says nothing about ethereum, so the virtual machine fails whatever made the call instead of handing it to the contract
+type EndpointFailure struct {
+	Cause error
+}
+
+func (f *EndpointFailure) Error() string {
+	return "ethereum endpoint failed: " + f.Cause.Error()
+}
+
+func IsNodeFault(err error) bool {
+	_, ok := err.(*EndpointFailure)
+	return ok
+}
+
+// every request is bounded by the call timeout so a stuck endpoint can't stall block execution,
+// only a reverted call and a missing result are answers about ethereum, any other failure is an EndpointFailure
+func (s *service) callJsonRpc(ctx context.Context, method string, params []interface{}, result interface{}) error {
+	if s.config.EthereumEndpoint() == "" {
+		return &EndpointFailure{errors.New("ethereum endpoint is not configured")}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.config.EthereumCallTimeout())
+	defer cancel()
+
+	body, err := json.Marshal(&jsonRpcRequest{
+		JsonRpc: "2.0",
+		Id:      1,
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.config.EthereumEndpoint(), bytes.NewReader(body))
+	if err != nil {
+		return &EndpointFailure{errors.Wrap(err, "invalid ethereum endpoint")}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return &EndpointFailure{errors.Wrap(err, "ethereum json rpc request failed")}
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return &EndpointFailure{errors.Errorf("ethereum json rpc request failed with http status %d", res.StatusCode)}
+	}
+
+	response := &jsonRpcResponse{}
+	err = json.NewDecoder(res.Body).Decode(response)
+	if err != nil {
+		return &EndpointFailure{errors.Wrap(err, "could not parse ethereum json rpc response")}
+	}
+	if response.Error != nil {
+		err := errors.Errorf("ethereum json rpc error %d: %s", response.Error.Code, response.Error.Message)
+		if response.Error.isExecutionReverted() {
+			return err
+		}
+		return &EndpointFailure{err}
+	}
+	if len(response.Result) == 0 || string(response.Result) == "null" {
+		return errJsonRpcNoResult
+	}
+	err = json.Unmarshal(response.Result, result)
+	if err != nil {
+		return &EndpointFailure{errors.Wrapf(err, "could not parse ethereum json rpc %s result", method)}
+	}
+	return nil
+}
+
+func (e *jsonRpcError) isExecutionReverted() bool {
+	return e.Code == JSON_RPC_ERROR_CODE_EXECUTION_REVERTED || strings.Contains(e.Message, "execution reverted")
+}
+
+func decodeHex(value string) ([]byte, error) {
+	res, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "ethereum json rpc value '%s' is not hex", value)
+	}
+	return res, nil
+}
+
+func decodeHexUint64(value string) (uint64, error) {
+	res, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	if err != nil {
+		return 0, errors.Wrapf(err, "ethereum json rpc value '%s' is not a hex number", value)
+	}
+	return res, nil
+}
@@ -2,16 +2,161 @@ package ethereum
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"net/http"
+	"sync"
 )
 
+var LogTag = log.Service("crosschain-connector-ethereum")
+
+// the crosschain connector along with the transaction log lookups contracts use to confirm ethereum events
+type Connector interface {
+	services.CrosschainConnector
+	TransactionLogGetter
+}
+
 type service struct {
+	config config.EthereumCrosschainConnectorConfig
+	logger log.BasicLogger
+	client *http.Client
+
+	mutex    *sync.Mutex
+	calls    *resultCache
+	receipts *resultCache
 }
 
-func NewEthereumCrosschainConnector() services.CrosschainConnector {
-	return &service{}
+func NewEthereumCrosschainConnector(config config.EthereumCrosschainConnectorConfig, logger log.BasicLogger) Connector {
+	return &service{
+		config:   config,
+		logger:   logger.WithTags(LogTag),
+		client:   &http.Client{},
+		mutex:    &sync.Mutex{},
+		calls:    newResultCache(int(config.EthereumCallCacheSize())),
+		receipts: newResultCache(int(config.EthereumCallCacheSize())),
+	}
 }
 
+// the block number is pinned by the transaction so every node sees the same ethereum state and results can be cached
 func (s *service) EthereumCallContract(ctx context.Context, input *services.EthereumCallContractInput) (*services.EthereumCallContractOutput, error) {
-	panic("Not implemented")
+	if input.EthereumBlockNumber == 0 {
+		return nil, errors.New("ethereum block number must be pinned")
+	}
+	address, err := parseAddress(input.EthereumContractAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	args := methodArgumentArrayToSlice(input.EthereumInputArgumentArray)
+	function, err := findAbiFunction(input.EthereumJsonAbi, input.EthereumFunctionName, len(args))
+	if err != nil {
+		return nil, err
+	}
+	callData, err := encodeCallData(function, args)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("%x:%d:%x", address, input.EthereumBlockNumber, callData)
+	returnData, found := s.getCached(s.calls, cacheKey)
+	if !found {
+		returnData, err = s.ethCall(ctx, address, callData, input.EthereumBlockNumber)
+		if err != nil {
+			s.logger.Info("ethereum call failed", log.Error(err), log.String("function", function.signature()), log.Uint64("block-number", input.EthereumBlockNumber))
+			return nil, err
+		}
+		s.addCached(s.calls, cacheKey, returnData)
+	}
+
+	outputArgs, err := decodeReturnData(function, returnData)
+	if err != nil {
+		return nil, err
+	}
+	return &services.EthereumCallContractOutput{
+		EthereumOutputArgumentArray: outputArgs,
+	}, nil
+}
+
+type ethCallParams struct {
+	To   string `json:"to"`
+	Data string `json:"data"`
+}
+
+func (s *service) ethCall(ctx context.Context, address []byte, callData []byte, blockNumber uint64) ([]byte, error) {
+	var result string
+	err := s.callJsonRpc(ctx, "eth_call", []interface{}{
+		&ethCallParams{To: "0x" + hex.EncodeToString(address), Data: "0x" + hex.EncodeToString(callData)},
+		fmt.Sprintf("0x%x", blockNumber),
+	}, &result)
+	if err == errJsonRpcNoResult {
+		return nil, &EndpointFailure{err}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeHex(result)
+}
+
+func methodArgumentArrayToSlice(args *protocol.MethodArgumentArray) []*protocol.MethodArgument {
+	var res []*protocol.MethodArgument
+	if args == nil {
+		return res
+	}
+	for i := args.ArgumentsIterator(); i.HasNext(); {
+		res = append(res, i.NextArguments())
+	}
+	return res
+}
+
+func (s *service) getCached(cache *resultCache, key string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return cache.get(key)
+}
+
+func (s *service) addCached(cache *resultCache, key string, value []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	cache.add(key, value)
+}
+
+// evicts in insertion order, entries are only added once they can no longer change so there is nothing to invalidate
+type resultCache struct {
+	maxSize int
+	entries map[string][]byte
+	order   []string
+}
+
+func newResultCache(maxSize int) *resultCache {
+	return &resultCache{
+		maxSize: maxSize,
+		entries: make(map[string][]byte),
+	}
+}
+
+func (c *resultCache) get(key string) ([]byte, bool) {
+	value, found := c.entries[key]
+	return value, found
+}
+
+func (c *resultCache) add(key string, value []byte) {
+	if c.maxSize <= 0 {
+		return
+	}
+	if _, found := c.entries[key]; found {
+		return
+	}
+	if len(c.order) >= c.maxSize {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.entries[key] = value
+	c.order = append(c.order, key)
 }
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+const OWNER = "0xffeeddccbbaa99887766554433221100ffeeddcc"
+const BALANCE_OF_1000 = "0x00000000000000000000000000000000000000000000000000000000000003e8"
+
+func TestEthereumCallContract_CallsAtPinnedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWith(BALANCE_OF_1000)
+
+		output, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, uint64(1000), output.EthereumOutputArgumentArray.ArgumentsIterator().NextArguments().Uint64Value(), "balance should be decoded")
+
+		requests := h.receivedRequests()
+		require.Len(t, requests, 1)
+		require.Equal(t, "eth_call", requests[0].Method)
+		require.Len(t, requests[0].Params, 2)
+
+		var callParams map[string]string
+		var blockNumber string
+		require.NoError(t, json.Unmarshal(requests[0].Params[0], &callParams))
+		require.NoError(t, json.Unmarshal(requests[0].Params[1], &blockNumber))
+		require.Equal(t, TEST_CONTRACT_ADDRESS, callParams["to"])
+		require.Equal(t, "0x70a08231000000000000000000000000ffeeddccbbaa99887766554433221100ffeeddcc", callParams["data"], "calldata should be the selector and the encoded owner")
+		require.Equal(t, "0x190", blockNumber, "call should be made at the pinned block")
+	})
+}
+
+func TestEthereumCallContract_CachesResultsPerBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWith(BALANCE_OF_1000)
+
+		for i := 0; i < 3; i++ {
+			_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+			require.NoError(t, err, "call should succeed")
+		}
+		require.Len(t, h.receivedRequests(), 1, "repeated calls at the same block should be served from cache")
+
+		_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 401))
+		require.NoError(t, err, "call should succeed")
+		require.Len(t, h.receivedRequests(), 2, "call at a different block should reach the endpoint")
+	})
+}
+
+func TestEthereumCallContract_RequiresPinnedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+
+		_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 0))
+		require.Error(t, err, "call without a block number should fail")
+		require.Empty(t, h.receivedRequests(), "endpoint should not be called")
+	})
+}
+
+func TestEthereumCallContract_FailsOnJsonRpcError(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithError("execution reverted")
+
+		_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+		require.Error(t, err, "call should fail")
+		require.Contains(t, err.Error(), "execution reverted")
+		require.False(t, ethereum.IsNodeFault(err), "reverted call is an answer about ethereum the contract should get")
+
+		h.respondWithError("missing trie node")
+		_, err = h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+		require.True(t, ethereum.IsNodeFault(err), "endpoint that can't serve the pinned block should fail as a node fault")
+
+		h.respondWithError("")
+		h.respondWith(BALANCE_OF_1000)
+		_, err = h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+		require.NoError(t, err, "failed calls should not be cached")
+	})
+}
+
+func TestEthereumCallContract_TimesOut(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWith(BALANCE_OF_1000)
+		h.respondAfter(TEST_CALL_TIMEOUT * 3)
+
+		start := time.Now()
+		_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, 400))
+		require.Error(t, err, "slow endpoint should time out")
+		require.True(t, ethereum.IsNodeFault(err), "timeout should fail as a node fault and not reach the contract")
+		require.True(t, time.Since(start) < TEST_CALL_TIMEOUT*2, "call should give up at the timeout")
+	})
+}
+
+func TestEthereumCallContract_RejectsArgsNotMatchingAbi(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+
+		input := balanceOfInput(OWNER, 400)
+		input.EthereumInputArgumentArray = builders.MethodArgumentsArray(uint64(17))
+		_, err := h.service.EthereumCallContract(ctx, input)
+		require.Error(t, err, "uint64 is not an address")
+		require.Empty(t, h.receivedRequests(), "endpoint should not be called")
+	})
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+)
+
+const TEST_CALL_TIMEOUT = 200 * time.Millisecond
+const TEST_CALL_CACHE_SIZE = 10
+const TEST_MIN_CONFIRMATIONS = 5
+
+const TEST_CONTRACT_ADDRESS = "0x00112233445566778899aabbccddeeff00112233"
+const TEST_JSON_ABI = `[
+	{"type":"function","name":"balanceOf","constant":true,"inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint64"}]},
+	{"type":"event","name":"Locked","anonymous":false,"inputs":[{"name":"owner","type":"address","indexed":true},{"name":"amount","type":"uint64","indexed":false},{"name":"orbsAddress","type":"bytes","indexed":false}]}
+]`
+
+type harness struct {
+	server  *httptest.Server
+	service ethereum.Connector
+
+	mutex    sync.Mutex
+	requests []*jsonRpcRequest
+	results  map[string]interface{}
+	rpcError string
+	delay    time.Duration
+}
+
+type jsonRpcRequest struct {
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+// stands in for an ethereum node, eth_call returns whatever the test configured
+func newHarness() *harness {
+	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+
+	h := &harness{results: make(map[string]interface{})}
+	h.server = httptest.NewServer(http.HandlerFunc(h.handleJsonRpc))
+	h.service = ethereum.NewEthereumCrosschainConnector(config.ForEthereumCrosschainConnectorTests(h.server.URL, TEST_CALL_TIMEOUT, TEST_CALL_CACHE_SIZE, TEST_MIN_CONFIRMATIONS), log)
+	return h
+}
+
+func (h *harness) close() {
+	h.server.Close()
+}
+
+func (h *harness) handleJsonRpc(w http.ResponseWriter, r *http.Request) {
+	request := &jsonRpcRequest{}
+	json.NewDecoder(r.Body).Decode(request)
+
+	h.mutex.Lock()
+	h.requests = append(h.requests, request)
+	result, rpcError, delay := h.results[request.Method], h.rpcError, h.delay
+	h.mutex.Unlock()
+
+	time.Sleep(delay)
+	w.Header().Set("Content-Type", "application/json")
+	if rpcError != "" {
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "error": map[string]interface{}{"code": -32000, "message": rpcError}})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "result": result})
+}
+
+func (h *harness) respondWith(result string) {
+	h.respondToMethodWith("eth_call", result)
+}
+
+func (h *harness) respondToMethodWith(method string, result interface{}) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.results[method] = result
+}
+
+func (h *harness) respondWithReceipt(latestBlockNumber uint64, receipt interface{}) {
+	h.respondToMethodWith("eth_blockNumber", fmt.Sprintf("0x%x", latestBlockNumber))
+	h.respondToMethodWith("eth_getTransactionReceipt", receipt)
+}
+
+func (h *harness) respondWithError(message string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.rpcError = message
+}
+
+func (h *harness) respondAfter(delay time.Duration) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.delay = delay
+}
+
+func (h *harness) receivedRequests() []*jsonRpcRequest {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	return h.requests
+}
+
+func receiptWithLog(blockNumber uint64, status string, logIndex uint64, address string, topics []string, data string) map[string]interface{} {
+	return map[string]interface{}{
+		"status":      status,
+		"blockNumber": fmt.Sprintf("0x%x", blockNumber),
+		"logs": []map[string]interface{}{{
+			"address":  address,
+			"topics":   topics,
+			"data":     data,
+			"logIndex": fmt.Sprintf("0x%x", logIndex),
+		}},
+	}
+}
+
+func (h *harness) getTransactionLog(ctx context.Context, input *ethereum.EthereumGetTransactionLogInput) (*ethereum.EthereumGetTransactionLogOutput, error) {
+	return h.service.EthereumGetTransactionLog(ctx, input)
+}
+
+func lockedLogInput(txHash string, logIndex uint32, blockNumber uint64) *ethereum.EthereumGetTransactionLogInput {
+	return &ethereum.EthereumGetTransactionLogInput{
+		EthereumContractAddress: TEST_CONTRACT_ADDRESS,
+		EthereumEventName:       "Locked",
+		EthereumJsonAbi:         TEST_JSON_ABI,
+		EthereumTxhash:          txHash,
+		EthereumLogIndex:        logIndex,
+		EthereumBlockNumber:     blockNumber,
+	}
+}
+
+func balanceOfInput(owner string, blockNumber uint64) *services.EthereumCallContractInput {
+	return &services.EthereumCallContractInput{
+		EthereumContractAddress:    TEST_CONTRACT_ADDRESS,
+		EthereumFunctionName:       "balanceOf",
+		EthereumJsonAbi:            TEST_JSON_ABI,
+		EthereumBlockNumber:        blockNumber,
+		EthereumInputArgumentArray: builders.MethodArgumentsArray(owner),
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+const TXHASH = "0x1122334455667788990011223344556677889900112233445566778899001122"
+const LOCKED_TOPIC = "0xaf39c45d8a4b46353c53767bd1535649153993dd27b45a2b7ad08b13adf56246"
+const OWNER_TOPIC = "0x000000000000000000000000ffeeddccbbaa99887766554433221100ffeeddcc"
+
+var LOCKED_500_DATA = "0x" + strings.Join([]string{
+	"00000000000000000000000000000000000000000000000000000000000001f4",
+	"0000000000000000000000000000000000000000000000000000000000000040",
+	"0000000000000000000000000000000000000000000000000000000000000003",
+	"0102030000000000000000000000000000000000000000000000000000000000",
+}, "")
+
+func TestEthereumGetTransactionLog_DecodesConfirmedLog(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithReceipt(120, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+
+		output, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.NoError(t, err, "log lookup should succeed")
+		require.EqualValues(t, 100, output.EthereumBlockNumber, "block of the transaction should be returned")
+
+		args := output.EthereumOutputArgumentArray.ArgumentsIterator()
+		owner := args.NextArguments()
+		require.Equal(t, "owner", owner.Name())
+		require.Equal(t, []byte{0xff, 0xee, 0xdd, 0xcc, 0xbb, 0xaa, 0x99, 0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11, 0x00, 0xff, 0xee, 0xdd, 0xcc}, owner.BytesValue(), "indexed owner should be decoded from the topic")
+		require.EqualValues(t, 500, args.NextArguments().Uint64Value(), "amount should be decoded from the data")
+		require.Equal(t, []byte{0x01, 0x02, 0x03}, args.NextArguments().BytesValue(), "orbs address should be decoded from the data")
+
+		requests := h.receivedRequests()
+		require.Len(t, requests, 2)
+		var txHash string
+		require.NoError(t, json.Unmarshal(requests[1].Params[0], &txHash))
+		require.Equal(t, "eth_getTransactionReceipt", requests[1].Method)
+		require.Equal(t, TXHASH, txHash)
+	})
+}
+
+func TestEthereumGetTransactionLog_CountsConfirmationsUpToThePinnedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithReceipt(1000, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+
+		_, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 100+TEST_MIN_CONFIRMATIONS-1))
+		require.Error(t, err, "log should not be confirmed at the pinned block even though the endpoint is far ahead")
+
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 100+TEST_MIN_CONFIRMATIONS))
+		require.NoError(t, err, "log should be confirmed at the pinned block")
+
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 100+TEST_MIN_CONFIRMATIONS-1))
+		require.Error(t, err, "cached receipt should still be checked against the pinned block")
+		require.Len(t, h.receivedRequests(), 4, "confirmed receipt should be served from cache")
+	})
+}
+
+func TestEthereumGetTransactionLog_FailsWhenEndpointIsBehindThePinnedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithReceipt(105, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+
+		_, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.True(t, ethereum.IsNodeFault(err), "endpoint that has not reached the pinned block should fail as a node fault")
+		require.Len(t, h.receivedRequests(), 1, "receipt should not be requested")
+	})
+}
+
+func TestEthereumGetTransactionLog_RejectsLogsThatDoNotMatch(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+
+		h.respondWithReceipt(120, receiptWithLog(100, "0x0", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+		_, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.Error(t, err, "log of a failed transaction should be rejected")
+
+		h.respondWithReceipt(120, receiptWithLog(100, "0x1", 7, "0xffeeddccbbaa99887766554433221100ffeeddcc", []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.Error(t, err, "log emitted by a different contract should be rejected")
+
+		h.respondWithReceipt(120, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{OWNER_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.Error(t, err, "log of a different event should be rejected")
+
+		h.respondWithReceipt(120, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 8, 110))
+		require.Error(t, err, "missing log index should be rejected")
+
+		h.respondWithReceipt(120, nil)
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.Error(t, err, "unknown transaction should be rejected")
+		require.False(t, ethereum.IsNodeFault(err), "unknown transaction is an answer about ethereum the contract should get")
+	})
+}
+
+func TestEthereumGetTransactionLog_RejectsReceiptsWithoutStatus(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithReceipt(120, receiptWithLog(100, "", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+
+		_, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.Error(t, err, "log of a transaction mined before byzantium can't be confirmed")
+		require.Contains(t, err.Error(), "without a status")
+		require.False(t, ethereum.IsNodeFault(err), "missing status is an answer about ethereum the contract should get")
+	})
+}
+
+func TestEthereumGetTransactionLog_CachesReceiptsApartFromCalls(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		defer h.close()
+		h.respondWithReceipt(120, receiptWithLog(100, "0x1", 7, TEST_CONTRACT_ADDRESS, []string{LOCKED_TOPIC, OWNER_TOPIC}, LOCKED_500_DATA))
+
+		_, err := h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.NoError(t, err, "log lookup should succeed")
+
+		t.Log("Filling the call cache does not evict the confirmed receipt")
+
+		h.respondWith(BALANCE_OF_1000)
+		for blockNumber := uint64(1); blockNumber <= TEST_CALL_CACHE_SIZE; blockNumber++ {
+			_, err := h.service.EthereumCallContract(ctx, balanceOfInput(OWNER, blockNumber))
+			require.NoError(t, err, "call should succeed")
+		}
+		requestsBefore := len(h.receivedRequests())
+
+		_, err = h.getTransactionLog(ctx, lockedLogInput(TXHASH, 7, 110))
+		require.NoError(t, err, "log lookup should succeed")
+		require.Len(t, h.receivedRequests(), requestsBefore, "confirmed receipt should still be served from cache")
+	})
+}
@@ -0,0 +1,193 @@
+package ethereum
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+const ETHEREUM_TXHASH_SIZE = 32
+
+// the virtual machine is given the ethereum connector as a TransactionLogGetter next to the crosschain connectors
+type TransactionLogGetter interface {
+	EthereumGetTransactionLog(ctx context.Context, input *EthereumGetTransactionLogInput) (*EthereumGetTransactionLogOutput, error)
+}
+
+type EthereumGetTransactionLogInput struct {
+	EthereumContractAddress string
+	EthereumJsonAbi         string
+	EthereumEventName       string
+	EthereumTxhash          string
+	EthereumLogIndex        uint32
+	EthereumBlockNumber     uint64
+}
+
+type EthereumGetTransactionLogOutput struct {
+	EthereumOutputArgumentArray *protocol.MethodArgumentArray
+	EthereumBlockNumber         uint64
+}
+
+type ethReceipt struct {
+	Status      string   `json:"status"`
+	BlockNumber string   `json:"blockNumber"`
+	Logs        []ethLog `json:"logs"`
+}
+
+type ethLog struct {
+	Address  string   `json:"address"`
+	Topics   []string `json:"topics"`
+	Data     string   `json:"data"`
+	LogIndex string   `json:"logIndex"`
+}
+
+// the verdict depends only on the inputs: confirmations are counted up to the block pinned by the transaction
+// and never up to the latest block the endpoint happens to know, so all federation nodes agree
+func (s *service) EthereumGetTransactionLog(ctx context.Context, input *EthereumGetTransactionLogInput) (*EthereumGetTransactionLogOutput, error) {
+	if input.EthereumBlockNumber == 0 {
+		return nil, errors.New("ethereum block number must be pinned")
+	}
+	address, err := parseAddress(input.EthereumContractAddress)
+	if err != nil {
+		return nil, err
+	}
+	txHash, err := decodeHex(input.EthereumTxhash)
+	if err != nil || len(txHash) != ETHEREUM_TXHASH_SIZE {
+		return nil, errors.Errorf("invalid ethereum txhash '%s'", input.EthereumTxhash)
+	}
+	event, err := findAbiEvent(input.EthereumJsonAbi, input.EthereumEventName)
+	if err != nil {
+		return nil, err
+	}
+
+	receipt, err := s.getConfirmedReceipt(ctx, txHash, input.EthereumBlockNumber)
+	if err != nil {
+		s.logger.Info("ethereum transaction log lookup failed", log.Error(err), log.String("txhash", input.EthereumTxhash), log.Uint64("block-number", input.EthereumBlockNumber))
+		return nil, err
+	}
+	receiptBlockNumber, err := decodeHexUint64(receipt.BlockNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	receiptLog, err := findLog(receipt, input.EthereumLogIndex)
+	if err != nil {
+		return nil, err
+	}
+	logAddress, err := decodeHex(receiptLog.Address)
+	if err != nil || !bytes.Equal(logAddress, address) {
+		return nil, errors.Errorf("ethereum log %d was emitted by %s and not by %s", input.EthereumLogIndex, receiptLog.Address, input.EthereumContractAddress)
+	}
+	var topics [][]byte
+	for _, topic := range receiptLog.Topics {
+		decoded, err := decodeHex(topic)
+		if err != nil {
+			return nil, err
+		}
+		topics = append(topics, decoded)
+	}
+	if len(topics) == 0 || !bytes.Equal(topics[0], event.eventTopic()) {
+		return nil, errors.Errorf("ethereum log %d is not a %s event", input.EthereumLogIndex, event.signature())
+	}
+	data, err := decodeHex(receiptLog.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	outputArgs, err := decodeEventLog(event, topics, data)
+	if err != nil {
+		return nil, err
+	}
+	return &EthereumGetTransactionLogOutput{
+		EthereumOutputArgumentArray: outputArgs,
+		EthereumBlockNumber:         receiptBlockNumber,
+	}, nil
+}
+
+// a receipt is only returned if its transaction succeeded and has enough confirmations at the pinned block,
+// confirmed receipts are cached since they can only change in a reorg deeper than the required confirmations
+func (s *service) getConfirmedReceipt(ctx context.Context, txHash []byte, blockNumber uint64) (*ethReceipt, error) {
+	cacheKey := fmt.Sprintf("%x", txHash)
+	receiptJson, found := s.getCached(s.receipts, cacheKey)
+	if !found {
+		// an endpoint that is behind the pinned block could miss the transaction and disagree with the other nodes
+		var latestBlockNumberHex string
+		err := s.callJsonRpc(ctx, "eth_blockNumber", []interface{}{}, &latestBlockNumberHex)
+		if err == errJsonRpcNoResult {
+			return nil, &EndpointFailure{err}
+		}
+		if err != nil {
+			return nil, err
+		}
+		latestBlockNumber, err := decodeHexUint64(latestBlockNumberHex)
+		if err != nil {
+			return nil, &EndpointFailure{err}
+		}
+		if latestBlockNumber < blockNumber {
+			return nil, &EndpointFailure{errors.Errorf("ethereum endpoint is at block %d and has not reached the pinned block %d", latestBlockNumber, blockNumber)}
+		}
+
+		var raw json.RawMessage
+		err = s.callJsonRpc(ctx, "eth_getTransactionReceipt", []interface{}{fmt.Sprintf("0x%x", txHash)}, &raw)
+		if err == errJsonRpcNoResult {
+			return nil, errors.Errorf("ethereum transaction 0x%x not found", txHash)
+		}
+		if err != nil {
+			return nil, err
+		}
+		receiptJson = raw
+	}
+
+	receipt := &ethReceipt{}
+	err := json.Unmarshal(receiptJson, receipt)
+	if err != nil {
+		return nil, &EndpointFailure{errors.Wrap(err, "could not parse ethereum transaction receipt")}
+	}
+	err = receipt.requireSucceeded(txHash)
+	if err != nil {
+		return nil, err
+	}
+	receiptBlockNumber, err := decodeHexUint64(receipt.BlockNumber)
+	if err != nil {
+		return nil, &EndpointFailure{err}
+	}
+	minConfirmations := uint64(s.config.EthereumMinConfirmations())
+	if receiptBlockNumber > blockNumber || blockNumber-receiptBlockNumber < minConfirmations {
+		return nil, errors.Errorf("ethereum transaction 0x%x in block %d does not have %d confirmations at block %d", txHash, receiptBlockNumber, minConfirmations, blockNumber)
+	}
+
+	s.addCached(s.receipts, cacheKey, receiptJson)
+	return receipt, nil
+}
+
+// receipts of transactions mined before byzantium have no status (only a state root), whether they succeeded can't
+// be told from the receipt so they are rejected like failed ones, every node reaches the same verdict either way
+func (r *ethReceipt) requireSucceeded(txHash []byte) error {
+	switch r.Status {
+	case "0x1":
+		return nil
+	case "0x0":
+		return errors.Errorf("ethereum transaction 0x%x failed", txHash)
+	case "":
+		return errors.Errorf("ethereum transaction 0x%x has a receipt without a status (mined before byzantium), its success can't be confirmed", txHash)
+	default:
+		return &EndpointFailure{errors.Errorf("ethereum transaction 0x%x has a receipt with an unknown status '%s'", txHash, r.Status)}
+	}
+}
+
+// the log index is the position of the log in its block as reported by the receipt
+func findLog(receipt *ethReceipt, logIndex uint32) (*ethLog, error) {
+	for i := range receipt.Logs {
+		index, err := decodeHexUint64(receipt.Logs[i].LogIndex)
+		if err != nil {
+			return nil, err
+		}
+		if index == uint64(logIndex) {
+			return &receipt.Logs[i], nil
+		}
+	}
+	return nil, errors.Errorf("ethereum transaction has no log with index %d", logIndex)
+}
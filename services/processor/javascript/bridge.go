@@ -0,0 +1,354 @@
+package javascript
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// contract code talks to the processor with json messages over the v8 worker bridge,
+// values are typed strings since js numbers can't hold every uint64
+type jsArgument struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+const (
+	JS_ARGUMENT_TYPE_UINT32 = "uint32"
+	JS_ARGUMENT_TYPE_UINT64 = "uint64"
+	JS_ARGUMENT_TYPE_STRING = "string"
+	JS_ARGUMENT_TYPE_BYTES  = "bytes" // hex
+)
+
+// object is one of the sdk objects (state, service, address, env, ethereum), "meter" which only charges ticks or "result" which ends the call,
+// every message carries the ticks counted since the previous one (see meter.go)
+type jsBridgeRequest struct {
+	Object string        `json:"object"`
+	Method string        `json:"method"`
+	Args   []*jsArgument `json:"args"`
+	Error  string        `json:"error"`
+	Ticks  uint64        `json:"ticks"`
+
+	Unexpected bool `json:"unexpected"` // the error was not raised by the contract (like an unknown method)
+}
+
+// once exhausted the contract can't do any more work, every following tick throws
+type jsBridgeResponse struct {
+	Args      []*jsArgument `json:"args"`
+	Error     string        `json:"error,omitempty"`
+	Exhausted bool          `json:"exhausted,omitempty"`
+}
+
+const JS_BRIDGE_OBJECT_RESULT = "result"
+const JS_BRIDGE_OBJECT_METER = "meter"
+
+// serves a single method call, sdk calls are made with the permissions of the called contract
+type jsBridge struct {
+	ctx                context.Context
+	executionContextId primitives.ExecutionContextId
+	handler            handlers.ContractSdkCallHandler
+	sdk                *sdk.BaseContract
+	env                *native.EnvSdk
+	ethereum           *native.EthereumSdk
+
+	exhausted   bool
+	returned    bool
+	outputArgs  *protocol.MethodArgumentArray
+	contractErr error
+	err         error
+}
+
+func newJsBridge(ctx context.Context, executionContextId primitives.ExecutionContextId, handler handlers.ContractSdkCallHandler) *jsBridge {
+	return &jsBridge{
+		ctx:                ctx,
+		executionContextId: executionContextId,
+		handler:            handler,
+		sdk:                native.NewSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+		env:                native.NewEnvSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+		ethereum:           native.NewEthereumSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+	}
+}
+
+func (b *jsBridge) handleMessage(msg []byte) []byte {
+	request := &jsBridgeRequest{}
+	err := json.Unmarshal(msg, request)
+	if err != nil {
+		// messages are only sent by the meter runtime, so a malformed one means its ticks can't be trusted
+		b.err = errors.Wrap(err, "malformed bridge message")
+		return b.exhaust(b.err)
+	}
+
+	err = b.chargeTicks(request.Ticks)
+	if request.Object == JS_BRIDGE_OBJECT_RESULT {
+		b.handleResult(request) // when the budget ran out the virtual machine fails the call anyway
+		return nil
+	}
+	if err != nil {
+		return b.exhaust(err)
+	}
+	if request.Object == JS_BRIDGE_OBJECT_METER {
+		return encodeJsBridgeResponse(nil, nil)
+	}
+
+	args, err := jsArgumentsToArgs(request.Args)
+	if err != nil {
+		return encodeJsBridgeResponse(nil, err)
+	}
+	return encodeJsBridgeResponse(b.callSdk(request.Object, request.Method, args))
+}
+
+func (b *jsBridge) chargeTicks(ticks uint64) error {
+	if b.exhausted {
+		return ErrExecutionBudgetExhausted
+	}
+	if ticks == 0 {
+		return nil
+	}
+	err := native.ChargeExecutionUnits(b.ctx, b.handler, b.executionContextId, ticks*JS_EXECUTION_COST_PER_TICK)
+	if err != nil {
+		b.exhausted = true
+	}
+	return err
+}
+
+func (b *jsBridge) exhaust(err error) []byte {
+	b.exhausted = true
+	response := &jsBridgeResponse{Error: err.Error(), Exhausted: true}
+	msg, _ := json.Marshal(response) // can't fail for these types
+	return msg
+}
+
+func (b *jsBridge) handleResult(request *jsBridgeRequest) {
+	b.returned = true
+	if request.Unexpected {
+		b.err = errors.New(request.Error)
+		return
+	}
+	if request.Error != "" {
+		b.contractErr = errors.New(request.Error)
+		b.outputArgs = native.ArgsToMethodArgumentArray(request.Error)
+		return
+	}
+	args, err := jsArgumentsToArgs(request.Args)
+	if err != nil {
+		b.err = errors.Wrap(err, "contract returned unsupported output")
+		return
+	}
+	b.outputArgs = native.ArgsToMethodArgumentArray(args...)
+}
+
+func (b *jsBridge) callSdk(object string, method string, args []interface{}) ([]interface{}, error) {
+	ctx := sdk.Context(b.executionContextId)
+	switch object + "." + method {
+	case "state.readBytesByKey":
+		var key string
+		if err := scanArgs(args, &key); err != nil {
+			return nil, err
+		}
+		return single(b.sdk.State.ReadBytesByKey(ctx, key))
+	case "state.writeBytesByKey":
+		var key string
+		var value []byte
+		if err := scanArgs(args, &key, &value); err != nil {
+			return nil, err
+		}
+		return nil, b.sdk.State.WriteBytesByKey(ctx, key, value)
+	case "state.readStringByKey":
+		var key string
+		if err := scanArgs(args, &key); err != nil {
+			return nil, err
+		}
+		return single(b.sdk.State.ReadStringByKey(ctx, key))
+	case "state.writeStringByKey":
+		var key, value string
+		if err := scanArgs(args, &key, &value); err != nil {
+			return nil, err
+		}
+		return nil, b.sdk.State.WriteStringByKey(ctx, key, value)
+	case "state.readUint32ByKey":
+		var key string
+		if err := scanArgs(args, &key); err != nil {
+			return nil, err
+		}
+		return single(b.sdk.State.ReadUint32ByKey(ctx, key))
+	case "state.writeUint32ByKey":
+		var key string
+		var value uint32
+		if err := scanArgs(args, &key, &value); err != nil {
+			return nil, err
+		}
+		return nil, b.sdk.State.WriteUint32ByKey(ctx, key, value)
+	case "state.readUint64ByKey":
+		var key string
+		if err := scanArgs(args, &key); err != nil {
+			return nil, err
+		}
+		return single(b.sdk.State.ReadUint64ByKey(ctx, key))
+	case "state.writeUint64ByKey":
+		var key string
+		var value uint64
+		if err := scanArgs(args, &key, &value); err != nil {
+			return nil, err
+		}
+		return nil, b.sdk.State.WriteUint64ByKey(ctx, key, value)
+	case "state.clearByKey":
+		var key string
+		if err := scanArgs(args, &key); err != nil {
+			return nil, err
+		}
+		return nil, b.sdk.State.ClearByKey(ctx, key)
+	case "service.callMethod":
+		var serviceName, methodName string
+		if len(args) < 2 {
+			return nil, errors.New("callMethod takes a service name, a method name and the method args")
+		}
+		if err := scanArgs(args[:2], &serviceName, &methodName); err != nil {
+			return nil, err
+		}
+		return b.sdk.Service.CallMethod(ctx, serviceName, methodName, args[2:]...)
+	case "address.getSignerAddress":
+		return single(b.sdk.Address.GetSignerAddress(ctx))
+	case "address.getCallerAddress":
+		return single(b.sdk.Address.GetCallerAddress(ctx))
+	case "env.getBlockHeight":
+		return single(b.env.GetBlockHeight(b.ctx, ctx))
+	case "env.getBlockTimestamp":
+		return single(b.env.GetBlockTimestamp(b.ctx, ctx))
+	case "env.getVirtualChainId":
+		return single(b.env.GetVirtualChainId(b.ctx, ctx))
+	case "env.getTxHash":
+		return single(b.env.GetTxHash(b.ctx, ctx))
+	case "ethereum.callMethod":
+		var contractAddress, jsonAbi, methodName string
+		var blockNumber uint64
+		if len(args) < 4 {
+			return nil, errors.New("callMethod takes a contract address, a json abi, a method name, a block number and the method args")
+		}
+		if err := scanArgs(args[:4], &contractAddress, &jsonAbi, &methodName, &blockNumber); err != nil {
+			return nil, err
+		}
+		return b.ethereum.CallMethod(b.ctx, ctx, contractAddress, jsonAbi, methodName, blockNumber, args[4:]...)
+	case "ethereum.getTransactionLog":
+		var contractAddress, jsonAbi, eventName, txHash string
+		var logIndex uint32
+		var blockNumber uint64
+		if err := scanArgs(args, &contractAddress, &jsonAbi, &eventName, &txHash, &logIndex, &blockNumber); err != nil {
+			return nil, err
+		}
+		ethereumBlockNumber, eventArgs, err := b.ethereum.GetTransactionLog(b.ctx, ctx, contractAddress, jsonAbi, eventName, txHash, logIndex, blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		return append([]interface{}{ethereumBlockNumber}, eventArgs...), nil
+	default:
+		return nil, errors.Errorf("unknown sdk method %s.%s", object, method)
+	}
+}
+
+func single(value interface{}, err error) ([]interface{}, error) {
+	if err != nil {
+		return nil, err
+	}
+	if address, ok := value.(sdk.Ripmd160Sha256); ok {
+		value = []byte(address)
+	}
+	return []interface{}{value}, nil
+}
+
+// assigns args to the pointed values, the number and types of args must match exactly
+func scanArgs(args []interface{}, pointers ...interface{}) error {
+	if len(args) != len(pointers) {
+		return errors.Errorf("expected %d args but received %d", len(pointers), len(args))
+	}
+	for i, arg := range args {
+		ok := false
+		switch pointer := pointers[i].(type) {
+		case *uint32:
+			*pointer, ok = arg.(uint32)
+		case *uint64:
+			*pointer, ok = arg.(uint64)
+		case *string:
+			*pointer, ok = arg.(string)
+		case *[]byte:
+			*pointer, ok = arg.([]byte)
+		}
+		if !ok {
+			return errors.Errorf("arg %d has unexpected type %T", i, arg)
+		}
+	}
+	return nil
+}
+
+func encodeJsBridgeResponse(values []interface{}, err error) []byte {
+	response := &jsBridgeResponse{}
+	if err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Args, err = argsToJsArguments(values)
+		if err != nil {
+			response.Error = err.Error()
+		}
+	}
+	msg, _ := json.Marshal(response) // can't fail for these types
+	return msg
+}
+
+func argsToJsArguments(args []interface{}) ([]*jsArgument, error) {
+	res := make([]*jsArgument, 0, len(args))
+	for i, arg := range args {
+		switch arg := arg.(type) {
+		case uint32:
+			res = append(res, &jsArgument{JS_ARGUMENT_TYPE_UINT32, strconv.FormatUint(uint64(arg), 10)})
+		case uint64:
+			res = append(res, &jsArgument{JS_ARGUMENT_TYPE_UINT64, strconv.FormatUint(arg, 10)})
+		case string:
+			res = append(res, &jsArgument{JS_ARGUMENT_TYPE_STRING, arg})
+		case []byte:
+			res = append(res, &jsArgument{JS_ARGUMENT_TYPE_BYTES, hex.EncodeToString(arg)})
+		default:
+			return nil, errors.Errorf("arg %d has unsupported type %T", i, arg)
+		}
+	}
+	return res, nil
+}
+
+func jsArgumentsToArgs(jsArgs []*jsArgument) ([]interface{}, error) {
+	res := make([]interface{}, 0, len(jsArgs))
+	for i, jsArg := range jsArgs {
+		if jsArg == nil {
+			return nil, errors.Errorf("arg %d is missing", i)
+		}
+		switch jsArg.Type {
+		case JS_ARGUMENT_TYPE_UINT32:
+			value, err := strconv.ParseUint(jsArg.Value, 10, 32)
+			if err != nil {
+				return nil, errors.Wrapf(err, "arg %d is not a valid uint32", i)
+			}
+			res = append(res, uint32(value))
+		case JS_ARGUMENT_TYPE_UINT64:
+			value, err := strconv.ParseUint(jsArg.Value, 10, 64)
+			if err != nil {
+				return nil, errors.Wrapf(err, "arg %d is not a valid uint64", i)
+			}
+			res = append(res, value)
+		case JS_ARGUMENT_TYPE_STRING:
+			res = append(res, jsArg.Value)
+		case JS_ARGUMENT_TYPE_BYTES:
+			value, err := hex.DecodeString(jsArg.Value)
+			if err != nil {
+				return nil, errors.Wrapf(err, "arg %d is not valid bytes", i)
+			}
+			res = append(res, value)
+		default:
+			return nil, errors.Errorf("arg %d has unsupported type %s", i, jsArg.Type)
+		}
+	}
+	return res, nil
+}
@@ -0,0 +1,170 @@
+package javascript
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"math"
+	"testing"
+)
+
+const EXAMPLE_CONTEXT = 0
+
+func TestJsArgumentsRoundTripEveryType(t *testing.T) {
+	args := []interface{}{uint32(math.MaxUint32), uint64(math.MaxUint64), "hello ✓", []byte{0x01, 0xab}}
+
+	jsArgs, err := argsToJsArguments(args)
+	require.NoError(t, err, "conversion to js should succeed")
+	require.Equal(t, &jsArgument{JS_ARGUMENT_TYPE_UINT64, "18446744073709551615"}, jsArgs[1], "uint64 should be passed as a decimal string")
+	require.Equal(t, &jsArgument{JS_ARGUMENT_TYPE_BYTES, "01ab"}, jsArgs[3], "bytes should be passed as hex")
+
+	res, err := jsArgumentsToArgs(jsArgs)
+	require.NoError(t, err, "conversion from js should succeed")
+	require.Equal(t, args, res, "args should survive the round trip")
+}
+
+func TestJsArgumentsRejectInvalidValues(t *testing.T) {
+	_, err := jsArgumentsToArgs([]*jsArgument{{JS_ARGUMENT_TYPE_UINT32, "4294967296"}})
+	require.Error(t, err, "uint32 overflow should fail")
+
+	_, err = jsArgumentsToArgs([]*jsArgument{{"float", "1.5"}})
+	require.Error(t, err, "unsupported type should fail")
+
+	_, err = argsToJsArguments([]interface{}{int(1)})
+	require.Error(t, err, "unsupported go type should fail")
+}
+
+func TestJsBridgeServesStateCalls(t *testing.T) {
+	b := createJsBridge()
+
+	response := sendToJsBridge(t, b, &jsBridgeRequest{Object: "state", Method: "writeUint64ByKey", Args: []*jsArgument{{JS_ARGUMENT_TYPE_STRING, "count"}, {JS_ARGUMENT_TYPE_UINT64, "17"}}})
+	require.Empty(t, response.Error, "write should succeed")
+
+	response = sendToJsBridge(t, b, &jsBridgeRequest{Object: "state", Method: "readUint64ByKey", Args: []*jsArgument{{JS_ARGUMENT_TYPE_STRING, "count"}}})
+	require.Empty(t, response.Error, "read should succeed")
+	require.Equal(t, []*jsArgument{{JS_ARGUMENT_TYPE_UINT64, "17"}}, response.Args, "read should return what was written")
+}
+
+func TestJsBridgeReturnsErrorsToContract(t *testing.T) {
+	b := createJsBridge()
+
+	response := sendToJsBridge(t, b, &jsBridgeRequest{Object: "state", Method: "writeUint64ByKey", Args: []*jsArgument{{JS_ARGUMENT_TYPE_STRING, "count"}}})
+	require.Contains(t, response.Error, "expected 2 args", "missing args should fail")
+
+	response = sendToJsBridge(t, b, &jsBridgeRequest{Object: "state", Method: "unknownMethod"})
+	require.Contains(t, response.Error, "unknown sdk method", "unknown sdk method should fail")
+
+	response = sendToJsBridge(t, b, &jsBridgeRequest{Object: "address", Method: "getSignerAddress"})
+	require.Contains(t, response.Error, "address not supported", "errors of the node should reach the contract")
+}
+
+func TestJsBridgeRecordsResult(t *testing.T) {
+	b := createJsBridge()
+	require.Nil(t, b.handleMessage(encodeJsBridgeRequest(t, &jsBridgeRequest{Object: JS_BRIDGE_OBJECT_RESULT, Args: []*jsArgument{{JS_ARGUMENT_TYPE_STRING, "hello"}, {JS_ARGUMENT_TYPE_UINT32, "3"}}})))
+	require.True(t, b.returned, "result should be recorded")
+	require.NoError(t, b.contractErr)
+	require.NoError(t, b.err)
+	require.Equal(t, native.ArgsToMethodArgumentArray("hello", uint32(3)).Raw(), b.outputArgs.Raw(), "outputs should be converted to method arguments")
+
+	b = createJsBridge()
+	b.handleMessage(encodeJsBridgeRequest(t, &jsBridgeRequest{Object: JS_BRIDGE_OBJECT_RESULT, Error: "insufficient balance"}))
+	require.EqualError(t, b.contractErr, "insufficient balance", "thrown errors should be contract errors")
+	require.Equal(t, native.ArgsToMethodArgumentArray("insufficient balance").Raw(), b.outputArgs.Raw(), "contract error should be the output")
+
+	b = createJsBridge()
+	b.handleMessage(encodeJsBridgeRequest(t, &jsBridgeRequest{Object: JS_BRIDGE_OBJECT_RESULT, Error: "method 'unknown' not found in contract", Unexpected: true}))
+	require.NoError(t, b.contractErr)
+	require.Error(t, b.err, "unknown method should not be a contract error")
+}
+
+func TestJsBridgeChargesTicksOfEveryMessage(t *testing.T) {
+	b := createJsBridgeWithExecutionBudget(100)
+
+	response := sendToJsBridge(t, b, &jsBridgeRequest{Object: JS_BRIDGE_OBJECT_METER, Ticks: 60})
+	require.Empty(t, response.Error, "ticks within the budget should be charged")
+
+	response = sendToJsBridge(t, b, &jsBridgeRequest{Object: "state", Method: "readUint64ByKey", Args: []*jsArgument{{JS_ARGUMENT_TYPE_STRING, "count"}}, Ticks: 60})
+	require.True(t, response.Exhausted, "ticks above the budget should exhaust the contract")
+	require.Contains(t, response.Error, "execution budget exceeded", "sdk call should not be served once the budget is exceeded")
+
+	response = sendToJsBridge(t, b, &jsBridgeRequest{Object: JS_BRIDGE_OBJECT_METER})
+	require.True(t, response.Exhausted, "contract should stay exhausted")
+}
+
+func TestJsBridgeTreatsMalformedMessagesAsFailures(t *testing.T) {
+	b := createJsBridge()
+
+	response := &jsBridgeResponse{}
+	err := json.Unmarshal(b.handleMessage([]byte("not json")), response)
+	require.NoError(t, err, "bridge response should be valid json")
+	require.True(t, response.Exhausted, "contract should not run after a malformed message")
+	require.Error(t, b.err, "malformed message should fail the call")
+}
+
+func TestIsValidJsIdentifier(t *testing.T) {
+	require.True(t, isValidJsIdentifier("CounterFrom100"))
+	require.True(t, isValidJsIdentifier("_Counter$"))
+	require.False(t, isValidJsIdentifier("100Counter"))
+	require.False(t, isValidJsIdentifier("Counter;alert(1)"))
+	require.False(t, isValidJsIdentifier(""))
+}
+
+func createJsBridge() *jsBridge {
+	return createJsBridgeWithExecutionBudget(math.MaxUint64)
+}
+
+func createJsBridgeWithExecutionBudget(executionBudget uint64) *jsBridge {
+	handler := &contractSdkStateCallHandlerStub{make(map[string]*protocol.MethodArgument), executionBudget}
+	return newJsBridge(context.Background(), EXAMPLE_CONTEXT, handler)
+}
+
+func encodeJsBridgeRequest(t *testing.T, request *jsBridgeRequest) []byte {
+	msg, err := json.Marshal(request)
+	require.NoError(t, err)
+	return msg
+}
+
+func sendToJsBridge(t *testing.T, b *jsBridge, request *jsBridgeRequest) *jsBridgeResponse {
+	response := &jsBridgeResponse{}
+	err := json.Unmarshal(b.handleMessage(encodeJsBridgeRequest(t, request)), response)
+	require.NoError(t, err, "bridge response should be valid json")
+	return response
+}
+
+type contractSdkStateCallHandlerStub struct {
+	store           map[string]*protocol.MethodArgument
+	executionBudget uint64
+}
+
+func (c *contractSdkStateCallHandlerStub) ChargeExecutionUnits(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+	if units > c.executionBudget {
+		return errors.New("execution budget exceeded")
+	}
+	c.executionBudget -= units
+	return nil
+}
+
+func (c *contractSdkStateCallHandlerStub) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCallInput) (*handlers.HandleSdkCallOutput, error) {
+	if input.PermissionScope != protocol.PERMISSION_SCOPE_SERVICE {
+		panic("permissions passed to SDK are incorrect")
+	}
+	if input.OperationName != native.SDK_OPERATION_NAME_STATE {
+		return nil, errors.New("address not supported by stub")
+	}
+	switch input.MethodName {
+	case "read":
+		return &handlers.HandleSdkCallOutput{
+			OutputArguments: []*protocol.MethodArgument{c.store[string(input.InputArguments[0].BytesValue())]},
+		}, nil
+	case "write":
+		c.store[string(input.InputArguments[0].BytesValue())] = input.InputArguments[1]
+		return nil, nil
+	default:
+		return nil, errors.New("unknown method")
+	}
+}
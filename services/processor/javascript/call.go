@@ -1,67 +1,105 @@
-// +build jsprocessor
-
 package javascript
 
 import (
-	"encoding/binary"
-	"encoding/hex"
+	"context"
+	"encoding/json"
 	"fmt"
-	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
 	"github.com/ry/v8worker2"
 )
 
-func (s *service) processMethodCall(executionContextId primitives.ExecutionContextId, code string, methodName primitives.MethodName, args *protocol.MethodArgumentArray) (contractOutputArgs *protocol.MethodArgumentArray, contractOutputErr error, err error) {
-	worker := v8worker2.New(func(msg []byte) []byte {
-		s.logger.Info("bridge msg received", log.String("msg", hex.EncodeToString(msg)))
-		contractOutputArgs, err = s.createMethodOutputArgs(msg)
-		return nil
-	})
-	// defer worker.Dispose()
+// code generation from strings (eval, Function) would run code that was not instrumented for metering
+func init() {
+	v8worker2.SetFlags([]string{"--disallow-code-generation-from-strings", "--no-expose-wasm"})
+}
+
+// the code is already instrumented for metering (see meter.go)
+func (s *service) processMethodCall(ctx context.Context, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName, code string, methodName primitives.MethodName, args *protocol.MethodArgumentArray) (contractOutputArgs *protocol.MethodArgumentArray, contractOutputErr error, err error) {
+	script, err := wrapCodeForExecution(contractName, code, methodName, args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bridge := newJsBridge(ctx, executionContextId, s.getContractSdkHandler())
+	worker := v8worker2.New(bridge.handleMessage)
+	defer worker.Dispose()
 
-	contractOutputArgs = nil
-	contractOutputErr = worker.Load("contract.js", s.wrapCodeForExecution(code, methodName, args))
-	if contractOutputErr != nil {
-		return nil, contractOutputErr, nil
+	err = worker.Load(string(contractName)+".js", script)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "contract code failed to load")
+	}
+	if !bridge.returned {
+		return nil, nil, errors.New("contract code did not return a result")
 	}
 
-	return contractOutputArgs, contractOutputErr, err
+	return bridge.outputArgs, bridge.contractErr, bridge.err
 }
 
+// the contract is a class named like the contract whose static methods are the contract methods
 const EXECUTION_WRAP_TEMPLATE = `
 // sdk
 %s
 
-const res = (function($sdk, V8Worker2) {
+// meter
+%s
+
+(function() {
+	let $result;
+	try {
+		const $contract = (function($sdk) {
 
 // contract code
 %s
 
-// the call
-return CounterFrom100.start();
+			return %s;
+		})($sdk);
 
-})($sdk);
-
-const buffer = new ArrayBuffer(4);
-const view = new DataView(buffer);
-view.setUint32(0, res, true);
-V8Worker2.send(buffer);
+		const $methodName = %s;
+		if (!Object.prototype.hasOwnProperty.call($contract, $methodName) || typeof $contract[$methodName] !== "function") {
+			if ($methodName === %s) {
+				$result = {object: "result", args: []};
+			} else {
+				$result = {object: "result", error: "method '" + $methodName + "' not found in contract", unexpected: true};
+			}
+		} else {
+			const $output = $contract[$methodName].apply($contract, $bridge.fromArguments(%s));
+			const $outputs = $output === undefined ? [] : (Array.isArray($output) ? $output : [$output]);
+			$result = {object: "result", args: $bridge.toArguments($outputs)};
+		}
+	} catch (e) {
+		$result = {object: "result", error: String(e && e.message !== undefined ? e.message : e)};
+	}
+	$bridge.send($result);
+})();
 `
 
-func (s *service) wrapCodeForExecution(code string, methodName primitives.MethodName, args *protocol.MethodArgumentArray) string {
-	return fmt.Sprintf(EXECUTION_WRAP_TEMPLATE, SDK_JS_IMPLEMENTATION, code)
-}
-
-func (s *service) createMethodOutputArgs(msg []byte) (*protocol.MethodArgumentArray, error) {
-	if len(msg) != 4 {
-		return nil, errors.Errorf("msg len is %d instead of 4", len(msg))
+func wrapCodeForExecution(contractName primitives.ContractName, code string, methodName primitives.MethodName, args *protocol.MethodArgumentArray) (string, error) {
+	if !isValidJsIdentifier(string(contractName)) {
+		return "", errors.Errorf("contract name '%s' is not a valid javascript class name", contractName)
+	}
+	methodNameJson, err := json.Marshal(string(methodName))
+	if err != nil {
+		return "", err
+	}
+	inputArgs, err := native.ParseMethodArgumentArray(args)
+	if err != nil {
+		return "", err
+	}
+	jsArgs, err := argsToJsArguments(inputArgs)
+	if err != nil {
+		return "", err
+	}
+	argsJson, err := json.Marshal(jsArgs)
+	if err != nil {
+		return "", err
 	}
-	res := []*protocol.MethodArgumentBuilder{
-		{Name: "uint64", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: uint64(binary.LittleEndian.Uint32(msg))},
+	preOrderMethodNameJson, err := json.Marshal(native.METHOD_NAME_PRE_ORDER)
+	if err != nil {
+		return "", err
 	}
-	return (&protocol.MethodArgumentArrayBuilder{
-		Arguments: res,
-	}).Build(), nil
+	meter := fmt.Sprintf(METER_JS_IMPLEMENTATION, JS_TICKS_PER_METER_CHARGE)
+	return fmt.Sprintf(EXECUTION_WRAP_TEMPLATE, SDK_JS_IMPLEMENTATION, meter, code, contractName, methodNameJson, preOrderMethodNameJson, argsJson), nil
 }
@@ -0,0 +1,490 @@
+package javascript
+
+import (
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// javascript can't be interrupted deterministically, so contract code is instrumented to count its own work:
+// every function body and loop body starts with a tick which is charged to the execution budget of the call,
+// code we can't instrument with certainty (like loops without blocks) is rejected
+const JS_METER_FUNCTION = "$$meter"
+
+const JS_EXECUTION_COST_PER_TICK = 1
+const JS_TICKS_PER_METER_CHARGE = 1000
+
+const jsMeterTick = JS_METER_FUNCTION + "();"
+
+var ErrExecutionBudgetExhausted = errors.New("execution budget exceeded")
+
+type jsBraceKind int
+
+const (
+	jsBraceBlock jsBraceKind = iota
+	jsBraceDoBody
+	jsBraceOther // object literals, class bodies and switch bodies
+	jsBraceTemplate
+)
+
+type jsTokenKind int
+
+const (
+	jsTokenNone jsTokenKind = iota
+	jsTokenIdentifier
+	jsTokenOperand // numbers, strings and templates
+	jsTokenPunctuator
+)
+
+type jsToken struct {
+	kind jsTokenKind
+	text string
+}
+
+// keywords after which a slash starts a regular expression
+var jsKeywordsBeforeExpression = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true, "delete": true, "void": true,
+	"throw": true, "case": true, "do": true, "else": true, "yield": true, "await": true, "extends": true,
+}
+
+// keywords of statements whose head in parentheses is followed by a statement
+var jsKeywordsBeforeStatementHead = map[string]bool{
+	"if": true, "while": true, "for": true, "switch": true, "catch": true,
+}
+
+// keywords followed by a block
+var jsKeywordsBeforeBlock = map[string]bool{
+	"else": true, "do": true, "try": true, "finally": true, "catch": true,
+}
+
+type jsInstrumenter struct {
+	code string
+	pos  int
+	out  strings.Builder
+
+	prev     jsToken
+	prevPrev jsToken
+
+	braces []jsBraceKind
+	parens []string // the statement keyword before every open parenthesis
+
+	closedParenKeyword string // of the last closing parenthesis
+	closedDoBody       bool   // the last token closed the body of a do-while
+	expectBlock        string // description of what requires the next token to be a block
+}
+
+func instrumentCodeForMetering(code string) (string, error) {
+	i := &jsInstrumenter{code: code}
+	err := i.run()
+	if err != nil {
+		return "", errors.Wrap(err, "contract code can't be metered")
+	}
+	return i.out.String(), nil
+}
+
+func (i *jsInstrumenter) run() error {
+	for i.pos < len(i.code) {
+		c := i.code[i.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f':
+			i.copy(1)
+		case c >= 0x80:
+			return i.errorf("non-ascii characters are only supported in strings and comments")
+		case c < 0x20 || c == 0x7f:
+			return i.errorf("unexpected control character")
+		case c == '/' && i.peek(1) == '/':
+			i.skipLineComment()
+		case c == '/' && i.peek(1) == '*':
+			if err := i.skipBlockComment(); err != nil {
+				return err
+			}
+		case c == '\'' || c == '"':
+			if err := i.scanString(c); err != nil {
+				return err
+			}
+		case c == '`':
+			i.copy(1)
+			if err := i.scanTemplate(); err != nil {
+				return err
+			}
+		case isJsIdentifierStart(c):
+			if err := i.scanIdentifier(); err != nil {
+				return err
+			}
+		case c >= '0' && c <= '9' || c == '.' && isJsDigit(i.peek(1)):
+			if err := i.scanNumber(); err != nil {
+				return err
+			}
+		case c == '\\':
+			return i.errorf("escapes are only supported in strings")
+		default:
+			if err := i.scanPunctuator(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(i.braces) != 0 || len(i.parens) != 0 {
+		return errors.New("unbalanced brackets at end of code")
+	}
+	if i.expectBlock != "" {
+		return errors.Errorf("%s must be a block", i.expectBlock)
+	}
+	return nil
+}
+
+func (i *jsInstrumenter) scanIdentifier() error {
+	start := i.pos
+	for i.pos < len(i.code) && (isJsIdentifierStart(i.code[i.pos]) || isJsDigit(i.code[i.pos])) {
+		i.pos++
+	}
+	text := i.code[start:i.pos]
+	i.out.WriteString(text)
+
+	if strings.HasPrefix(text, "$$") {
+		return errors.Errorf("identifier '%s' is reserved (at offset %d)", text, start)
+	}
+
+	isPropertyName := i.prev.kind == jsTokenPunctuator && (i.prev.text == "." || i.prev.text == "?.")
+	if err := i.beforeToken(text); err != nil {
+		return err
+	}
+
+	if !isPropertyName && text == "with" {
+		return errors.Errorf("with statements are not supported (at offset %d)", start)
+	}
+	if !isPropertyName && text == "do" {
+		i.expectBlock = "body of do-while"
+	}
+	i.pushToken(jsToken{jsTokenIdentifier, text})
+	if isPropertyName {
+		i.prev.text = "" // property names are never keywords
+	}
+	return nil
+}
+
+func (i *jsInstrumenter) scanNumber() error {
+	start := i.pos
+	for i.pos < len(i.code) && (isJsIdentifierStart(i.code[i.pos]) || isJsDigit(i.code[i.pos]) || i.code[i.pos] == '.') {
+		i.pos++
+	}
+	i.out.WriteString(i.code[start:i.pos])
+	err := i.beforeToken("")
+	i.pushToken(jsToken{jsTokenOperand, ""})
+	return err
+}
+
+func (i *jsInstrumenter) scanString(quote byte) error {
+	start := i.pos
+	i.pos++
+	for {
+		if i.pos >= len(i.code) {
+			return errors.Errorf("unterminated string at offset %d", start)
+		}
+		c := i.code[i.pos]
+		if c == quote {
+			i.pos++
+			break
+		}
+		if c == '\n' || c == '\r' {
+			return errors.Errorf("unterminated string at offset %d", start)
+		}
+		if c == '\\' {
+			if i.peek(1) == '\r' && i.peek(2) == '\n' {
+				i.pos++
+			}
+			i.pos++
+		}
+		i.pos++
+	}
+	i.out.WriteString(i.code[start:i.pos])
+	if err := i.beforeToken(""); err != nil {
+		return err
+	}
+	i.pushToken(jsToken{jsTokenOperand, ""})
+	return nil
+}
+
+// scans template text up to its end or up to a substitution, which is scanned as code until its closing brace
+func (i *jsInstrumenter) scanTemplate() error {
+	start := i.pos
+	for {
+		if i.pos >= len(i.code) {
+			return errors.Errorf("unterminated template at offset %d", start)
+		}
+		c := i.code[i.pos]
+		switch {
+		case c == '\\':
+			i.pos += 2
+		case c == '`':
+			i.pos++
+			i.out.WriteString(i.code[start:i.pos])
+			if err := i.beforeToken(""); err != nil {
+				return err
+			}
+			i.pushToken(jsToken{jsTokenOperand, ""})
+			return nil
+		case c == '$' && i.peek(1) == '{':
+			i.pos += 2
+			i.out.WriteString(i.code[start:i.pos])
+			i.braces = append(i.braces, jsBraceTemplate)
+			i.pushToken(jsToken{jsTokenPunctuator, "${"})
+			return nil
+		default:
+			i.pos++
+		}
+	}
+}
+
+func (i *jsInstrumenter) scanPunctuator() error {
+	start := i.pos
+	c := i.code[i.pos]
+
+	switch {
+	case c == '<' && strings.HasPrefix(i.code[i.pos:], "<!--"), c == '-' && strings.HasPrefix(i.code[i.pos:], "-->"):
+		return i.errorf("html comments are not supported")
+	case c == '/':
+		if i.isRegularExpressionAllowedHere() {
+			return i.errorf("regular expressions are not supported")
+		}
+	}
+
+	text := string(c)
+	for _, multi := range []string{"=>", "?.", "++", "--"} {
+		if strings.HasPrefix(i.code[i.pos:], multi) && !(multi == "?." && isJsDigit(i.peek(2))) {
+			text = multi
+			break
+		}
+	}
+	i.pos += len(text)
+	i.out.WriteString(i.code[start:i.pos])
+
+	if err := i.beforeToken(text); err != nil {
+		return err
+	}
+
+	switch text {
+	case "(":
+		i.parens = append(i.parens, i.statementKeywordBeforeParen())
+	case ")":
+		if len(i.parens) == 0 {
+			return errors.Errorf("unbalanced parenthesis at offset %d", start)
+		}
+		i.closedParenKeyword = i.parens[len(i.parens)-1]
+		i.parens = i.parens[:len(i.parens)-1]
+		if i.closedParenKeyword == "for" || i.closedParenKeyword == "while" {
+			i.expectBlock = "body of " + i.closedParenKeyword
+		}
+	case "=>":
+		i.expectBlock = "body of arrow function"
+	case "{":
+		kind := i.braceKindOpenedAfter(i.prev)
+		i.braces = append(i.braces, kind)
+		if kind == jsBraceBlock || kind == jsBraceDoBody {
+			i.out.WriteString(jsMeterTick)
+		}
+	case "}":
+		if len(i.braces) == 0 {
+			return errors.Errorf("unbalanced brace at offset %d", start)
+		}
+		kind := i.braces[len(i.braces)-1]
+		i.braces = i.braces[:len(i.braces)-1]
+		if kind == jsBraceTemplate {
+			return i.scanTemplate()
+		}
+		i.pushToken(jsToken{jsTokenPunctuator, text})
+		i.closedDoBody = kind == jsBraceDoBody
+		return nil
+	}
+
+	i.pushToken(jsToken{jsTokenPunctuator, text})
+	return nil
+}
+
+// checks the token about to be pushed against blocks required by the previous tokens
+func (i *jsInstrumenter) beforeToken(text string) error {
+	if i.expectBlock == "" {
+		return nil
+	}
+	expected := i.expectBlock
+	i.expectBlock = ""
+	if text == "{" {
+		return nil
+	}
+	if expected == "body of do-while" && (text == ":" || text == "(" || text == "," || text == "}") {
+		return nil // "do" was a property name
+	}
+	return errors.Errorf("%s must be a block (at offset %d)", expected, i.pos)
+}
+
+func (i *jsInstrumenter) braceKindOpenedAfter(prev jsToken) jsBraceKind {
+	switch {
+	case prev.kind == jsTokenPunctuator && prev.text == ")":
+		if i.closedParenKeyword == "switch" {
+			return jsBraceOther
+		}
+		return jsBraceBlock
+	case prev.kind == jsTokenPunctuator && prev.text == "=>":
+		return jsBraceBlock
+	case prev.kind == jsTokenIdentifier && prev.text == "do":
+		return jsBraceDoBody
+	case prev.kind == jsTokenIdentifier && jsKeywordsBeforeBlock[prev.text]:
+		return jsBraceBlock
+	}
+	return jsBraceOther
+}
+
+func (i *jsInstrumenter) statementKeywordBeforeParen() string {
+	if i.prev.kind != jsTokenIdentifier {
+		return ""
+	}
+	if i.prev.text == "while" && i.closedDoBody {
+		return "do-while"
+	}
+	if i.prev.text == "await" && i.prevPrev.kind == jsTokenIdentifier && i.prevPrev.text == "for" {
+		return "for"
+	}
+	if jsKeywordsBeforeStatementHead[i.prev.text] {
+		return i.prev.text
+	}
+	return ""
+}
+
+// when the slash may start a regular expression we don't try to tell (and reject the code), a wrong guess could hide code from metering
+func (i *jsInstrumenter) isRegularExpressionAllowedHere() bool {
+	switch i.prev.kind {
+	case jsTokenOperand:
+		return false
+	case jsTokenIdentifier:
+		return jsKeywordsBeforeExpression[i.prev.text]
+	case jsTokenPunctuator:
+		switch i.prev.text {
+		case "]":
+			return false
+		case ")":
+			return i.closedParenKeyword != ""
+		}
+	}
+	return true
+}
+
+func (i *jsInstrumenter) pushToken(token jsToken) {
+	if token.kind != jsTokenPunctuator || token.text != "}" {
+		i.closedDoBody = i.closedDoBody && token.kind == jsTokenIdentifier && token.text == "while"
+	}
+	i.prevPrev = i.prev
+	i.prev = token
+}
+
+func (i *jsInstrumenter) skipLineComment() {
+	start := i.pos
+	for i.pos < len(i.code) {
+		c := i.code[i.pos]
+		if c == '\n' || c == '\r' || strings.HasPrefix(i.code[i.pos:], "\u2028") || strings.HasPrefix(i.code[i.pos:], "\u2029") {
+			break
+		}
+		i.pos++
+	}
+	i.out.WriteString(i.code[start:i.pos])
+}
+
+func (i *jsInstrumenter) skipBlockComment() error {
+	end := strings.Index(i.code[i.pos+2:], "*/")
+	if end == -1 {
+		return i.errorf("unterminated comment")
+	}
+	i.copy(end + 4)
+	return nil
+}
+
+func (i *jsInstrumenter) copy(n int) {
+	i.out.WriteString(i.code[i.pos : i.pos+n])
+	i.pos += n
+}
+
+func (i *jsInstrumenter) peek(offset int) byte {
+	if i.pos+offset >= len(i.code) {
+		return 0
+	}
+	return i.code[i.pos+offset]
+}
+
+func (i *jsInstrumenter) errorf(message string) error {
+	return errors.Errorf("%s (at offset %d)", message, i.pos)
+}
+
+func isJsIdentifierStart(c byte) bool {
+	return c == '_' || c == '$' || c == '#' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isJsDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// runs after the sdk and before the contract: removes globals which are local to the node or non-deterministic,
+// keeps the worker reachable only from the meter so every message carries the ticks counted since the previous one,
+// and freezes the built-ins so contract code can't tamper with the messages
+const METER_JS_IMPLEMENTATION = `
+delete this.Date;
+delete this.Intl;
+delete this.WeakRef;
+delete this.FinalizationRegistry;
+delete this.SharedArrayBuffer;
+delete this.Atomics;
+delete this.WebAssembly;
+delete this.RegExp; // matching is not metered
+delete Math.random;
+delete String.prototype.match;
+delete String.prototype.matchAll;
+delete String.prototype.search;
+
+const [$$meter, $$send] = (function(worker, encode, decode, stringify, parse) {
+	let ticks = 0;
+	let exhausted = false;
+	function send(message) {
+		message.ticks = ticks;
+		ticks = 0;
+		const response = worker.send(encode(stringify(message)));
+		if (response === undefined) {
+			return {};
+		}
+		const parsed = parse(decode(response));
+		if (parsed.exhausted) {
+			exhausted = true;
+		}
+		return parsed;
+	}
+	function meter() {
+		if (exhausted) {
+			throw new Error("execution budget exceeded");
+		}
+		ticks++;
+		if (ticks >= %d) {
+			send({object: "meter"});
+			if (exhausted) {
+				throw new Error("execution budget exceeded");
+			}
+		}
+	}
+	return [meter, send];
+})(V8Worker2, $bridge.encode, $bridge.decode, JSON.stringify, JSON.parse);
+
+delete this.V8Worker2;
+
+(function(root) {
+	const seen = new Set();
+	const pending = [root, $bridge, $sdk];
+	while (pending.length > 0) {
+		const value = pending.pop();
+		if ((typeof value !== "object" && typeof value !== "function") || value === null || seen.has(value)) {
+			continue;
+		}
+		seen.add(value);
+		Object.freeze(value);
+		pending.push(Object.getPrototypeOf(value));
+		for (const key of Reflect.ownKeys(value)) {
+			const descriptor = Object.getOwnPropertyDescriptor(value, key);
+			pending.push(descriptor.value, descriptor.get, descriptor.set);
+		}
+	}
+})(this);
+`
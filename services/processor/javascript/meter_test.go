@@ -0,0 +1,62 @@
+package javascript
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestInstrumentCodeForMetering_TicksEveryFunctionAndLoopBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		code     string
+		expected string
+	}{
+		{"class methods", "class A { static f(a) { return a; } }", "class A { static f(a) {$$meter(); return a; } }"},
+		{"functions", "function f() { return 1; }", "function f() {$$meter(); return 1; }"},
+		{"arrow functions", "const f = (a) => { return a; };", "const f = (a) => {$$meter(); return a; };"},
+		{"while loops", "while (true) { x++; }", "while (true) {$$meter(); x++; }"},
+		{"for loops", "for (let i = 0; i < 10; i++) { }", "for (let i = 0; i < 10; i++) {$$meter(); }"},
+		{"do-while loops", "do { x++; } while (x < 10);", "do {$$meter(); x++; } while (x < 10);"},
+		{"try blocks", "try { f(); } catch (e) { g(); } finally { h(); }", "try {$$meter(); f(); } catch (e) {$$meter(); g(); } finally {$$meter(); h(); }"},
+		{"object literals are not blocks", "const o = {a: 1, b: {c: 2}};", "const o = {a: 1, b: {c: 2}};"},
+		{"switch bodies are not blocks", "switch (x) { case 1: break; }", "switch (x) { case 1: break; }"},
+		{"braces in strings and comments", "const s = \"{\"; // while (true) {\n/* { */", "const s = \"{\"; // while (true) {\n/* { */"},
+		{"template substitutions", "const s = `a${(() => { return 1; })()}b`;", "const s = `a${(() => {$$meter(); return 1; })()}b`;"},
+		{"division", "const x = (a + b) / 2 / c[0];", "const x = (a + b) / 2 / c[0];"},
+		{"property names are not keywords", "x.do = {do: 1, while: 2};", "x.do = {do: 1, while: 2};"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			instrumented, err := instrumentCodeForMetering(tt.code)
+			require.NoError(t, err, "code should be instrumented")
+			require.Equal(t, tt.expected, instrumented, "code should be instrumented with ticks")
+		})
+	}
+}
+
+func TestInstrumentCodeForMetering_RejectsCodeThatCantBeMetered(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"loop without a block", "while (true) x++;"},
+		{"empty loop", "for (;;);"},
+		{"do-while without a block", "do x++; while (true);"},
+		{"arrow function without a block", "const f = a => f(a) + f(a);"},
+		{"regular expression", "const r = /{/;"},
+		{"regular expression after statement head", "if (x) /\"/.test(y); while (true) {}//\""},
+		{"regular expression after keyword", "return /a/;"},
+		{"html comment", "x <!-- y\nwhile (true);"},
+		{"reserved identifier", "const $$meter = () => {};"},
+		{"with statement", "with ({}) { }"},
+		{"non-ascii whitespace", "return\u00a0/\"/; while (true);//\""},
+		{"unterminated string", "const s = \"abc\nwhile (true);"},
+		{"unbalanced braces", "function f() {"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := instrumentCodeForMetering(tt.code)
+			require.Error(t, err, "code should be rejected")
+		})
+	}
+}
@@ -1,14 +1,167 @@
 package javascript
 
+// every sdk call is a synchronous json message over the worker bridge (see bridge.go) sent through the meter runtime (see meter.go),
+// plain numbers are passed as uint64 and $sdk.uint32() / $sdk.uint64() wrap values that need an explicit type
 const SDK_JS_IMPLEMENTATION = `
-$sdk = {
-	state: class {
-		static readUint64ByKey(key) {
-			return 17;
+const $bridge = {
+	encode(str) {
+		const utf8 = unescape(encodeURIComponent(str));
+		const bytes = new Uint8Array(utf8.length);
+		for (let i = 0; i < utf8.length; i++) {
+			bytes[i] = utf8.charCodeAt(i);
 		}
-		static writeUint64ByKey(key, value) {
-			return;
+		return bytes.buffer;
+	},
+	decode(buffer) {
+		const bytes = new Uint8Array(buffer);
+		let utf8 = "";
+		for (let i = 0; i < bytes.length; i++) {
+			utf8 += String.fromCharCode(bytes[i]);
+		}
+		return decodeURIComponent(escape(utf8));
+	},
+	toHex(bytes) {
+		let hex = "";
+		for (let i = 0; i < bytes.length; i++) {
+			hex += (bytes[i] < 16 ? "0" : "") + bytes[i].toString(16);
+		}
+		return hex;
+	},
+	fromHex(hex) {
+		const bytes = new Uint8Array(hex.length / 2);
+		for (let i = 0; i < bytes.length; i++) {
+			bytes[i] = parseInt(hex.substr(i * 2, 2), 16);
+		}
+		return bytes;
+	},
+	toArgument(value) {
+		if (value instanceof $sdk.TypedNumber) {
+			return {type: value.type, value: value.value};
+		}
+		if (typeof value === "number") {
+			if (!Number.isSafeInteger(value) || value < 0) {
+				throw new Error("number " + value + " is not a valid uint64");
+			}
+			return {type: "uint64", value: String(value)};
 		}
+		if (typeof value === "string") {
+			return {type: "string", value: value};
+		}
+		if (value instanceof Uint8Array) {
+			return {type: "bytes", value: $bridge.toHex(value)};
+		}
+		throw new Error("unsupported argument type " + typeof value);
+	},
+	fromArgument(arg) {
+		switch (arg.type) {
+			case "uint32":
+			case "uint64":
+				return Number(arg.value);
+			case "string":
+				return arg.value;
+			case "bytes":
+				return $bridge.fromHex(arg.value);
+		}
+		throw new Error("unsupported argument type " + arg.type);
+	},
+	toArguments(values) {
+		return values.map($bridge.toArgument);
+	},
+	fromArguments(args) {
+		return (args || []).map($bridge.fromArgument);
+	},
+	send(message) {
+		return $$send(message);
+	},
+	call(object, method, args) {
+		const response = $bridge.send({object: object, method: method, args: $bridge.toArguments(args)});
+		if (response.error) {
+			throw new Error(response.error);
+		}
+		return $bridge.fromArguments(response.args);
+	},
+	callSingle(object, method, args) {
+		return $bridge.call(object, method, args)[0];
+	},
+};
+
+const $sdk = {
+	TypedNumber: class {
+		constructor(type, value) {
+			this.type = type;
+			this.value = String(value instanceof $sdk.TypedNumber ? value.value : value);
+		}
+	},
+	uint32(value) {
+		return new $sdk.TypedNumber("uint32", value);
+	},
+	uint64(value) {
+		return new $sdk.TypedNumber("uint64", value);
+	},
+	state: {
+		readBytesByKey(key) {
+			return $bridge.callSingle("state", "readBytesByKey", [key]);
+		},
+		writeBytesByKey(key, value) {
+			$bridge.call("state", "writeBytesByKey", [key, value]);
+		},
+		readStringByKey(key) {
+			return $bridge.callSingle("state", "readStringByKey", [key]);
+		},
+		writeStringByKey(key, value) {
+			$bridge.call("state", "writeStringByKey", [key, value]);
+		},
+		readUint32ByKey(key) {
+			return $bridge.callSingle("state", "readUint32ByKey", [key]);
+		},
+		writeUint32ByKey(key, value) {
+			$bridge.call("state", "writeUint32ByKey", [key, $sdk.uint32(value)]);
+		},
+		readUint64ByKey(key) {
+			return $bridge.callSingle("state", "readUint64ByKey", [key]);
+		},
+		writeUint64ByKey(key, value) {
+			$bridge.call("state", "writeUint64ByKey", [key, $sdk.uint64(value)]);
+		},
+		clearByKey(key) {
+			$bridge.call("state", "clearByKey", [key]);
+		},
+	},
+	service: {
+		callMethod(serviceName, methodName, ...args) {
+			return $bridge.call("service", "callMethod", [serviceName, methodName].concat(args));
+		},
+	},
+	address: {
+		getSignerAddress() {
+			return $bridge.callSingle("address", "getSignerAddress", []);
+		},
+		getCallerAddress() {
+			return $bridge.callSingle("address", "getCallerAddress", []);
+		},
+	},
+	env: {
+		getBlockHeight() {
+			return $bridge.callSingle("env", "getBlockHeight", []);
+		},
+		getBlockTimestamp() {
+			return $bridge.callSingle("env", "getBlockTimestamp", []);
+		},
+		getVirtualChainId() {
+			return $bridge.callSingle("env", "getVirtualChainId", []);
+		},
+		getTxHash() {
+			return $bridge.callSingle("env", "getTxHash", []);
+		},
+	},
+	ethereum: {
+		callMethod(contractAddress, jsonAbi, methodName, blockNumber, ...args) {
+			return $bridge.call("ethereum", "callMethod", [contractAddress, jsonAbi, methodName, blockNumber].concat(args));
+		},
+		getTransactionLog(contractAddress, jsonAbi, eventName, txHash, logIndex, blockNumber) {
+			const [ethereumBlockNumber, ...args] = $bridge.call("ethereum", "getTransactionLog", [contractAddress, jsonAbi, eventName, txHash, $sdk.uint32(logIndex), blockNumber]);
+			return {ethereumBlockNumber, args};
+		},
 	},
 };
 `
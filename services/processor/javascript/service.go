@@ -3,6 +3,7 @@ package javascript
 import (
 	"context"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -17,14 +18,14 @@ type service struct {
 
 	mutex                        *sync.RWMutex
 	contractSdkHandlerUnderMutex handlers.ContractSdkCallHandler
-	contractsUnderMutex          map[primitives.ContractName]string
+	contractsUnderMutex          map[string]string // instrumented code by code hash
 }
 
 func NewJavaScriptProcessor(logger log.BasicLogger) services.Processor {
 	return &service{
 		logger:              logger.WithTags(LogTag),
 		mutex:               &sync.RWMutex{},
-		contractsUnderMutex: make(map[primitives.ContractName]string),
+		contractsUnderMutex: make(map[string]string),
 	}
 }
 
@@ -46,8 +47,17 @@ func (s *service) ProcessCall(ctx context.Context, input *services.ProcessCallIn
 		}, err
 	}
 
+	// check permissions
+	err = native.VerifyUntypedMethodPermissions(input.ContractName, input.MethodName, input.CallingService, input.CallingPermissionScope)
+	if err != nil {
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: native.ArgsToMethodArgumentArray(err.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+		}, err
+	}
+
 	// execute
-	outputArgs, contractErr, err := s.processMethodCall(input.ContextId, code, input.MethodName, input.InputArgumentArray)
+	outputArgs, contractErr, err := s.processMethodCall(ctx, input.ContextId, input.ContractName, code, input.MethodName, input.InputArgumentArray)
 	if outputArgs == nil {
 		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
 	}
@@ -70,7 +80,30 @@ func (s *service) ProcessCall(ctx context.Context, input *services.ProcessCallIn
 }
 
 func (s *service) GetContractInfo(ctx context.Context, input *services.GetContractInfoInput) (*services.GetContractInfoOutput, error) {
-	panic("Not implemented")
+	// makes sure the contract is deployed
+	_, err := s.retrieveContractCodeFromRepository(ctx, input.ContextId, input.ContractName)
+	if err != nil {
+		return nil, err
+	}
+
+	// javascript is untyped so the methods of the contract can't be described
+	return &services.GetContractInfoOutput{
+		PermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+	}, nil
+}
+
+func isValidJsIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		isLetter := r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		isDigit := r >= '0' && r <= '9'
+		if !isLetter && !(isDigit && i > 0) {
+			return false
+		}
+	}
+	return true
 }
 
 func (s *service) getContractSdkHandler() handlers.ContractSdkCallHandler {
@@ -80,22 +113,22 @@ func (s *service) getContractSdkHandler() handlers.ContractSdkCallHandler {
 	return s.contractSdkHandlerUnderMutex
 }
 
-func (s *service) getContractFromRepository(contractName primitives.ContractName) string {
+func (s *service) getContractFromRepository(codeHash primitives.Sha256) string {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	if s.contractsUnderMutex == nil {
 		return ""
 	}
-	return s.contractsUnderMutex[contractName]
+	return s.contractsUnderMutex[codeHash.KeyForMap()]
 }
 
-func (s *service) addContractToRepository(contractName primitives.ContractName, code string) {
+func (s *service) addContractToRepository(codeHash primitives.Sha256, code string) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if s.contractsUnderMutex == nil {
 		return
 	}
-	s.contractsUnderMutex[contractName] = code
+	s.contractsUnderMutex[codeHash.KeyForMap()] = code
 }
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProcessCall_DispatchesToMethodWithArgs(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "add").WithArgs(uint64(5)).WithWriteAccess().Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+		h.expectSdkCallMadeWithStateRead(nil, uint64ToBytes(100))
+		h.expectSdkCallMadeWithStateWrite(nil, uint64ToBytes(105))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult, "call result should be success")
+		require.False(t, output.OutputArgumentArray.ArgumentsIterator().HasNext(), "method without return value should have no outputs")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_ReturnsMethodOutput(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "get").Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+		h.expectSdkCallMadeWithStateRead(nil, uint64ToBytes(117))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, builders.MethodArgumentsArray(uint64(117)).Raw(), output.OutputArgumentArray.Raw(), "call should return the counter value")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_UnknownMethodFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "unknownMethod").Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, output.CallResult, "call result should be unexpected error")
+	})
+}
+
+func TestProcessCall_InternalMethodFromDifferentServiceFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "_init").WithDifferentCallingService().Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, output.CallResult, "call result should be unexpected error")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+const ENDLESS_LOOP_JAVASCRIPT_SOURCE_CODE = `
+class EndlessLoop {
+	static loop() {
+		for (;;) {
+			try {
+				while (true) {
+				}
+			} catch (e) {
+			}
+		}
+	}
+}
+`
+
+func TestProcessCall_EndlessLoopExhaustsExecutionBudget(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.executionBudget = 100 * 1000
+		input := processCallInput().WithMethod("EndlessLoop", "loop").Build()
+		h.expectCodeRetrieved(input.ContractName, []byte(ENDLESS_LOOP_JAVASCRIPT_SOURCE_CODE))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, output.CallResult, "call result should be smart contract error")
+		require.Zero(t, h.executionBudget, "endless loop should use the whole budget even when it catches the error")
+	})
+}
+
+const GLOBALS_JAVASCRIPT_SOURCE_CODE = `
+class Globals {
+	static types() {
+		return typeof Date + " " + typeof Math.random + " " + typeof RegExp;
+	}
+	static evaluate() {
+		return eval("1 + 1");
+	}
+}
+`
+
+func TestProcessCall_NonDeterministicGlobalsAreRemoved(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("Globals", "types").Build()
+		h.expectCodeRetrieved(input.ContractName, []byte(GLOBALS_JAVASCRIPT_SOURCE_CODE))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, builders.MethodArgumentsArray("undefined undefined undefined").Raw(), output.OutputArgumentArray.Raw(), "non-deterministic globals should not exist")
+	})
+}
+
+func TestProcessCall_CodeGenerationFromStringsFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("Globals", "evaluate").Build()
+		h.expectCodeRetrieved(input.ContractName, []byte(GLOBALS_JAVASCRIPT_SOURCE_CODE))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, output.CallResult, "call result should be smart contract error")
+	})
+}
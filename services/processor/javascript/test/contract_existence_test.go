@@ -1,5 +1,3 @@
-// +build jsprocessor
-
 package test
 
 import (
@@ -17,7 +15,7 @@ func TestProcessCall_WithUnknownContractFails(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
 		input := processCallInput().WithUnknownContract().Build()
-		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
+		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
 
 		_, err := h.service.ProcessCall(ctx, input)
 		require.Error(t, err, "call should fail")
@@ -30,8 +28,7 @@ func TestProcessCall_WithDeployableContractSucceeds(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
 		input := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
-		codeOutput := builders.MethodArgumentsArray([]byte(contracts.JavaScriptSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM)))
-		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(input.ContractName)), codeOutput, nil)
+		h.expectCounterCodeRetrieved(input.ContractName, contracts.MOCK_COUNTER_CONTRACT_START_FROM)
 
 		output, err := h.service.ProcessCall(ctx, input)
 		require.NoError(t, err, "call should succeed")
@@ -40,6 +37,8 @@ func TestProcessCall_WithDeployableContractSucceeds(t *testing.T) {
 		t.Log("First call should getCode for compilation")
 		h.verifySdkCallMade(t)
 
+		h.expectCodeHashRequested(input.ContractName, []byte(contracts.JavaScriptSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM)))
+
 		output, err = h.service.ProcessCall(ctx, input)
 		require.NoError(t, err, "call should succeed")
 		require.Equal(t, contracts.MOCK_COUNTER_CONTRACT_START_FROM, output.OutputArgumentArray.ArgumentsIterator().NextArguments().Uint64Value(), "call return value should be counter value")
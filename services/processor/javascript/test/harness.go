@@ -2,37 +2,71 @@ package test
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"github.com/orbs-network/go-mock"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/services/processor/javascript"
 	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"os"
 	"testing"
 )
 
 type harness struct {
-	sdkCallHandler *handlers.MockContractSdkCallHandler
-	service        services.Processor
+	sdkCallHandler  *contractSdkCallHandlerMock
+	service         services.Processor
+	executionBudget uint64
 }
 
+// the spec mock can't charge execution units since processors charge them outside of HandleSdkCall
+type contractSdkCallHandlerMock struct {
+	handlers.MockContractSdkCallHandler
+}
+
+func (h *contractSdkCallHandlerMock) ChargeExecutionUnits(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+	ret := h.Called(ctx, executionContextId, units)
+	return ret.Error(0)
+}
+
+const DEFAULT_TEST_EXECUTION_BUDGET = 1000 * 1000 * 1000
+
 func newHarness() *harness {
 	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
 
-	sdkCallHandler := &handlers.MockContractSdkCallHandler{}
+	sdkCallHandler := &contractSdkCallHandlerMock{}
 
 	service := javascript.NewJavaScriptProcessor(log)
 	service.RegisterContractSdkCallHandler(sdkCallHandler)
 
-	return &harness{
-		sdkCallHandler: sdkCallHandler,
-		service:        service,
+	h := &harness{
+		sdkCallHandler:  sdkCallHandler,
+		service:         service,
+		executionBudget: DEFAULT_TEST_EXECUTION_BUDGET,
 	}
+	h.expectExecutionMeterCharged()
+	return h
+}
+
+// contract code charges its ticks while it runs, they are charged to the budget of the harness like the virtual machine does
+func (h *harness) expectExecutionMeterCharged() {
+	h.sdkCallHandler.When("ChargeExecutionUnits", mock.Any, mock.Any, mock.Any).Call(func(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+		if units > h.executionBudget {
+			h.executionBudget = 0
+			return errors.New("execution budget exceeded")
+		}
+		h.executionBudget -= units
+		return nil
+	}).AtLeast(0)
 }
 
 func (h *harness) expectSdkCallMadeWithStateRead(expectedKey []byte, returnValue []byte) {
@@ -103,6 +137,20 @@ func (h *harness) expectSdkCallMadeWithAddressGetCaller(returnAddress []byte) {
 	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.Address, method equals getCallerAddress and 1 arg match", addressGetCallerCallMatcher)).Return(returnOutput, nil).Times(1)
 }
 
+func (h *harness) expectCounterCodeRetrieved(contractName primitives.ContractName, counterStart uint64) {
+	h.expectCodeRetrieved(contractName, []byte(contracts.JavaScriptSourceCodeForCounter(counterStart)))
+}
+
+func (h *harness) expectCodeRetrieved(contractName primitives.ContractName, code []byte) {
+	h.expectCodeHashRequested(contractName, code)
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(contractName)), builders.MethodArgumentsArray(code), nil)
+}
+
+func (h *harness) expectCodeHashRequested(contractName primitives.ContractName, code []byte) {
+	codeHash := sha256.Sum256(code)
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(contractName)), builders.MethodArgumentsArray(codeHash[:]), nil)
+}
+
 func (h *harness) verifySdkCallMade(t *testing.T) {
 	_, err := h.sdkCallHandler.Verify()
 	require.NoError(t, err, "sdkCallHandler should be called as expected")
@@ -0,0 +1,101 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"reflect"
+	"sort"
+)
+
+// contract abis are not part of the pinned spec, so processors that can describe the methods of their contracts
+// implement this next to services.Processor (javascript and wasm contracts are untyped so their processors don't)
+type ContractDescriber interface {
+	DescribeContract(ctx context.Context, input *services.GetContractInfoInput) (*DescribeContractOutput, error)
+}
+
+type DescribeContractOutput struct {
+	PermissionScope protocol.ExecutionPermissionScope
+	Methods         []*ContractMethod
+}
+
+// the argument types exclude the context and the output types exclude the contract error
+type ContractMethod struct {
+	MethodName          primitives.MethodName
+	AccessScope         protocol.ExecutionAccessScope
+	InputArgumentTypes  []protocol.MethodArgumentType
+	OutputArgumentTypes []protocol.MethodArgumentType
+}
+
+// describes the external methods of a contract so clients can validate calls before sending them,
+// methods with signatures that can't be called (see prepareMethodInputArgsForCall) are left out
+func describeExternalMethods(contractInfo *sdk.ContractInfo) []*ContractMethod {
+	methodNames := make([]string, 0, len(contractInfo.Methods))
+	for name, methodInfo := range contractInfo.Methods {
+		if methodInfo.External {
+			methodNames = append(methodNames, name)
+		}
+	}
+	sort.Strings(methodNames)
+
+	res := make([]*ContractMethod, 0, len(methodNames))
+	for _, name := range methodNames {
+		method := describeMethod(contractInfo.Methods[name])
+		if method != nil {
+			res = append(res, method)
+		}
+	}
+	return res
+}
+
+func describeMethod(methodInfo sdk.MethodInfo) *ContractMethod {
+	const NUM_ARGS_RECEIVER_AND_CONTEXT = 2
+
+	methodType := reflect.TypeOf(methodInfo.Implementation)
+	if methodType == nil || methodType.Kind() != reflect.Func || methodType.NumOut() < 1 ||
+		methodType.NumIn() < NUM_ARGS_RECEIVER_AND_CONTEXT || methodType.In(1) != reflect.TypeOf(sdk.Context(0)) {
+		return nil
+	}
+
+	inputTypes := []protocol.MethodArgumentType{}
+	for i := NUM_ARGS_RECEIVER_AND_CONTEXT; i < methodType.NumIn(); i++ {
+		argType, ok := methodArgumentType(methodType.In(i))
+		if !ok {
+			return nil
+		}
+		inputTypes = append(inputTypes, argType)
+	}
+
+	// the last output is the contract error which is not part of the output arguments
+	outputTypes := []protocol.MethodArgumentType{}
+	for i := 0; i < methodType.NumOut()-1; i++ {
+		argType, ok := methodArgumentType(methodType.Out(i))
+		if !ok {
+			return nil
+		}
+		outputTypes = append(outputTypes, argType)
+	}
+
+	return &ContractMethod{
+		MethodName:          primitives.MethodName(methodInfo.Name),
+		AccessScope:         protocol.ExecutionAccessScope(methodInfo.Access),
+		InputArgumentTypes:  inputTypes,
+		OutputArgumentTypes: outputTypes,
+	}
+}
+
+func methodArgumentType(t reflect.Type) (protocol.MethodArgumentType, bool) {
+	switch t.Kind() {
+	case reflect.Uint32:
+		return protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, true
+	case reflect.Uint64:
+		return protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, true
+	case reflect.String:
+		return protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, true
+	case reflect.Slice:
+		return protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, t.Elem().Kind() == reflect.Uint8
+	}
+	return 0, false
+}
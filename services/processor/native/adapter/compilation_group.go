@@ -0,0 +1,53 @@
+package adapter
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"sync"
+)
+
+// deduplicates concurrent compilations of the same code, later callers wait for the compilation already in flight
+type compilationGroup struct {
+	mutex              sync.Mutex
+	inFlightUnderMutex map[string]*compilation
+}
+
+type compilation struct {
+	done         chan struct{}
+	contractInfo *sdk.ContractInfo
+	err          error
+}
+
+func newCompilationGroup() *compilationGroup {
+	return &compilationGroup{
+		inFlightUnderMutex: make(map[string]*compilation),
+	}
+}
+
+func (g *compilationGroup) compile(ctx context.Context, code string, compile func() (*sdk.ContractInfo, error)) (*sdk.ContractInfo, error) {
+	hashOfCode := getHashOfCode(code)
+
+	g.mutex.Lock()
+	inFlight, found := g.inFlightUnderMutex[hashOfCode]
+	if found {
+		g.mutex.Unlock()
+		select {
+		case <-inFlight.done:
+			return inFlight.contractInfo, inFlight.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	c := &compilation{done: make(chan struct{})}
+	g.inFlightUnderMutex[hashOfCode] = c
+	g.mutex.Unlock()
+
+	c.contractInfo, c.err = compile()
+
+	g.mutex.Lock()
+	delete(g.inFlightUnderMutex, hashOfCode)
+	g.mutex.Unlock()
+	close(c.done)
+
+	return c.contractInfo, c.err
+}
@@ -0,0 +1,59 @@
+package adapter
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/stretchr/testify/require"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCompilationGroup_DeduplicatesConcurrentCompilationsOfSameCode(t *testing.T) {
+	g := newCompilationGroup()
+	contractInfo := &sdk.ContractInfo{Name: "Deduplicated"}
+	release := make(chan struct{})
+	var compilations int32
+
+	var wg sync.WaitGroup
+	results := make([]*sdk.ContractInfo, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := g.compile(context.Background(), "same code", func() (*sdk.ContractInfo, error) {
+				atomic.AddInt32(&compilations, 1)
+				<-release
+				return contractInfo, nil
+			})
+			require.NoError(t, err, "compile should succeed")
+			results[i] = res
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond) // let all callers reach the group
+	close(release)
+	wg.Wait()
+
+	require.EqualValues(t, 1, atomic.LoadInt32(&compilations), "code should be compiled once")
+	for _, res := range results {
+		require.Equal(t, contractInfo, res, "all callers should get the same contract")
+	}
+}
+
+func TestCompilationGroup_CompilesAgainAfterCompilationCompleted(t *testing.T) {
+	g := newCompilationGroup()
+	var compilations int32
+	compile := func() (*sdk.ContractInfo, error) {
+		atomic.AddInt32(&compilations, 1)
+		return &sdk.ContractInfo{}, nil
+	}
+
+	_, err := g.compile(context.Background(), "code", compile)
+	require.NoError(t, err)
+	_, err = g.compile(context.Background(), "code", compile)
+	require.NoError(t, err)
+
+	require.EqualValues(t, 2, atomic.LoadInt32(&compilations), "completed compilations should not be reused by the group")
+}
@@ -0,0 +1,249 @@
+package adapter
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"reflect"
+	"sync"
+)
+
+type ExecutorConfig interface {
+	Config
+	ProcessorNativeExecutorPath() string
+	ProcessorNativeExecutorCallMemoryLimitInBytes() uint32
+	ProcessorNativeExecutorCallCpuLimitInSeconds() uint32
+}
+
+// implemented by compilers that need to serve sdk calls themselves (the native processor passes its handler on)
+type ContractSdkCallHandlerRegistrar interface {
+	RegisterContractSdkCallHandler(handler handlers.ContractSdkCallHandler)
+}
+
+// remote methods are called through reflection like contracts running in process, which passes them no context,
+// so the processor sets the context of the call under its execution context id until the call returns
+type CallContextSetter interface {
+	SetCallContext(ctx context.Context, executionContextId primitives.ExecutionContextId) (restore func())
+}
+
+// compiles contracts like the native compiler but never loads them into the node process,
+// the returned contract runs every call in an executor child process of its own
+type executorCompiler struct {
+	config       ExecutorConfig
+	logger       log.BasicLogger
+	executor     *executorProcess
+	compilations *compilationGroup
+
+	callContextsMutex      sync.Mutex
+	callContextsUnderMutex map[primitives.ExecutionContextId]context.Context
+}
+
+func NewExecutorCompiler(config ExecutorConfig, logger log.BasicLogger) Compiler {
+	c := &executorCompiler{
+		config:       config,
+		logger:       logger.WithTags(LogTag),
+		compilations: newCompilationGroup(),
+
+		callContextsUnderMutex: make(map[primitives.ExecutionContextId]context.Context),
+	}
+	c.executor = newExecutorProcess(config.ProcessorNativeExecutorPath(), config.ProcessorNativeExecutorCallMemoryLimitInBytes(), config.ProcessorNativeExecutorCallCpuLimitInSeconds(), c.logger)
+
+	c.warmUpCompilationCache() // so next compilations take 200 ms instead of 2 sec
+	startRebuildingKeptSources(config.ProcessorArtifactPath(), c.logger)
+
+	return c
+}
+
+func (c *executorCompiler) RegisterContractSdkCallHandler(handler handlers.ContractSdkCallHandler) {
+	c.executor.registerContractSdkCallHandler(handler)
+}
+
+// nested calls run under the execution context of their caller, so restoring brings back the context of the caller
+func (c *executorCompiler) SetCallContext(ctx context.Context, executionContextId primitives.ExecutionContextId) (restore func()) {
+	c.callContextsMutex.Lock()
+	defer c.callContextsMutex.Unlock()
+
+	previous, hadPrevious := c.callContextsUnderMutex[executionContextId]
+	c.callContextsUnderMutex[executionContextId] = ctx
+	return func() {
+		c.callContextsMutex.Lock()
+		defer c.callContextsMutex.Unlock()
+
+		if hadPrevious {
+			c.callContextsUnderMutex[executionContextId] = previous
+		} else {
+			delete(c.callContextsUnderMutex, executionContextId)
+		}
+	}
+}
+
+func (c *executorCompiler) callContext(executionContextId primitives.ExecutionContextId) context.Context {
+	c.callContextsMutex.Lock()
+	defer c.callContextsMutex.Unlock()
+
+	if ctx, found := c.callContextsUnderMutex[executionContextId]; found {
+		return ctx
+	}
+	return context.Background()
+}
+
+func (c *executorCompiler) warmUpCompilationCache() {
+	ctx, cancel := context.WithTimeout(context.Background(), MAX_WARM_UP_COMPILATION_TIME)
+	defer cancel()
+
+	_, err := compileSharedObject(ctx, string(contracts.SourceCodeForNop()), c.config.ProcessorArtifactPath())
+	if err != nil {
+		c.logger.Error("warm up compilation on init failed", log.Error(err))
+	}
+}
+
+func (c *executorCompiler) Compile(ctx context.Context, code string) (*sdk.ContractInfo, error) {
+	return c.compilations.compile(ctx, code, func() (*sdk.ContractInfo, error) {
+		return c.compileAndDescribe(ctx, code)
+	})
+}
+
+func (c *executorCompiler) compileAndDescribe(ctx context.Context, code string) (*sdk.ContractInfo, error) {
+	soFilePath, err := compileSharedObject(ctx, code, c.config.ProcessorArtifactPath())
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.executor.request(ctx, &ExecutorMessage{
+		Type:             EXECUTOR_MESSAGE_DESCRIBE,
+		SharedObjectPath: soFilePath,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "contract executor failed to load compiled contract")
+	}
+	if result.ContractDescription == nil {
+		return nil, errors.New("contract executor returned no contract description")
+	}
+
+	return c.remoteContractInfo(soFilePath, result.ContractDescription)
+}
+
+type remoteContract struct{}
+
+var remoteContractType = reflect.TypeOf(&remoteContract{})
+var contextType = reflect.TypeOf(sdk.Context(0))
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+var executorTypes = map[string]reflect.Type{
+	EXECUTOR_TYPE_UINT32: reflect.TypeOf(uint32(0)),
+	EXECUTOR_TYPE_UINT64: reflect.TypeOf(uint64(0)),
+	EXECUTOR_TYPE_STRING: reflect.TypeOf(""),
+	EXECUTOR_TYPE_BYTES:  reflect.TypeOf([]byte{}),
+}
+
+// the method implementations are generated with the exact signatures of the remote methods so the native processor
+// converts arguments exactly like it does for contracts running in process
+func (c *executorCompiler) remoteContractInfo(soFilePath string, description *ExecutorContractDescription) (*sdk.ContractInfo, error) {
+	contractInfo := &sdk.ContractInfo{
+		Name:       description.Name,
+		Permission: description.Permission,
+		Methods:    make(map[string]sdk.MethodInfo),
+		InitSingleton: func(base *sdk.BaseContract) sdk.ContractInstance {
+			return &remoteContract{}
+		},
+	}
+
+	for _, method := range description.Methods {
+		implementation, err := c.remoteMethodImplementation(soFilePath, method)
+		if err != nil {
+			return nil, err
+		}
+		contractInfo.Methods[method.Name] = sdk.MethodInfo{
+			Name:           method.Name,
+			External:       method.External,
+			Access:         method.Access,
+			Implementation: implementation,
+		}
+	}
+
+	return contractInfo, nil
+}
+
+func (c *executorCompiler) remoteMethodImplementation(soFilePath string, method *ExecutorMethodDescription) (interface{}, error) {
+	in := []reflect.Type{remoteContractType, contextType}
+	for _, typeName := range method.InputTypes {
+		t, found := executorTypes[typeName]
+		if !found {
+			return nil, errors.Errorf("method '%s' has an argument of unsupported type '%s'", method.Name, typeName)
+		}
+		in = append(in, t)
+	}
+	out := []reflect.Type{}
+	for _, typeName := range method.OutputTypes {
+		t, found := executorTypes[typeName]
+		if !found {
+			return nil, errors.Errorf("method '%s' has an output of unsupported type '%s'", method.Name, typeName)
+		}
+		out = append(out, t)
+	}
+	out = append(out, errorType)
+
+	methodType := reflect.FuncOf(in, out, false)
+	return reflect.MakeFunc(methodType, func(args []reflect.Value) []reflect.Value {
+		arguments := []interface{}{}
+		for _, arg := range args[2:] {
+			arguments = append(arguments, arg.Interface())
+		}
+		outputs, err := c.callRemoteMethod(soFilePath, method.Name, args[1].Interface().(sdk.Context), arguments)
+		return remoteMethodResults(methodType, outputs, err)
+	}).Interface(), nil
+}
+
+// a contract error (including running past the limits of the executor) is the result of the call, an executor failure
+// is not so it panics out of the method instead, the executor is killed once the context of the call is done
+func (c *executorCompiler) callRemoteMethod(soFilePath string, methodName string, executionContextId sdk.Context, arguments []interface{}) ([]interface{}, error) {
+	contextId := primitives.ExecutionContextId(executionContextId)
+	result, err := c.executor.request(c.callContext(contextId), &ExecutorMessage{
+		Type:             EXECUTOR_MESSAGE_CALL,
+		SharedObjectPath: soFilePath,
+		MethodName:       methodName,
+		ContextId:        contextId,
+		Arguments:        arguments,
+	})
+	if limitExceeded, ok := err.(*ExecutorLimitExceeded); ok {
+		return nil, limitExceeded
+	}
+	if err != nil {
+		panic(&ExecutorFailure{Cause: err})
+	}
+	if result.ContractError != "" {
+		return nil, errors.New(result.ContractError)
+	}
+	return result.Arguments, nil
+}
+
+// on error all outputs are zero values and the error is returned like a contract error
+func remoteMethodResults(methodType reflect.Type, outputs []interface{}, err error) []reflect.Value {
+	numOutputs := methodType.NumOut() - 1
+	if err == nil && len(outputs) != numOutputs {
+		err = errors.Errorf("contract executor returned %d outputs instead of %d", len(outputs), numOutputs)
+	}
+	for i := 0; err == nil && i < numOutputs; i++ {
+		if reflect.TypeOf(outputs[i]) != methodType.Out(i) {
+			err = errors.Errorf("contract executor returned output %d of type %T instead of %s", i, outputs[i], methodType.Out(i))
+		}
+	}
+
+	res := make([]reflect.Value, 0, numOutputs+1)
+	for i := 0; i < numOutputs; i++ {
+		if err == nil {
+			res = append(res, reflect.ValueOf(outputs[i]))
+		} else {
+			res = append(res, reflect.Zero(methodType.Out(i)))
+		}
+	}
+
+	if err != nil {
+		return append(res, reflect.ValueOf(&err).Elem())
+	}
+	return append(res, reflect.Zero(errorType))
+}
@@ -0,0 +1,317 @@
+package adapter
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// the go runtime crashes the process with this when an allocation fails past the memory limit
+const GO_RUNTIME_OUT_OF_MEMORY = "runtime: out of memory"
+
+// enough to hold the crash report of the go runtime
+const MAX_EXECUTOR_STDERR_TAIL_SIZE = 4096
+
+// every request runs in an executor child process of its own which is never shared with another call, so the limits
+// of the process are the limits of the call and a crash or a runaway contract only fails its own call
+type executorProcess struct {
+	path               string
+	memoryLimitInBytes uint32
+	cpuLimitInSeconds  uint32
+	logger             log.BasicLogger
+	lastId             uint64
+
+	mutex                        sync.Mutex
+	contractSdkHandlerUnderMutex handlers.ContractSdkCallHandler
+}
+
+type executorChild struct {
+	cmd     *exec.Cmd
+	input   *os.File
+	exited  chan struct{}
+	encoder *gob.Encoder
+	results chan *ExecutorMessage
+	stderr  *stderrTail
+
+	sdkCalls uint32
+
+	writeMutex sync.Mutex
+
+	exitMutex            sync.Mutex
+	exitReasonUnderMutex error
+}
+
+func newExecutorProcess(path string, memoryLimitInBytes uint32, cpuLimitInSeconds uint32, logger log.BasicLogger) *executorProcess {
+	return &executorProcess{
+		path:               path,
+		memoryLimitInBytes: memoryLimitInBytes,
+		cpuLimitInSeconds:  cpuLimitInSeconds,
+		logger:             logger,
+	}
+}
+
+func (p *executorProcess) registerContractSdkCallHandler(handler handlers.ContractSdkCallHandler) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.contractSdkHandlerUnderMutex = handler
+}
+
+func (p *executorProcess) getContractSdkHandler() handlers.ContractSdkCallHandler {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.contractSdkHandlerUnderMutex
+}
+
+// starts a child for the request and waits for its result, the child is killed once the result arrives or ctx is done
+func (p *executorProcess) request(ctx context.Context, message *ExecutorMessage) (*ExecutorMessage, error) {
+	child, err := p.startChild()
+	if err != nil {
+		return nil, err
+	}
+	defer child.stop()
+
+	message.Id = atomic.AddUint64(&p.lastId, 1)
+	err = child.send(message)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to send request to contract executor")
+	}
+
+	select {
+	case result := <-child.results:
+		return resultOrError(result)
+	case <-child.exited:
+		select {
+		case result := <-child.results: // delivered by the reader before it saw the exit
+			return resultOrError(result)
+		default:
+			if cause := child.limitExceeded(p.cpuLimitInSeconds); cause != nil {
+				return nil, &ExecutorLimitExceeded{Cause: cause}
+			}
+			p.logger.Info("contract executor exited during the call", log.Error(child.exitReason()), log.String("stderr", child.stderr.String()))
+			return nil, errors.Wrap(child.exitReason(), "contract executor exited during the call")
+		}
+	case <-ctx.Done():
+		p.logger.Info("request to contract executor cancelled, killing executor", log.String("method", message.MethodName), log.Error(ctx.Err()))
+		return nil, ctx.Err()
+	}
+}
+
+func resultOrError(result *ExecutorMessage) (*ExecutorMessage, error) {
+	if result.Error != "" {
+		return result, errors.New(result.Error)
+	}
+	return result, nil
+}
+
+func (p *executorProcess) startChild() (*executorChild, error) {
+	inputReader, inputWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	outputReader, outputWriter, err := os.Pipe()
+	if err != nil {
+		inputReader.Close()
+		inputWriter.Close()
+		return nil, err
+	}
+
+	stderr := &stderrTail{}
+	cmd := exec.Command(p.path)
+	cmd.Stderr = stderr
+	cmd.ExtraFiles = []*os.File{inputReader, outputWriter} // become EXECUTOR_INPUT_FD and EXECUTOR_OUTPUT_FD
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%s=%d", EXECUTOR_MEMORY_LIMIT_ENV, p.memoryLimitInBytes),
+		fmt.Sprintf("%s=%d", EXECUTOR_CPU_LIMIT_ENV, p.cpuLimitInSeconds))
+	err = cmd.Start()
+	inputReader.Close()
+	outputWriter.Close()
+	if err != nil {
+		inputWriter.Close()
+		outputReader.Close()
+		return nil, errors.Wrapf(err, "failed to start contract executor '%s'", p.path)
+	}
+
+	child := &executorChild{
+		cmd:     cmd,
+		input:   inputWriter,
+		exited:  make(chan struct{}),
+		encoder: gob.NewEncoder(inputWriter),
+		results: make(chan *ExecutorMessage, 1),
+		stderr:  stderr,
+	}
+
+	supervised.GoOnce(p.logger, func() {
+		decoder := gob.NewDecoder(outputReader)
+		for {
+			message := &ExecutorMessage{}
+			err := decoder.Decode(message)
+			if err != nil {
+				child.handleExit(err)
+				outputReader.Close()
+				return
+			}
+			p.handleMessageFromChild(child, message)
+		}
+	})
+
+	return child, nil
+}
+
+func (p *executorProcess) handleMessageFromChild(child *executorChild, message *ExecutorMessage) {
+	switch message.Type {
+	case EXECUTOR_MESSAGE_RESULT:
+		select {
+		case child.results <- message:
+		default:
+			p.logger.Info("contract executor sent more than one result")
+		}
+	case EXECUTOR_MESSAGE_SDK_CALL:
+		// sdk calls may lead to nested contract calls, so they must not block reading
+		supervised.GoOnce(p.logger, func() {
+			result := p.handleSdkCallFromChild(child, message)
+			err := child.send(result)
+			if err != nil {
+				p.logger.Info("failed to send sdk call result to contract executor", log.Error(err))
+			}
+		})
+	default:
+		p.logger.Info("contract executor sent unexpected message", log.Int("type", int(message.Type)))
+	}
+}
+
+// like the sdk of contracts running in process, the calls are made without the context of the processor call
+func (p *executorProcess) handleSdkCallFromChild(child *executorChild, message *ExecutorMessage) *ExecutorMessage {
+	result := &ExecutorMessage{
+		Id:   message.Id,
+		Type: EXECUTOR_MESSAGE_RESULT,
+	}
+
+	if atomic.AddUint32(&child.sdkCalls, 1) > MAX_EXECUTOR_SDK_CALLS_PER_CALL {
+		result.Error = fmt.Sprintf("contract made more than %d sdk calls", MAX_EXECUTOR_SDK_CALLS_PER_CALL)
+		return result
+	}
+	handler := p.getContractSdkHandler()
+	if handler == nil {
+		result.Error = "ContractSdkCallHandler has not registered yet"
+		return result
+	}
+	if message.SdkCall == nil {
+		result.Error = "sdk call message is missing the call"
+		return result
+	}
+
+	inputArguments := make([]*protocol.MethodArgument, 0, len(message.SdkCall.Arguments))
+	for _, raw := range message.SdkCall.Arguments {
+		inputArguments = append(inputArguments, protocol.MethodArgumentReader(raw))
+	}
+
+	output, err := handler.HandleSdkCall(context.TODO(), &handlers.HandleSdkCallInput{
+		ContextId:       message.ContextId,
+		OperationName:   message.SdkCall.OperationName,
+		MethodName:      message.SdkCall.MethodName,
+		InputArguments:  inputArguments,
+		PermissionScope: message.SdkCall.PermissionScope,
+	})
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.SdkCall = &ExecutorSdkCall{}
+	if output != nil {
+		for _, arg := range output.OutputArguments {
+			result.SdkCall.Arguments = append(result.SdkCall.Arguments, arg.Raw())
+		}
+	}
+	return result
+}
+
+func (c *executorChild) send(message *ExecutorMessage) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+
+	return c.encoder.Encode(message)
+}
+
+// closing the input ends a healthy executor, the kill ends one that is still running a contract
+func (c *executorChild) stop() {
+	c.input.Close()
+	c.cmd.Process.Kill()
+}
+
+func (c *executorChild) handleExit(readErr error) {
+	waitErr := c.cmd.Wait()
+	if waitErr == nil {
+		waitErr = readErr
+	}
+
+	c.exitMutex.Lock()
+	c.exitReasonUnderMutex = waitErr
+	c.exitMutex.Unlock()
+
+	close(c.exited)
+}
+
+func (c *executorChild) exitReason() error {
+	c.exitMutex.Lock()
+	defer c.exitMutex.Unlock()
+
+	return c.exitReasonUnderMutex
+}
+
+// only valid once the child exited, a child killed at the hard cpu limit had no chance to exit with its code
+// but it used up its cpu time
+func (c *executorChild) limitExceeded(cpuLimitInSeconds uint32) error {
+	state := c.cmd.ProcessState
+	if state == nil {
+		return nil
+	}
+	if status, ok := state.Sys().(syscall.WaitStatus); ok && status.Exited() && status.ExitStatus() == EXECUTOR_EXIT_CODE_CPU_LIMIT_EXCEEDED {
+		return errors.New("cpu limit exceeded")
+	}
+	if cpuLimitInSeconds > 0 && state.UserTime()+state.SystemTime() >= time.Duration(cpuLimitInSeconds)*time.Second {
+		return errors.New("cpu limit exceeded")
+	}
+	if strings.Contains(c.stderr.String(), GO_RUNTIME_OUT_OF_MEMORY) {
+		return errors.New("memory limit exceeded")
+	}
+	return nil
+}
+
+// keeps the end of what the child wrote to stderr, where the go runtime reports why the process crashed
+type stderrTail struct {
+	mutex sync.Mutex
+	tail  []byte
+}
+
+func (t *stderrTail) Write(p []byte) (int, error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.tail = append(t.tail, p...)
+	if len(t.tail) > MAX_EXECUTOR_STDERR_TAIL_SIZE {
+		t.tail = t.tail[len(t.tail)-MAX_EXECUTOR_STDERR_TAIL_SIZE:]
+	}
+	return len(p), nil
+}
+
+func (t *stderrTail) String() string {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return string(t.tail)
+}
@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// the fake executor reads the start of the request and exits like the real one would
+func newFakeExecutorProcess(t *testing.T, dir string, script string) *executorProcess {
+	path := filepath.Join(dir, "executor.sh")
+	err := ioutil.WriteFile(path, []byte("#!/bin/sh\nhead -c 1 <&3 >/dev/null\n"+script+"\n"), 0755)
+	require.NoError(t, err, "fake executor should be written")
+	return newExecutorProcess(path, 0, 0, log.GetLogger())
+}
+
+func TestExecutorRequest_ExitOnCpuLimitIsLimitExceeded(t *testing.T) {
+	tmpDir := test.CreateTempDirForTest(t)
+	defer os.RemoveAll(tmpDir)
+	p := newFakeExecutorProcess(t, tmpDir, "exit 3")
+
+	_, err := p.request(context.Background(), &ExecutorMessage{Type: EXECUTOR_MESSAGE_CALL})
+	require.IsType(t, &ExecutorLimitExceeded{}, err, "executor exiting on the cpu limit should fail the call as a contract error")
+}
+
+func TestExecutorRequest_OutOfMemoryCrashIsLimitExceeded(t *testing.T) {
+	tmpDir := test.CreateTempDirForTest(t)
+	defer os.RemoveAll(tmpDir)
+	p := newFakeExecutorProcess(t, tmpDir, "echo 'fatal error: runtime: out of memory' >&2\nexit 2")
+
+	_, err := p.request(context.Background(), &ExecutorMessage{Type: EXECUTOR_MESSAGE_CALL})
+	require.IsType(t, &ExecutorLimitExceeded{}, err, "executor running out of memory should fail the call as a contract error")
+}
+
+func TestExecutorRequest_OtherExitIsNotLimitExceeded(t *testing.T) {
+	tmpDir := test.CreateTempDirForTest(t)
+	defer os.RemoveAll(tmpDir)
+	p := newFakeExecutorProcess(t, tmpDir, "exit 1")
+
+	_, err := p.request(context.Background(), &ExecutorMessage{Type: EXECUTOR_MESSAGE_CALL})
+	require.Error(t, err, "executor exiting without a result should fail the request")
+	_, limitExceeded := err.(*ExecutorLimitExceeded)
+	require.False(t, limitExceeded, "executor exiting for another reason is not a result of the call")
+}
+
+func TestExecutorRequest_MissingExecutorIsNotLimitExceeded(t *testing.T) {
+	p := newExecutorProcess("/nonexistent/executor", 0, 0, log.GetLogger())
+
+	_, err := p.request(context.Background(), &ExecutorMessage{Type: EXECUTOR_MESSAGE_CALL})
+	require.Error(t, err, "executor that can't start should fail the request")
+	_, limitExceeded := err.(*ExecutorLimitExceeded)
+	require.False(t, limitExceeded, "executor that can't start is not a result of the call")
+}
+
+func TestCallRemoteMethod_UsesTheContextSetForTheCall(t *testing.T) {
+	c := &executorCompiler{callContextsUnderMutex: make(map[primitives.ExecutionContextId]context.Context)}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	restore := c.SetCallContext(ctx, 7)
+	require.Equal(t, ctx, c.callContext(7), "call should run with the context set for it")
+	nestedRestore := c.SetCallContext(context.TODO(), 7)
+	nestedRestore()
+	require.Equal(t, ctx, c.callContext(7), "nested call should restore the context of its caller")
+	restore()
+	require.Equal(t, context.Background(), c.callContext(7), "call without a context set should run with the background context")
+}
@@ -0,0 +1,96 @@
+package adapter
+
+import (
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+)
+
+// the out of process executor speaks gob encoded messages over two pipes passed as extra files,
+// so contract code writing to stdout or stderr can never interfere with the protocol
+const EXECUTOR_INPUT_FD = 3
+const EXECUTOR_OUTPUT_FD = 4
+
+// the executor process limits its own data segment to this many bytes (when set), it runs a single call so this limits the call
+const EXECUTOR_MEMORY_LIMIT_ENV = "ORBS_CONTRACT_EXECUTOR_MEMORY_LIMIT"
+
+// the executor process limits its own cpu time to this many seconds (when set), it runs a single call so this limits the call
+const EXECUTOR_CPU_LIMIT_ENV = "ORBS_CONTRACT_EXECUTOR_CPU_LIMIT"
+
+// the executor exits with this when the contract it runs exceeds the cpu limit
+const EXECUTOR_EXIT_CODE_CPU_LIMIT_EXCEEDED = 3
+
+// part of the protocol since it decides the result of a call, every node must fail the same call on the same sdk call
+const MAX_EXECUTOR_SDK_CALLS_PER_CALL = 10000
+
+type ExecutorMessageType int
+
+const (
+	EXECUTOR_MESSAGE_DESCRIBE ExecutorMessageType = iota + 1 // node to executor: load a compiled contract and describe it
+	EXECUTOR_MESSAGE_CALL                                    // node to executor: call a method of a loaded contract
+	EXECUTOR_MESSAGE_SDK_CALL                                // executor to node: a contract is calling the sdk
+	EXECUTOR_MESSAGE_RESULT                                  // reply to any of the above, matched by id
+)
+
+// the argument and output values are uint32, uint64, string or []byte like the native processor supports
+type ExecutorMessage struct {
+	Id                  uint64
+	Type                ExecutorMessageType
+	SharedObjectPath    string
+	MethodName          string
+	ContextId           primitives.ExecutionContextId
+	Arguments           []interface{}
+	SdkCall             *ExecutorSdkCall
+	ContractDescription *ExecutorContractDescription
+	ContractError       string // returned or panicked by the contract, this is the result of the call
+	Error               string // the executor failed to run the call, there is no result
+}
+
+// the executor failed to run a call (could not start, lost its pipes or crashed), which says nothing about the contract,
+// remote methods panic with it so the processor fails the call as a fault of the node instead of turning it into a result
+type ExecutorFailure struct {
+	Cause error
+}
+
+func (f *ExecutorFailure) Error() string {
+	return "contract executor failed: " + f.Cause.Error()
+}
+
+// the contract ran past the cpu or memory limit of the executor running its call, which every node limits the same way,
+// so unlike an ExecutorFailure remote methods return it as the contract error of the call
+type ExecutorLimitExceeded struct {
+	Cause error
+}
+
+func (e *ExecutorLimitExceeded) Error() string {
+	return "contract exceeded the limits of its executor: " + e.Cause.Error()
+}
+
+type ExecutorSdkCall struct {
+	OperationName   string
+	MethodName      primitives.MethodName
+	Arguments       [][]byte // raw protocol.MethodArgument
+	PermissionScope protocol.ExecutionPermissionScope
+}
+
+type ExecutorContractDescription struct {
+	Name       string
+	Permission sdk.PermissionScope
+	Methods    []*ExecutorMethodDescription
+}
+
+// types exclude the receiver, the context and the trailing error
+type ExecutorMethodDescription struct {
+	Name        string
+	External    bool
+	Access      sdk.AccessScope
+	InputTypes  []string
+	OutputTypes []string
+}
+
+const (
+	EXECUTOR_TYPE_UINT32 = "uint32"
+	EXECUTOR_TYPE_UINT64 = "uint64"
+	EXECUTOR_TYPE_STRING = "string"
+	EXECUTOR_TYPE_BYTES  = "bytes"
+)
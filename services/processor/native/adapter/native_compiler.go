@@ -2,18 +2,24 @@ package adapter
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
 	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
 	"github.com/orbs-network/orbs-network-go/crypto/hash"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
 	"github.com/orbs-network/orbs-network-go/test/contracts"
 	"github.com/pkg/errors"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"plugin"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -30,17 +36,20 @@ type Config interface {
 }
 
 type nativeCompiler struct {
-	config Config
-	logger log.BasicLogger
+	config       Config
+	logger       log.BasicLogger
+	compilations *compilationGroup
 }
 
 func NewNativeCompiler(config Config, logger log.BasicLogger) Compiler {
 	c := &nativeCompiler{
-		config: config,
-		logger: logger.WithTags(LogTag),
+		config:       config,
+		logger:       logger.WithTags(LogTag),
+		compilations: newCompilationGroup(),
 	}
 
 	c.warmUpCompilationCache() // so next compilations take 200 ms instead of 2 sec
+	startRebuildingKeptSources(config.ProcessorArtifactPath(), c.logger)
 
 	return c
 }
@@ -56,21 +65,72 @@ func (c *nativeCompiler) warmUpCompilationCache() {
 }
 
 func (c *nativeCompiler) Compile(ctx context.Context, code string) (*sdk.ContractInfo, error) {
-	artifactsPath := c.config.ProcessorArtifactPath()
+	return c.compilations.compile(ctx, code, func() (*sdk.ContractInfo, error) {
+		soFilePath, err := compileSharedObject(ctx, code, c.config.ProcessorArtifactPath())
+		if err != nil {
+			return nil, err
+		}
+
+		return loadSharedObject(soFilePath)
+	})
+}
+
+func compileSharedObject(ctx context.Context, code string, artifactsPath string) (string, error) {
 	hashOfCode := getHashOfCode(code)
 
+	soFilePath := sharedObjectFilePath(hashOfCode, artifactsPath)
+	if _, err := os.Stat(soFilePath); err == nil {
+		return soFilePath, nil
+	}
+
 	sourceCodeFilePath, err := writeSourceCodeToDisk(hashOfCode, code, artifactsPath)
-	defer os.Remove(sourceCodeFilePath)
 	if err != nil {
-		return nil, err
+		os.Remove(sourceCodeFilePath)
+		return "", err
 	}
 
-	soFilePath, err := buildSharedObject(ctx, hashOfCode, sourceCodeFilePath, artifactsPath)
+	// the source is kept so the artifact can be rebuilt ahead of time when the node is upgraded, see startRebuildingKeptSources
+	soFilePath, err = buildSharedObject(ctx, hashOfCode, sourceCodeFilePath, artifactsPath)
 	if err != nil {
-		return nil, err
+		os.Remove(sourceCodeFilePath)
+	}
+	return soFilePath, err
+}
+
+// a new build of the node can't load the artifacts of the previous one, so every contract this node compiled before
+// (including contracts deployed before _Deployments listed them, which the virtual machine can't preload) is rebuilt
+// in the background for the new build
+func startRebuildingKeptSources(artifactsPath string, logger log.BasicLogger) {
+	supervised.GoOnce(logger, func() {
+		rebuildKeptSources(artifactsPath, logger)
+	})
+}
+
+func rebuildKeptSources(artifactsPath string, logger log.BasicLogger) {
+	sourceFilePaths, err := filepath.Glob(filepath.Join(artifactsPath, SOURCE_CODE_PATH, "*.go"))
+	if err != nil {
+		logger.Info("failed to list kept contract sources", log.Error(err))
+		return
+	}
+
+	rebuilt := 0
+	for _, sourceFilePath := range sourceFilePaths {
+		hashOfCode := strings.TrimSuffix(filepath.Base(sourceFilePath), ".go")
+		if _, err := os.Stat(sharedObjectFilePath(hashOfCode, artifactsPath)); err == nil {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), MAX_WARM_UP_COMPILATION_TIME)
+		_, err := buildSharedObject(ctx, hashOfCode, sourceFilePath, artifactsPath)
+		cancel()
+		if err != nil {
+			logger.Info("failed to rebuild kept contract source", log.Error(err), log.String("source", sourceFilePath))
+			continue
+		}
+		rebuilt++
 	}
 
-	return loadSharedObject(soFilePath)
+	logger.Info("rebuilt kept contract sources", log.Int("rebuilt", rebuilt), log.Int("kept", len(sourceFilePaths)), log.String("build", nodeBuildId()))
 }
 
 func getHashOfCode(code string) string {
@@ -93,13 +153,52 @@ func writeSourceCodeToDisk(filenamePrefix string, code string, artifactsPath str
 	return sourceFilePath, nil
 }
 
+// artifacts are content addressed by the hash of their code under a directory per build of the node (plugins only load
+// into a binary built by the same toolchain from the same sdk and packages), so an existing artifact never needs to be rebuilt
+func sharedObjectFilePath(filenamePrefix string, artifactsPath string) string {
+	return filepath.Join(artifactsPath, SHARED_OBJECT_PATH, nodeBuildId(), filenamePrefix) + ".so"
+}
+
+var nodeBuildIdOnce sync.Once
+var nodeBuildIdValue string
+
+// the toolchain version and the hash of the running executable, which changes whenever the node is rebuilt
+func nodeBuildId() string {
+	nodeBuildIdOnce.Do(func() {
+		nodeBuildIdValue = runtime.Version()
+		executableHash, err := hashOfExecutable()
+		if err == nil {
+			nodeBuildIdValue += "-" + executableHash
+		}
+	})
+	return nodeBuildIdValue
+}
+
+func hashOfExecutable() (string, error) {
+	executablePath, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	executable, err := os.Open(executablePath)
+	if err != nil {
+		return "", err
+	}
+	defer executable.Close()
+
+	hasher := sha256.New()
+	_, err = io.Copy(hasher, executable)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16], nil
+}
+
 func buildSharedObject(ctx context.Context, filenamePrefix string, sourceFilePath string, artifactsPath string) (string, error) {
-	dir := filepath.Join(artifactsPath, SHARED_OBJECT_PATH)
-	err := os.MkdirAll(dir, 0700)
+	soFilePath := sharedObjectFilePath(filenamePrefix, artifactsPath)
+	err := os.MkdirAll(filepath.Dir(soFilePath), 0700)
 	if err != nil {
 		return "", err
 	}
-	soFilePath := filepath.Join(dir, filenamePrefix) + ".so"
 
 	// if the file is currently loaded as plugin, we won't be able to delete and it's ok
 	if _, err = os.Stat(soFilePath); err == nil {
@@ -109,8 +208,10 @@ func buildSharedObject(ctx context.Context, filenamePrefix string, sourceFilePat
 		}
 	}
 
-	// compile
-	cmd := exec.CommandContext(ctx, "go", "build", "-buildmode=plugin", "-o", soFilePath, sourceFilePath)
+	// compile next to the artifact and rename when done, so an interrupted build never leaves a partial artifact behind
+	tmpFilePath := fmt.Sprintf("%s.%d.tmp", soFilePath, time.Now().UnixNano()) // unique since kept sources are rebuilt in the background
+	defer os.Remove(tmpFilePath)
+	cmd := exec.CommandContext(ctx, "go", "build", "-buildmode=plugin", "-o", tmpFilePath, sourceFilePath)
 	cmd.Env = []string{
 		"GOPATH=" + getGOPATH(),
 		"PATH=" + os.Getenv("PATH"),
@@ -124,6 +225,10 @@ func buildSharedObject(ctx context.Context, filenamePrefix string, sourceFilePat
 		buildOutput = strings.Replace(buildOutput, "\n", "; ", -1)
 		return "", errors.Errorf("error building go source: %s, go build output: %s", err.Error(), buildOutput)
 	}
+	err = os.Rename(tmpFilePath, soFilePath)
+	if err != nil {
+		return "", err
+	}
 
 	return soFilePath, nil
 }
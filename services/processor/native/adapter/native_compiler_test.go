@@ -3,6 +3,7 @@ package adapter
 import (
 	"context"
 	"fmt"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/contracts"
 	"github.com/stretchr/testify/require"
@@ -88,6 +89,66 @@ func TestCompileCodeWithExistingArtifacts(t *testing.T) {
 	require.Equal(t, fmt.Sprintf("CounterFrom%d", COUNTER_CONTRACT_START_FROM), contractInfo.Name, "loaded object should be valid")
 }
 
+func TestCompileSharedObjectReusesCachedArtifact(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping compilation of contracts in short mode")
+	}
+
+	// give the test one minute timeout to compile
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	code := string(contracts.NativeSourceCodeForCounter(COUNTER_CONTRACT_START_FROM))
+	tmpDir := test.CreateTempDirForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	soFilePath, err := compileSharedObject(ctx, code, tmpDir)
+	require.NoError(t, err, "compilation should succeed")
+	firstBuild, err := os.Stat(soFilePath)
+	require.NoError(t, err, "artifact should exist")
+
+	t.Log("Compiling the same code again should reuse the artifact")
+
+	cachedFilePath, err := compileSharedObject(ctx, code, tmpDir)
+	require.NoError(t, err, "compilation should succeed")
+	require.Equal(t, soFilePath, cachedFilePath, "artifact path should be derived from the code")
+	secondBuild, err := os.Stat(cachedFilePath)
+	require.NoError(t, err, "artifact should exist")
+	require.Equal(t, firstBuild.ModTime(), secondBuild.ModTime(), "artifact should not be rebuilt")
+
+	t.Log("Different code should get a different artifact")
+
+	otherFilePath, err := compileSharedObject(ctx, string(contracts.NativeSourceCodeForCounter(COUNTER_CONTRACT_START_FROM+1)), tmpDir)
+	require.NoError(t, err, "compilation should succeed")
+	require.NotEqual(t, soFilePath, otherFilePath, "artifact path should be derived from the code")
+}
+
+func TestRebuildKeptSourcesBuildsMissingArtifacts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping compilation of contracts in short mode")
+	}
+
+	// give the test one minute timeout to compile
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	code := string(contracts.NativeSourceCodeForCounter(COUNTER_CONTRACT_START_FROM))
+	tmpDir := test.CreateTempDirForTest(t)
+	defer os.RemoveAll(tmpDir)
+
+	soFilePath, err := compileSharedObject(ctx, code, tmpDir)
+	require.NoError(t, err, "compilation should succeed")
+	require.Contains(t, soFilePath, nodeBuildId(), "artifact should be kept per build of the node")
+
+	t.Log("Artifact of a previous build is missing for the current build")
+
+	err = os.Remove(soFilePath)
+	require.NoError(t, err, "artifact should be removed")
+
+	rebuildKeptSources(tmpDir, log.GetLogger())
+	require.FileExists(t, soFilePath, "artifact should be rebuilt from the kept source")
+}
+
 func getFileSize(filePath string) int64 {
 	fi, err := os.Stat(filePath)
 	if err != nil {
@@ -2,12 +2,17 @@ package native
 
 import (
 	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
 	"reflect"
 )
 
+// optional method a contract can define to reject transactions before they are ordered,
+// contracts that don't define it approve every transaction
+const METHOD_NAME_PRE_ORDER = "_preOrder"
+
 func (s *service) verifyMethodPermissions(contractInfo *sdk.ContractInfo, methodInfo *sdk.MethodInfo, callingService primitives.ContractName, permissionScope protocol.ExecutionPermissionScope, accessScope protocol.ExecutionAccessScope) error {
 	// allow external but protect internal
 	if !methodInfo.External {
@@ -37,10 +42,21 @@ func (s *service) verifyInternalMethodCall(contractInfo *sdk.ContractInfo, metho
 	return errors.Errorf("internal method '%s' called from different service '%s' without system permissions", methodInfo.Name, callingService)
 }
 
+// the node could not run the call to a result (the executor process running it was lost), which says nothing about
+// the contract, so the virtual machine fails whatever ran the call instead of giving the call a result
+func IsNodeFault(err error) bool {
+	_, ok := err.(*adapter.ExecutorFailure)
+	return ok
+}
+
 func (s *service) processMethodCall(executionContextId sdk.Context, contractInfo *sdk.ContractInfo, methodInfo *sdk.MethodInfo, args *protocol.MethodArgumentArray) (contractOutputArgs *protocol.MethodArgumentArray, contractOutputErr error, err error) {
 
 	defer func() {
 		if r := recover(); r != nil {
+			if failure, ok := r.(*adapter.ExecutorFailure); ok { // the contract did not run to a result, so there is none
+				contractOutputArgs, contractOutputErr, err = nil, nil, failure
+				return
+			}
 			contractOutputErr = errors.Errorf("contract panic: %s", r)
 			contractOutputArgs = s.createMethodOutputArgsWithString(contractOutputErr.Error())
 		}
@@ -53,7 +69,7 @@ func (s *service) processMethodCall(executionContextId sdk.Context, contractInfo
 	}
 
 	// execute the call
-	contractInstance := s.getContractInstanceFromRepository(contractInfo.Name)
+	contractInstance := s.getContractInstanceFromRepository(contractInfo)
 	if contractInstance == nil {
 		return nil, nil, errors.New("contract repository is not initialized yet")
 	}
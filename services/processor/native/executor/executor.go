@@ -0,0 +1,275 @@
+// Package executor runs deployed native contracts in a child process of the node (see adapter.NewExecutorCompiler).
+// The node starts an executor process for every call and stops it once the call returns, so a panic, endless loop or
+// memory blow up in a contract takes down the executor of that call but never the node or another call.
+package executor
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"io"
+	"os"
+	"plugin"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type executor struct {
+	encoder    *gob.Encoder
+	writeMutex sync.Mutex
+	lastId     uint64
+
+	pendingMutex      sync.Mutex
+	pendingUnderMutex map[uint64]chan *adapter.ExecutorMessage
+
+	contractsMutex      sync.Mutex
+	contractsUnderMutex map[string]*loadedContract
+}
+
+type loadedContract struct {
+	info     *sdk.ContractInfo
+	instance sdk.ContractInstance
+}
+
+// serves requests from the node until it closes the input
+func Run(input io.Reader, output io.Writer) error {
+	e := &executor{
+		encoder:             gob.NewEncoder(output),
+		pendingUnderMutex:   make(map[uint64]chan *adapter.ExecutorMessage),
+		contractsUnderMutex: make(map[string]*loadedContract),
+	}
+
+	decoder := gob.NewDecoder(input)
+	for {
+		message := &adapter.ExecutorMessage{}
+		err := decoder.Decode(message)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch message.Type {
+		case adapter.EXECUTOR_MESSAGE_RESULT:
+			e.deliverResult(message)
+		case adapter.EXECUTOR_MESSAGE_DESCRIBE:
+			go e.reply(message, e.describe(message))
+		case adapter.EXECUTOR_MESSAGE_CALL:
+			// calls run concurrently since a contract may call another contract (through the node) while executing
+			go e.reply(message, e.call(message))
+		default:
+			go e.reply(message, errorResult(errors.Errorf("unknown message type %d", message.Type)))
+		}
+	}
+}
+
+func (e *executor) describe(message *adapter.ExecutorMessage) *adapter.ExecutorMessage {
+	contract, err := e.load(message.SharedObjectPath)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	description := &adapter.ExecutorContractDescription{
+		Name:       contract.info.Name,
+		Permission: contract.info.Permission,
+	}
+	for _, methodInfo := range contract.info.Methods {
+		methodType := reflect.TypeOf(methodInfo.Implementation)
+		if methodType == nil || methodType.Kind() != reflect.Func || methodType.NumIn() < 2 || methodType.NumOut() < 1 {
+			return errorResult(errors.Errorf("method '%s' implementation is not a valid contract method", methodInfo.Name))
+		}
+		method := &adapter.ExecutorMethodDescription{
+			Name:     methodInfo.Name,
+			External: methodInfo.External,
+			Access:   methodInfo.Access,
+		}
+		for i := 2; i < methodType.NumIn(); i++ {
+			method.InputTypes = append(method.InputTypes, executorTypeName(methodType.In(i)))
+		}
+		for i := 0; i < methodType.NumOut()-1; i++ {
+			method.OutputTypes = append(method.OutputTypes, executorTypeName(methodType.Out(i)))
+		}
+		description.Methods = append(description.Methods, method)
+	}
+	sort.Slice(description.Methods, func(i, j int) bool {
+		return description.Methods[i].Name < description.Methods[j].Name
+	})
+
+	return &adapter.ExecutorMessage{ContractDescription: description}
+}
+
+func (e *executor) call(message *adapter.ExecutorMessage) (result *adapter.ExecutorMessage) {
+	contract, err := e.load(message.SharedObjectPath)
+	if err != nil {
+		return errorResult(err)
+	}
+	methodInfo, found := contract.info.Methods[message.MethodName]
+	if !found {
+		return errorResult(errors.Errorf("method '%s' not found in contract '%s'", message.MethodName, contract.info.Name))
+	}
+
+	methodValue := reflect.ValueOf(methodInfo.Implementation)
+	methodType := methodValue.Type()
+	if methodType.NumIn() != len(message.Arguments)+2 {
+		return errorResult(errors.Errorf("method '%s' takes %d args but received %d", methodInfo.Name, methodType.NumIn()-2, len(message.Arguments)))
+	}
+	inValues := []reflect.Value{reflect.ValueOf(contract.instance), reflect.ValueOf(sdk.Context(message.ContextId))}
+	for i, arg := range message.Arguments {
+		if reflect.TypeOf(arg) != methodType.In(i+2) {
+			return errorResult(errors.Errorf("method '%s' expects arg %d to be %s but it has %T", methodInfo.Name, i, methodType.In(i+2), arg))
+		}
+		inValues = append(inValues, reflect.ValueOf(arg))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = &adapter.ExecutorMessage{ContractError: fmt.Sprintf("contract panic: %s", r)}
+		}
+	}()
+
+	outValues := methodValue.Call(inValues)
+	result = &adapter.ExecutorMessage{}
+	for _, outValue := range outValues[:len(outValues)-1] {
+		result.Arguments = append(result.Arguments, outValue.Interface())
+	}
+	if contractErr, ok := outValues[len(outValues)-1].Interface().(error); ok && contractErr != nil {
+		return &adapter.ExecutorMessage{ContractError: contractErr.Error()}
+	}
+	return result
+}
+
+func (e *executor) load(sharedObjectPath string) (*loadedContract, error) {
+	e.contractsMutex.Lock()
+	defer e.contractsMutex.Unlock()
+
+	if contract, found := e.contractsUnderMutex[sharedObjectPath]; found {
+		return contract, nil
+	}
+
+	loadedPlugin, err := plugin.Open(sharedObjectPath)
+	if err != nil {
+		return nil, err
+	}
+	contractSymbol, err := loadedPlugin.Lookup("CONTRACT")
+	if err != nil {
+		return nil, err
+	}
+	contractInfo, ok := contractSymbol.(*sdk.ContractInfo)
+	if !ok {
+		return nil, errors.New("CONTRACT symbol is not a ContractInfo")
+	}
+
+	contract := &loadedContract{
+		info:     contractInfo,
+		instance: native.NewContractInstance(contractInfo, e),
+	}
+	e.contractsUnderMutex[sharedObjectPath] = contract
+	return contract, nil
+}
+
+// contracts call the sdk through the node which owns the execution context
+func (e *executor) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCallInput) (*handlers.HandleSdkCallOutput, error) {
+	sdkCall := &adapter.ExecutorSdkCall{
+		OperationName:   input.OperationName,
+		MethodName:      input.MethodName,
+		PermissionScope: input.PermissionScope,
+	}
+	for _, arg := range input.InputArguments {
+		sdkCall.Arguments = append(sdkCall.Arguments, arg.Raw())
+	}
+
+	id := atomic.AddUint64(&e.lastId, 1)
+	resultChan := e.addPending(id)
+	defer e.removePending(id)
+
+	err := e.send(&adapter.ExecutorMessage{
+		Id:        id,
+		Type:      adapter.EXECUTOR_MESSAGE_SDK_CALL,
+		ContextId: input.ContextId,
+		SdkCall:   sdkCall,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := <-resultChan
+	if result.Error != "" {
+		return nil, errors.New(result.Error)
+	}
+	output := &handlers.HandleSdkCallOutput{}
+	if result.SdkCall != nil {
+		for _, raw := range result.SdkCall.Arguments {
+			output.OutputArguments = append(output.OutputArguments, protocol.MethodArgumentReader(raw))
+		}
+	}
+	return output, nil
+}
+
+func (e *executor) reply(request *adapter.ExecutorMessage, result *adapter.ExecutorMessage) {
+	result.Id = request.Id
+	result.Type = adapter.EXECUTOR_MESSAGE_RESULT
+	err := e.send(result)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contract executor failed to send result: %s\n", err)
+	}
+}
+
+func (e *executor) send(message *adapter.ExecutorMessage) error {
+	e.writeMutex.Lock()
+	defer e.writeMutex.Unlock()
+
+	return e.encoder.Encode(message)
+}
+
+func (e *executor) addPending(id uint64) chan *adapter.ExecutorMessage {
+	e.pendingMutex.Lock()
+	defer e.pendingMutex.Unlock()
+
+	resultChan := make(chan *adapter.ExecutorMessage, 1)
+	e.pendingUnderMutex[id] = resultChan
+	return resultChan
+}
+
+func (e *executor) removePending(id uint64) {
+	e.pendingMutex.Lock()
+	defer e.pendingMutex.Unlock()
+
+	delete(e.pendingUnderMutex, id)
+}
+
+func (e *executor) deliverResult(message *adapter.ExecutorMessage) {
+	e.pendingMutex.Lock()
+	defer e.pendingMutex.Unlock()
+
+	if resultChan, found := e.pendingUnderMutex[message.Id]; found {
+		resultChan <- message
+	}
+}
+
+func errorResult(err error) *adapter.ExecutorMessage {
+	return &adapter.ExecutorMessage{Error: err.Error()}
+}
+
+func executorTypeName(t reflect.Type) string {
+	switch {
+	case t.Kind() == reflect.Uint32:
+		return adapter.EXECUTOR_TYPE_UINT32
+	case t.Kind() == reflect.Uint64:
+		return adapter.EXECUTOR_TYPE_UINT64
+	case t.Kind() == reflect.String:
+		return adapter.EXECUTOR_TYPE_STRING
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8:
+		return adapter.EXECUTOR_TYPE_BYTES
+	default:
+		return t.String()
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/executor"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+func main() {
+	err := limitMemory()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contract executor failed to limit its memory: %s\n", err)
+		os.Exit(1)
+	}
+	err = limitCpu()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contract executor failed to limit its cpu time: %s\n", err)
+		os.Exit(1)
+	}
+
+	input := os.NewFile(adapter.EXECUTOR_INPUT_FD, "executor-input")
+	output := os.NewFile(adapter.EXECUTOR_OUTPUT_FD, "executor-output")
+
+	err = executor.Run(input, output)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "contract executor failed: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+// a contract allocating past the limit crashes this process (the go runtime reports running out of memory), which fails its call like a contract error
+func limitMemory() error {
+	limit, err := readLimit(adapter.EXECUTOR_MEMORY_LIMIT_ENV)
+	if err != nil || limit == 0 {
+		return err
+	}
+	return syscall.Setrlimit(syscall.RLIMIT_DATA, &syscall.Rlimit{Cur: limit, Max: limit})
+}
+
+// the process runs a single call, so a contract running past the limit gets SIGXCPU and fails its call like a contract error
+func limitCpu() error {
+	limit, err := readLimit(adapter.EXECUTOR_CPU_LIMIT_ENV)
+	if err != nil || limit == 0 {
+		return err
+	}
+	exitOnCpuLimit()
+	return syscall.Setrlimit(syscall.RLIMIT_CPU, &syscall.Rlimit{Cur: limit, Max: limit + 1}) // SIGKILL at the hard limit
+}
+
+// the go runtime ignores SIGXCPU, so a contract running past the cpu limit would otherwise keep running
+func exitOnCpuLimit() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGXCPU)
+	go func() {
+		<-signals
+		fmt.Fprintf(os.Stderr, "contract executor exceeded its cpu limit\n")
+		os.Exit(adapter.EXECUTOR_EXIT_CODE_CPU_LIMIT_EXCEEDED)
+	}()
+}
+
+// a missing limit is zero, which means no limit
+func readLimit(env string) (uint64, error) {
+	value := os.Getenv(env)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
@@ -9,25 +9,36 @@ import (
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
 	"github.com/pkg/errors"
 	"time"
 )
 
-func initializePreBuiltRepositoryContractInstances(sdkHandler handlers.ContractSdkCallHandler) map[string]sdk.ContractInstance {
-	preBuiltRepository := make(map[string]sdk.ContractInstance)
+func initializePreBuiltRepositoryContractInstances(sdkHandler handlers.ContractSdkCallHandler) map[*sdk.ContractInfo]sdk.ContractInstance {
+	preBuiltRepository := make(map[*sdk.ContractInfo]sdk.ContractInstance)
 	for _, contractInfo := range repository.PreBuiltContracts {
-		preBuiltRepository[contractInfo.Name] = initializeContractInstance(contractInfo, sdkHandler)
+		preBuiltRepository[contractInfo] = initializeContractInstance(contractInfo, sdkHandler)
 	}
 	return preBuiltRepository
 }
 
 func initializeContractInstance(contractInfo *sdk.ContractInfo, sdkHandler handlers.ContractSdkCallHandler) sdk.ContractInstance {
-	return contractInfo.InitSingleton(sdk.NewBaseContract(
-		&stateSdk{sdkHandler, protocol.ExecutionPermissionScope(contractInfo.Permission)},
-		&serviceSdk{sdkHandler, protocol.ExecutionPermissionScope(contractInfo.Permission)},
-		&addressSdk{sdkHandler, protocol.ExecutionPermissionScope(contractInfo.Permission)},
-	))
+	return contractInfo.InitSingleton(NewSdk(sdkHandler, protocol.ExecutionPermissionScope(contractInfo.Permission)))
+}
+
+// used by processors of other languages so their contracts reach the node exactly like native contracts
+func NewSdk(sdkHandler handlers.ContractSdkCallHandler, permissionScope protocol.ExecutionPermissionScope) *sdk.BaseContract {
+	return sdk.NewBaseContract(
+		&stateSdk{sdkHandler, permissionScope},
+		&serviceSdk{sdkHandler, permissionScope},
+		&addressSdk{sdkHandler, permissionScope},
+	)
+}
+
+// used by the out of process executor to run loaded contracts with an sdk that calls back to the node
+func NewContractInstance(contractInfo *sdk.ContractInfo, sdkHandler handlers.ContractSdkCallHandler) sdk.ContractInstance {
+	return initializeContractInstance(contractInfo, sdkHandler)
 }
 
 func (s *service) retrieveContractAndMethodInfoFromRepository(ctx context.Context, executionContextId sdk.Context, contractName string, methodName string) (*sdk.ContractInfo, *sdk.MethodInfo, error) {
@@ -37,7 +48,7 @@ func (s *service) retrieveContractAndMethodInfoFromRepository(ctx context.Contex
 	}
 	method, found := contract.Methods[methodName]
 	if !found {
-		return nil, nil, errors.Errorf("method '%s' not found in contract '%s'", methodName, contractName)
+		return contract, nil, errors.Errorf("method '%s' not found in contract '%s'", methodName, contractName)
 	}
 	return contract, &method, nil
 }
@@ -49,20 +60,47 @@ func (s *service) retrieveContractInfoFromRepository(ctx context.Context, execut
 		return contractInfo, nil
 	}
 
-	// 2. try deployable artifact cache (if already compiled)
-	contractInfo = s.getDeployableContractInfoFromRepository(contractName)
+	// 2. try deployable artifact cache (if this version of the code was already compiled)
+	codeHash, err := CallGetCodeHashOfDeploymentSystemContract(ctx, s.getContractSdkHandler(), primitives.ExecutionContextId(executionContextId), primitives.ContractName(contractName))
+	if err != nil {
+		return nil, err
+	}
+	contractInfo = s.getDeployableContractInfoFromRepository(codeHash)
 	if contractInfo != nil {
 		return contractInfo, nil
 	}
 
 	// 3. try deployable code from state (if not yet compiled)
-	return s.retrieveDeployableContractInfoFromState(ctx, executionContextId, contractName)
+	return s.retrieveDeployableContractInfoFromState(ctx, executionContextId, contractName, codeHash)
+}
+
+// the virtual machine decides whether to keep an upgrade with this instead of loading the code, compilation depends on
+// the local toolchain (it may fail on one node and not on another) while the audit depends only on the code
+type DeployedCodeValidator interface {
+	ValidateDeployedCode(ctx context.Context, input *services.GetContractInfoInput) error
+}
+
+func (s *service) ValidateDeployedCode(ctx context.Context, input *services.GetContractInfoInput) error {
+	if _, found := repository.PreBuiltContracts[string(input.ContractName)]; found {
+		return nil
+	}
+
+	codeBytes, err := CallGetCodeOfDeploymentSystemContract(ctx, s.getContractSdkHandler(), input.ContextId, input.ContractName)
+	if err != nil {
+		return err
+	}
+
+	_, err = sanitizeDeployedSourceCode(string(codeBytes))
+	if err != nil {
+		return errors.Wrapf(err, "source code for contract '%s' failed security sandbox audit", input.ContractName)
+	}
+	return nil
 }
 
-func (s *service) retrieveDeployableContractInfoFromState(ctx context.Context, executionContextId sdk.Context, contractName string) (*sdk.ContractInfo, error) {
+func (s *service) retrieveDeployableContractInfoFromState(ctx context.Context, executionContextId sdk.Context, contractName string, codeHash primitives.Sha256) (*sdk.ContractInfo, error) {
 	start := time.Now()
 
-	codeBytes, err := s.callGetCodeOfDeploymentSystemContract(ctx, executionContextId, contractName)
+	codeBytes, err := CallGetCodeOfDeploymentSystemContract(ctx, s.getContractSdkHandler(), primitives.ExecutionContextId(executionContextId), primitives.ContractName(contractName))
 	if err != nil {
 		return nil, err
 	}
@@ -83,6 +121,9 @@ func (s *service) retrieveDeployableContractInfoFromState(ctx context.Context, e
 	if newContractInfo == nil {
 		return nil, errors.Errorf("compilation and load of deployable contract '%s' did not return a valid symbol", contractName)
 	}
+	if newContractInfo.Name != contractName { // instances are kept in the repository by the name the code declares
+		return nil, errors.Errorf("deployable contract '%s' declares the contract name '%s'", contractName, newContractInfo.Name)
+	}
 
 	sdkHandler := s.getContractSdkHandler()
 	if sdkHandler == nil {
@@ -90,9 +131,9 @@ func (s *service) retrieveDeployableContractInfoFromState(ctx context.Context, e
 	}
 	contractInstance := initializeContractInstance(newContractInfo, sdkHandler)
 
-	s.addContractInstanceToRepository(contractName, contractInstance)
-	s.addDeployableContractInfoToRepository(contractName, newContractInfo) // must add after instance to avoid race (when somebody RunsMethod at same time)
-	s.logger.Info("compiled and loaded deployable contract successfully", log.String("contract", contractName))
+	s.addContractInstanceToRepository(newContractInfo, contractInstance)
+	s.addDeployableContractInfoToRepository(codeHash, newContractInfo) // must add after instance to avoid race (when somebody RunsMethod at same time)
+	s.logger.Info("compiled and loaded deployable contract successfully", log.String("contract", contractName), log.Stringable("code-hash", codeHash))
 
 	s.metrics.deployedContracts.Inc()
 	s.metrics.contractCompilationTime.RecordSince(start)
@@ -101,17 +142,40 @@ func (s *service) retrieveDeployableContractInfoFromState(ctx context.Context, e
 	return newContractInfo, nil
 }
 
-func (s *service) callGetCodeOfDeploymentSystemContract(ctx context.Context, executionContextId sdk.Context, contractName string) ([]byte, error) {
-	handler := s.getContractSdkHandler()
+// used by processors of other languages so they read deployed code through _Deployments exactly like native contracts
+func CallGetCodeOfDeploymentSystemContract(ctx context.Context, handler handlers.ContractSdkCallHandler, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName) ([]byte, error) {
+	return callDeploymentSystemContractForBytes(ctx, handler, executionContextId, deployments_systemcontract.METHOD_GET_CODE.Name, contractName)
+}
+
+// the virtual machine serves the code hashes stored by _Deployments from a cache so processors don't make a nested
+// _Deployments call on every call to a deployed contract, the pinned spec has no such call on the sdk handler
+type DeployedCodeHashGetter interface {
+	GetDeployedCodeHash(ctx context.Context, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName) (primitives.Sha256, error)
+}
+
+// compiled code is cached by the hash of the code the execution context sees in state, so an upgrade takes effect exactly
+// when its transaction is committed and a cached artifact is never served for a different version of the code
+func CallGetCodeHashOfDeploymentSystemContract(ctx context.Context, handler handlers.ContractSdkCallHandler, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName) (primitives.Sha256, error) {
+	if getter, ok := handler.(DeployedCodeHashGetter); ok {
+		codeHash, err := getter.GetDeployedCodeHash(ctx, executionContextId, contractName)
+		if err != nil || codeHash != nil {
+			return codeHash, err
+		}
+		// no hash stored, _Deployments calculates it from the code (or fails if the contract has none)
+	}
+	return callDeploymentSystemContractForBytes(ctx, handler, executionContextId, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, contractName)
+}
+
+func callDeploymentSystemContractForBytes(ctx context.Context, handler handlers.ContractSdkCallHandler, executionContextId primitives.ExecutionContextId, methodName string, contractName primitives.ContractName) ([]byte, error) {
 	if handler == nil {
 		return nil, errors.New("ContractSdkCallHandler has not registered yet")
 	}
 
 	systemContractName := primitives.ContractName(deployments_systemcontract.CONTRACT.Name)
-	systemMethodName := primitives.MethodName(deployments_systemcontract.METHOD_GET_CODE.Name)
+	systemMethodName := primitives.MethodName(methodName)
 
 	output, err := handler.HandleSdkCall(ctx, &handlers.HandleSdkCallInput{
-		ContextId:     primitives.ExecutionContextId(executionContextId),
+		ContextId:     executionContextId,
 		OperationName: SDK_OPERATION_NAME_SERVICE,
 		MethodName:    "callMethod",
 		InputArguments: []*protocol.MethodArgument{
@@ -128,7 +192,7 @@ func (s *service) callGetCodeOfDeploymentSystemContract(ctx context.Context, exe
 			(&protocol.MethodArgumentBuilder{
 				Name:       "inputArgs",
 				Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
-				BytesValue: argsToMethodArgumentArray(string(contractName)).Raw(),
+				BytesValue: ArgsToMethodArgumentArray(string(contractName)).Raw(),
 			}).Build(),
 		},
 		PermissionScope: protocol.PERMISSION_SCOPE_SYSTEM,
@@ -137,16 +201,16 @@ func (s *service) callGetCodeOfDeploymentSystemContract(ctx context.Context, exe
 		return nil, err
 	}
 	if len(output.OutputArguments) != 1 || !output.OutputArguments[0].IsTypeBytesValue() {
-		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.getCode returned corrupt output value")
+		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.%s returned corrupt output value", methodName)
 	}
 	methodArgumentArray := protocol.MethodArgumentArrayReader(output.OutputArguments[0].BytesValue())
 	argIterator := methodArgumentArray.ArgumentsIterator()
 	if !argIterator.HasNext() {
-		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.getCode returned corrupt output value")
+		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.%s returned corrupt output value", methodName)
 	}
 	arg0 := argIterator.NextArguments()
 	if !arg0.IsTypeBytesValue() {
-		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.getCode returned corrupt output value")
+		return nil, errors.Errorf("callMethod Sdk.Service of _Deployments.%s returned corrupt output value", methodName)
 	}
 	return arg0.BytesValue(), nil
 }
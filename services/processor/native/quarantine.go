@@ -0,0 +1,90 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+var ErrContractTimeout = errors.New("contract execution time limit exceeded")
+var ErrContractQuarantined = errors.New("contract is quarantined since an earlier call to it is still running")
+
+// go can't kill a goroutine, so a timed out call keeps running after it is abandoned and its contract is quarantined
+// until all of its abandoned calls return (which for an infinite loop means until the node restarts)
+type contractQuarantine struct {
+	mutex          *sync.Mutex
+	abandonedCalls map[string]int
+}
+
+func newContractQuarantine() *contractQuarantine {
+	return &contractQuarantine{
+		mutex:          &sync.Mutex{},
+		abandonedCalls: make(map[string]int),
+	}
+}
+
+func (q *contractQuarantine) isQuarantined(contractName string) bool {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	return q.abandonedCalls[contractName] > 0
+}
+
+func (q *contractQuarantine) abandon(contractName string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.abandonedCalls[contractName]++
+}
+
+func (q *contractQuarantine) release(contractName string) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.abandonedCalls[contractName]--
+	if q.abandonedCalls[contractName] <= 0 {
+		delete(q.abandonedCalls, contractName)
+	}
+}
+
+type methodCallResult struct {
+	outputArgs  *protocol.MethodArgumentArray
+	contractErr error
+	err         error
+}
+
+// without a deadline the call runs inline, otherwise it runs in its own goroutine so the processor can return when the context expires
+func (s *service) processMethodCallUntilDone(ctx context.Context, executionContextId sdk.Context, contractInfo *sdk.ContractInfo, methodInfo *sdk.MethodInfo, args *protocol.MethodArgumentArray) (*protocol.MethodArgumentArray, error, error) {
+	if setter, ok := s.compiler.(adapter.CallContextSetter); ok {
+		restore := setter.SetCallContext(ctx, primitives.ExecutionContextId(executionContextId))
+		defer restore()
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		return s.processMethodCall(executionContextId, contractInfo, methodInfo, args)
+	}
+
+	done := make(chan *methodCallResult, 1)
+	go func() {
+		outputArgs, contractErr, err := s.processMethodCall(executionContextId, contractInfo, methodInfo, args)
+		done <- &methodCallResult{outputArgs, contractErr, err}
+	}()
+
+	select {
+	case result := <-done:
+		return result.outputArgs, result.contractErr, result.err
+	case <-ctx.Done():
+		s.quarantine.abandon(contractInfo.Name)
+		s.metrics.abandonedCalls.Inc()
+		go func() {
+			<-done
+			s.quarantine.release(contractInfo.Name)
+			s.metrics.abandonedCalls.Dec()
+		}()
+		return nil, nil, ErrContractTimeout
+	}
+}
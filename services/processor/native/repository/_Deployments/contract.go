@@ -1,6 +1,8 @@
 package deployments_systemcontract
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
@@ -10,10 +12,18 @@ var CONTRACT = sdk.ContractInfo{
 	Name:       "_Deployments",
 	Permission: sdk.PERMISSION_SCOPE_SYSTEM,
 	Methods: map[string]sdk.MethodInfo{
-		METHOD_INIT.Name:           METHOD_INIT,
-		METHOD_GET_INFO.Name:       METHOD_GET_INFO,
-		METHOD_GET_CODE.Name:       METHOD_GET_CODE,
-		METHOD_DEPLOY_SERVICE.Name: METHOD_DEPLOY_SERVICE,
+		METHOD_INIT.Name:                        METHOD_INIT,
+		METHOD_GET_INFO.Name:                    METHOD_GET_INFO,
+		METHOD_GET_CODE.Name:                    METHOD_GET_CODE,
+		METHOD_GET_CODE_HASH.Name:               METHOD_GET_CODE_HASH,
+		METHOD_GET_VERSION.Name:                 METHOD_GET_VERSION,
+		METHOD_GET_OWNER.Name:                   METHOD_GET_OWNER,
+		METHOD_IS_NON_REENTRANT.Name:            METHOD_IS_NON_REENTRANT,
+		METHOD_GET_DEPLOYED_SERVICES_COUNT.Name: METHOD_GET_DEPLOYED_SERVICES_COUNT,
+		METHOD_GET_DEPLOYED_SERVICE_NAME.Name:   METHOD_GET_DEPLOYED_SERVICE_NAME,
+		METHOD_DEPLOY_SERVICE.Name:              METHOD_DEPLOY_SERVICE,
+		METHOD_UPGRADE_SERVICE.Name:             METHOD_UPGRADE_SERVICE,
+		METHOD_SET_NON_REENTRANT.Name:           METHOD_SET_NON_REENTRANT,
 	},
 	InitSingleton: newContract,
 }
@@ -66,8 +76,17 @@ var METHOD_GET_CODE = sdk.MethodInfo{
 	Implementation: (*contract).getCode,
 }
 
+// every version is kept under Code.<version>, contracts deployed before versions were stored only have Code
 func (c *contract) getCode(ctx sdk.Context, serviceName string) ([]byte, error) {
-	code, err := c.State.ReadBytesByKey(ctx, serviceName+".Code")
+	version, err := c.State.ReadUint32ByKey(ctx, serviceName+".Version")
+	if err != nil {
+		return nil, err
+	}
+	codeKey := serviceName + ".Code"
+	if version != 0 {
+		codeKey = fmt.Sprintf("%s.Code.%d", serviceName, version)
+	}
+	code, err := c.State.ReadBytesByKey(ctx, codeKey)
 	if err == nil && len(code) == 0 {
 		err = errors.New("contract code not available")
 	}
@@ -76,6 +95,111 @@ func (c *contract) getCode(ctx sdk.Context, serviceName string) ([]byte, error)
 
 ///////////////////////////////////////////////////////////////////////////
 
+var METHOD_GET_CODE_HASH = sdk.MethodInfo{
+	Name:           "getCodeHash",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getCodeHash,
+}
+
+// processors cache compiled code by this hash, contracts deployed before it was stored have it calculated from their code
+func (c *contract) getCodeHash(ctx sdk.Context, serviceName string) ([]byte, error) {
+	codeHash, err := c.State.ReadBytesByKey(ctx, serviceName+".CodeHash")
+	if err != nil || len(codeHash) != 0 {
+		return codeHash, err
+	}
+	code, err := c.getCode(ctx, serviceName)
+	if err != nil {
+		return nil, err
+	}
+	calculated := sha256.Sum256(code)
+	return calculated[:], nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_VERSION = sdk.MethodInfo{
+	Name:           "getVersion",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getVersion,
+}
+
+func (c *contract) getVersion(ctx sdk.Context, serviceName string) (uint32, error) {
+	version, err := c.State.ReadUint32ByKey(ctx, serviceName+".Version")
+	if err == nil && version == 0 {
+		err = errors.New("contract code not available")
+	}
+	return version, err
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_OWNER = sdk.MethodInfo{
+	Name:           "getOwner",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getOwner,
+}
+
+func (c *contract) getOwner(ctx sdk.Context, serviceName string) ([]byte, error) {
+	owner, err := c.State.ReadBytesByKey(ctx, serviceName+".Owner")
+	if err == nil && len(owner) == 0 {
+		err = errors.New("contract has no owner")
+	}
+	return owner, err
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_IS_NON_REENTRANT = sdk.MethodInfo{
+	Name:           "isNonReentrant",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).isNonReentrant,
+}
+
+// the virtual machine fails calls into a non-reentrant contract while it's already on the call stack (1 if so, 0 otherwise)
+func (c *contract) isNonReentrant(ctx sdk.Context, serviceName string) (uint32, error) {
+	return c.State.ReadUint32ByKey(ctx, serviceName+".NonReentrant")
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_DEPLOYED_SERVICES_COUNT = sdk.MethodInfo{
+	Name:           "getDeployedServicesCount",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getDeployedServicesCount,
+}
+
+// only services deployed with code are listed, pre-built native contracts are always available
+func (c *contract) getDeployedServicesCount(ctx sdk.Context) (uint32, error) {
+	return c.State.ReadUint32ByKey(ctx, "DeployedServices.Count")
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_DEPLOYED_SERVICE_NAME = sdk.MethodInfo{
+	Name:           "getDeployedServiceName",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getDeployedServiceName,
+}
+
+func (c *contract) getDeployedServiceName(ctx sdk.Context, index uint32) (string, error) {
+	count, err := c.getDeployedServicesCount(ctx)
+	if err != nil {
+		return "", err
+	}
+	if index >= count {
+		return "", fmt.Errorf("index %d is out of range, %d services deployed", index, count)
+	}
+	return c.State.ReadStringByKey(ctx, fmt.Sprintf("DeployedServices.%d", index))
+}
+
+///////////////////////////////////////////////////////////////////////////
+
 var METHOD_DEPLOY_SERVICE = sdk.MethodInfo{
 	Name:           "deployService",
 	External:       true,
@@ -96,17 +220,144 @@ func (c *contract) deployService(ctx sdk.Context, serviceName string, processorT
 		return fmt.Errorf("failed writing Processor key: %s", err.Error())
 	}
 
+	// pre-built native contracts are deployed without code and have no owner
 	if len(code) != 0 {
-		err = c.State.WriteBytesByKey(ctx, serviceName+".Code", code)
+		ownerAddress, err := c.Address.GetSignerAddress(ctx)
+		if err != nil {
+			return fmt.Errorf("failed getting signer address: %s", err.Error())
+		}
+		err = c.State.WriteBytesByKey(ctx, serviceName+".Owner", ownerAddress)
+		if err != nil {
+			return fmt.Errorf("failed writing Owner key: %s", err.Error())
+		}
+		err = c.writeCodeVersion(ctx, serviceName, 1, code)
+		if err != nil {
+			return err
+		}
+		err = c.addDeployedService(ctx, serviceName)
 		if err != nil {
-			return fmt.Errorf("failed writing Code key: %s", err.Error())
+			return err
 		}
 	}
 
 	_, err = c.Service.CallMethod(ctx, serviceName, "_init")
 	if err != nil {
-		return errors.New("failed to initialize contract")
+		return fmt.Errorf("failed to initialize contract: %s", err.Error())
+	}
+
+	return nil
+}
+
+// deployed services are listed by index since state keys are hashed and cannot be enumerated
+func (c *contract) addDeployedService(ctx sdk.Context, serviceName string) error {
+	count, err := c.getDeployedServicesCount(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.State.WriteStringByKey(ctx, fmt.Sprintf("DeployedServices.%d", count), serviceName)
+	if err != nil {
+		return fmt.Errorf("failed writing DeployedServices key: %s", err.Error())
+	}
+	err = c.State.WriteUint32ByKey(ctx, "DeployedServices.Count", count+1)
+	if err != nil {
+		return fmt.Errorf("failed writing DeployedServices count key: %s", err.Error())
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_UPGRADE_SERVICE = sdk.MethodInfo{
+	Name:           "upgradeService",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).upgradeService,
+}
+
+// replaces the code of a deployed contract, its state is kept and _init is not called again,
+// the virtual machine only keeps the upgrade if the processor of the contract loads the upgraded code
+func (c *contract) upgradeService(ctx sdk.Context, serviceName string, processorType uint32, code []byte) error {
+	if len(code) == 0 {
+		return errors.New("upgraded code is empty")
+	}
+
+	deployedProcessorType, err := c.getInfo(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	if processorType != deployedProcessorType {
+		return fmt.Errorf("contract was deployed for processor %d, upgraded code is for processor %d", deployedProcessorType, processorType)
 	}
 
+	owner, err := c.getOwner(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	signerAddress, err := c.Address.GetSignerAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting signer address: %s", err.Error())
+	}
+	if !bytes.Equal(owner, signerAddress) {
+		return errors.New("only the contract owner can upgrade it")
+	}
+
+	version, err := c.getVersion(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+
+	return c.writeCodeVersion(ctx, serviceName, version+1, code)
+}
+
+// every version is kept once under Code.<version> for history, Version points at the current one
+func (c *contract) writeCodeVersion(ctx sdk.Context, serviceName string, version uint32, code []byte) error {
+	err := c.State.WriteBytesByKey(ctx, fmt.Sprintf("%s.Code.%d", serviceName, version), code)
+	if err != nil {
+		return fmt.Errorf("failed writing Code key: %s", err.Error())
+	}
+	codeHash := sha256.Sum256(code)
+	err = c.State.WriteBytesByKey(ctx, serviceName+".CodeHash", codeHash[:])
+	if err != nil {
+		return fmt.Errorf("failed writing CodeHash key: %s", err.Error())
+	}
+	err = c.State.WriteUint32ByKey(ctx, serviceName+".Version", version)
+	if err != nil {
+		return fmt.Errorf("failed writing Version key: %s", err.Error())
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_SET_NON_REENTRANT = sdk.MethodInfo{
+	Name:           "setNonReentrant",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).setNonReentrant,
+}
+
+// the sdk contract info has no reentrancy, so the owner of a contract marks it after deploying it (pre-built native
+// contracts have no owner and stay reentrant)
+func (c *contract) setNonReentrant(ctx sdk.Context, serviceName string, nonReentrant uint32) error {
+	if nonReentrant > 1 {
+		return fmt.Errorf("nonReentrant must be 0 or 1, got %d", nonReentrant)
+	}
+
+	owner, err := c.getOwner(ctx, serviceName)
+	if err != nil {
+		return err
+	}
+	signerAddress, err := c.Address.GetSignerAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting signer address: %s", err.Error())
+	}
+	if !bytes.Equal(owner, signerAddress) {
+		return errors.New("only the contract owner can change its reentrancy")
+	}
+
+	err = c.State.WriteUint32ByKey(ctx, serviceName+".NonReentrant", nonReentrant)
+	if err != nil {
+		return fmt.Errorf("failed writing NonReentrant key: %s", err.Error())
+	}
 	return nil
 }
@@ -1,6 +1,9 @@
 package globalpreorder_systemcontract
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
 )
 
@@ -8,8 +11,12 @@ var CONTRACT = sdk.ContractInfo{
 	Name:       "_GlobalPreOrder",
 	Permission: sdk.PERMISSION_SCOPE_SYSTEM,
 	Methods: map[string]sdk.MethodInfo{
-		METHOD_INIT.Name:    METHOD_INIT,
-		METHOD_APPROVE.Name: METHOD_APPROVE,
+		METHOD_INIT.Name:             METHOD_INIT,
+		METHOD_APPROVE.Name:          METHOD_APPROVE,
+		METHOD_GET_SUBSCRIPTION.Name: METHOD_GET_SUBSCRIPTION,
+		METHOD_SET_SUBSCRIPTION.Name: METHOD_SET_SUBSCRIPTION,
+		METHOD_GET_ADMIN.Name:        METHOD_GET_ADMIN,
+		METHOD_SET_ADMIN.Name:        METHOD_SET_ADMIN,
 	},
 	InitSingleton: newContract,
 }
@@ -29,7 +36,21 @@ var METHOD_INIT = sdk.MethodInfo{
 	Implementation: (*contract)._init,
 }
 
-func (c *contract) _init(ctx sdk.Context) error {
+// the virtual machine passes the admin and subscription the virtual chain starts with (from its config), either may be empty
+func (c *contract) _init(ctx sdk.Context, admin []byte, plan string, expiry uint64, transactionsPerBlock uint32) error {
+	if len(admin) != 0 {
+		err := c.Address.ValidateAddress(ctx, admin)
+		if err != nil {
+			return err
+		}
+		err = c.State.WriteBytesByKey(ctx, "Admin", admin)
+		if err != nil {
+			return fmt.Errorf("failed writing Admin key: %s", err.Error())
+		}
+	}
+	if plan != "" {
+		return c.writeSubscription(ctx, plan, expiry, transactionsPerBlock)
+	}
 	return nil
 }
 
@@ -42,7 +63,142 @@ var METHOD_APPROVE = sdk.MethodInfo{
 	Implementation: (*contract).approve,
 }
 
-func (c *contract) approve(ctx sdk.Context) error {
-	// TODO: add subscription check here
+// returns how many transactions the virtual chain may order in a block, the virtual machine passes the timestamp
+// of the last committed block since the contract sdk has no way to read it
+func (c *contract) approve(ctx sdk.Context, blockTimestamp uint64) (uint32, error) {
+	plan, expiry, transactionsPerBlock, err := c.getSubscription(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if expiry <= blockTimestamp {
+		return 0, fmt.Errorf("subscription to plan '%s' expired", plan)
+	}
+	if transactionsPerBlock == 0 {
+		return 0, fmt.Errorf("subscription to plan '%s' allows no transactions", plan)
+	}
+	return transactionsPerBlock, nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_SUBSCRIPTION = sdk.MethodInfo{
+	Name:           "getSubscription",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getSubscription,
+}
+
+// expiry is a unix timestamp in nanoseconds compared against the block timestamp
+func (c *contract) getSubscription(ctx sdk.Context) (string, uint64, uint32, error) {
+	plan, err := c.State.ReadStringByKey(ctx, "Subscription.Plan")
+	if err != nil {
+		return "", 0, 0, err
+	}
+	if plan == "" {
+		return "", 0, 0, errors.New("virtual chain has no subscription")
+	}
+	expiry, err := c.State.ReadUint64ByKey(ctx, "Subscription.Expiry")
+	if err != nil {
+		return "", 0, 0, err
+	}
+	transactionsPerBlock, err := c.State.ReadUint32ByKey(ctx, "Subscription.TransactionsPerBlock")
+	if err != nil {
+		return "", 0, 0, err
+	}
+	return plan, expiry, transactionsPerBlock, nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_SET_SUBSCRIPTION = sdk.MethodInfo{
+	Name:           "setSubscription",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).setSubscription,
+}
+
+func (c *contract) setSubscription(ctx sdk.Context, plan string, expiry uint64, transactionsPerBlock uint32) error {
+	err := c.verifySignerIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	if plan == "" {
+		return errors.New("subscription plan must not be empty")
+	}
+	return c.writeSubscription(ctx, plan, expiry, transactionsPerBlock)
+}
+
+func (c *contract) writeSubscription(ctx sdk.Context, plan string, expiry uint64, transactionsPerBlock uint32) error {
+	err := c.State.WriteStringByKey(ctx, "Subscription.Plan", plan)
+	if err != nil {
+		return fmt.Errorf("failed writing Plan key: %s", err.Error())
+	}
+	err = c.State.WriteUint64ByKey(ctx, "Subscription.Expiry", expiry)
+	if err != nil {
+		return fmt.Errorf("failed writing Expiry key: %s", err.Error())
+	}
+	err = c.State.WriteUint32ByKey(ctx, "Subscription.TransactionsPerBlock", transactionsPerBlock)
+	if err != nil {
+		return fmt.Errorf("failed writing TransactionsPerBlock key: %s", err.Error())
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_GET_ADMIN = sdk.MethodInfo{
+	Name:           "getAdmin",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).getAdmin,
+}
+
+func (c *contract) getAdmin(ctx sdk.Context) ([]byte, error) {
+	admin, err := c.State.ReadBytesByKey(ctx, "Admin")
+	if err == nil && len(admin) == 0 {
+		err = errors.New("virtual chain has no admin")
+	}
+	return admin, err
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_SET_ADMIN = sdk.MethodInfo{
+	Name:           "setAdmin",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).setAdmin,
+}
+
+// only the admin can hand it over, the first admin is set by _init
+func (c *contract) setAdmin(ctx sdk.Context, newAdmin []byte) error {
+	err := c.verifySignerIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.Address.ValidateAddress(ctx, newAdmin)
+	if err != nil {
+		return err
+	}
+
+	err = c.State.WriteBytesByKey(ctx, "Admin", newAdmin)
+	if err != nil {
+		return fmt.Errorf("failed writing Admin key: %s", err.Error())
+	}
+	return nil
+}
+
+func (c *contract) verifySignerIsAdmin(ctx sdk.Context) error {
+	admin, err := c.getAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	signer, err := c.Address.GetSignerAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting signer address: %s", err.Error())
+	}
+	if !bytes.Equal(admin, signer) {
+		return errors.New("only the virtual chain admin can do this")
+	}
 	return nil
 }
@@ -0,0 +1,152 @@
+package virtualchainpreorder_systemcontract
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+)
+
+// optional, the virtual machine only calls approve once the contract is deployed (its first transaction deploys it)
+var CONTRACT = sdk.ContractInfo{
+	Name:       "_VirtualChainPreOrder",
+	Permission: sdk.PERMISSION_SCOPE_SYSTEM,
+	Methods: map[string]sdk.MethodInfo{
+		METHOD_INIT.Name:              METHOD_INIT,
+		METHOD_APPROVE.Name:           METHOD_APPROVE,
+		METHOD_IS_SIGNER_BLOCKED.Name: METHOD_IS_SIGNER_BLOCKED,
+		METHOD_BLOCK_SIGNER.Name:      METHOD_BLOCK_SIGNER,
+		METHOD_UNBLOCK_SIGNER.Name:    METHOD_UNBLOCK_SIGNER,
+	},
+	InitSingleton: newContract,
+}
+
+func newContract(base *sdk.BaseContract) sdk.ContractInstance {
+	return &contract{base}
+}
+
+type contract struct{ *sdk.BaseContract }
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_INIT = sdk.MethodInfo{
+	Name:           "_init",
+	External:       false,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract)._init,
+}
+
+func (c *contract) _init(ctx sdk.Context) error {
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_APPROVE = sdk.MethodInfo{
+	Name:           "approve",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).approve,
+}
+
+// called for every transaction of the set with the transaction as the execution context
+func (c *contract) approve(ctx sdk.Context, contractName string, methodName string) error {
+	signer, err := c.Address.GetSignerAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting signer address: %s", err.Error())
+	}
+	blocked, err := c.isSignerBlocked(ctx, signer)
+	if err != nil {
+		return err
+	}
+	if blocked != 0 {
+		return fmt.Errorf("signer %x is blocked on this virtual chain", signer)
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_IS_SIGNER_BLOCKED = sdk.MethodInfo{
+	Name:           "isSignerBlocked",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).isSignerBlocked,
+}
+
+func (c *contract) isSignerBlocked(ctx sdk.Context, signer []byte) (uint32, error) {
+	return c.State.ReadUint32ByKey(ctx, blockedSignerKey(signer))
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_BLOCK_SIGNER = sdk.MethodInfo{
+	Name:           "blockSigner",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).blockSigner,
+}
+
+func (c *contract) blockSigner(ctx sdk.Context, signer []byte) error {
+	err := c.verifySignerIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.Address.ValidateAddress(ctx, signer)
+	if err != nil {
+		return err
+	}
+	err = c.State.WriteUint32ByKey(ctx, blockedSignerKey(signer), 1)
+	if err != nil {
+		return fmt.Errorf("failed writing blocked signer key: %s", err.Error())
+	}
+	return nil
+}
+
+///////////////////////////////////////////////////////////////////////////
+
+var METHOD_UNBLOCK_SIGNER = sdk.MethodInfo{
+	Name:           "unblockSigner",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_WRITE,
+	Implementation: (*contract).unblockSigner,
+}
+
+func (c *contract) unblockSigner(ctx sdk.Context, signer []byte) error {
+	err := c.verifySignerIsAdmin(ctx)
+	if err != nil {
+		return err
+	}
+	err = c.State.ClearByKey(ctx, blockedSignerKey(signer))
+	if err != nil {
+		return fmt.Errorf("failed clearing blocked signer key: %s", err.Error())
+	}
+	return nil
+}
+
+// the virtual chain admin is managed by _GlobalPreOrder
+func (c *contract) verifySignerIsAdmin(ctx sdk.Context) error {
+	output, err := c.Service.CallMethod(ctx, "_GlobalPreOrder", "getAdmin")
+	if err != nil {
+		return err
+	}
+	if len(output) != 1 {
+		return errors.New("_GlobalPreOrder.getAdmin returned corrupt output value")
+	}
+	admin, ok := output[0].([]byte)
+	if !ok {
+		return errors.New("_GlobalPreOrder.getAdmin returned corrupt output value")
+	}
+	signer, err := c.Address.GetSignerAddress(ctx)
+	if err != nil {
+		return fmt.Errorf("failed getting signer address: %s", err.Error())
+	}
+	if !bytes.Equal(admin, signer) {
+		return errors.New("only the virtual chain admin can do this")
+	}
+	return nil
+}
+
+func blockedSignerKey(signer []byte) string {
+	return fmt.Sprintf("Blocked.%x", signer)
+}
@@ -7,13 +7,15 @@ import (
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_GlobalPreOrder"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Info"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_VirtualChainPreOrder"
 )
 
 var PreBuiltContracts = map[string]*sdk.ContractInfo{
-	globalpreorder_systemcontract.CONTRACT.Name: &globalpreorder_systemcontract.CONTRACT,
-	deployments_systemcontract.CONTRACT.Name:    &deployments_systemcontract.CONTRACT,
-	info_systemcontract.CONTRACT.Name:           &info_systemcontract.CONTRACT,
-	benchmarkcontract.CONTRACT.Name:             &benchmarkcontract.CONTRACT,
-	benchmarktoken.CONTRACT.Name:                &benchmarktoken.CONTRACT,
+	globalpreorder_systemcontract.CONTRACT.Name:       &globalpreorder_systemcontract.CONTRACT,
+	virtualchainpreorder_systemcontract.CONTRACT.Name: &virtualchainpreorder_systemcontract.CONTRACT,
+	deployments_systemcontract.CONTRACT.Name:          &deployments_systemcontract.CONTRACT,
+	info_systemcontract.CONTRACT.Name:                 &info_systemcontract.CONTRACT,
+	benchmarkcontract.CONTRACT.Name:                   &benchmarkcontract.CONTRACT,
+	benchmarktoken.CONTRACT.Name:                      &benchmarktoken.CONTRACT,
 	// add new pre-built native system contracts here
 }
@@ -1,6 +1,341 @@
 package native
 
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const SDK_IMPORT_PATH = "github.com/orbs-network/orbs-contract-sdk/go/sdk"
+
+// deployed code is compiled and loaded into the node process, so it may only import the sdk and pure packages
+var allowedDeployedSourceImports = map[string]bool{
+	SDK_IMPORT_PATH:   true,
+	"bytes":           true,
+	"encoding/binary": true,
+	"encoding/hex":    true,
+	"errors":          true,
+	"math":            true,
+	"math/big":        true,
+	"sort":            true,
+	"strconv":         true,
+	"strings":         true,
+	"unicode":         true,
+	"unicode/utf8":    true,
+}
+
+// the only package level variables a contract may declare are its sdk.ContractInfo and sdk.MethodInfo registrations
+var allowedDeployedSourceGlobalTypes = map[string]bool{
+	"ContractInfo": true,
+	"MethodInfo":   true,
+}
+
+const DEPLOYED_SOURCE_FILE_NAME = "contract.go"
+
 func sanitizeDeployedSourceCode(code string) (string, error) {
-	// TODO: finish implementing
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, DEPLOYED_SOURCE_FILE_NAME, code, parser.ParseComments)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse source code")
+	}
+
+	s := &sourceSanitizer{fset: fset, sdkImportName: "sdk", packageNames: map[string]bool{}, packageVariables: map[interface{}]bool{}}
+	s.checkImports(file)
+	s.collectPackageNames(file)
+	s.checkDeclarations(file)
+	s.checkSingletons(file)
+	s.checkStatements(file)
+
+	if len(s.violations) > 0 {
+		return "", errors.New(strings.Join(s.violations, "\n"))
+	}
 	return code, nil
 }
+
+type sourceSanitizer struct {
+	fset                  *token.FileSet
+	sdkImportName         string
+	packageNames          map[string]bool
+	packageVariables      map[interface{}]bool // the value specs of package level variables
+	singletonConstructors []ast.Expr
+	violations            []string
+}
+
+func (s *sourceSanitizer) reject(pos token.Pos, format string, args ...interface{}) {
+	s.violations = append(s.violations, fmt.Sprintf("%s: %s", s.fset.Position(pos), fmt.Sprintf(format, args...)))
+}
+
+// cgo is covered here as well since it requires import "C"
+func (s *sourceSanitizer) checkImports(file *ast.File) {
+	for _, importSpec := range file.Imports {
+		path, err := strconv.Unquote(importSpec.Path.Value)
+		if err != nil {
+			s.reject(importSpec.Pos(), "malformed import %s", importSpec.Path.Value)
+			continue
+		}
+		if !allowedDeployedSourceImports[path] {
+			s.reject(importSpec.Pos(), "import %q is not allowed", path)
+			continue
+		}
+		if importSpec.Name != nil && (importSpec.Name.Name == "." || importSpec.Name.Name == "_") {
+			s.reject(importSpec.Pos(), "import %q must not be a dot or blank import", path)
+			continue
+		}
+		if path == SDK_IMPORT_PATH && importSpec.Name != nil {
+			s.sdkImportName = importSpec.Name.Name
+		}
+	}
+}
+
+func (s *sourceSanitizer) checkDeclarations(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name == "init" {
+				s.reject(decl.Pos(), "init functions are not allowed")
+			}
+		case *ast.GenDecl:
+			if decl.Tok == token.VAR {
+				for _, spec := range decl.Specs {
+					s.packageVariables[spec] = true
+					s.checkGlobalVariable(spec.(*ast.ValueSpec))
+				}
+			}
+		}
+	}
+}
+
+// registrations may refer to the functions, types, constants and other registrations declared in the contract
+func (s *sourceSanitizer) collectPackageNames(file *ast.File) {
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				s.packageNames[decl.Name.Name] = true
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					s.packageNames[spec.Name.Name] = true
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						s.packageNames[name.Name] = true
+					}
+				}
+			}
+		}
+	}
+}
+
+func (s *sourceSanitizer) checkGlobalVariable(spec *ast.ValueSpec) {
+	for i, name := range spec.Names {
+		if i >= len(spec.Values) || !s.isSdkRegistration(spec.Values[i]) {
+			s.reject(name.Pos(), "package level variable '%s' is not allowed, keep mutable data in contract state", name.Name)
+			continue
+		}
+		s.checkConstantFields(spec.Values[i].(*ast.CompositeLit))
+		s.collectSingletonConstructor(spec.Values[i].(*ast.CompositeLit))
+	}
+}
+
+func (s *sourceSanitizer) collectSingletonConstructor(literal *ast.CompositeLit) {
+	for _, element := range literal.Elts {
+		keyValue, ok := element.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		if key, ok := keyValue.Key.(*ast.Ident); ok && key.Name == "InitSingleton" {
+			s.singletonConstructors = append(s.singletonConstructors, keyValue.Value)
+		}
+	}
+}
+
+// the singleton is shared by all calls for as long as the contract is loaded, so it may only embed (like the sdk)
+// and never hold fields that would carry data from one call to the next outside of contract state
+func (s *sourceSanitizer) checkSingletons(file *ast.File) {
+	funcs := map[string]*ast.FuncDecl{}
+	types := map[string]*ast.TypeSpec{}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil {
+				funcs[decl.Name.Name] = decl
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				if typeSpec, ok := spec.(*ast.TypeSpec); ok {
+					types[typeSpec.Name.Name] = typeSpec
+				}
+			}
+		}
+	}
+
+	for _, constructor := range s.singletonConstructors {
+		ident, ok := constructor.(*ast.Ident)
+		if !ok || funcs[ident.Name] == nil || funcs[ident.Name].Body == nil {
+			s.reject(constructor.Pos(), "InitSingleton must be a function declared in the contract")
+			continue
+		}
+		ast.Inspect(funcs[ident.Name].Body, func(node ast.Node) bool {
+			if _, ok := node.(*ast.FuncLit); ok {
+				return false
+			}
+			if returnStmt, ok := node.(*ast.ReturnStmt); ok {
+				for _, result := range returnStmt.Results {
+					s.checkSingletonLiteral(result, types)
+				}
+			}
+			return true
+		})
+	}
+}
+
+func (s *sourceSanitizer) checkSingletonLiteral(expr ast.Expr, types map[string]*ast.TypeSpec) {
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+	literal, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		s.reject(expr.Pos(), "InitSingleton must return a literal of a struct declared in the contract")
+		return
+	}
+	typeName, ok := literal.Type.(*ast.Ident)
+	if !ok || types[typeName.Name] == nil {
+		s.reject(expr.Pos(), "InitSingleton must return a literal of a struct declared in the contract")
+		return
+	}
+	structType, ok := types[typeName.Name].Type.(*ast.StructType)
+	if !ok {
+		s.reject(expr.Pos(), "InitSingleton must return a literal of a struct declared in the contract")
+		return
+	}
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 && !s.isSdkType(field.Type) {
+			s.reject(field.Pos(), "embedded contract field is not allowed, only sdk types may be embedded")
+		}
+		for _, name := range field.Names {
+			s.reject(name.Pos(), "contract field '%s' is not allowed, keep mutable data in contract state", name.Name)
+		}
+	}
+}
+
+// sdk.BaseContract or *sdk.BaseContract, a type declared in the contract could carry fields of its own
+func (s *sourceSanitizer) isSdkType(expr ast.Expr) bool {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	selector, ok := expr.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == s.sdkImportName
+}
+
+func (s *sourceSanitizer) isSdkRegistration(expr ast.Expr) bool {
+	literal, ok := expr.(*ast.CompositeLit)
+	if !ok {
+		return false
+	}
+	selector, ok := literal.Type.(*ast.SelectorExpr)
+	if !ok {
+		return false
+	}
+	pkg, ok := selector.X.(*ast.Ident)
+	return ok && pkg.Name == s.sdkImportName && allowedDeployedSourceGlobalTypes[selector.Sel.Name]
+}
+
+// registrations are evaluated when the plugin is loaded into the node process, so they must not run any contract code
+func (s *sourceSanitizer) checkConstantFields(literal *ast.CompositeLit) {
+	_, isMap := literal.Type.(*ast.MapType)
+	for _, element := range literal.Elts {
+		keyValue, ok := element.(*ast.KeyValueExpr)
+		if !ok {
+			s.reject(element.Pos(), "registration fields must be set by name")
+			continue
+		}
+		if isMap && !s.isConstant(keyValue.Key) {
+			s.reject(keyValue.Key.Pos(), "registration map keys must be constants")
+		}
+		if !s.isConstant(keyValue.Value) {
+			s.reject(keyValue.Value.Pos(), "registration fields must be constants, literals, sdk constants, package functions or methods")
+		}
+	}
+}
+
+func (s *sourceSanitizer) isConstant(expr ast.Expr) bool {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return true
+	case *ast.Ident:
+		return expr.Name == "true" || expr.Name == "false" || expr.Name == "nil" || s.packageNames[expr.Name]
+	case *ast.SelectorExpr:
+		return s.isConstantSelector(expr)
+	case *ast.CompositeLit:
+		s.checkConstantFields(expr)
+		return true // violations inside the literal are reported by checkConstantFields
+	default:
+		return false
+	}
+}
+
+// sdk.PERMISSION_SCOPE_SERVICE, METHOD_INIT.Name, (*contract).method or contract.method
+func (s *sourceSanitizer) isConstantSelector(selector *ast.SelectorExpr) bool {
+	x := selector.X
+	if paren, ok := x.(*ast.ParenExpr); ok {
+		star, ok := paren.X.(*ast.StarExpr)
+		if !ok {
+			return false
+		}
+		x = star.X
+	}
+	ident, ok := x.(*ast.Ident)
+	return ok && (ident.Name == s.sdkImportName || s.packageNames[ident.Name])
+}
+
+// registrations are shared by all calls, a contract assigning to them would carry data from one call to the next
+func (s *sourceSanitizer) checkStatements(file *ast.File) {
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.GoStmt:
+			s.reject(node.Pos(), "goroutines are not allowed")
+		case *ast.AssignStmt:
+			if node.Tok != token.DEFINE {
+				for _, lhs := range node.Lhs {
+					s.checkNotAssigningPackageVariable(lhs)
+				}
+			}
+		case *ast.IncDecStmt:
+			s.checkNotAssigningPackageVariable(node.X)
+		}
+		return true
+	})
+}
+
+func (s *sourceSanitizer) checkNotAssigningPackageVariable(expr ast.Expr) {
+	for {
+		switch e := expr.(type) {
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.Ident:
+			// the parser resolves identifiers of the file, a local variable of the same name is declared by another spec
+			if e.Obj != nil && s.packageVariables[e.Obj.Decl] {
+				s.reject(e.Pos(), "assigning to package level variable '%s' is not allowed, registrations are shared by all calls", e.Name)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package native
+
+import (
+	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSanitizeDeployedSourceCode_AllowsCounterContract(t *testing.T) {
+	code := string(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM))
+
+	sanitized, err := sanitizeDeployedSourceCode(code)
+	require.NoError(t, err, "counter contract should pass sanitization")
+	require.Equal(t, code, sanitized, "sanitized code should be unchanged")
+}
+
+func TestSanitizeDeployedSourceCode_AllowsPureImportsAndRenamedSdk(t *testing.T) {
+	code := `package main
+
+import (
+	"math"
+	orbs "github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"strings"
+)
+
+var CONTRACT = orbs.ContractInfo{}
+
+func sqrt(s string) float64 {
+	return math.Sqrt(float64(len(strings.TrimSpace(s))))
+}
+`
+	_, err := sanitizeDeployedSourceCode(code)
+	require.NoError(t, err, "pure imports should pass sanitization")
+}
+
+func TestSanitizeDeployedSourceCode_RejectsUnsafeCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		code          string
+		expectedError string
+	}{
+		{"os import", "package main\n\nimport \"os\"\n", `contract.go:3:8: import "os" is not allowed`},
+		{"net import", "package main\n\nimport \"net/http\"\n", `contract.go:3:8: import "net/http" is not allowed`},
+		{"syscall import", "package main\n\nimport \"syscall\"\n", `contract.go:3:8: import "syscall" is not allowed`},
+		{"unsafe import", "package main\n\nimport \"unsafe\"\n", `contract.go:3:8: import "unsafe" is not allowed`},
+		{"cgo", "package main\n\n// #include <stdlib.h>\nimport \"C\"\n", `contract.go:4:8: import "C" is not allowed`},
+		{"fmt import", "package main\n\nimport \"fmt\"\n", `contract.go:3:8: import "fmt" is not allowed`},
+		{"dot import", "package main\n\nimport . \"strings\"\n", `contract.go:3:8: import "strings" must not be a dot or blank import`},
+		{"goroutine", "package main\n\nfunc f() {\n\tgo f()\n}\n", `contract.go:4:2: goroutines are not allowed`},
+		{"global variable", "package main\n\nvar counter = 0\n", `contract.go:3:5: package level variable 'counter' is not allowed`},
+		{"uninitialized global variable", "package main\n\nvar cache map[string]string\n", `contract.go:3:5: package level variable 'cache' is not allowed`},
+		{"registration field set by a call", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar CONTRACT = sdk.ContractInfo{Name: name()}\n", `contract.go:5:39: registration fields must be constants`},
+		{"registration map key set by a call", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar CONTRACT = sdk.ContractInfo{Methods: map[string]sdk.MethodInfo{name(): {}}}\n", `contract.go:5:68: registration map keys must be constants`},
+		{"registration field set by a function literal", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar METHOD = sdk.MethodInfo{Implementation: func() {}}\n", `contract.go:5:45: registration fields must be constants`},
+		{"contract singleton with a field", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar CONTRACT = sdk.ContractInfo{InitSingleton: newContract}\n\nfunc newContract(base *sdk.BaseContract) sdk.ContractInstance {\n\treturn &contract{base, 0}\n}\n\ntype contract struct {\n\t*sdk.BaseContract\n\tcalls int\n}\n", `contract.go:13:2: contract field 'calls' is not allowed`},
+		{"contract singleton not returned as a literal", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar CONTRACT = sdk.ContractInfo{InitSingleton: newContract}\n\nfunc newContract(base *sdk.BaseContract) sdk.ContractInstance {\n\treturn base\n}\n", `contract.go:8:9: InitSingleton must return a literal of a struct declared in the contract`},
+		{"contract singleton embedding a contract type", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar CONTRACT = sdk.ContractInfo{InitSingleton: newContract}\n\nfunc newContract(base *sdk.BaseContract) sdk.ContractInstance {\n\treturn &contract{base, &counter{}}\n}\n\ntype counter struct {\n\tcalls int\n}\n\ntype contract struct {\n\t*sdk.BaseContract\n\t*counter\n}\n", `contract.go:17:2: embedded contract field is not allowed`},
+		{"assignment to a registration", "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar METHOD = sdk.MethodInfo{Name: \"method\"}\n\nfunc method() {\n\tMETHOD.Name = \"other\"\n}\n", `contract.go:8:2: assigning to package level variable 'METHOD' is not allowed`},
+		{"init function", "package main\n\nfunc init() {\n}\n", `contract.go:3:1: init functions are not allowed`},
+		{"syntax error", "package main\n\nfunc {\n", `contract.go:3:6`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sanitizeDeployedSourceCode(tt.code)
+			require.Error(t, err, "code should fail sanitization")
+			require.Contains(t, err.Error(), tt.expectedError, "error should point at the offending position")
+		})
+	}
+}
+
+func TestSanitizeDeployedSourceCode_AllowsLocalVariableNamedLikeRegistration(t *testing.T) {
+	code := "package main\n\nimport \"github.com/orbs-network/orbs-contract-sdk/go/sdk\"\n\nvar METHOD = sdk.MethodInfo{Name: \"method\"}\n\nfunc method() string {\n\tMETHOD := \"local\"\n\tMETHOD = METHOD + \"!\"\n\treturn METHOD\n}\n"
+
+	_, err := sanitizeDeployedSourceCode(code)
+	require.NoError(t, err, "assigning to a local variable should pass sanitization")
+}
+
+func TestSanitizeDeployedSourceCode_ReportsAllViolations(t *testing.T) {
+	code := "package main\n\nimport \"os\"\n\nvar counter = 0\n"
+
+	_, err := sanitizeDeployedSourceCode(code)
+	require.Error(t, err, "code should fail sanitization")
+	require.Contains(t, err.Error(), "contract.go:3:8", "import violation should be reported")
+	require.Contains(t, err.Error(), "contract.go:5:5", "global variable violation should be reported")
+}
@@ -0,0 +1,84 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+)
+
+// the pinned contract sdk has no Env, so native contracts can't reach it and processors of other languages call it directly
+type EnvSdk struct {
+	handler         handlers.ContractSdkCallHandler
+	permissionScope protocol.ExecutionPermissionScope
+}
+
+func NewEnvSdk(handler handlers.ContractSdkCallHandler, permissionScope protocol.ExecutionPermissionScope) *EnvSdk {
+	return &EnvSdk{handler, permissionScope}
+}
+
+const SDK_OPERATION_NAME_ENV = "Sdk.Env"
+
+func (s *EnvSdk) GetBlockHeight(ctx context.Context, executionContextId sdk.Context) (uint64, error) {
+	output, err := s.call(ctx, executionContextId, "getBlockHeight")
+	if err != nil {
+		return 0, err
+	}
+	if !output.IsTypeUint64Value() {
+		return 0, errors.Errorf("getBlockHeight Sdk.Env returned corrupt output value")
+	}
+	return output.Uint64Value(), nil
+}
+
+func (s *EnvSdk) GetBlockTimestamp(ctx context.Context, executionContextId sdk.Context) (uint64, error) {
+	output, err := s.call(ctx, executionContextId, "getBlockTimestamp")
+	if err != nil {
+		return 0, err
+	}
+	if !output.IsTypeUint64Value() {
+		return 0, errors.Errorf("getBlockTimestamp Sdk.Env returned corrupt output value")
+	}
+	return output.Uint64Value(), nil
+}
+
+func (s *EnvSdk) GetVirtualChainId(ctx context.Context, executionContextId sdk.Context) (uint32, error) {
+	output, err := s.call(ctx, executionContextId, "getVirtualChainId")
+	if err != nil {
+		return 0, err
+	}
+	if !output.IsTypeUint32Value() {
+		return 0, errors.Errorf("getVirtualChainId Sdk.Env returned corrupt output value")
+	}
+	return output.Uint32Value(), nil
+}
+
+func (s *EnvSdk) GetTxHash(ctx context.Context, executionContextId sdk.Context) ([]byte, error) {
+	output, err := s.call(ctx, executionContextId, "getTxHash")
+	if err != nil {
+		return nil, err
+	}
+	if !output.IsTypeBytesValue() {
+		return nil, errors.Errorf("getTxHash Sdk.Env returned corrupt output value")
+	}
+	return output.BytesValue(), nil
+}
+
+// every env method takes no args and returns a single value
+func (s *EnvSdk) call(ctx context.Context, executionContextId sdk.Context, methodName primitives.MethodName) (*protocol.MethodArgument, error) {
+	output, err := s.handler.HandleSdkCall(ctx, &handlers.HandleSdkCallInput{
+		ContextId:       primitives.ExecutionContextId(executionContextId),
+		OperationName:   SDK_OPERATION_NAME_ENV,
+		MethodName:      methodName,
+		InputArguments:  []*protocol.MethodArgument{},
+		PermissionScope: s.permissionScope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.OutputArguments) != 1 {
+		return nil, errors.Errorf("%s Sdk.Env returned corrupt output value", methodName)
+	}
+	return output.OutputArguments[0], nil
+}
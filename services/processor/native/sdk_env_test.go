@@ -0,0 +1,63 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetBlockHeightAndTimestamp(t *testing.T) {
+	s := createEnvSdk()
+
+	blockHeight, err := s.GetBlockHeight(context.Background(), EXAMPLE_CONTEXT)
+	require.NoError(t, err, "call should be successful")
+	require.EqualValues(t, 12, blockHeight, "block height should be returned")
+
+	blockTimestamp, err := s.GetBlockTimestamp(context.Background(), EXAMPLE_CONTEXT)
+	require.NoError(t, err, "call should be successful")
+	require.EqualValues(t, 1540000000000000000, blockTimestamp, "block timestamp should be returned")
+}
+
+func TestGetVirtualChainIdAndTxHash(t *testing.T) {
+	s := createEnvSdk()
+
+	virtualChainId, err := s.GetVirtualChainId(context.Background(), EXAMPLE_CONTEXT)
+	require.NoError(t, err, "call should be successful")
+	require.EqualValues(t, 42, virtualChainId, "virtual chain id should be returned")
+
+	txHash, err := s.GetTxHash(context.Background(), EXAMPLE_CONTEXT)
+	require.NoError(t, err, "call should be successful")
+	require.Equal(t, []byte{0x01, 0x02, 0x03}, txHash, "tx hash should be returned")
+}
+
+func createEnvSdk() *EnvSdk {
+	return &EnvSdk{
+		handler:         &contractSdkEnvCallHandlerStub{},
+		permissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+	}
+}
+
+type contractSdkEnvCallHandlerStub struct {
+}
+
+func (c *contractSdkEnvCallHandlerStub) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCallInput) (*handlers.HandleSdkCallOutput, error) {
+	if input.PermissionScope != protocol.PERMISSION_SCOPE_SERVICE {
+		panic("permissions passed to SDK are incorrect")
+	}
+	switch input.MethodName {
+	case "getBlockHeight":
+		return &handlers.HandleSdkCallOutput{OutputArguments: builders.MethodArguments(uint64(12))}, nil
+	case "getBlockTimestamp":
+		return &handlers.HandleSdkCallOutput{OutputArguments: builders.MethodArguments(uint64(1540000000000000000))}, nil
+	case "getVirtualChainId":
+		return &handlers.HandleSdkCallOutput{OutputArguments: builders.MethodArguments(uint32(42))}, nil
+	case "getTxHash":
+		return &handlers.HandleSdkCallOutput{OutputArguments: builders.MethodArguments([]byte{0x01, 0x02, 0x03})}, nil
+	default:
+		return nil, errors.New("unknown method")
+	}
+}
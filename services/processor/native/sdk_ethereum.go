@@ -0,0 +1,115 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+)
+
+// the pinned contract sdk has no Ethereum, so native contracts can't reach it and processors of other languages call it directly
+type EthereumSdk struct {
+	handler         handlers.ContractSdkCallHandler
+	permissionScope protocol.ExecutionPermissionScope
+}
+
+func NewEthereumSdk(handler handlers.ContractSdkCallHandler, permissionScope protocol.ExecutionPermissionScope) *EthereumSdk {
+	return &EthereumSdk{handler, permissionScope}
+}
+
+const SDK_OPERATION_NAME_ETHEREUM = "Sdk.Ethereum"
+
+func (s *EthereumSdk) CallMethod(ctx context.Context, executionContextId sdk.Context, contractAddress string, jsonAbi string, methodName string, blockNumber uint64, args ...interface{}) ([]interface{}, error) {
+	output, err := s.handler.HandleSdkCall(ctx, &handlers.HandleSdkCallInput{
+		ContextId:     primitives.ExecutionContextId(executionContextId),
+		OperationName: SDK_OPERATION_NAME_ETHEREUM,
+		MethodName:    "callMethod",
+		InputArguments: []*protocol.MethodArgument{
+			(&protocol.MethodArgumentBuilder{
+				Name:        "contractAddress",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: contractAddress,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "jsonAbi",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: jsonAbi,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "methodName",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: methodName,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "blockNumber",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE,
+				Uint64Value: blockNumber,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:       "inputArgs",
+				Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
+				BytesValue: ArgsToMethodArgumentArray(args...).Raw(),
+			}).Build(),
+		},
+		PermissionScope: s.permissionScope,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(output.OutputArguments) != 1 || !output.OutputArguments[0].IsTypeBytesValue() {
+		return nil, errors.Errorf("callMethod Sdk.Ethereum returned corrupt output value")
+	}
+	methodArgumentArray := protocol.MethodArgumentArrayReader(output.OutputArguments[0].BytesValue())
+	return methodArgumentArrayToArgs(methodArgumentArray), nil
+}
+
+func (s *EthereumSdk) GetTransactionLog(ctx context.Context, executionContextId sdk.Context, contractAddress string, jsonAbi string, eventName string, txHash string, logIndex uint32, blockNumber uint64) (ethereumBlockNumber uint64, eventArgs []interface{}, err error) {
+	output, err := s.handler.HandleSdkCall(ctx, &handlers.HandleSdkCallInput{
+		ContextId:     primitives.ExecutionContextId(executionContextId),
+		OperationName: SDK_OPERATION_NAME_ETHEREUM,
+		MethodName:    "getTransactionLog",
+		InputArguments: []*protocol.MethodArgument{
+			(&protocol.MethodArgumentBuilder{
+				Name:        "contractAddress",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: contractAddress,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "jsonAbi",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: jsonAbi,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "eventName",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: eventName,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "txHash",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: txHash,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "logIndex",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE,
+				Uint32Value: logIndex,
+			}).Build(),
+			(&protocol.MethodArgumentBuilder{
+				Name:        "blockNumber",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE,
+				Uint64Value: blockNumber,
+			}).Build(),
+		},
+		PermissionScope: s.permissionScope,
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	if len(output.OutputArguments) != 2 || !output.OutputArguments[0].IsTypeUint64Value() || !output.OutputArguments[1].IsTypeBytesValue() {
+		return 0, nil, errors.Errorf("getTransactionLog Sdk.Ethereum returned corrupt output value")
+	}
+	methodArgumentArray := protocol.MethodArgumentArrayReader(output.OutputArguments[1].BytesValue())
+	return output.OutputArguments[0].Uint64Value(), methodArgumentArrayToArgs(methodArgumentArray), nil
+}
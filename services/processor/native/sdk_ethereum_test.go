@@ -0,0 +1,74 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const EXAMPLE_ETHEREUM_ADDRESS = "0x00112233445566778899aabbccddeeff00112233"
+const EXAMPLE_ETHEREUM_TXHASH = "0x1122334455667788990011223344556677889900112233445566778899001122"
+const EXAMPLE_JSON_ABI = `[{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint64"}]}]`
+
+func TestEthereumCallMethod(t *testing.T) {
+	s := createEthereumSdk()
+
+	res, err := s.CallMethod(context.Background(), EXAMPLE_CONTEXT, EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "balanceOf", 400, []byte{0x01, 0x02})
+	require.NoError(t, err, "callMethod should succeed")
+	require.Equal(t, []interface{}{uint64(1000)}, res, "callMethod result should match expected")
+}
+
+func TestEthereumGetTransactionLog(t *testing.T) {
+	s := createEthereumSdk()
+
+	ethereumBlockNumber, res, err := s.GetTransactionLog(context.Background(), EXAMPLE_CONTEXT, EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "Locked", EXAMPLE_ETHEREUM_TXHASH, 7, 400)
+	require.NoError(t, err, "getTransactionLog should succeed")
+	require.EqualValues(t, 390, ethereumBlockNumber, "block number of the log should match expected")
+	require.Equal(t, []interface{}{uint64(500), []byte{0x01, 0x02, 0x03}}, res, "getTransactionLog result should match expected")
+}
+
+func createEthereumSdk() *EthereumSdk {
+	return &EthereumSdk{
+		handler:         &contractSdkEthereumCallHandlerStub{},
+		permissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+	}
+}
+
+type contractSdkEthereumCallHandlerStub struct {
+}
+
+func (c *contractSdkEthereumCallHandlerStub) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCallInput) (*handlers.HandleSdkCallOutput, error) {
+	if input.PermissionScope != protocol.PERMISSION_SCOPE_SERVICE {
+		panic("permissions passed to SDK are incorrect")
+	}
+	switch input.MethodName {
+	case "callMethod":
+		if len(input.InputArguments) != 5 ||
+			input.InputArguments[0].StringValue() != EXAMPLE_ETHEREUM_ADDRESS ||
+			input.InputArguments[1].StringValue() != EXAMPLE_JSON_ABI ||
+			input.InputArguments[2].StringValue() != "balanceOf" ||
+			input.InputArguments[3].Uint64Value() != 400 {
+			return nil, errors.New("unexpected args")
+		}
+		return &handlers.HandleSdkCallOutput{
+			OutputArguments: builders.MethodArguments(builders.MethodArgumentsArray(uint64(1000)).Raw()),
+		}, nil
+	case "getTransactionLog":
+		if len(input.InputArguments) != 6 ||
+			input.InputArguments[2].StringValue() != "Locked" ||
+			input.InputArguments[3].StringValue() != EXAMPLE_ETHEREUM_TXHASH ||
+			input.InputArguments[4].Uint32Value() != 7 ||
+			input.InputArguments[5].Uint64Value() != 400 {
+			return nil, errors.New("unexpected args")
+		}
+		return &handlers.HandleSdkCallOutput{
+			OutputArguments: builders.MethodArguments(uint64(390), builders.MethodArgumentsArray(uint64(500), []byte{0x01, 0x02, 0x03}).Raw()),
+		}, nil
+	default:
+		return nil, errors.New("unknown method")
+	}
+}
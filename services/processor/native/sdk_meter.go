@@ -0,0 +1,23 @@
+package native
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+)
+
+// processors that run untrusted code measure it themselves and charge it to the execution budget of the call,
+// this is not an sdk operation so contracts can't reach it through HandleSdkCall (the virtual machine implements
+// it next to handlers.ContractSdkCallHandler)
+type ExecutionUnitsCharger interface {
+	ChargeExecutionUnits(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error
+}
+
+func ChargeExecutionUnits(ctx context.Context, handler handlers.ContractSdkCallHandler, executionContextId primitives.ExecutionContextId, units uint64) error {
+	charger, ok := handler.(ExecutionUnitsCharger)
+	if !ok {
+		return errors.New("contract sdk call handler can't charge execution units")
+	}
+	return charger.ChargeExecutionUnits(ctx, executionContextId, units)
+}
@@ -35,7 +35,7 @@ func (s *serviceSdk) CallMethod(executionContextId sdk.Context, serviceName stri
 			(&protocol.MethodArgumentBuilder{
 				Name:       "inputArgs",
 				Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
-				BytesValue: argsToMethodArgumentArray(args...).Raw(),
+				BytesValue: ArgsToMethodArgumentArray(args...).Raw(),
 			}).Build(),
 		},
 		PermissionScope: s.permissionScope,
@@ -50,23 +50,6 @@ func (s *serviceSdk) CallMethod(executionContextId sdk.Context, serviceName stri
 	return methodArgumentArrayToArgs(methodArgumentArray), nil
 }
 
-func argsToMethodArgumentArray(args ...interface{}) *protocol.MethodArgumentArray {
-	res := []*protocol.MethodArgumentBuilder{}
-	for _, arg := range args {
-		switch arg.(type) {
-		case uint32:
-			res = append(res, &protocol.MethodArgumentBuilder{Name: "uint32", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, Uint32Value: arg.(uint32)})
-		case uint64:
-			res = append(res, &protocol.MethodArgumentBuilder{Name: "uint64", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: arg.(uint64)})
-		case string:
-			res = append(res, &protocol.MethodArgumentBuilder{Name: "string", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: arg.(string)})
-		case []byte:
-			res = append(res, &protocol.MethodArgumentBuilder{Name: "bytes", Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: arg.([]byte)})
-		}
-	}
-	return (&protocol.MethodArgumentArrayBuilder{Arguments: res}).Build()
-}
-
 func methodArgumentArrayToArgs(methodArgumentArray *protocol.MethodArgumentArray) []interface{} {
 	res := []interface{}{}
 	for i := methodArgumentArray.ArgumentsIterator(); i.HasNext(); {
@@ -7,6 +7,7 @@ import (
 	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
@@ -22,8 +23,9 @@ type service struct {
 
 	mutex                         *sync.RWMutex
 	contractSdkHandlerUnderMutex  handlers.ContractSdkCallHandler
-	contractInstancesUnderMutex   map[string]sdk.ContractInstance
-	deployableContractsUnderMutex map[string]*sdk.ContractInfo
+	contractInstancesUnderMutex   map[*sdk.ContractInfo]sdk.ContractInstance
+	deployableContractsUnderMutex map[string]*sdk.ContractInfo // by code hash, old versions are kept since loaded plugins can't be unloaded anyway
+	quarantine                    *contractQuarantine
 
 	metrics *metrics
 }
@@ -32,6 +34,7 @@ type metrics struct {
 	deployedContracts       *metric.Gauge
 	processCallTime         *metric.Histogram
 	contractCompilationTime *metric.Histogram
+	abandonedCalls          *metric.Gauge
 }
 
 func getMetrics(m metric.Factory) *metrics {
@@ -39,6 +42,7 @@ func getMetrics(m metric.Factory) *metrics {
 		deployedContracts:       m.NewGauge("Processor.Native.DeployedContractsNumber"),
 		processCallTime:         m.NewLatency("Processor.Native.ProcessCallTime", 10*time.Second),
 		contractCompilationTime: m.NewLatency("Processor.Native.ContractCompilationTime", 10*time.Second),
+		abandonedCalls:          m.NewGauge("Processor.Native.AbandonedCallsNumber"),
 	}
 }
 
@@ -48,10 +52,11 @@ func NewNativeProcessor(
 	metricFactory metric.Factory,
 ) services.Processor {
 	return &service{
-		compiler: compiler,
-		logger:   logger.WithTags(LogTag),
-		mutex:    &sync.RWMutex{},
-		metrics:  getMetrics(metricFactory),
+		compiler:   compiler,
+		logger:     logger.WithTags(LogTag),
+		mutex:      &sync.RWMutex{},
+		quarantine: newContractQuarantine(),
+		metrics:    getMetrics(metricFactory),
 	}
 }
 
@@ -61,6 +66,9 @@ func (s *service) RegisterContractSdkCallHandler(handler handlers.ContractSdkCal
 	defer s.mutex.Unlock()
 
 	s.contractSdkHandlerUnderMutex = handler
+	if registrar, ok := s.compiler.(adapter.ContractSdkCallHandlerRegistrar); ok {
+		registrar.RegisterContractSdkCallHandler(handler) // contracts executed out of process call the sdk through the compiler
+	}
 
 	if s.contractInstancesUnderMutex == nil && s.deployableContractsUnderMutex == nil {
 		s.contractInstancesUnderMutex = initializePreBuiltRepositoryContractInstances(handler)
@@ -73,6 +81,12 @@ func (s *service) ProcessCall(ctx context.Context, input *services.ProcessCallIn
 	// retrieve code
 	executionContextId := sdk.Context(input.ContextId)
 	contractInfo, methodInfo, err := s.retrieveContractAndMethodInfoFromRepository(ctx, executionContextId, string(input.ContractName), string(input.MethodName))
+	if contractInfo != nil && methodInfo == nil && input.MethodName == METHOD_NAME_PRE_ORDER { // the contract has no pre-order hook
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: (&protocol.MethodArgumentArrayBuilder{}).Build(),
+			CallResult:          protocol.EXECUTION_RESULT_SUCCESS,
+		}, nil
+	}
 	if err != nil {
 		return &services.ProcessCallOutput{
 			// TODO: do we need to remove system errors from OutputArguments? https://github.com/orbs-network/orbs-spec/issues/97
@@ -91,16 +105,42 @@ func (s *service) ProcessCall(ctx context.Context, input *services.ProcessCallIn
 		}, err
 	}
 
+	// a quarantined contract fails like it would if it ran into the time limit again, the virtual machine turns this
+	// into a fault of the node for calls of block execution so receipts don't depend on what else the node ran
+	if _, timed := ctx.Deadline(); timed && s.quarantine.isQuarantined(contractInfo.Name) {
+		logger.Info("quarantined contract not executed", log.String("contract", contractInfo.Name), log.String("method", methodInfo.Name))
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: s.createMethodOutputArgsWithString(ErrContractQuarantined.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT,
+		}, ErrContractQuarantined
+	}
+
 	start := time.Now()
 	defer s.metrics.processCallTime.RecordSince(start)
 
 	// execute
 	logger.Info("processor executing contract", log.String("contract", contractInfo.Name), log.String("method", methodInfo.Name))
 
-	outputArgs, contractErr, err := s.processMethodCall(executionContextId, contractInfo, methodInfo, input.InputArgumentArray)
+	outputArgs, contractErr, err := s.processMethodCallUntilDone(ctx, executionContextId, contractInfo, methodInfo, input.InputArgumentArray)
 	if outputArgs == nil {
 		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
 	}
+	if err == ErrContractTimeout {
+		logger.Error("contract execution timed out, contract quarantined until the call returns", log.String("contract", contractInfo.Name), log.String("method", methodInfo.Name))
+
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: s.createMethodOutputArgsWithString(err.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT,
+		}, err
+	}
+	if IsNodeFault(err) {
+		logger.Error("contract executor failed, the call has no result", log.String("contract", contractInfo.Name), log.String("method", methodInfo.Name), log.Error(err))
+
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: s.createMethodOutputArgsWithString(err.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+		}, err
+	}
 	if err != nil {
 		logger.Info("contract execution failed", log.Error(err))
 
@@ -138,6 +178,19 @@ func (s *service) GetContractInfo(ctx context.Context, input *services.GetContra
 	}, nil
 }
 
+func (s *service) DescribeContract(ctx context.Context, input *services.GetContractInfoInput) (*DescribeContractOutput, error) {
+	executionContextId := sdk.Context(input.ContextId)
+	contractInfo, err := s.retrieveContractInfoFromRepository(ctx, executionContextId, string(input.ContractName))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DescribeContractOutput{
+		PermissionScope: protocol.ExecutionPermissionScope(contractInfo.Permission),
+		Methods:         describeExternalMethods(contractInfo),
+	}, nil
+}
+
 func (s *service) getContractSdkHandler() handlers.ContractSdkCallHandler {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
@@ -145,42 +198,42 @@ func (s *service) getContractSdkHandler() handlers.ContractSdkCallHandler {
 	return s.contractSdkHandlerUnderMutex
 }
 
-func (s *service) getContractInstanceFromRepository(contractName string) sdk.ContractInstance {
+func (s *service) getContractInstanceFromRepository(contractInfo *sdk.ContractInfo) sdk.ContractInstance {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	if s.contractInstancesUnderMutex == nil {
 		return nil
 	}
-	return s.contractInstancesUnderMutex[contractName]
+	return s.contractInstancesUnderMutex[contractInfo]
 }
 
-func (s *service) addContractInstanceToRepository(contractName string, contractInstance sdk.ContractInstance) {
+func (s *service) addContractInstanceToRepository(contractInfo *sdk.ContractInfo, contractInstance sdk.ContractInstance) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if s.contractInstancesUnderMutex == nil {
 		return
 	}
-	s.contractInstancesUnderMutex[contractName] = contractInstance
+	s.contractInstancesUnderMutex[contractInfo] = contractInstance
 }
 
-func (s *service) getDeployableContractInfoFromRepository(contractName string) *sdk.ContractInfo {
+func (s *service) getDeployableContractInfoFromRepository(codeHash primitives.Sha256) *sdk.ContractInfo {
 	s.mutex.RLock()
 	defer s.mutex.RUnlock()
 
 	if s.deployableContractsUnderMutex == nil {
 		return nil
 	}
-	return s.deployableContractsUnderMutex[contractName]
+	return s.deployableContractsUnderMutex[codeHash.KeyForMap()]
 }
 
-func (s *service) addDeployableContractInfoToRepository(contractName string, contractInfo *sdk.ContractInfo) {
+func (s *service) addDeployableContractInfoToRepository(codeHash primitives.Sha256, contractInfo *sdk.ContractInfo) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if s.deployableContractsUnderMutex == nil {
 		return
 	}
-	s.deployableContractsUnderMutex[contractName] = contractInfo
+	s.deployableContractsUnderMutex[codeHash.KeyForMap()] = contractInfo
 }
@@ -2,10 +2,12 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -15,7 +17,7 @@ func TestProcessCall_WithUnknownContractFails(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
 		input := processCallInput().WithUnknownContract().Build()
-		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
+		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
 
 		_, err := h.service.ProcessCall(ctx, input)
 		require.Error(t, err, "call should fail")
@@ -28,7 +30,7 @@ func TestGetContractInfo_WithUnknownContractFails(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
 		input := getContractInfoInput().WithUnknownContract().Build()
-		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
+		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(input.ContractName)), nil, errors.New("code not found error"))
 
 		_, err := h.service.GetContractInfo(ctx, input)
 		require.Error(t, err, "GetContractInfo should fail")
@@ -37,12 +39,23 @@ func TestGetContractInfo_WithUnknownContractFails(t *testing.T) {
 	})
 }
 
+func TestProcessCall_UndefinedPreOrderHookApproves(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("BenchmarkContract", native.METHOD_NAME_PRE_ORDER).Build()
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult, "call result should be success")
+	})
+}
+
 func TestProcessCall_WithDeployableContractThatCompiles(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
 		input := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
-		codeOutput := builders.MethodArgumentsArray([]byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM)))
-		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(input.ContractName)), codeOutput, nil)
+		code := []byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM))
+		h.expectCounterCodeRequested(input, code)
 
 		output, err := h.service.ProcessCall(ctx, input)
 		require.NoError(t, err, "call should succeed")
@@ -51,6 +64,8 @@ func TestProcessCall_WithDeployableContractThatCompiles(t *testing.T) {
 		t.Log("First call (not compiled) should getCode for compilation")
 		h.verifySdkCallMade(t)
 
+		h.expectCodeHashRequested(input, code)
+
 		output, err = h.service.ProcessCall(ctx, input)
 		require.NoError(t, err, "call should succeed")
 		require.Equal(t, contracts.MOCK_COUNTER_CONTRACT_START_FROM, output.OutputArgumentArray.ArgumentsIterator().NextArguments().Uint64Value(), "call return value should be counter value")
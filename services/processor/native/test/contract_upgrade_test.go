@@ -0,0 +1,155 @@
+package test
+
+import (
+	"context"
+	"crypto/sha256"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProcessCall_UpgradedContractIsRecompiledOnceItsCodeHashChanges(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		counterCall := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
+		code := []byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM))
+		upgradedCode := []byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM + 1))
+		h.compiler.ProvideFakeContract(contracts.MockForCounter(), string(upgradedCode))
+		ownerAddress := builders.AddressForEd25519SignerForTests(0)
+
+		t.Log("First call compiles the contract")
+		h.expectCounterCodeRequested(counterCall, code)
+		_, err := h.service.ProcessCall(ctx, counterCall)
+		require.NoError(t, err, "call should succeed")
+		h.verifySdkCallMade(t)
+
+		t.Log("Owner upgrades the contract")
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Processor"), uint32ToBytes(uint32(protocol.PROCESSOR_TYPE_NATIVE)))
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Owner"), ownerAddress)
+		h.expectSdkCallMadeWithAddressGetSigner(ownerAddress)
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Version"), uint32ToBytes(1))
+		h.expectSdkCallMadeWithStateWrite(deploymentsKey(counterCall.ContractName, "Code.2"), upgradedCode)
+		h.expectSdkCallMadeWithStateWrite(deploymentsKey(counterCall.ContractName, "CodeHash"), codeHash(upgradedCode))
+		h.expectSdkCallMadeWithStateWrite(deploymentsKey(counterCall.ContractName, "Version"), uint32ToBytes(2))
+		_, err = h.service.ProcessCall(ctx, upgradeServiceCall(counterCall.ContractName, protocol.PROCESSOR_TYPE_NATIVE, upgradedCode))
+		require.NoError(t, err, "upgrade should succeed")
+		h.verifySdkCallMade(t)
+
+		t.Log("A call that does not see the upgrade yet (not committed) keeps using the compiled contract")
+		h.expectCodeHashRequested(counterCall, code)
+		_, err = h.service.ProcessCall(ctx, counterCall)
+		require.NoError(t, err, "call should succeed")
+		h.verifySdkCallMade(t)
+
+		t.Log("A call that sees the upgraded code compiles it")
+		h.expectCounterCodeRequested(counterCall, upgradedCode)
+		_, err = h.service.ProcessCall(ctx, counterCall)
+		require.NoError(t, err, "call should succeed")
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_UpgradeServiceByNonOwnerFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		counterCall := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
+		code := []byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM))
+
+		h.expectCounterCodeRequested(counterCall, code)
+		_, err := h.service.ProcessCall(ctx, counterCall)
+		require.NoError(t, err, "call should succeed")
+		h.verifySdkCallMade(t)
+
+		t.Log("Somebody else attempts to upgrade the contract")
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Processor"), uint32ToBytes(uint32(protocol.PROCESSOR_TYPE_NATIVE)))
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Owner"), builders.AddressForEd25519SignerForTests(0))
+		h.expectSdkCallMadeWithAddressGetSigner(builders.AddressForEd25519SignerForTests(1))
+		_, err = h.service.ProcessCall(ctx, upgradeServiceCall(counterCall.ContractName, protocol.PROCESSOR_TYPE_NATIVE, code))
+		require.Error(t, err, "upgrade by non owner should fail")
+		h.verifySdkCallMade(t)
+
+		t.Log("Make sure the compiled contract was kept")
+		h.expectCodeHashRequested(counterCall, code)
+		_, err = h.service.ProcessCall(ctx, counterCall)
+		require.NoError(t, err, "call should succeed")
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_UpgradeServiceForAnotherProcessorFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		counterCall := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
+
+		h.expectSdkCallMadeWithStateRead(deploymentsKey(counterCall.ContractName, "Processor"), uint32ToBytes(uint32(protocol.PROCESSOR_TYPE_NATIVE)))
+		_, err := h.service.ProcessCall(ctx, upgradeServiceCall(counterCall.ContractName, protocol.PROCESSOR_TYPE_JAVASCRIPT, []byte("class CounterFrom100 {}")))
+		require.Error(t, err, "upgrade with code of another processor should fail")
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestValidateDeployedCode_AcceptsAuditedCodeWithoutCompilingIt(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		counterCall := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
+		uncompilableCode := []byte(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM + 5)) // not known to the fake compiler
+
+		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(counterCall.ContractName)), builders.MethodArgumentsArray(uncompilableCode), nil)
+		err := h.service.(native.DeployedCodeValidator).ValidateDeployedCode(ctx, &services.GetContractInfoInput{
+			ContextId:    counterCall.ContextId,
+			ContractName: counterCall.ContractName,
+		})
+		require.NoError(t, err, "code that passes the audit should be accepted regardless of the local compiler")
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestValidateDeployedCode_RejectsCodeThatFailsTheAudit(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		counterCall := processCallInput().WithDeployableCounterContract(contracts.MOCK_COUNTER_CONTRACT_START_FROM).Build()
+		code := []byte("package main\n\nimport \"os\"\n\nfunc f() { os.Exit(1) }\n")
+
+		h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(counterCall.ContractName)), builders.MethodArgumentsArray(code), nil)
+		err := h.service.(native.DeployedCodeValidator).ValidateDeployedCode(ctx, &services.GetContractInfoInput{
+			ContextId:    counterCall.ContextId,
+			ContractName: counterCall.ContractName,
+		})
+		require.Error(t, err, "code that fails the audit should be rejected")
+		h.verifySdkCallMade(t)
+	})
+}
+
+func (h *harness) expectCounterCodeRequested(counterCall *services.ProcessCallInput, code []byte) {
+	h.expectCodeHashRequested(counterCall, code)
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(counterCall.ContractName)), builders.MethodArgumentsArray(code), nil)
+}
+
+func (h *harness) expectCodeHashRequested(counterCall *services.ProcessCallInput, code []byte) {
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(counterCall.ContractName)), builders.MethodArgumentsArray(codeHash(code)), nil)
+}
+
+func codeHash(code []byte) []byte {
+	calculated := sha256.Sum256(code)
+	return calculated[:]
+}
+
+func upgradeServiceCall(contractName primitives.ContractName, processorType protocol.ProcessorType, code []byte) *services.ProcessCallInput {
+	return processCallInput().
+		WithMethod(primitives.ContractName(deployments_systemcontract.CONTRACT.Name), primitives.MethodName(deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name)).
+		WithArgs(string(contractName), uint32(processorType), code).
+		WithWriteAccess().
+		Build()
+}
+
+func deploymentsKey(contractName primitives.ContractName, suffix string) []byte {
+	return hash.CalcRipmd160Sha256([]byte(string(contractName) + "." + suffix))
+}
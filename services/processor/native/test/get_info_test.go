@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -45,3 +46,29 @@ func TestGetContractInfo(t *testing.T) {
 		})
 	}
 }
+
+func TestDescribeContract_DescribesExternalMethods(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		output, err := h.service.(native.ContractDescriber).DescribeContract(ctx, getContractInfoInput().WithRegularService().Build())
+		require.NoError(t, err, "DescribeContract should not fail")
+
+		methodNames := []string{}
+		for _, method := range output.Methods {
+			methodNames = append(methodNames, string(method.MethodName))
+		}
+		require.Equal(t, []string{"add", "argTypes", "get", "panic", "set", "throw"}, methodNames, "only valid external methods should be described, sorted by name")
+
+		argTypes := output.Methods[1]
+		require.Equal(t, protocol.ACCESS_SCOPE_READ_ONLY, argTypes.AccessScope, "access scope should match")
+		allTypes := []protocol.MethodArgumentType{protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE}
+		require.Equal(t, allTypes, argTypes.InputArgumentTypes, "input types should match")
+		require.Equal(t, allTypes, argTypes.OutputArgumentTypes, "output types should match")
+
+		set := output.Methods[4]
+		require.Equal(t, protocol.ACCESS_SCOPE_READ_WRITE, set.AccessScope, "access scope should match")
+		require.Equal(t, []protocol.MethodArgumentType{protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE}, set.InputArgumentTypes, "input types should match")
+		require.Empty(t, set.OutputArgumentTypes, "output types should not include the error")
+	})
+}
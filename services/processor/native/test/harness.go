@@ -20,6 +20,7 @@ import (
 
 type harness struct {
 	sdkCallHandler *handlers.MockContractSdkCallHandler
+	compiler       adapter.FakeCompiler
 	service        services.Processor
 }
 
@@ -38,6 +39,7 @@ func newHarness() *harness {
 
 	return &harness{
 		sdkCallHandler: sdkCallHandler,
+		compiler:       compiler,
 		service:        service,
 	}
 }
@@ -110,11 +112,32 @@ func (h *harness) expectSdkCallMadeWithAddressGetCaller(returnAddress []byte) {
 	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.Address, method equals getCallerAddress and 1 arg match", addressGetCallerCallMatcher)).Return(returnOutput, nil).Times(1)
 }
 
+func (h *harness) expectSdkCallMadeWithAddressGetSigner(returnAddress []byte) {
+	addressGetSignerCallMatcher := func(i interface{}) bool {
+		input, ok := i.(*handlers.HandleSdkCallInput)
+		return ok &&
+			input.OperationName == native.SDK_OPERATION_NAME_ADDRESS &&
+			input.MethodName == "getSignerAddress"
+	}
+
+	returnOutput := &handlers.HandleSdkCallOutput{
+		OutputArguments: builders.MethodArguments(returnAddress),
+	}
+
+	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.Address, method equals getSignerAddress", addressGetSignerCallMatcher)).Return(returnOutput, nil).Times(1)
+}
+
 func (h *harness) verifySdkCallMade(t *testing.T) {
 	_, err := h.sdkCallHandler.Verify()
 	require.NoError(t, err, "sdkCallHandler should be called as expected")
 }
 
+func uint32ToBytes(num uint32) []byte {
+	res := make([]byte, 4)
+	binary.LittleEndian.PutUint32(res, num)
+	return res
+}
+
 func uint64ToBytes(num uint64) []byte {
 	res := make([]byte, 8)
 	binary.LittleEndian.PutUint64(res, num)
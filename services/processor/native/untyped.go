@@ -0,0 +1,63 @@
+package native
+
+import (
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"strings"
+)
+
+// processors of untyped languages (javascript, wasm) share these so their contracts look exactly like native ones to callers
+
+// untyped languages have no method visibility, so like in go, methods starting with _ are internal
+func VerifyUntypedMethodPermissions(contractName primitives.ContractName, methodName primitives.MethodName, callingService primitives.ContractName, permissionScope protocol.ExecutionPermissionScope) error {
+	if !strings.HasPrefix(string(methodName), "_") {
+		return nil
+	}
+	if callingService.Equal(contractName) {
+		return nil
+	}
+	if permissionScope == protocol.PERMISSION_SCOPE_SYSTEM {
+		return nil
+	}
+	return errors.Errorf("internal method '%s' called from different service '%s' without system permissions", methodName, callingService)
+}
+
+func ArgsToMethodArgumentArray(args ...interface{}) *protocol.MethodArgumentArray {
+	res := []*protocol.MethodArgumentBuilder{}
+	for _, arg := range args {
+		switch arg.(type) {
+		case uint32:
+			res = append(res, &protocol.MethodArgumentBuilder{Name: "uint32", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, Uint32Value: arg.(uint32)})
+		case uint64:
+			res = append(res, &protocol.MethodArgumentBuilder{Name: "uint64", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: arg.(uint64)})
+		case string:
+			res = append(res, &protocol.MethodArgumentBuilder{Name: "string", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: arg.(string)})
+		case []byte:
+			res = append(res, &protocol.MethodArgumentBuilder{Name: "bytes", Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: arg.([]byte)})
+		}
+	}
+	return (&protocol.MethodArgumentArrayBuilder{Arguments: res}).Build()
+}
+
+// unlike native contracts, untyped contracts can't reject args of a type they don't know, so it's done here
+func ParseMethodArgumentArray(methodArgumentArray *protocol.MethodArgumentArray) ([]interface{}, error) {
+	res := []interface{}{}
+	i := 0
+	for iter := methodArgumentArray.ArgumentsIterator(); iter.HasNext(); i++ {
+		methodArgument := iter.NextArguments()
+		switch methodArgument.Type() {
+		case protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE:
+			res = append(res, methodArgument.Uint32Value())
+		case protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE:
+			res = append(res, methodArgument.Uint64Value())
+		case protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE:
+			res = append(res, methodArgument.StringValue())
+		case protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE:
+			res = append(res, methodArgument.BytesValue())
+		default:
+			return nil, errors.Errorf("arg %d has unsupported type %s", i, methodArgument.StringType())
+		}
+	}
+	return res, nil
+}
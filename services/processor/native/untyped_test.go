@@ -0,0 +1,20 @@
+package native
+
+import (
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestVerifyUntypedMethodPermissionsProtectsInternalMethods(t *testing.T) {
+	require.NoError(t, VerifyUntypedMethodPermissions("Counter", "add", "Other", protocol.PERMISSION_SCOPE_SERVICE), "external method should be callable by anyone")
+	require.NoError(t, VerifyUntypedMethodPermissions("Counter", "_init", "Counter", protocol.PERMISSION_SCOPE_SERVICE), "internal method should be callable by the same service")
+	require.NoError(t, VerifyUntypedMethodPermissions("Counter", "_init", "_Deployments", protocol.PERMISSION_SCOPE_SYSTEM), "internal method should be callable with system permissions")
+	require.Error(t, VerifyUntypedMethodPermissions("Counter", "_init", "Other", protocol.PERMISSION_SCOPE_SERVICE), "internal method should not be callable by other services")
+}
+
+func TestParseMethodArgumentArrayRoundTrips(t *testing.T) {
+	args, err := ParseMethodArgumentArray(ArgsToMethodArgumentArray(uint32(1), uint64(2), "three", []byte{4}))
+	require.NoError(t, err, "supported args should be parsed")
+	require.Equal(t, []interface{}{uint32(1), uint64(2), "three", []byte{4}}, args, "args should round trip")
+}
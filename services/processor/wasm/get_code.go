@@ -0,0 +1,37 @@
+package wasm
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/wasm/interpreter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+)
+
+func (s *service) retrieveContractFromRepository(ctx context.Context, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName) (*interpreter.Module, error) {
+	// 1. try artifact cache (if this version of the code was already compiled)
+	codeHash, err := native.CallGetCodeHashOfDeploymentSystemContract(ctx, s.getContractSdkHandler(), executionContextId, contractName)
+	if err != nil {
+		return nil, err
+	}
+	module := s.getContractFromRepository(codeHash)
+	if module != nil {
+		return module, nil
+	}
+
+	// 2. try deployable code from state
+	code, err := native.CallGetCodeOfDeploymentSystemContract(ctx, s.getContractSdkHandler(), executionContextId, contractName)
+	if err != nil {
+		return nil, err
+	}
+
+	module, err = interpreter.Compile(code)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not compile deployed contract '%s'", contractName)
+	}
+	s.addContractToRepository(codeHash, module)
+	s.logger.Info("loaded deployable contract successfully", log.Stringable("contract", contractName), log.Stringable("code-hash", codeHash))
+
+	return module, nil
+}
@@ -0,0 +1,422 @@
+package wasm
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/wasm/interpreter"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+)
+
+// contracts import their host functions from this module, pointers and lengths are i32 into the contract memory
+const HOST_MODULE_NAME = "orbs"
+
+// types returned by argType
+const (
+	ARG_TYPE_UINT32 = 0
+	ARG_TYPE_UINT64 = 1
+	ARG_TYPE_STRING = 2
+	ARG_TYPE_BYTES  = 3
+)
+
+var (
+	i32 = interpreter.I32
+	i64 = interpreter.I64
+)
+
+// serves a single method call, sdk calls are made with the permissions of the called contract
+type host struct {
+	ctx                context.Context
+	executionContextId sdk.Context
+	sdk                *sdk.BaseContract
+	env                *native.EnvSdk
+	ethereum           *native.EthereumSdk
+
+	inputArgs  []interface{}
+	outputArgs []interface{}
+	result     []byte // variable length values are returned here and copied out with resultRead
+}
+
+func newHost(ctx context.Context, executionContextId sdk.Context, handler handlers.ContractSdkCallHandler, inputArgs []interface{}) *host {
+	return &host{
+		ctx:                ctx,
+		executionContextId: executionContextId,
+		sdk:                native.NewSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+		env:                native.NewEnvSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+		ethereum:           native.NewEthereumSdk(handler, protocol.PERMISSION_SCOPE_SERVICE),
+		inputArgs:          inputArgs,
+	}
+}
+
+func (h *host) imports() interpreter.Imports {
+	return interpreter.Imports{HOST_MODULE_NAME: {
+		// args
+		"argCount":  hostFunction(nil, []interpreter.ValueType{i32}, h.argCount),
+		"argType":   hostFunction([]interpreter.ValueType{i32}, []interpreter.ValueType{i32}, h.argType),
+		"argUint32": hostFunction([]interpreter.ValueType{i32}, []interpreter.ValueType{i32}, h.argUint32),
+		"argUint64": hostFunction([]interpreter.ValueType{i32}, []interpreter.ValueType{i64}, h.argUint64),
+		"argLength": hostFunction([]interpreter.ValueType{i32}, []interpreter.ValueType{i32}, h.argLength),
+		"argRead":   hostFunction([]interpreter.ValueType{i32, i32}, nil, h.argRead),
+
+		// return values
+		"returnUint32": hostFunction([]interpreter.ValueType{i32}, nil, h.returnUint32),
+		"returnUint64": hostFunction([]interpreter.ValueType{i64}, nil, h.returnUint64),
+		"returnString": hostFunction([]interpreter.ValueType{i32, i32}, nil, h.returnString),
+		"returnBytes":  hostFunction([]interpreter.ValueType{i32, i32}, nil, h.returnBytes),
+		"fail":         hostFunction([]interpreter.ValueType{i32, i32}, nil, h.fail),
+		"resultRead":   hostFunction([]interpreter.ValueType{i32}, nil, h.resultRead),
+
+		// Sdk.State
+		"stateReadBytes":   hostFunction([]interpreter.ValueType{i32, i32}, []interpreter.ValueType{i32}, h.stateReadBytes),
+		"stateWriteBytes":  hostFunction([]interpreter.ValueType{i32, i32, i32, i32}, nil, h.stateWriteBytes),
+		"stateReadUint32":  hostFunction([]interpreter.ValueType{i32, i32}, []interpreter.ValueType{i32}, h.stateReadUint32),
+		"stateWriteUint32": hostFunction([]interpreter.ValueType{i32, i32, i32}, nil, h.stateWriteUint32),
+		"stateReadUint64":  hostFunction([]interpreter.ValueType{i32, i32}, []interpreter.ValueType{i64}, h.stateReadUint64),
+		"stateWriteUint64": hostFunction([]interpreter.ValueType{i32, i32, i64}, nil, h.stateWriteUint64),
+		"stateClear":       hostFunction([]interpreter.ValueType{i32, i32}, nil, h.stateClear),
+
+		// Sdk.Service, args and output are a raw MethodArgumentArray
+		"serviceCallMethod": hostFunction([]interpreter.ValueType{i32, i32, i32, i32, i32, i32}, []interpreter.ValueType{i32}, h.serviceCallMethod),
+
+		// Sdk.Address
+		"addressGetSigner": hostFunction(nil, []interpreter.ValueType{i32}, h.addressGetSigner),
+		"addressGetCaller": hostFunction(nil, []interpreter.ValueType{i32}, h.addressGetCaller),
+
+		// Sdk.Env
+		"envGetBlockHeight":    hostFunction(nil, []interpreter.ValueType{i64}, h.envGetBlockHeight),
+		"envGetBlockTimestamp": hostFunction(nil, []interpreter.ValueType{i64}, h.envGetBlockTimestamp),
+		"envGetVirtualChainId": hostFunction(nil, []interpreter.ValueType{i32}, h.envGetVirtualChainId),
+		"envGetTxHash":         hostFunction(nil, []interpreter.ValueType{i32}, h.envGetTxHash),
+
+		// Sdk.Ethereum, args and output are a raw MethodArgumentArray
+		"ethereumCallMethod":        hostFunction([]interpreter.ValueType{i32, i32, i32, i32, i32, i32, i64, i32, i32}, []interpreter.ValueType{i32}, h.ethereumCallMethod),
+		"ethereumGetTransactionLog": hostFunction([]interpreter.ValueType{i32, i32, i32, i32, i32, i32, i32, i32, i32, i64}, []interpreter.ValueType{i32}, h.ethereumGetTransactionLog),
+	}}
+}
+
+func hostFunction(params []interpreter.ValueType, results []interpreter.ValueType, call func(instance *interpreter.Instance, args []uint64) ([]uint64, error)) *interpreter.HostFunction {
+	return &interpreter.HostFunction{
+		Type: &interpreter.FuncType{Params: params, Results: results},
+		Call: call,
+	}
+}
+
+func (h *host) argCount(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	return []uint64{uint64(len(h.inputArgs))}, nil
+}
+
+func (h *host) argType(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	arg, err := h.inputArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	switch arg.(type) {
+	case uint32:
+		return []uint64{ARG_TYPE_UINT32}, nil
+	case uint64:
+		return []uint64{ARG_TYPE_UINT64}, nil
+	case string:
+		return []uint64{ARG_TYPE_STRING}, nil
+	default:
+		return []uint64{ARG_TYPE_BYTES}, nil
+	}
+}
+
+func (h *host) argUint32(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	arg, err := h.inputArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := arg.(uint32)
+	if !ok {
+		return nil, errors.Errorf("arg %d is not a uint32", args[0])
+	}
+	return []uint64{uint64(value)}, nil
+}
+
+func (h *host) argUint64(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	arg, err := h.inputArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	value, ok := arg.(uint64)
+	if !ok {
+		return nil, errors.Errorf("arg %d is not a uint64", args[0])
+	}
+	return []uint64{value}, nil
+}
+
+func (h *host) argLength(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.variableLengthInputArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{uint64(len(value))}, nil
+}
+
+func (h *host) argRead(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.variableLengthInputArg(args[0])
+	if err != nil {
+		return nil, err
+	}
+	return nil, instance.MemoryWrite(uint32(args[1]), value)
+}
+
+func (h *host) returnUint32(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	h.outputArgs = append(h.outputArgs, uint32(args[0]))
+	return nil, nil
+}
+
+func (h *host) returnUint64(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	h.outputArgs = append(h.outputArgs, args[0])
+	return nil, nil
+}
+
+func (h *host) returnString(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	h.outputArgs = append(h.outputArgs, string(value))
+	return nil, nil
+}
+
+func (h *host) returnBytes(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	h.outputArgs = append(h.outputArgs, value)
+	return nil, nil
+}
+
+// ends the call with the given error message, like a panic in a native contract
+func (h *host) fail(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	message, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return nil, errors.New(string(message))
+}
+
+func (h *host) resultRead(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	return nil, instance.MemoryWrite(uint32(args[0]), h.result)
+}
+
+func (h *host) stateReadBytes(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	value, err := h.sdk.State.ReadBytesByKey(h.executionContextId, string(key))
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(value)
+}
+
+func (h *host) stateWriteBytes(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	value, err := instance.MemoryRead(uint32(args[2]), uint32(args[3]))
+	if err != nil {
+		return nil, err
+	}
+	return nil, h.sdk.State.WriteBytesByKey(h.executionContextId, string(key), value)
+}
+
+func (h *host) stateReadUint32(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	value, err := h.sdk.State.ReadUint32ByKey(h.executionContextId, string(key))
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{uint64(value)}, nil
+}
+
+func (h *host) stateWriteUint32(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return nil, h.sdk.State.WriteUint32ByKey(h.executionContextId, string(key), uint32(args[2]))
+}
+
+func (h *host) stateReadUint64(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	value, err := h.sdk.State.ReadUint64ByKey(h.executionContextId, string(key))
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{value}, nil
+}
+
+func (h *host) stateWriteUint64(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return nil, h.sdk.State.WriteUint64ByKey(h.executionContextId, string(key), args[2])
+}
+
+func (h *host) stateClear(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	key, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	return nil, h.sdk.State.ClearByKey(h.executionContextId, string(key))
+}
+
+func (h *host) serviceCallMethod(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	serviceName, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	methodName, err := instance.MemoryRead(uint32(args[2]), uint32(args[3]))
+	if err != nil {
+		return nil, err
+	}
+	inputArgs, err := h.readMethodArgumentArray(instance, uint32(args[4]), uint32(args[5]))
+	if err != nil {
+		return nil, err
+	}
+	outputArgs, err := h.sdk.Service.CallMethod(h.executionContextId, string(serviceName), string(methodName), inputArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(native.ArgsToMethodArgumentArray(outputArgs...).Raw())
+}
+
+func (h *host) addressGetSigner(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	address, err := h.sdk.Address.GetSignerAddress(h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(address)
+}
+
+func (h *host) addressGetCaller(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	address, err := h.sdk.Address.GetCallerAddress(h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(address)
+}
+
+func (h *host) envGetBlockHeight(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.env.GetBlockHeight(h.ctx, h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{value}, nil
+}
+
+func (h *host) envGetBlockTimestamp(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.env.GetBlockTimestamp(h.ctx, h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{value}, nil
+}
+
+func (h *host) envGetVirtualChainId(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.env.GetVirtualChainId(h.ctx, h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return []uint64{uint64(value)}, nil
+}
+
+func (h *host) envGetTxHash(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	value, err := h.env.GetTxHash(h.ctx, h.executionContextId)
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(value)
+}
+
+func (h *host) ethereumCallMethod(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	contractAddress, err := instance.MemoryRead(uint32(args[0]), uint32(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	jsonAbi, err := instance.MemoryRead(uint32(args[2]), uint32(args[3]))
+	if err != nil {
+		return nil, err
+	}
+	methodName, err := instance.MemoryRead(uint32(args[4]), uint32(args[5]))
+	if err != nil {
+		return nil, err
+	}
+	inputArgs, err := h.readMethodArgumentArray(instance, uint32(args[7]), uint32(args[8]))
+	if err != nil {
+		return nil, err
+	}
+	outputArgs, err := h.ethereum.CallMethod(h.ctx, h.executionContextId, string(contractAddress), string(jsonAbi), string(methodName), args[6], inputArgs...)
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(native.ArgsToMethodArgumentArray(outputArgs...).Raw())
+}
+
+// the result is the event params prefixed by the block number of the log (uint64)
+func (h *host) ethereumGetTransactionLog(instance *interpreter.Instance, args []uint64) ([]uint64, error) {
+	var stringArgs [4]string
+	for i := range stringArgs {
+		value, err := instance.MemoryRead(uint32(args[2*i]), uint32(args[2*i+1]))
+		if err != nil {
+			return nil, err
+		}
+		stringArgs[i] = string(value)
+	}
+	ethereumBlockNumber, eventArgs, err := h.ethereum.GetTransactionLog(h.ctx, h.executionContextId, stringArgs[0], stringArgs[1], stringArgs[2], stringArgs[3], uint32(args[8]), args[9])
+	if err != nil {
+		return nil, err
+	}
+	return h.setResult(native.ArgsToMethodArgumentArray(append([]interface{}{ethereumBlockNumber}, eventArgs...)...).Raw())
+}
+
+func (h *host) setResult(value []byte) ([]uint64, error) {
+	h.result = value
+	return []uint64{uint64(len(value))}, nil
+}
+
+func (h *host) inputArg(index uint64) (interface{}, error) {
+	if index >= uint64(len(h.inputArgs)) {
+		return nil, errors.Errorf("arg %d is out of range, the method received %d args", index, len(h.inputArgs))
+	}
+	return h.inputArgs[index], nil
+}
+
+func (h *host) variableLengthInputArg(index uint64) ([]byte, error) {
+	arg, err := h.inputArg(index)
+	if err != nil {
+		return nil, err
+	}
+	switch arg := arg.(type) {
+	case string:
+		return []byte(arg), nil
+	case []byte:
+		return arg, nil
+	default:
+		return nil, errors.Errorf("arg %d is not a string or bytes", index)
+	}
+}
+
+func (h *host) readMethodArgumentArray(instance *interpreter.Instance, ptr uint32, length uint32) ([]interface{}, error) {
+	raw, err := instance.MemoryRead(ptr, length)
+	if err != nil {
+		return nil, err
+	}
+	return native.ParseMethodArgumentArray(protocol.MethodArgumentArrayReader(raw))
+}
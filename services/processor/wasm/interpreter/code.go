@@ -0,0 +1,435 @@
+package interpreter
+
+const (
+	opUnreachable  = 0x00
+	opNop          = 0x01
+	opBlock        = 0x02
+	opLoop         = 0x03
+	opIf           = 0x04
+	opElse         = 0x05
+	opEnd          = 0x0b
+	opBr           = 0x0c
+	opBrIf         = 0x0d
+	opBrTable      = 0x0e
+	opReturn       = 0x0f
+	opCall         = 0x10
+	opCallIndirect = 0x11
+	opDrop         = 0x1a
+	opSelect       = 0x1b
+	opSelectTyped  = 0x1c
+	opLocalGet     = 0x20
+	opLocalSet     = 0x21
+	opLocalTee     = 0x22
+	opGlobalGet    = 0x23
+	opGlobalSet    = 0x24
+	opI32Load      = 0x28
+	opI64Load      = 0x29
+	opI32Load8S    = 0x2c
+	opI32Load8U    = 0x2d
+	opI32Load16S   = 0x2e
+	opI32Load16U   = 0x2f
+	opI64Load8S    = 0x30
+	opI64Load8U    = 0x31
+	opI64Load16S   = 0x32
+	opI64Load16U   = 0x33
+	opI64Load32S   = 0x34
+	opI64Load32U   = 0x35
+	opI32Store     = 0x36
+	opI64Store     = 0x37
+	opI32Store8    = 0x3a
+	opI32Store16   = 0x3b
+	opI64Store8    = 0x3c
+	opI64Store16   = 0x3d
+	opI64Store32   = 0x3e
+	opMemorySize   = 0x3f
+	opMemoryGrow   = 0x40
+	opI32Const     = 0x41
+	opI64Const     = 0x42
+
+	opI32Eqz  = 0x45
+	opI32GeU  = 0x4f
+	opI64Eqz  = 0x50
+	opI64GeU  = 0x5a
+	opI32Clz  = 0x67
+	opI32Rotr = 0x78
+	opI64Clz  = 0x79
+	opI64Rotr = 0x8a
+
+	opI32WrapI64     = 0xa7
+	opI64ExtendI32S  = 0xac
+	opI64ExtendI32U  = 0xad
+	opI32Extend8S    = 0xc0
+	opI64Extend32S   = 0xc4
+	opPrefixed       = 0xfc
+	opMemoryCopy     = 10 // prefixed
+	opMemoryFill     = 11 // prefixed
+	blockTypeEmpty   = 0x40
+	blockTypeI32     = 0x7f
+	blockTypeI64     = 0x7e
+	maxBrTableLength = 65536
+)
+
+// walks the function once before it runs: rejects unsupported (floating point) instructions, checks indices and the
+// types of all operands (like the validation of the wasm spec) so execution never pops a value that isn't there,
+// and records where every block ends so branches don't need to search for it
+func (m *Module) scanCode(functionIndex uint32, f *function) {
+	r := &reader{buf: f.code}
+	f.blocks = make(map[int]*block)
+	v := &validator{functionIndex: functionIndex}
+	v.pushFrame(opBlock, &FuncType{Results: f.typ.Results}, nil)
+
+	for r.len() > 0 {
+		offset := r.pos
+		op := r.byte()
+		switch {
+		case op == opBlock || op == opLoop || op == opIf:
+			b := &block{typ: m.blockType(r)}
+			b.bodyStart = r.pos
+			f.blocks[offset] = b
+			if op == opIf {
+				v.popExpected(I32)
+			}
+			v.popAll(b.typ.Params)
+			v.pushFrame(op, b.typ, b)
+		case op == opElse:
+			frame := v.popFrame()
+			if frame.opcode != opIf {
+				fail("function %d has else without if", functionIndex)
+			}
+			frame.block.elseStart = offset
+			v.pushFrame(opElse, frame.typ, frame.block)
+		case op == opEnd:
+			frame := v.popFrame()
+			if frame.opcode == opIf && !equalTypes(frame.typ.Params, frame.typ.Results) {
+				fail("function %d has if without else that changes the operand types", functionIndex)
+			}
+			if len(v.frames) == 0 {
+				if r.len() != 0 {
+					fail("function %d has code after its end", functionIndex)
+				}
+				return
+			}
+			frame.block.end = offset
+			v.pushAll(frame.typ.Results)
+		case op == opBr:
+			v.popAll(v.labelTypes(r.u32()))
+			v.markUnreachable()
+		case op == opBrIf:
+			v.popExpected(I32)
+			types := v.labelTypes(r.u32())
+			v.popAll(types)
+			v.pushAll(types)
+		case op == opBrTable:
+			v.popExpected(I32)
+			n := r.u32()
+			if n > maxBrTableLength {
+				fail("function %d has a br_table that is too long", functionIndex)
+			}
+			var targets [][]ValueType
+			for ; n > 0; n-- {
+				targets = append(targets, v.labelTypes(r.u32()))
+			}
+			types := v.labelTypes(r.u32())
+			for _, target := range targets {
+				if !equalTypes(target, types) {
+					fail("function %d has a br_table with labels of different types", functionIndex)
+				}
+			}
+			v.popAll(types)
+			v.markUnreachable()
+		case op == opReturn:
+			v.popAll(f.typ.Results)
+			v.markUnreachable()
+		case op == opUnreachable:
+			v.markUnreachable()
+		case op == opNop:
+		case op == opCall:
+			index := r.u32()
+			if index >= m.numFunctions() {
+				fail("function %d calls unknown function %d", functionIndex, index)
+			}
+			typ := m.functionType(index)
+			v.popAll(typ.Params)
+			v.pushAll(typ.Results)
+		case op == opCallIndirect:
+			typ := m.types[m.typeIndex(r.u32())]
+			if r.byte() != 0 || m.table == nil {
+				fail("function %d uses call_indirect without a table", functionIndex)
+			}
+			v.popExpected(I32)
+			v.popAll(typ.Params)
+			v.pushAll(typ.Results)
+		case op == opDrop:
+			v.pop()
+		case op == opSelect:
+			v.popExpected(I32)
+			v.push(v.popExpected(v.pop()))
+		case op == opSelectTyped:
+			if r.u32() != 1 {
+				fail("function %d has a select with more than one type", functionIndex)
+			}
+			typ := r.valueType()
+			v.popExpected(I32)
+			v.popExpected(typ)
+			v.popExpected(typ)
+			v.push(typ)
+		case op == opLocalGet || op == opLocalSet || op == opLocalTee:
+			index := r.u32()
+			if index >= uint32(len(f.locals)) {
+				fail("function %d uses unknown local %d", functionIndex, index)
+			}
+			if op != opLocalGet {
+				v.popExpected(f.locals[index])
+			}
+			if op != opLocalSet {
+				v.push(f.locals[index])
+			}
+		case op == opGlobalGet || op == opGlobalSet:
+			index := r.u32()
+			if index >= uint32(len(m.globals)) {
+				fail("function %d uses unknown global %d", functionIndex, index)
+			}
+			if op == opGlobalSet && !m.globals[index].mutable {
+				fail("function %d sets immutable global %d", functionIndex, index)
+			}
+			if op == opGlobalSet {
+				v.popExpected(m.globals[index].typ)
+			} else {
+				v.push(m.globals[index].typ)
+			}
+		case op == opI32Load || op == opI64Load || (op >= opI32Load8S && op <= opI64Load32U):
+			m.checkMemory(functionIndex)
+			r.u32() // alignment is only a hint
+			r.u32()
+			v.popExpected(I32)
+			v.push(memoryValueType(op))
+		case op >= opI32Store && op <= opI64Store32 && op != 0x38 && op != 0x39:
+			m.checkMemory(functionIndex)
+			r.u32()
+			r.u32()
+			v.popExpected(memoryValueType(op))
+			v.popExpected(I32)
+		case op == opMemorySize || op == opMemoryGrow:
+			m.checkMemory(functionIndex)
+			if r.byte() != 0 {
+				fail("function %d refers to unknown memory", functionIndex)
+			}
+			if op == opMemoryGrow {
+				v.popExpected(I32)
+			}
+			v.push(I32)
+		case op == opI32Const:
+			r.s32()
+			v.push(I32)
+		case op == opI64Const:
+			r.s64()
+			v.push(I64)
+		case op == opPrefixed:
+			switch sub := r.u32(); sub {
+			case opMemoryCopy:
+				m.checkMemory(functionIndex)
+				if r.byte() != 0 || r.byte() != 0 {
+					fail("function %d refers to unknown memory", functionIndex)
+				}
+			case opMemoryFill:
+				m.checkMemory(functionIndex)
+				if r.byte() != 0 {
+					fail("function %d refers to unknown memory", functionIndex)
+				}
+			default:
+				fail("function %d uses unsupported instruction 0xfc %d", functionIndex, sub)
+			}
+			v.popAll([]ValueType{I32, I32, I32})
+		case isNumericInstruction(op):
+			params, result := numericType(op)
+			v.popAll(params)
+			v.push(result)
+		default:
+			fail("function %d uses unsupported instruction 0x%x (floating point is not supported)", functionIndex, op)
+		}
+	}
+	fail("function %d has no end", functionIndex)
+}
+
+// instructions without immediates that only take and return numbers
+func isNumericInstruction(op byte) bool {
+	switch {
+	case op >= opI32Eqz && op <= opI64GeU:
+		return true
+	case op >= opI32Clz && op <= opI64Rotr:
+		return true
+	case op == opI32WrapI64, op == opI64ExtendI32S, op == opI64ExtendI32U:
+		return true
+	case op >= opI32Extend8S && op <= opI64Extend32S:
+		return true
+	}
+	return false
+}
+
+func numericType(op byte) ([]ValueType, ValueType) {
+	switch {
+	case op == opI32Eqz:
+		return []ValueType{I32}, I32
+	case op < opI64Eqz:
+		return []ValueType{I32, I32}, I32
+	case op == opI64Eqz:
+		return []ValueType{I64}, I32
+	case op <= opI64GeU:
+		return []ValueType{I64, I64}, I32
+	case op <= 0x69: // i32 clz, ctz, popcnt
+		return []ValueType{I32}, I32
+	case op <= opI32Rotr:
+		return []ValueType{I32, I32}, I32
+	case op <= 0x7b: // i64 clz, ctz, popcnt
+		return []ValueType{I64}, I64
+	case op <= opI64Rotr:
+		return []ValueType{I64, I64}, I64
+	case op == opI32WrapI64:
+		return []ValueType{I64}, I32
+	case op == opI64ExtendI32S || op == opI64ExtendI32U:
+		return []ValueType{I32}, I64
+	case op <= 0xc1: // i32 extend8_s, extend16_s
+		return []ValueType{I32}, I32
+	default:
+		return []ValueType{I64}, I64
+	}
+}
+
+func memoryValueType(op byte) ValueType {
+	switch op {
+	case opI32Load, opI32Load8S, opI32Load8U, opI32Load16S, opI32Load16U, opI32Store, opI32Store8, opI32Store16:
+		return I32
+	default:
+		return I64
+	}
+}
+
+// operands are unknown only in unreachable code, where any type is accepted
+const unknownType ValueType = 0
+
+type controlFrame struct {
+	opcode      byte
+	typ         *FuncType
+	block       *block // nil for the function body
+	height      int    // of the operand stack when the frame was entered
+	unreachable bool
+}
+
+type validator struct {
+	functionIndex uint32
+	operands      []ValueType
+	frames        []*controlFrame
+}
+
+func (v *validator) push(t ValueType) {
+	v.operands = append(v.operands, t)
+}
+
+func (v *validator) pushAll(types []ValueType) {
+	for _, t := range types {
+		v.push(t)
+	}
+}
+
+func (v *validator) pop() ValueType {
+	frame := v.frames[len(v.frames)-1]
+	if len(v.operands) == frame.height {
+		if frame.unreachable {
+			return unknownType
+		}
+		fail("function %d pops an operand that isn't there", v.functionIndex)
+	}
+	t := v.operands[len(v.operands)-1]
+	v.operands = v.operands[:len(v.operands)-1]
+	return t
+}
+
+// returns the type of the operand, which is the expected one when either is unknown
+func (v *validator) popExpected(expected ValueType) ValueType {
+	actual := v.pop()
+	if actual == unknownType {
+		return expected
+	}
+	if expected != unknownType && actual != expected {
+		fail("function %d expects an operand of type %s but found %s", v.functionIndex, expected, actual)
+	}
+	return actual
+}
+
+func (v *validator) popAll(types []ValueType) {
+	for t := len(types) - 1; t >= 0; t-- {
+		v.popExpected(types[t])
+	}
+}
+
+func (v *validator) pushFrame(opcode byte, typ *FuncType, b *block) {
+	v.frames = append(v.frames, &controlFrame{opcode: opcode, typ: typ, block: b, height: len(v.operands)})
+	v.pushAll(typ.Params)
+}
+
+func (v *validator) popFrame() *controlFrame {
+	if len(v.frames) == 0 {
+		fail("function %d has end without block", v.functionIndex)
+	}
+	frame := v.frames[len(v.frames)-1]
+	v.popAll(frame.typ.Results)
+	if len(v.operands) != frame.height {
+		fail("function %d leaves extra operands at the end of a block", v.functionIndex)
+	}
+	v.frames = v.frames[:len(v.frames)-1]
+	return frame
+}
+
+func (v *validator) markUnreachable() {
+	frame := v.frames[len(v.frames)-1]
+	v.operands = v.operands[:frame.height]
+	frame.unreachable = true
+}
+
+// a branch to a loop continues at its start so it carries the params, otherwise it carries the results
+func (v *validator) labelTypes(depth uint32) []ValueType {
+	if depth >= uint32(len(v.frames)) {
+		fail("function %d branches to unknown label %d", v.functionIndex, depth)
+	}
+	frame := v.frames[len(v.frames)-1-int(depth)]
+	if frame.opcode == opLoop {
+		return frame.typ.Params
+	}
+	return frame.typ.Results
+}
+
+func equalTypes(a []ValueType, b []ValueType) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for t := range a {
+		if a[t] != b[t] {
+			return false
+		}
+	}
+	return true
+}
+
+func (m *Module) checkMemory(functionIndex uint32) {
+	if m.memory == nil {
+		fail("function %d accesses memory but the module has none", functionIndex)
+	}
+}
+
+func (m *Module) blockType(r *reader) *FuncType {
+	if r.len() == 0 {
+		fail("unexpected end of module")
+	}
+	switch r.buf[r.pos] {
+	case blockTypeEmpty:
+		r.pos++
+		return &FuncType{}
+	case blockTypeI32, blockTypeI64, 0x7d, 0x7c:
+		return &FuncType{Results: []ValueType{r.valueType()}}
+	}
+	index := r.s33()
+	if index < 0 {
+		fail("unsupported block type")
+	}
+	return m.types[m.typeIndex(uint32(index))]
+}
@@ -0,0 +1,487 @@
+package interpreter
+
+import (
+	"math/bits"
+)
+
+type label struct {
+	arity        int // values carried by a branch to the label
+	height       int // stack height below the values of the block
+	continuation int // where a branch to the label continues
+	isLoop       bool
+}
+
+// calls the function with its params on top of the stack and leaves its results there
+func (i *Instance) call(functionIndex uint32) {
+	i.callDepth++
+	defer func() { i.callDepth-- }()
+	if i.callDepth > MAX_CALL_DEPTH {
+		trap("call stack exhausted")
+	}
+
+	typ := i.module.functionType(functionIndex)
+	args := i.stack[len(i.stack)-len(typ.Params):]
+
+	if functionIndex < uint32(len(i.hostFunctions)) {
+		i.chargeMeter() // host functions charge their own costs, so the instructions that led to them come first
+		results, err := i.hostFunctions[functionIndex].Call(i, append([]uint64{}, args...))
+		if err != nil {
+			panic(&hostError{err})
+		}
+		if len(results) != len(typ.Results) {
+			trap("host function returned %d results instead of %d", len(results), len(typ.Results))
+		}
+		i.stack = append(i.stack[:len(i.stack)-len(args)], results...)
+		return
+	}
+
+	f := i.module.functions[functionIndex-uint32(len(i.hostFunctions))]
+	i.localsInUse += len(f.locals)
+	defer func() { i.localsInUse -= len(f.locals) }()
+	if i.localsInUse > MAX_STACK_SIZE {
+		trap("call stack exhausted")
+	}
+	locals := make([]uint64, len(f.locals))
+	copy(locals, args)
+	i.stack = i.stack[:len(i.stack)-len(args)]
+	i.execute(f, locals)
+}
+
+func (i *Instance) execute(f *function, locals []uint64) {
+	r := &reader{buf: f.code}
+	labels := []label{{arity: len(f.typ.Results), height: len(i.stack), continuation: len(f.code)}}
+
+	for len(labels) > 0 {
+		i.countInstructions(1)
+		if len(i.stack) > MAX_STACK_SIZE {
+			trap("value stack exhausted")
+		}
+
+		offset := r.pos
+		op := r.byte()
+		switch op {
+		case opUnreachable:
+			trap("unreachable executed")
+		case opNop:
+		case opBlock, opLoop:
+			b := f.blocks[offset]
+			r.pos = b.bodyStart
+			l := label{arity: len(b.typ.Results), height: len(i.stack) - len(b.typ.Params), continuation: b.end + 1}
+			if op == opLoop {
+				l = label{arity: len(b.typ.Params), height: l.height, continuation: b.bodyStart, isLoop: true}
+			}
+			labels = append(labels, l)
+		case opIf:
+			b := f.blocks[offset]
+			condition := i.pop()
+			l := label{arity: len(b.typ.Results), height: len(i.stack) - len(b.typ.Params), continuation: b.end + 1}
+			switch {
+			case condition != 0:
+				r.pos = b.bodyStart
+				labels = append(labels, l)
+			case b.elseStart != 0:
+				r.pos = b.elseStart + 1
+				labels = append(labels, l)
+			default:
+				r.pos = b.end + 1
+			}
+		case opElse:
+			// the then branch finished, skip the else branch
+			r.pos = labels[len(labels)-1].continuation
+			labels = labels[:len(labels)-1]
+		case opEnd:
+			labels = labels[:len(labels)-1]
+		case opBr:
+			labels = i.branch(r, labels, r.u32())
+		case opBrIf:
+			depth := r.u32()
+			if i.pop() != 0 {
+				labels = i.branch(r, labels, depth)
+			}
+		case opBrTable:
+			index := uint32(i.pop())
+			n := r.u32()
+			var depth uint32
+			for t := uint32(0); t <= n; t++ { // the last target is the default
+				target := r.u32()
+				if t == index || t == n {
+					depth = target
+					break
+				}
+			}
+			labels = i.branch(r, labels, depth)
+		case opReturn:
+			labels = i.branch(r, labels, uint32(len(labels)-1))
+		case opCall:
+			i.call(r.u32())
+		case opCallIndirect:
+			expected := i.module.types[r.u32()]
+			r.byte()
+			index := uint32(i.pop())
+			if index >= uint32(len(i.table)) || i.table[index] < 0 {
+				trap("call_indirect to undefined table element %d", index)
+			}
+			functionIndex := uint32(i.table[index])
+			if !i.module.functionType(functionIndex).equal(expected) {
+				trap("call_indirect signature mismatch")
+			}
+			i.call(functionIndex)
+		case opDrop:
+			i.pop()
+		case opSelect, opSelectTyped:
+			if op == opSelectTyped {
+				for n := r.u32(); n > 0; n-- {
+					r.byte()
+				}
+			}
+			condition := i.pop()
+			second := i.pop()
+			if condition == 0 {
+				i.stack[len(i.stack)-1] = second
+			}
+		case opLocalGet:
+			i.push(locals[r.u32()])
+		case opLocalSet:
+			locals[r.u32()] = i.pop()
+		case opLocalTee:
+			locals[r.u32()] = i.stack[len(i.stack)-1]
+		case opGlobalGet:
+			i.push(i.globals[r.u32()])
+		case opGlobalSet:
+			i.globals[r.u32()] = i.pop()
+		case opI32Load, opI64Load, opI32Load8S, opI32Load8U, opI32Load16S, opI32Load16U,
+			opI64Load8S, opI64Load8U, opI64Load16S, opI64Load16U, opI64Load32S, opI64Load32U:
+			r.u32()
+			offset := r.u32()
+			i.push(loadResult(op, i.load(i.pop(), offset, loadSize(op))))
+		case opI32Store, opI64Store, opI32Store8, opI32Store16, opI64Store8, opI64Store16, opI64Store32:
+			r.u32()
+			offset := r.u32()
+			value := i.pop()
+			i.store(i.pop(), offset, storeSize(op), value)
+		case opMemorySize:
+			r.byte()
+			i.push(uint64(len(i.memory) / PAGE_SIZE))
+		case opMemoryGrow:
+			r.byte()
+			i.push(uint64(uint32(i.growMemory(uint32(i.pop())))))
+		case opI32Const:
+			i.push(uint64(uint32(r.s32())))
+		case opI64Const:
+			i.push(uint64(r.s64()))
+		case opPrefixed:
+			i.executePrefixed(r)
+		default:
+			i.executeNumeric(op)
+		}
+	}
+}
+
+// moves the values carried by the branch to the height of the target label and continues there
+func (i *Instance) branch(r *reader, labels []label, depth uint32) []label {
+	target := labels[len(labels)-1-int(depth)]
+	values := i.stack[len(i.stack)-target.arity:]
+	i.stack = append(i.stack[:target.height], values...)
+	r.pos = target.continuation
+	if target.isLoop {
+		return labels[:len(labels)-int(depth)]
+	}
+	return labels[:len(labels)-1-int(depth)]
+}
+
+func (i *Instance) executePrefixed(r *reader) {
+	switch r.u32() {
+	case opMemoryCopy:
+		r.byte()
+		r.byte()
+		n := i.pop()
+		source := i.pop()
+		destination := i.pop()
+		i.chargeBytes(n)
+		i.memoryAddress(source, 0, n)
+		i.memoryAddress(destination, 0, n)
+		copy(i.memory[uint32(destination):uint64(uint32(destination))+n], i.memory[uint32(source):uint64(uint32(source))+n])
+	case opMemoryFill:
+		r.byte()
+		n := i.pop()
+		value := byte(i.pop())
+		destination := i.pop()
+		i.chargeBytes(n)
+		i.memoryAddress(destination, 0, n)
+		for b := uint64(uint32(destination)); b < uint64(uint32(destination))+n; b++ {
+			i.memory[b] = value
+		}
+	}
+}
+
+// bulk memory instructions cost one instruction for every 64 bytes
+func (i *Instance) chargeBytes(n uint64) {
+	i.countInstructions(uint64(uint32(n)) / 64)
+}
+
+func (i *Instance) push(value uint64) {
+	i.stack = append(i.stack, value)
+}
+
+func (i *Instance) pop() uint64 {
+	value := i.stack[len(i.stack)-1]
+	i.stack = i.stack[:len(i.stack)-1]
+	return value
+}
+
+func loadSize(op byte) uint64 {
+	switch op {
+	case opI32Load8S, opI32Load8U, opI64Load8S, opI64Load8U:
+		return 1
+	case opI32Load16S, opI32Load16U, opI64Load16S, opI64Load16U:
+		return 2
+	case opI32Load, opI64Load32S, opI64Load32U:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func storeSize(op byte) uint64 {
+	switch op {
+	case opI32Store8, opI64Store8:
+		return 1
+	case opI32Store16, opI64Store16:
+		return 2
+	case opI32Store, opI64Store32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func loadResult(op byte, value uint64) uint64 {
+	switch op {
+	case opI32Load8S:
+		return uint64(uint32(int32(int8(value))))
+	case opI32Load16S:
+		return uint64(uint32(int32(int16(value))))
+	case opI64Load8S:
+		return uint64(int64(int8(value)))
+	case opI64Load16S:
+		return uint64(int64(int16(value)))
+	case opI64Load32S:
+		return uint64(int64(int32(value)))
+	default:
+		return value
+	}
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (i *Instance) executeNumeric(op byte) {
+	switch op {
+	case opI32Eqz:
+		i.push(boolValue(uint32(i.pop()) == 0))
+		return
+	case opI64Eqz:
+		i.push(boolValue(i.pop() == 0))
+		return
+	case opI32Clz:
+		i.push(uint64(bits.LeadingZeros32(uint32(i.pop()))))
+		return
+	case 0x68: // i32.ctz
+		i.push(uint64(bits.TrailingZeros32(uint32(i.pop()))))
+		return
+	case 0x69: // i32.popcnt
+		i.push(uint64(bits.OnesCount32(uint32(i.pop()))))
+		return
+	case opI64Clz:
+		i.push(uint64(bits.LeadingZeros64(i.pop())))
+		return
+	case 0x7a: // i64.ctz
+		i.push(uint64(bits.TrailingZeros64(i.pop())))
+		return
+	case 0x7b: // i64.popcnt
+		i.push(uint64(bits.OnesCount64(i.pop())))
+		return
+	case opI32WrapI64:
+		i.push(uint64(uint32(i.pop())))
+		return
+	case opI64ExtendI32S:
+		i.push(uint64(int64(int32(i.pop()))))
+		return
+	case opI64ExtendI32U:
+		i.push(uint64(uint32(i.pop())))
+		return
+	case opI32Extend8S:
+		i.push(uint64(uint32(int32(int8(i.pop())))))
+		return
+	case 0xc1: // i32.extend16_s
+		i.push(uint64(uint32(int32(int16(i.pop())))))
+		return
+	case 0xc2: // i64.extend8_s
+		i.push(uint64(int64(int8(i.pop()))))
+		return
+	case 0xc3: // i64.extend16_s
+		i.push(uint64(int64(int16(i.pop()))))
+		return
+	case opI64Extend32S:
+		i.push(uint64(int64(int32(i.pop()))))
+		return
+	}
+
+	b := i.pop()
+	a := i.pop()
+	if op <= opI32GeU || (op >= 0x6a && op <= opI32Rotr) {
+		i.push(uint64(binaryI32(op, uint32(a), uint32(b))))
+	} else {
+		i.push(binaryI64(op, a, b))
+	}
+}
+
+func binaryI32(op byte, a uint32, b uint32) uint32 {
+	switch op {
+	case 0x46:
+		return uint32(boolValue(a == b))
+	case 0x47:
+		return uint32(boolValue(a != b))
+	case 0x48:
+		return uint32(boolValue(int32(a) < int32(b)))
+	case 0x49:
+		return uint32(boolValue(a < b))
+	case 0x4a:
+		return uint32(boolValue(int32(a) > int32(b)))
+	case 0x4b:
+		return uint32(boolValue(a > b))
+	case 0x4c:
+		return uint32(boolValue(int32(a) <= int32(b)))
+	case 0x4d:
+		return uint32(boolValue(a <= b))
+	case 0x4e:
+		return uint32(boolValue(int32(a) >= int32(b)))
+	case 0x4f:
+		return uint32(boolValue(a >= b))
+	case 0x6a:
+		return a + b
+	case 0x6b:
+		return a - b
+	case 0x6c:
+		return a * b
+	case 0x6d:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		if int32(a) == -1<<31 && int32(b) == -1 {
+			trap("integer overflow")
+		}
+		return uint32(int32(a) / int32(b))
+	case 0x6e:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		return a / b
+	case 0x6f:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		if int32(b) == -1 {
+			return 0
+		}
+		return uint32(int32(a) % int32(b))
+	case 0x70:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		return a % b
+	case 0x71:
+		return a & b
+	case 0x72:
+		return a | b
+	case 0x73:
+		return a ^ b
+	case 0x74:
+		return a << (b & 31)
+	case 0x75:
+		return uint32(int32(a) >> (b & 31))
+	case 0x76:
+		return a >> (b & 31)
+	case 0x77:
+		return bits.RotateLeft32(a, int(b&31))
+	default: // rotr
+		return bits.RotateLeft32(a, -int(b&31))
+	}
+}
+
+func binaryI64(op byte, a uint64, b uint64) uint64 {
+	switch op {
+	case 0x51:
+		return boolValue(a == b)
+	case 0x52:
+		return boolValue(a != b)
+	case 0x53:
+		return boolValue(int64(a) < int64(b))
+	case 0x54:
+		return boolValue(a < b)
+	case 0x55:
+		return boolValue(int64(a) > int64(b))
+	case 0x56:
+		return boolValue(a > b)
+	case 0x57:
+		return boolValue(int64(a) <= int64(b))
+	case 0x58:
+		return boolValue(a <= b)
+	case 0x59:
+		return boolValue(int64(a) >= int64(b))
+	case 0x5a:
+		return boolValue(a >= b)
+	case 0x7c:
+		return a + b
+	case 0x7d:
+		return a - b
+	case 0x7e:
+		return a * b
+	case 0x7f:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		if int64(a) == -1<<63 && int64(b) == -1 {
+			trap("integer overflow")
+		}
+		return uint64(int64(a) / int64(b))
+	case 0x80:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		return a / b
+	case 0x81:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		if int64(b) == -1 {
+			return 0
+		}
+		return uint64(int64(a) % int64(b))
+	case 0x82:
+		if b == 0 {
+			trap("integer divide by zero")
+		}
+		return a % b
+	case 0x83:
+		return a & b
+	case 0x84:
+		return a | b
+	case 0x85:
+		return a ^ b
+	case 0x86:
+		return a << (b & 63)
+	case 0x87:
+		return uint64(int64(a) >> (b & 63))
+	case 0x88:
+		return a >> (b & 63)
+	case 0x89:
+		return bits.RotateLeft64(a, int(b&63))
+	default: // rotr
+		return bits.RotateLeft64(a, -int(b&63))
+	}
+}
@@ -0,0 +1,245 @@
+package interpreter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"github.com/pkg/errors"
+)
+
+const MAX_CALL_DEPTH = 1024
+const MAX_STACK_SIZE = 1024 * 1024
+const INSTRUCTIONS_PER_METER_CHARGE = 1000
+
+// host functions are how a module reaches anything outside its own memory
+type HostFunction struct {
+	Type *FuncType
+	Call func(instance *Instance, args []uint64) ([]uint64, error)
+}
+
+type Imports map[string]map[string]*HostFunction
+
+// a trap ends the execution of the instance, like a panic in go
+type Trap struct {
+	Reason string
+}
+
+func (t *Trap) Error() string {
+	return "wasm trap: " + t.Reason
+}
+
+// executed instructions are reported in batches (and before every host call and when the invoke ends) so the host
+// can charge them to a budget, the points where the meter is called are the same on every node
+type Meter func(instructions uint64) error
+
+// errors of host functions and of the meter end the execution like a trap but are returned as is
+type hostError struct {
+	err error
+}
+
+// an instance is a module with its own memory, globals and table, it is not safe for concurrent or re-entrant use
+type Instance struct {
+	module           *Module
+	hostFunctions    []*HostFunction
+	memory           []byte
+	memoryMaxPages   uint32
+	globals          []uint64
+	table            []int64 // function index or -1
+	stack            []uint64
+	callDepth        int
+	localsInUse      int
+	meter            Meter
+	instructionsUsed uint64
+	uncharged        uint64 // instructions not yet reported to the meter
+}
+
+// runs the start function of the module, which is also metered
+func Instantiate(module *Module, imports Imports, meter Meter) (*Instance, error) {
+	i := &Instance{
+		module: module,
+		meter:  meter,
+	}
+
+	for _, imp := range module.imports {
+		hostFunction := imports[imp.module][imp.name]
+		if hostFunction == nil {
+			return nil, errors.Errorf("unknown import '%s.%s'", imp.module, imp.name)
+		}
+		if expected := module.types[imp.typeIndex]; !hostFunction.Type.equal(expected) {
+			return nil, errors.Errorf("import '%s.%s' has type %s but the module expects %s", imp.module, imp.name, hostFunction.Type, expected)
+		}
+		i.hostFunctions = append(i.hostFunctions, hostFunction)
+	}
+
+	for _, g := range module.globals {
+		i.globals = append(i.globals, g.init)
+	}
+
+	if module.memory != nil {
+		i.memory = make([]byte, int(module.memory.min)*PAGE_SIZE)
+		i.memoryMaxPages = module.memory.max
+	}
+	for _, segment := range module.data {
+		if uint64(segment.offset)+uint64(len(segment.bytes)) > uint64(len(i.memory)) {
+			return nil, errors.New("data segment does not fit in memory")
+		}
+		copy(i.memory[segment.offset:], segment.bytes)
+	}
+
+	if module.table != nil {
+		i.table = make([]int64, module.table.min)
+		for t := range i.table {
+			i.table[t] = -1
+		}
+	}
+	for _, segment := range module.elements {
+		if uint64(segment.offset)+uint64(len(segment.functions)) > uint64(len(i.table)) {
+			return nil, errors.New("element segment does not fit in table")
+		}
+		for f, functionIndex := range segment.functions {
+			i.table[int(segment.offset)+f] = int64(functionIndex)
+		}
+	}
+
+	if module.start != nil {
+		_, err := i.invoke(*module.start, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return i, nil
+}
+
+func (i *Instance) Invoke(exportName string, args ...uint64) ([]uint64, error) {
+	if i.callDepth > 0 {
+		return nil, errors.New("instance is already running")
+	}
+	exp, found := i.module.exports[exportName]
+	if !found || exp.kind != externalFunction {
+		return nil, errors.Errorf("function '%s' is not exported", exportName)
+	}
+	if t := i.module.functionType(exp.index); len(t.Params) != len(args) {
+		return nil, errors.Errorf("function '%s' takes %d args but received %d", exportName, len(t.Params), len(args))
+	}
+	return i.invoke(exp.index, args)
+}
+
+func (i *Instance) InstructionsUsed() uint64 {
+	return i.instructionsUsed
+}
+
+func (i *Instance) invoke(functionIndex uint32, args []uint64) (results []uint64, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			i.stack = i.stack[:0]
+			i.callDepth = 0
+			i.localsInUse = 0
+			switch r := r.(type) {
+			case *Trap:
+				err = r
+			case *hostError:
+				err = r.err
+			default:
+				panic(r) // compile validates operand types, so anything else is a bug in the interpreter
+			}
+		}
+		if meterErr := i.reportUncharged(); meterErr != nil {
+			results, err = nil, meterErr
+		}
+	}()
+
+	i.stack = append(i.stack[:0], args...)
+	i.call(functionIndex)
+	results = append([]uint64{}, i.stack...)
+	i.stack = i.stack[:0]
+	return results, nil
+}
+
+func (i *Instance) countInstructions(n uint64) {
+	i.instructionsUsed += n
+	i.uncharged += n
+	if i.uncharged >= INSTRUCTIONS_PER_METER_CHARGE {
+		i.chargeMeter()
+	}
+}
+
+func (i *Instance) chargeMeter() {
+	if err := i.reportUncharged(); err != nil {
+		panic(&hostError{err})
+	}
+}
+
+func (i *Instance) reportUncharged() error {
+	n := i.uncharged
+	i.uncharged = 0
+	if n == 0 {
+		return nil
+	}
+	return i.meter(n)
+}
+
+func trap(format string, args ...interface{}) {
+	panic(&Trap{Reason: fmt.Sprintf(format, args...)})
+}
+
+// memory access for host functions
+
+func (i *Instance) MemoryRead(ptr uint32, length uint32) ([]byte, error) {
+	if uint64(ptr)+uint64(length) > uint64(len(i.memory)) {
+		return nil, &Trap{Reason: fmt.Sprintf("memory read of %d bytes at %d is out of bounds", length, ptr)}
+	}
+	return append([]byte{}, i.memory[ptr:ptr+length]...), nil
+}
+
+func (i *Instance) MemoryWrite(ptr uint32, data []byte) error {
+	if uint64(ptr)+uint64(len(data)) > uint64(len(i.memory)) {
+		return &Trap{Reason: fmt.Sprintf("memory write of %d bytes at %d is out of bounds", len(data), ptr)}
+	}
+	copy(i.memory[ptr:], data)
+	return nil
+}
+
+func (i *Instance) memoryAddress(base uint64, offset uint32, size uint64) uint64 {
+	address := uint64(uint32(base)) + uint64(offset)
+	if address+size > uint64(len(i.memory)) {
+		trap("out of bounds memory access")
+	}
+	return address
+}
+
+func (i *Instance) load(base uint64, offset uint32, size uint64) uint64 {
+	address := i.memoryAddress(base, offset, size)
+	switch size {
+	case 1:
+		return uint64(i.memory[address])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(i.memory[address:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(i.memory[address:]))
+	default:
+		return binary.LittleEndian.Uint64(i.memory[address:])
+	}
+}
+
+func (i *Instance) store(base uint64, offset uint32, size uint64, value uint64) {
+	address := i.memoryAddress(base, offset, size)
+	switch size {
+	case 1:
+		i.memory[address] = byte(value)
+	case 2:
+		binary.LittleEndian.PutUint16(i.memory[address:], uint16(value))
+	case 4:
+		binary.LittleEndian.PutUint32(i.memory[address:], uint32(value))
+	default:
+		binary.LittleEndian.PutUint64(i.memory[address:], value)
+	}
+}
+
+// returns the previous size in pages or -1 if the memory can't grow
+func (i *Instance) growMemory(pages uint32) int32 {
+	current := uint32(len(i.memory) / PAGE_SIZE)
+	if uint64(current)+uint64(pages) > uint64(i.memoryMaxPages) {
+		return -1
+	}
+	i.memory = append(i.memory, make([]byte, int(pages)*PAGE_SIZE)...)
+	return int32(current)
+}
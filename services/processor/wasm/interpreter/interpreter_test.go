@@ -0,0 +1,353 @@
+package interpreter
+
+import (
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const TEST_INSTRUCTION_BUDGET = 1000000
+
+var errTestBudgetExhausted = errors.New("test budget exhausted")
+
+// like the execution meter of the virtual machine, fails once the budget is exhausted
+func newTestMeter(budget uint64) Meter {
+	return func(instructions uint64) error {
+		if instructions > budget {
+			budget = 0
+			return errTestBudgetExhausted
+		}
+		budget -= instructions
+		return nil
+	}
+}
+
+func TestInvoke_AddsIntegers(t *testing.T) {
+	instance := instantiateTestModule(t, nil,
+		sectionOf(sectionType, vec(funcType([]ValueType{I32, I32}, []ValueType{I32}))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("add", 0))),
+		sectionOf(sectionCode, vec(body(nil, opLocalGet, 0, opLocalGet, 1, 0x6a, opEnd))),
+	)
+
+	results, err := instance.Invoke("add", 2, 0xffffffff)
+	require.NoError(t, err, "invoke should succeed")
+	require.Equal(t, []uint64{1}, results, "i32 addition should wrap around")
+}
+
+func TestInvoke_RunsLoopsWithI64Locals(t *testing.T) {
+	instance := instantiateTestModule(t, nil,
+		sectionOf(sectionType, vec(funcType([]ValueType{I64}, []ValueType{I64}))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("factorial", 0))),
+		sectionOf(sectionCode, vec(body([]byte{1, byte(I64)},
+			opI64Const, 1, opLocalSet, 1,
+			opBlock, blockTypeEmpty,
+			opLoop, blockTypeEmpty,
+			opLocalGet, 0, opI64Eqz, opBrIf, 1,
+			opLocalGet, 1, opLocalGet, 0, 0x7e, opLocalSet, 1,
+			opLocalGet, 0, opI64Const, 1, 0x7d, opLocalSet, 0,
+			opBr, 0,
+			opEnd,
+			opEnd,
+			opLocalGet, 1,
+			opEnd,
+		))),
+	)
+
+	results, err := instance.Invoke("factorial", 20)
+	require.NoError(t, err, "invoke should succeed")
+	require.Equal(t, []uint64{2432902008176640000}, results, "factorial should be computed")
+}
+
+func TestInvoke_BranchesWithIfElseAndBrTable(t *testing.T) {
+	instance := instantiateTestModule(t, nil,
+		sectionOf(sectionType, vec(funcType([]ValueType{I32}, []ValueType{I32}))),
+		sectionOf(sectionFunction, vec(u32(0), u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("choose", 0), exportFunc("switch", 1))),
+		sectionOf(sectionCode, vec(
+			body(nil, opLocalGet, 0, opIf, blockTypeI32, opI32Const, 10, opElse, opI32Const, 20, opEnd, opEnd),
+			body(nil,
+				opBlock, blockTypeEmpty,
+				opBlock, blockTypeEmpty,
+				opLocalGet, 0, opBrTable, 1, 0, 1,
+				opEnd,
+				opI32Const, 30, opNop, opReturn,
+				opEnd,
+				opI32Const, 40, opEnd,
+			),
+		)),
+	)
+
+	requireResult(t, instance, "choose", 1, 10)
+	requireResult(t, instance, "choose", 0, 20)
+	requireResult(t, instance, "switch", 0, 30)
+	requireResult(t, instance, "switch", 7, 40)
+}
+
+func TestInvoke_ChargesInstructionsToTheMeterDeterministically(t *testing.T) {
+	sections := [][]byte{
+		sectionOf(sectionType, vec(funcType(nil, nil))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("forever", 0))),
+		sectionOf(sectionCode, vec(body(nil, opLoop, blockTypeEmpty, opBr, 0, opEnd, opEnd))),
+	}
+
+	for run := 0; run < 2; run++ {
+		instance := instantiateTestModule(t, nil, sections...)
+		_, err := instance.Invoke("forever")
+		require.Equal(t, errTestBudgetExhausted, err, "endless loop should exhaust the budget")
+		require.EqualValues(t, TEST_INSTRUCTION_BUDGET+INSTRUCTIONS_PER_METER_CHARGE, instance.InstructionsUsed(), "metering should be deterministic")
+	}
+}
+
+func TestInvoke_ChargesRemainingInstructionsWhenTheCallEnds(t *testing.T) {
+	var charges []uint64
+	module, err := Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType(nil, []ValueType{I32}))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("one", 0))),
+		sectionOf(sectionCode, vec(body(nil, opI32Const, 1, opEnd))),
+	))
+	require.NoError(t, err, "compile should succeed")
+	instance, err := Instantiate(module, nil, func(instructions uint64) error {
+		charges = append(charges, instructions)
+		return nil
+	})
+	require.NoError(t, err, "instantiate should succeed")
+
+	results, err := instance.Invoke("one")
+	require.NoError(t, err, "invoke should succeed")
+	require.Equal(t, []uint64{1}, results)
+	require.Equal(t, []uint64{2}, charges, "instructions of a short call should be charged when it ends")
+}
+
+func TestInvoke_TrapsOnRuntimeErrors(t *testing.T) {
+	instance := instantiateTestModule(t, nil,
+		sectionOf(sectionType, vec(funcType([]ValueType{I32, I32}, []ValueType{I32}), funcType(nil, nil))),
+		sectionOf(sectionFunction, vec(u32(0), u32(1), u32(1))),
+		sectionOf(sectionExport, vec(exportFunc("div", 0), exportFunc("recurse", 1), exportFunc("unreachable", 2))),
+		sectionOf(sectionCode, vec(
+			body(nil, opLocalGet, 0, opLocalGet, 1, 0x6e, opEnd),
+			body(nil, opCall, 1, opEnd),
+			body(nil, opUnreachable, opEnd),
+		)),
+	)
+
+	_, err := instance.Invoke("div", 1, 0)
+	require.EqualError(t, err, "wasm trap: integer divide by zero")
+	_, err = instance.Invoke("recurse")
+	require.EqualError(t, err, "wasm trap: call stack exhausted")
+	_, err = instance.Invoke("unreachable")
+	require.EqualError(t, err, "wasm trap: unreachable executed")
+
+	results, err := instance.Invoke("div", 7, 2)
+	require.NoError(t, err, "instance should still be usable after a trap")
+	require.Equal(t, []uint64{3}, results)
+}
+
+func TestInvoke_AccessesMemoryInitializedByData(t *testing.T) {
+	instance := instantiateTestModule(t, nil,
+		sectionOf(sectionType, vec(funcType([]ValueType{I32}, []ValueType{I64}))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionMemory, vec([]byte{0, 1})),
+		sectionOf(sectionExport, vec(exportFunc("load", 0))),
+		sectionOf(sectionCode, vec(body(nil,
+			opI32Const, 16, opLocalGet, 0, opI64Load16U, 1, 0, opI64Store, 3, 0,
+			opI32Const, 16, opI64Load, 3, 0,
+			opEnd,
+		))),
+		sectionOf(sectionData, vec(cat(u32(0), []byte{opI32Const, 8, opEnd}, u32(2), []byte{0x34, 0x12}))),
+	)
+
+	requireResult(t, instance, "load", 8, 0x1234)
+
+	_, err := instance.Invoke("load", PAGE_SIZE-1)
+	require.EqualError(t, err, "wasm trap: out of bounds memory access")
+
+	data, err := instance.MemoryRead(8, 2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x34, 0x12}, data, "host should read the memory of the instance")
+}
+
+func TestInvoke_CallsHostFunctions(t *testing.T) {
+	hostErr := errors.New("host failure")
+	imports := Imports{"env": {
+		"double": {
+			Type: &FuncType{Params: []ValueType{I32}, Results: []ValueType{I32}},
+			Call: func(instance *Instance, args []uint64) ([]uint64, error) {
+				if args[0] == 0 {
+					return nil, hostErr
+				}
+				return []uint64{args[0] * 2}, nil
+			},
+		},
+	}}
+	instance := instantiateTestModule(t, imports,
+		sectionOf(sectionType, vec(funcType([]ValueType{I32}, []ValueType{I32}))),
+		sectionOf(sectionImport, vec(cat(name("env"), name("double"), []byte{externalFunction}, u32(0)))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionExport, vec(exportFunc("quadruple", 1))),
+		sectionOf(sectionCode, vec(body(nil, opLocalGet, 0, opCall, 0, opCall, 0, opEnd))),
+	)
+
+	requireResult(t, instance, "quadruple", 5, 20)
+
+	_, err := instance.Invoke("quadruple", 0)
+	require.Equal(t, hostErr, err, "host errors should be returned as is")
+}
+
+func TestInstantiate_FailsOnMissingOrMismatchingImports(t *testing.T) {
+	module, err := Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType([]ValueType{I32}, nil))),
+		sectionOf(sectionImport, vec(cat(name("env"), name("log"), []byte{externalFunction}, u32(0)))),
+	))
+	require.NoError(t, err)
+
+	_, err = Instantiate(module, nil, newTestMeter(TEST_INSTRUCTION_BUDGET))
+	require.EqualError(t, err, "unknown import 'env.log'")
+
+	_, err = Instantiate(module, Imports{"env": {"log": {Type: &FuncType{}}}}, newTestMeter(TEST_INSTRUCTION_BUDGET))
+	require.Error(t, err, "import with a different type should fail")
+}
+
+func TestCompile_RejectsFloatingPoint(t *testing.T) {
+	_, err := Compile(moduleOf(sectionOf(sectionType, vec(funcType([]ValueType{0x7c}, nil)))))
+	require.EqualError(t, err, "floating point types are not supported")
+
+	_, err = Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType(nil, nil))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionCode, vec(body(nil, 0x43, 0, 0, 0, 0, opDrop, opEnd))),
+	))
+	require.Error(t, err, "f32.const should be rejected")
+}
+
+func TestCompile_RejectsMalformedModules(t *testing.T) {
+	_, err := Compile([]byte("not wasm"))
+	require.Error(t, err)
+
+	_, err = Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType(nil, nil))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionCode, vec(body(nil, opBlock, blockTypeEmpty, opBr, 2, opEnd, opEnd))),
+	))
+	require.Error(t, err, "branch to unknown label should be rejected")
+
+	_, err = Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType(nil, nil))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionCode, vec(body(nil, opBlock, blockTypeEmpty, opEnd))),
+	))
+	require.Error(t, err, "function without end should be rejected")
+}
+
+func TestCompile_RejectsOperandsOfTheWrongType(t *testing.T) {
+	tests := []struct {
+		name string
+		typ  []byte
+		code []byte
+	}{
+		{"popping from an empty stack", funcType(nil, nil), []byte{opDrop, opEnd}},
+		{"i64 operand of an i32 instruction", funcType(nil, []ValueType{I32}), []byte{opI64Const, 1, opI32Const, 1, 0x6a, opEnd}},
+		{"missing result", funcType(nil, []ValueType{I32}), []byte{opEnd}},
+		{"extra operands at the end", funcType(nil, nil), []byte{opI32Const, 1, opEnd}},
+		{"i64 condition of an if", funcType(nil, nil), []byte{opI64Const, 1, opIf, blockTypeEmpty, opEnd, opEnd}},
+		{"if without else that has a result", funcType(nil, []ValueType{I32}), []byte{opI32Const, 1, opIf, blockTypeI32, opI32Const, 1, opEnd, opEnd}},
+		{"branch without the label value", funcType(nil, []ValueType{I32}), []byte{opBlock, blockTypeI32, opBr, 0, opEnd, opEnd}},
+		{"select of different types", funcType(nil, []ValueType{I32}), []byte{opI32Const, 1, opI64Const, 1, opI32Const, 0, opSelect, opEnd}},
+		{"block that reaches below its own operands", funcType(nil, nil), []byte{opI32Const, 1, opBlock, blockTypeEmpty, opDrop, opEnd, opDrop, opEnd}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(moduleOf(
+				sectionOf(sectionType, vec(tt.typ)),
+				sectionOf(sectionFunction, vec(u32(0))),
+				sectionOf(sectionCode, vec(body(nil, tt.code...))),
+			))
+			require.Error(t, err, "function should be rejected")
+		})
+	}
+}
+
+func TestCompile_AcceptsAnyOperandsInUnreachableCode(t *testing.T) {
+	_, err := Compile(moduleOf(
+		sectionOf(sectionType, vec(funcType(nil, []ValueType{I32}))),
+		sectionOf(sectionFunction, vec(u32(0))),
+		sectionOf(sectionCode, vec(body(nil, opUnreachable, 0x6a, opEnd))),
+	))
+	require.NoError(t, err, "operands after unreachable should have any type")
+}
+
+func requireResult(t *testing.T, instance *Instance, name string, arg uint64, expected uint64) {
+	results, err := instance.Invoke(name, arg)
+	require.NoError(t, err, "invoke of %s(%d) should succeed", name, arg)
+	require.Equal(t, []uint64{expected}, results, "invoke of %s(%d) should return %d", name, arg, expected)
+}
+
+func instantiateTestModule(t *testing.T, imports Imports, sections ...[]byte) *Instance {
+	module, err := Compile(moduleOf(sections...))
+	require.NoError(t, err, "compile should succeed")
+	instance, err := Instantiate(module, imports, newTestMeter(TEST_INSTRUCTION_BUDGET))
+	require.NoError(t, err, "instantiate should succeed")
+	return instance
+}
+
+func moduleOf(sections ...[]byte) []byte {
+	return cat(append([][]byte{{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}}, sections...)...)
+}
+
+func sectionOf(id byte, contents []byte) []byte {
+	return cat([]byte{id}, u32(uint32(len(contents))), contents)
+}
+
+func vec(items ...[]byte) []byte {
+	return cat(append([][]byte{u32(uint32(len(items)))}, items...)...)
+}
+
+func funcType(params []ValueType, results []ValueType) []byte {
+	res := []byte{0x60}
+	res = append(res, u32(uint32(len(params)))...)
+	for _, p := range params {
+		res = append(res, byte(p))
+	}
+	res = append(res, u32(uint32(len(results)))...)
+	for _, r := range results {
+		res = append(res, byte(r))
+	}
+	return res
+}
+
+func exportFunc(exportName string, index uint32) []byte {
+	return cat(name(exportName), []byte{externalFunction}, u32(index))
+}
+
+// locals are pairs of count and type
+func body(locals []byte, code ...byte) []byte {
+	contents := cat(u32(uint32(len(locals)/2)), locals, code)
+	return cat(u32(uint32(len(contents))), contents)
+}
+
+func name(s string) []byte {
+	return cat(u32(uint32(len(s))), []byte(s))
+}
+
+func u32(v uint32) []byte {
+	var res []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(res, b)
+		}
+		res = append(res, b|0x80)
+	}
+}
+
+func cat(parts ...[]byte) []byte {
+	var res []byte
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+	return res
+}
@@ -0,0 +1,527 @@
+// Package interpreter executes WebAssembly modules without cgo.
+// Only the integer subset of WebAssembly is supported: floating point types and instructions are rejected when
+// the module is compiled, so a module runs exactly the same on every node. Every executed instruction is metered.
+package interpreter
+
+import (
+	"fmt"
+	"github.com/pkg/errors"
+	"sort"
+)
+
+type ValueType byte
+
+const (
+	I32 ValueType = 0x7f
+	I64 ValueType = 0x7e
+)
+
+func (t ValueType) String() string {
+	switch t {
+	case I32:
+		return "i32"
+	case I64:
+		return "i64"
+	default:
+		return fmt.Sprintf("type(0x%x)", byte(t))
+	}
+}
+
+type FuncType struct {
+	Params  []ValueType
+	Results []ValueType
+}
+
+func (t *FuncType) equal(other *FuncType) bool {
+	if len(t.Params) != len(other.Params) || len(t.Results) != len(other.Results) {
+		return false
+	}
+	for i := range t.Params {
+		if t.Params[i] != other.Params[i] {
+			return false
+		}
+	}
+	for i := range t.Results {
+		if t.Results[i] != other.Results[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *FuncType) String() string {
+	return fmt.Sprintf("%v -> %v", t.Params, t.Results)
+}
+
+const (
+	PAGE_SIZE        = 65536
+	MAX_MEMORY_PAGES = 256 // 16 MB
+	MAX_TABLE_SIZE   = 65536
+	MAX_LOCALS       = 50000
+)
+
+const (
+	sectionCustom    = 0
+	sectionType      = 1
+	sectionImport    = 2
+	sectionFunction  = 3
+	sectionTable     = 4
+	sectionMemory    = 5
+	sectionGlobal    = 6
+	sectionExport    = 7
+	sectionStart     = 8
+	sectionElement   = 9
+	sectionCode      = 10
+	sectionData      = 11
+	sectionDataCount = 12
+)
+
+const (
+	externalFunction = 0
+	externalTable    = 1
+	externalMemory   = 2
+	externalGlobal   = 3
+)
+
+// a compiled module is immutable and may be instantiated concurrently
+type Module struct {
+	types     []*FuncType
+	imports   []*importedFunction
+	functions []*function
+	table     *limits
+	memory    *limits
+	globals   []*global
+	exports   map[string]*export
+	start     *uint32
+	elements  []*elementSegment
+	data      []*dataSegment
+}
+
+type importedFunction struct {
+	module    string
+	name      string
+	typeIndex uint32
+}
+
+type function struct {
+	typ    *FuncType
+	locals []ValueType // params followed by declared locals
+	code   []byte
+	blocks map[int]*block // by offset of the block, loop or if instruction
+}
+
+type block struct {
+	typ       *FuncType
+	bodyStart int
+	elseStart int // offset of the else instruction, 0 if there is none
+	end       int // offset of the end instruction
+}
+
+type limits struct {
+	min uint32
+	max uint32
+}
+
+type global struct {
+	typ     ValueType
+	mutable bool
+	init    uint64
+}
+
+type export struct {
+	kind  byte
+	index uint32
+}
+
+type elementSegment struct {
+	offset    uint32
+	functions []uint32
+}
+
+type dataSegment struct {
+	offset uint32
+	bytes  []byte
+}
+
+type compileError struct {
+	err error
+}
+
+func Compile(code []byte) (module *Module, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if compileErr, ok := r.(*compileError); ok {
+				module, err = nil, compileErr.err
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	m := &Module{exports: make(map[string]*export)}
+	m.decode(&reader{buf: code})
+	return m, nil
+}
+
+func (m *Module) ExportedFunctionType(name string) (*FuncType, bool) {
+	exp, found := m.exports[name]
+	if !found || exp.kind != externalFunction {
+		return nil, false
+	}
+	return m.functionType(exp.index), true
+}
+
+func (m *Module) ExportedFunctionNames() []string {
+	var res []string
+	for name, exp := range m.exports {
+		if exp.kind == externalFunction {
+			res = append(res, name)
+		}
+	}
+	sort.Strings(res)
+	return res
+}
+
+func (m *Module) functionType(index uint32) *FuncType {
+	if index < uint32(len(m.imports)) {
+		return m.types[m.imports[index].typeIndex]
+	}
+	return m.functions[index-uint32(len(m.imports))].typ
+}
+
+func (m *Module) numFunctions() uint32 {
+	return uint32(len(m.imports) + len(m.functions))
+}
+
+func fail(format string, args ...interface{}) {
+	panic(&compileError{errors.Errorf(format, args...)})
+}
+
+func (m *Module) decode(r *reader) {
+	if r.len() < 8 || string(r.bytes(4)) != "\x00asm" {
+		fail("not a wasm module")
+	}
+	if version := r.bytes(4); version[0] != 1 || version[1] != 0 || version[2] != 0 || version[3] != 0 {
+		fail("unsupported wasm version")
+	}
+
+	var functionTypeIndices []uint32
+	lastId := byte(0)
+	for r.len() > 0 {
+		id := r.byte()
+		section := &reader{buf: r.bytes(int(r.u32()))}
+		if id != sectionCustom {
+			if id <= lastId && !(id == sectionDataCount && lastId < sectionCode) {
+				fail("section %d out of order", id)
+			}
+			lastId = id
+		}
+
+		switch id {
+		case sectionCustom:
+			// names and debug info are ignored
+		case sectionType:
+			m.decodeTypes(section)
+		case sectionImport:
+			m.decodeImports(section)
+		case sectionFunction:
+			for n := section.u32(); n > 0; n-- {
+				functionTypeIndices = append(functionTypeIndices, m.typeIndex(section.u32()))
+			}
+		case sectionTable:
+			if section.u32() != 1 {
+				fail("only a single table is supported")
+			}
+			if section.byte() != 0x70 {
+				fail("only funcref tables are supported")
+			}
+			m.table = section.limits(MAX_TABLE_SIZE)
+		case sectionMemory:
+			if section.u32() != 1 {
+				fail("only a single memory is supported")
+			}
+			m.memory = section.limits(MAX_MEMORY_PAGES)
+		case sectionGlobal:
+			for n := section.u32(); n > 0; n-- {
+				typ := section.valueType()
+				mutable := section.byte() == 1
+				m.globals = append(m.globals, &global{typ: typ, mutable: mutable, init: m.constExpr(section, typ)})
+			}
+		case sectionExport:
+			for n := section.u32(); n > 0; n-- {
+				name := section.name()
+				exp := &export{kind: section.byte(), index: section.u32()}
+				if exp.kind == externalFunction && exp.index >= m.numFunctionsDeclared(functionTypeIndices) {
+					fail("export '%s' refers to unknown function %d", name, exp.index)
+				}
+				if _, found := m.exports[name]; found {
+					fail("duplicate export '%s'", name)
+				}
+				m.exports[name] = exp
+			}
+		case sectionStart:
+			index := section.u32()
+			if index >= m.numFunctionsDeclared(functionTypeIndices) {
+				fail("start refers to unknown function %d", index)
+			}
+			m.start = &index
+		case sectionElement:
+			m.decodeElements(section, functionTypeIndices)
+		case sectionCode:
+			m.decodeCode(section, functionTypeIndices)
+		case sectionData:
+			m.decodeData(section)
+		case sectionDataCount:
+			section.u32()
+		default:
+			fail("unknown section %d", id)
+		}
+
+		if id != sectionCustom && section.len() != 0 {
+			fail("section %d has trailing bytes", id)
+		}
+	}
+
+	if len(m.functions) != len(functionTypeIndices) {
+		fail("function and code sections do not match")
+	}
+	if m.start != nil {
+		if t := m.functionType(*m.start); len(t.Params) != 0 || len(t.Results) != 0 {
+			fail("start function must take no params and return no results")
+		}
+	}
+}
+
+func (m *Module) numFunctionsDeclared(functionTypeIndices []uint32) uint32 {
+	return uint32(len(m.imports) + len(functionTypeIndices))
+}
+
+func (m *Module) typeIndex(index uint32) uint32 {
+	if index >= uint32(len(m.types)) {
+		fail("unknown type %d", index)
+	}
+	return index
+}
+
+func (m *Module) decodeTypes(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		if r.byte() != 0x60 {
+			fail("malformed function type")
+		}
+		t := &FuncType{}
+		for p := r.u32(); p > 0; p-- {
+			t.Params = append(t.Params, r.valueType())
+		}
+		for p := r.u32(); p > 0; p-- {
+			t.Results = append(t.Results, r.valueType())
+		}
+		m.types = append(m.types, t)
+	}
+}
+
+func (m *Module) decodeImports(r *reader) {
+	for n := r.u32(); n > 0; n-- {
+		imp := &importedFunction{module: r.name(), name: r.name()}
+		if r.byte() != externalFunction {
+			fail("import '%s.%s' is not a function, only function imports are supported", imp.module, imp.name)
+		}
+		imp.typeIndex = m.typeIndex(r.u32())
+		m.imports = append(m.imports, imp)
+	}
+}
+
+func (m *Module) decodeElements(r *reader, functionTypeIndices []uint32) {
+	if m.table == nil {
+		fail("element section without a table")
+	}
+	for n := r.u32(); n > 0; n-- {
+		if r.u32() != 0 {
+			fail("only active element segments of table 0 are supported")
+		}
+		segment := &elementSegment{offset: uint32(m.constExpr(r, I32))}
+		for f := r.u32(); f > 0; f-- {
+			index := r.u32()
+			if index >= m.numFunctionsDeclared(functionTypeIndices) {
+				fail("element refers to unknown function %d", index)
+			}
+			segment.functions = append(segment.functions, index)
+		}
+		m.elements = append(m.elements, segment)
+	}
+}
+
+func (m *Module) decodeData(r *reader) {
+	if m.memory == nil {
+		fail("data section without a memory")
+	}
+	for n := r.u32(); n > 0; n-- {
+		switch r.u32() {
+		case 0:
+		case 2:
+			if r.u32() != 0 {
+				fail("data segment refers to unknown memory")
+			}
+		default:
+			fail("only active data segments are supported")
+		}
+		segment := &dataSegment{offset: uint32(m.constExpr(r, I32))}
+		segment.bytes = r.bytes(int(r.u32()))
+		m.data = append(m.data, segment)
+	}
+}
+
+func (m *Module) decodeCode(r *reader, functionTypeIndices []uint32) {
+	n := r.u32()
+	if n != uint32(len(functionTypeIndices)) {
+		fail("function and code sections do not match")
+	}
+	for i := uint32(0); i < n; i++ {
+		body := &reader{buf: r.bytes(int(r.u32()))}
+		typ := m.types[functionTypeIndices[i]]
+		f := &function{typ: typ, locals: append([]ValueType{}, typ.Params...)}
+		for groups := body.u32(); groups > 0; groups-- {
+			count := body.u32()
+			valueType := body.valueType()
+			if uint64(len(f.locals))+uint64(count) > MAX_LOCALS {
+				fail("function %d has too many locals", i)
+			}
+			for ; count > 0; count-- {
+				f.locals = append(f.locals, valueType)
+			}
+		}
+		f.code = body.buf[body.pos:]
+		m.functions = append(m.functions, f)
+	}
+	for i, f := range m.functions {
+		m.scanCode(uint32(len(m.imports)+i), f)
+	}
+}
+
+// constant expressions initialize globals and segment offsets
+func (m *Module) constExpr(r *reader, typ ValueType) uint64 {
+	var value uint64
+	var valueType ValueType
+	switch op := r.byte(); op {
+	case opI32Const:
+		value, valueType = uint64(uint32(r.s32())), I32
+	case opI64Const:
+		value, valueType = uint64(r.s64()), I64
+	case opGlobalGet:
+		index := r.u32()
+		if index >= uint32(len(m.globals)) {
+			fail("constant expression refers to unknown global %d", index)
+		}
+		value, valueType = m.globals[index].init, m.globals[index].typ
+	default:
+		fail("unsupported constant expression instruction 0x%x", op)
+	}
+	if r.byte() != opEnd {
+		fail("constant expression must be a single instruction")
+	}
+	if valueType != typ {
+		fail("constant expression is %s instead of %s", valueType, typ)
+	}
+	return value
+}
+
+type reader struct {
+	buf []byte
+	pos int
+}
+
+func (r *reader) len() int {
+	return len(r.buf) - r.pos
+}
+
+func (r *reader) byte() byte {
+	if r.pos >= len(r.buf) {
+		fail("unexpected end of module")
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *reader) bytes(n int) []byte {
+	if n < 0 || n > r.len() {
+		fail("unexpected end of module")
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *reader) name() string {
+	return string(r.bytes(int(r.u32())))
+}
+
+func (r *reader) valueType() ValueType {
+	switch t := ValueType(r.byte()); t {
+	case I32, I64:
+		return t
+	case 0x7d, 0x7c:
+		fail("floating point types are not supported")
+	default:
+		fail("unsupported value type 0x%x", byte(t))
+	}
+	return 0
+}
+
+func (r *reader) limits(maxAllowed uint32) *limits {
+	l := &limits{max: maxAllowed}
+	switch r.byte() {
+	case 0:
+		l.min = r.u32()
+	case 1:
+		l.min = r.u32()
+		l.max = r.u32()
+		if l.max > maxAllowed {
+			l.max = maxAllowed
+		}
+	default:
+		fail("malformed limits")
+	}
+	if l.min > l.max {
+		fail("minimum size %d is above the maximum %d", l.min, l.max)
+	}
+	return l
+}
+
+func (r *reader) u32() uint32 {
+	return uint32(r.leb128(32, false))
+}
+
+func (r *reader) s32() int32 {
+	return int32(r.leb128(32, true))
+}
+
+func (r *reader) s64() int64 {
+	return r.leb128(64, true)
+}
+
+func (r *reader) s33() int64 {
+	return r.leb128(33, true)
+}
+
+func (r *reader) leb128(bits uint, signed bool) int64 {
+	var result uint64
+	var shift uint
+	for {
+		b := r.byte()
+		if shift >= bits {
+			fail("integer too long")
+		}
+		result |= uint64(b&0x7f) << shift
+		shift += 7
+		if b&0x80 == 0 {
+			if signed && shift < 64 && b&0x40 != 0 {
+				result |= ^uint64(0) << shift
+			}
+			break
+		}
+	}
+	if !signed && bits < 64 && result>>bits != 0 {
+		fail("integer too large")
+	}
+	return int64(result)
+}
@@ -0,0 +1,164 @@
+package wasm
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/wasm/interpreter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+var LogTag = log.Service("processor-wasm")
+
+// not in the protocol spec yet, deploy wasm contracts with this processor type
+const PROCESSOR_TYPE_WASM = protocol.ProcessorType(3)
+
+// part of consensus, every executed instruction is charged to the execution budget of the call
+const WASM_EXECUTION_COST_PER_INSTRUCTION = 1
+
+type service struct {
+	logger log.BasicLogger
+
+	mutex                        *sync.RWMutex
+	contractSdkHandlerUnderMutex handlers.ContractSdkCallHandler
+	contractsUnderMutex          map[string]*interpreter.Module // by code hash
+}
+
+func NewWasmProcessor(logger log.BasicLogger) services.Processor {
+	return &service{
+		logger:              logger.WithTags(LogTag),
+		mutex:               &sync.RWMutex{},
+		contractsUnderMutex: make(map[string]*interpreter.Module),
+	}
+}
+
+// runs once on system initialization (called by the virtual machine constructor)
+func (s *service) RegisterContractSdkCallHandler(handler handlers.ContractSdkCallHandler) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.contractSdkHandlerUnderMutex = handler
+}
+
+func (s *service) ProcessCall(ctx context.Context, input *services.ProcessCallInput) (*services.ProcessCallOutput, error) {
+	// retrieve code
+	module, err := s.retrieveContractFromRepository(ctx, input.ContextId, input.ContractName)
+	if err != nil {
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: (&protocol.MethodArgumentArrayBuilder{}).Build(),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+		}, err
+	}
+
+	// check permissions
+	err = native.VerifyUntypedMethodPermissions(input.ContractName, input.MethodName, input.CallingService, input.CallingPermissionScope)
+	if err != nil {
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: native.ArgsToMethodArgumentArray(err.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+		}, err
+	}
+
+	// execute
+	outputArgs, contractErr, err := s.processMethodCall(ctx, input.ContextId, module, input.MethodName, input.InputArgumentArray)
+	if outputArgs == nil {
+		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
+	}
+	if err != nil {
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: outputArgs,
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+		}, err
+	}
+
+	// result
+	callResult := protocol.EXECUTION_RESULT_SUCCESS
+	if contractErr != nil {
+		callResult = protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT
+	}
+	return &services.ProcessCallOutput{
+		OutputArgumentArray: outputArgs,
+		CallResult:          callResult,
+	}, contractErr
+}
+
+func (s *service) GetContractInfo(ctx context.Context, input *services.GetContractInfoInput) (*services.GetContractInfoOutput, error) {
+	// makes sure the contract is deployed
+	_, err := s.retrieveContractFromRepository(ctx, input.ContextId, input.ContractName)
+	if err != nil {
+		return nil, err
+	}
+
+	// methods read their args through host functions so their types can't be described (see native.ContractDescriber)
+	return &services.GetContractInfoOutput{
+		PermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+	}, nil
+}
+
+// every call runs on a fresh instance so nothing leaks between calls except through state
+func (s *service) processMethodCall(ctx context.Context, executionContextId primitives.ExecutionContextId, module *interpreter.Module, methodName primitives.MethodName, args *protocol.MethodArgumentArray) (contractOutputArgs *protocol.MethodArgumentArray, contractOutputErr error, err error) {
+	methodType, found := module.ExportedFunctionType(string(methodName))
+	if !found && methodName == native.METHOD_NAME_PRE_ORDER { // the contract has no pre-order hook
+		return nil, nil, nil
+	}
+	if !found {
+		return nil, nil, errors.Errorf("method '%s' not found in contract", methodName)
+	}
+	if len(methodType.Params) != 0 || len(methodType.Results) != 0 {
+		return nil, nil, errors.Errorf("method '%s' has type %s but contract methods must not take params or return results", methodName, methodType)
+	}
+
+	inputArgs, err := native.ParseMethodArgumentArray(args)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	handler := s.getContractSdkHandler()
+	meter := func(instructions uint64) error {
+		return native.ChargeExecutionUnits(ctx, handler, executionContextId, instructions*WASM_EXECUTION_COST_PER_INSTRUCTION)
+	}
+	host := newHost(ctx, sdk.Context(executionContextId), handler, inputArgs)
+	instance, err := interpreter.Instantiate(module, host.imports(), meter)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "contract failed to instantiate")
+	}
+
+	_, err = instance.Invoke(string(methodName))
+	if err != nil {
+		return native.ArgsToMethodArgumentArray(err.Error()), err, nil
+	}
+	return native.ArgsToMethodArgumentArray(host.outputArgs...), nil, nil
+}
+
+func (s *service) getContractSdkHandler() handlers.ContractSdkCallHandler {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	return s.contractSdkHandlerUnderMutex
+}
+
+func (s *service) getContractFromRepository(codeHash primitives.Sha256) *interpreter.Module {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	if s.contractsUnderMutex == nil {
+		return nil
+	}
+	return s.contractsUnderMutex[codeHash.KeyForMap()]
+}
+
+func (s *service) addContractToRepository(codeHash primitives.Sha256, module *interpreter.Module) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.contractsUnderMutex == nil {
+		return
+	}
+	s.contractsUnderMutex[codeHash.KeyForMap()] = module
+}
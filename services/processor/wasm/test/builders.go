@@ -0,0 +1,62 @@
+package test
+
+import (
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+)
+
+// process call
+
+type processCall struct {
+	input *services.ProcessCallInput
+}
+
+func processCallInput() *processCall {
+	p := &processCall{
+		input: &services.ProcessCallInput{
+			ContextId:              0,
+			ContractName:           "CounterFrom100",
+			MethodName:             "get",
+			InputArgumentArray:     (&protocol.MethodArgumentArrayBuilder{}).Build(),
+			AccessScope:            protocol.ACCESS_SCOPE_READ_ONLY,
+			CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+			CallingService:         "",
+		},
+	}
+	return p
+}
+
+func (p *processCall) Build() *services.ProcessCallInput {
+	if p.input.CallingService == "" {
+		p.WithSameCallingService()
+	}
+	return p.input
+}
+
+func (p *processCall) WithMethod(contractName primitives.ContractName, methodName primitives.MethodName) *processCall {
+	p.input.ContractName = contractName
+	p.input.MethodName = methodName
+	return p
+}
+
+func (p *processCall) WithSameCallingService() *processCall {
+	p.input.CallingService = p.input.ContractName
+	return p
+}
+
+func (p *processCall) WithDifferentCallingService() *processCall {
+	p.input.CallingService = "DifferentFrom" + p.input.ContractName
+	return p
+}
+
+func (p *processCall) WithWriteAccess() *processCall {
+	p.input.AccessScope = protocol.ACCESS_SCOPE_READ_WRITE
+	return p
+}
+
+func (p *processCall) WithArgs(args ...interface{}) *processCall {
+	p.input.InputArgumentArray = builders.MethodArgumentsArray(args...)
+	return p
+}
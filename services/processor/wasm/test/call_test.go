@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestProcessCall_DispatchesToMethodWithArgs(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "add").WithArgs(uint64(5)).WithWriteAccess().Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+		h.expectSdkCallMadeWithStateRead(nil, uint64ToBytes(100))
+		h.expectSdkCallMadeWithStateWrite(nil, uint64ToBytes(105))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult, "call result should be success")
+		require.False(t, output.OutputArgumentArray.ArgumentsIterator().HasNext(), "method without return value should have no outputs")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_ReturnsMethodOutput(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "get").Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+		h.expectSdkCallMadeWithStateRead(nil, uint64ToBytes(117))
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, builders.MethodArgumentsArray(uint64(117)).Raw(), output.OutputArgumentArray.Raw(), "call should return the counter value")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_ContractFailureIsSmartContractError(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "add").WithWriteAccess().Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.EqualError(t, err, "add takes a single amount", "call should fail with the contract error")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, output.CallResult, "call result should be smart contract error")
+		require.Equal(t, builders.MethodArgumentsArray("add takes a single amount").Raw(), output.OutputArgumentArray.Raw(), "call should return the contract error")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+func TestProcessCall_UnknownMethodFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "unknownMethod").Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, output.CallResult, "call result should be unexpected error")
+	})
+}
+
+func TestProcessCall_UndefinedPreOrderHookApproves(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", native.METHOD_NAME_PRE_ORDER).WithArgs("add", []byte{}).Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.NoError(t, err, "call should succeed")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.CallResult, "call result should be success")
+		require.False(t, output.OutputArgumentArray.ArgumentsIterator().HasNext(), "undefined pre order hook should have no outputs")
+	})
+}
+
+func TestProcessCall_InternalMethodFromDifferentServiceFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		input := processCallInput().WithMethod("CounterFrom100", "_init").WithDifferentCallingService().Build()
+		h.expectCounterCodeRetrieved(input.ContractName, 100)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, output.CallResult, "call result should be unexpected error")
+
+		h.verifySdkCallMade(t)
+	})
+}
+
+// exports a method named loop that branches back to the start of its loop forever
+var ENDLESS_LOOP_WASM_CODE = []byte{
+	0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00,
+	0x01, 0x04, 0x01, 0x60, 0x00, 0x00, // type () -> ()
+	0x03, 0x02, 0x01, 0x00, // function of type 0
+	0x07, 0x08, 0x01, 0x04, 'l', 'o', 'o', 'p', 0x00, 0x00, // export function 0 as loop
+	0x0a, 0x09, 0x01, 0x07, 0x00, 0x03, 0x40, 0x0c, 0x00, 0x0b, 0x0b, // loop, br 0, end, end
+}
+
+func TestProcessCall_EndlessLoopExhaustsExecutionBudget(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.executionBudget = 100 * 1000
+		input := processCallInput().WithMethod("EndlessLoop", "loop").Build()
+		h.expectCodeRetrieved(input.ContractName, ENDLESS_LOOP_WASM_CODE)
+
+		output, err := h.service.ProcessCall(ctx, input)
+		require.Error(t, err, "call should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, output.CallResult, "call result should be smart contract error")
+		require.Zero(t, h.executionBudget, "endless loop should use the whole budget")
+	})
+}
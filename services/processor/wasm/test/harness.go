@@ -0,0 +1,148 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/processor/wasm"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-network-go/test/contracts"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+)
+
+type harness struct {
+	sdkCallHandler  *contractSdkCallHandlerMock
+	service         services.Processor
+	executionBudget uint64
+}
+
+// the spec mock can't charge execution units since processors charge them outside of HandleSdkCall
+type contractSdkCallHandlerMock struct {
+	handlers.MockContractSdkCallHandler
+}
+
+func (h *contractSdkCallHandlerMock) ChargeExecutionUnits(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+	ret := h.Called(ctx, executionContextId, units)
+	return ret.Error(0)
+}
+
+const DEFAULT_TEST_EXECUTION_BUDGET = 1000 * 1000 * 1000
+
+func newHarness() *harness {
+	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+
+	sdkCallHandler := &contractSdkCallHandlerMock{}
+
+	service := wasm.NewWasmProcessor(log)
+	service.RegisterContractSdkCallHandler(sdkCallHandler)
+
+	h := &harness{
+		sdkCallHandler:  sdkCallHandler,
+		service:         service,
+		executionBudget: DEFAULT_TEST_EXECUTION_BUDGET,
+	}
+	h.expectExecutionMeterCharged()
+	return h
+}
+
+// executed instructions are charged while the contract runs, they are charged to the budget of the harness like the virtual machine does
+func (h *harness) expectExecutionMeterCharged() {
+	h.sdkCallHandler.When("ChargeExecutionUnits", mock.Any, mock.Any, mock.Any).Call(func(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+		if units > h.executionBudget {
+			h.executionBudget = 0
+			return errors.New("execution budget exceeded")
+		}
+		h.executionBudget -= units
+		return nil
+	}).AtLeast(0)
+}
+
+func (h *harness) expectSdkCallMadeWithStateRead(expectedKey []byte, returnValue []byte) {
+	stateReadCallMatcher := func(i interface{}) bool {
+		input, ok := i.(*handlers.HandleSdkCallInput)
+		return ok &&
+			input.OperationName == native.SDK_OPERATION_NAME_STATE &&
+			input.MethodName == "read" &&
+			len(input.InputArguments) == 1 &&
+			(expectedKey == nil || bytes.Equal(input.InputArguments[0].BytesValue(), expectedKey))
+	}
+
+	readReturn := &handlers.HandleSdkCallOutput{
+		OutputArguments: builders.MethodArguments(returnValue),
+	}
+
+	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.State, method equals read and 1 arg matches", stateReadCallMatcher)).Return(readReturn, nil).Times(1)
+}
+
+func (h *harness) expectSdkCallMadeWithStateWrite(expectedKey []byte, expectedValue []byte) {
+	stateWriteCallMatcher := func(i interface{}) bool {
+		input, ok := i.(*handlers.HandleSdkCallInput)
+		return ok &&
+			input.OperationName == native.SDK_OPERATION_NAME_STATE &&
+			input.MethodName == "write" &&
+			len(input.InputArguments) == 2 &&
+			(expectedKey == nil || bytes.Equal(input.InputArguments[0].BytesValue(), expectedKey)) &&
+			(expectedValue == nil || bytes.Equal(input.InputArguments[1].BytesValue(), expectedValue))
+	}
+
+	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.State, method equals write and 2 args match", stateWriteCallMatcher)).Return(nil, nil).Times(1)
+}
+
+func (h *harness) expectSdkCallMadeWithServiceCallMethod(expectedContractName string, expectedMethodName string, expectedArgArray *protocol.MethodArgumentArray, returnArgArray *protocol.MethodArgumentArray, returnError error) {
+	serviceCallMethodCallMatcher := func(i interface{}) bool {
+		input, ok := i.(*handlers.HandleSdkCallInput)
+		return ok &&
+			input.OperationName == native.SDK_OPERATION_NAME_SERVICE &&
+			input.MethodName == "callMethod" &&
+			len(input.InputArguments) == 3 &&
+			input.InputArguments[0].StringValue() == expectedContractName &&
+			input.InputArguments[1].StringValue() == expectedMethodName &&
+			bytes.Equal(input.InputArguments[2].BytesValue(), expectedArgArray.Raw())
+	}
+
+	var returnOutput *handlers.HandleSdkCallOutput
+	if returnArgArray != nil {
+		returnOutput = &handlers.HandleSdkCallOutput{
+			OutputArguments: builders.MethodArguments(returnArgArray.Raw()),
+		}
+	}
+
+	h.sdkCallHandler.When("HandleSdkCall", mock.Any, mock.AnyIf("Contract equals Sdk.Service, method equals callMethod and 3 args match", serviceCallMethodCallMatcher)).Return(returnOutput, returnError).Times(1)
+}
+
+func (h *harness) expectCounterCodeRetrieved(contractName primitives.ContractName, counterStart uint64) {
+	h.expectCodeRetrieved(contractName, contracts.WasmCodeForCounter(counterStart))
+}
+
+func (h *harness) expectCodeRetrieved(contractName primitives.ContractName, code []byte) {
+	h.expectCodeHashRequested(contractName, code)
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE.Name, builders.MethodArgumentsArray(string(contractName)), builders.MethodArgumentsArray(code), nil)
+}
+
+func (h *harness) expectCodeHashRequested(contractName primitives.ContractName, code []byte) {
+	codeHash := sha256.Sum256(code)
+	h.expectSdkCallMadeWithServiceCallMethod(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_CODE_HASH.Name, builders.MethodArgumentsArray(string(contractName)), builders.MethodArgumentsArray(codeHash[:]), nil)
+}
+
+func (h *harness) verifySdkCallMade(t *testing.T) {
+	_, err := h.sdkCallHandler.Verify()
+	require.NoError(t, err, "sdkCallHandler should be called as expected")
+}
+
+func uint64ToBytes(num uint64) []byte {
+	res := make([]byte, 8)
+	binary.LittleEndian.PutUint64(res, num)
+	return res
+}
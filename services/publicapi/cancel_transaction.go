@@ -0,0 +1,82 @@
+package publicapi
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// requests and responses of the http server are plain json with the cancellation itself membuffer encoded (like in send transaction requests)
+type CancelTransactionRequest struct {
+	SignedTransaction []byte `json:"signedTransaction"`
+}
+
+// the transaction status is only given when the cancellation was rejected
+type CancelTransactionResponse struct {
+	RequestStatus      string `json:"requestStatus"`
+	CancellationStatus string `json:"cancellationStatus,omitempty"`
+	TransactionStatus  string `json:"transactionStatus,omitempty"`
+	CancelledTxhash    []byte `json:"cancelledTxhash,omitempty"`
+	BlockHeight        uint64 `json:"blockHeight"`
+	BlockTimestamp     uint64 `json:"blockTimestamp"`
+}
+
+func (s *service) CancelTransaction(parentCtx context.Context, input *transactionpool.CancelTransactionInput) (*transactionpool.CancelTransactionOutput, error) {
+	if input.SignedTransaction == nil {
+		err := errors.Errorf("error: missing input (signed transaction is nil)")
+		s.logger.Info("cancel transaction received via public api failed", log.Error(err))
+		return nil, err
+	}
+
+	ctx := trace.NewContext(parentCtx, "PublicApi.CancelTransaction")
+
+	cancellation := input.SignedTransaction
+	txHash := digest.CalcTxHash(cancellation.Transaction())
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.Transaction(txHash), log.String("flow", "checkpoint"))
+
+	if txStatus := isTransactionRequestValid(s.config, cancellation.Transaction()); txStatus != protocol.TRANSACTION_STATUS_RESERVED {
+		err := &transactionpool.ErrTransactionRejected{TransactionStatus: txStatus, Expected: log.String("cancellation", "valid"), Actual: log.Stringable("cancellation", txStatus)}
+		logger.Info("cancel transaction received input failed", log.Error(err))
+		return nil, err
+	}
+	logger.Info("cancel transaction request received")
+
+	canceller, ok := s.transactionPool.(transactionpool.TransactionCanceller)
+	if !ok {
+		err := errors.New("transaction pool does not support cancelling transactions")
+		logger.Info("cancel transaction request failed", log.Error(err))
+		return nil, err
+	}
+
+	result, err := canceller.CancelTransaction(ctx, input)
+	if err != nil {
+		logger.Info("cancel transaction request failed", log.Error(err))
+	}
+	return result, err
+}
+
+// rejected cancellations are answered with the transaction status they were rejected with, other errors fail the request
+func ToCancelTransactionResponse(result *transactionpool.CancelTransactionOutput, err error) (protocol.RequestStatus, *CancelTransactionResponse) {
+	if rejected, ok := err.(*transactionpool.ErrTransactionRejected); ok {
+		requestStatus := translateTxStatusToResponseCode(rejected.TransactionStatus)
+		return requestStatus, &CancelTransactionResponse{
+			RequestStatus:     requestStatus.String(),
+			TransactionStatus: rejected.TransactionStatus.String(),
+		}
+	}
+	if err != nil || result == nil {
+		return protocol.REQUEST_STATUS_SYSTEM_ERROR, &CancelTransactionResponse{RequestStatus: protocol.REQUEST_STATUS_SYSTEM_ERROR.String()}
+	}
+
+	return protocol.REQUEST_STATUS_COMPLETED, &CancelTransactionResponse{
+		RequestStatus:      protocol.REQUEST_STATUS_COMPLETED.String(),
+		CancellationStatus: result.CancellationStatus.String(),
+		CancelledTxhash:    result.CancelledTxhash,
+		BlockHeight:        uint64(result.BlockHeight),
+		BlockTimestamp:     uint64(result.BlockTimestamp),
+	}
+}
@@ -0,0 +1,143 @@
+package publicapi
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// dry runs are not part of the pinned spec so the http server reaches them through this interface,
+// requests and responses are plain json with the transaction itself membuffer encoded (like in call method requests)
+type DryRunner interface {
+	DryRunTransaction(ctx context.Context, input *DryRunTransactionInput) (*DryRunTransactionOutput, error)
+}
+
+// a zero block height dry runs on top of the last committed block
+type DryRunTransactionRequest struct {
+	Transaction    []byte               `json:"transaction"`
+	BlockHeight    uint64               `json:"blockHeight"`
+	StateOverrides []*ContractStateDiff `json:"stateOverrides"`
+}
+
+type DryRunTransactionInput struct {
+	ClientRequest *DryRunTransactionRequest
+}
+
+type DryRunTransactionOutput struct {
+	RequestStatus  protocol.RequestStatus
+	ClientResponse *DryRunTransactionResponse
+}
+
+type DryRunTransactionResponse struct {
+	RequestStatus       string               `json:"requestStatus"`
+	Txhash              []byte               `json:"txhash,omitempty"`
+	ExecutionResult     string               `json:"executionResult,omitempty"`
+	OutputArgumentArray []byte               `json:"outputArgumentArray,omitempty"`
+	ContractStateDiffs  []*ContractStateDiff `json:"contractStateDiffs"`
+	BlockHeight         uint64               `json:"blockHeight"`
+}
+
+type ContractStateDiff struct {
+	ContractName string         `json:"contractName"`
+	StateDiffs   []*StateRecord `json:"stateDiffs"`
+}
+
+type StateRecord struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+// previews a transaction on top of a recent block (and optional state overrides) and returns the receipt and state diff it would produce, nothing is committed
+func (s *service) DryRunTransaction(parentCtx context.Context, input *DryRunTransactionInput) (*DryRunTransactionOutput, error) {
+	if input.ClientRequest == nil {
+		err := errors.Errorf("error: missing input (client request is nil)")
+		s.logger.Info("dry run transaction received via public api failed", log.Error(err))
+		return nil, err
+	}
+
+	ctx := trace.NewContext(parentCtx, "PublicApi.DryRunTransaction")
+	request := input.ClientRequest
+	blockHeight := primitives.BlockHeight(request.BlockHeight)
+
+	tx := protocol.TransactionReader(request.Transaction)
+	if !tx.IsValid() {
+		err := errors.New("error input transaction is not a valid membuffer")
+		s.logger.Info("dry run transaction received via public api", log.Error(err))
+		return toDryRunTransactionOutput(protocol.REQUEST_STATUS_REJECTED, nil, nil, 0), err
+	}
+	txHash := digest.CalcTxHash(tx)
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.Transaction(txHash))
+
+	if txStatus := isTransactionRequestValid(s.config, tx); txStatus != protocol.TRANSACTION_STATUS_RESERVED {
+		err := errors.Errorf("error input %s", txStatus.String())
+		logger.Info("dry run transaction received via public api", log.Error(err))
+		return toDryRunTransactionOutput(translateTxStatusToResponseCode(txStatus), nil, nil, 0), err
+	}
+	logger.Info("dry run transaction request received via public api", log.BlockHeight(blockHeight))
+
+	simulator, ok := s.virtualMachine.(virtualmachine.Simulator)
+	if !ok {
+		err := errors.New("virtual machine does not support simulating transactions")
+		logger.Info("dry run transaction request failed", log.Error(err))
+		return nil, err
+	}
+
+	result, err := simulator.SimulateTransaction(ctx, &virtualmachine.SimulateTransactionInput{
+		BlockHeight:    blockHeight,
+		Transaction:    tx,
+		StateOverrides: toProtocolContractStateDiffs(request.StateOverrides),
+	})
+	if err != nil {
+		logger.Info("dry run transaction request failed", log.Error(err), log.BlockHeight(blockHeight))
+		return toDryRunTransactionOutput(protocol.REQUEST_STATUS_REJECTED, nil, nil, blockHeight), err
+	}
+
+	return toDryRunTransactionOutput(translateExecutionStatusToResponseCode(result.TransactionReceipt.ExecutionResult()), result.TransactionReceipt, result.ContractStateDiffs, result.ReferenceBlockHeight), nil
+}
+
+func toProtocolContractStateDiffs(contractStateDiffs []*ContractStateDiff) []*protocol.ContractStateDiff {
+	var res []*protocol.ContractStateDiff
+	for _, contractStateDiff := range contractStateDiffs {
+		records := []*protocol.StateRecordBuilder{}
+		for _, record := range contractStateDiff.StateDiffs {
+			records = append(records, &protocol.StateRecordBuilder{Key: record.Key, Value: record.Value})
+		}
+		res = append(res, (&protocol.ContractStateDiffBuilder{
+			ContractName: primitives.ContractName(contractStateDiff.ContractName),
+			StateDiffs:   records,
+		}).Build())
+	}
+	return res
+}
+
+func toDryRunTransactionOutput(requestStatus protocol.RequestStatus, receipt *protocol.TransactionReceipt, contractStateDiffs []*protocol.ContractStateDiff, blockHeight primitives.BlockHeight) *DryRunTransactionOutput {
+	response := &DryRunTransactionResponse{
+		RequestStatus:      requestStatus.String(),
+		ContractStateDiffs: []*ContractStateDiff{},
+		BlockHeight:        uint64(blockHeight),
+	}
+	if receipt != nil {
+		response.Txhash = receipt.Txhash()
+		response.ExecutionResult = receipt.ExecutionResult().String()
+		response.OutputArgumentArray = receipt.OutputArgumentArray()
+	}
+
+	for _, contractStateDiff := range contractStateDiffs {
+		records := []*StateRecord{}
+		for i := contractStateDiff.StateDiffsIterator(); i.HasNext(); {
+			record := i.NextStateDiffs()
+			records = append(records, &StateRecord{Key: record.Key(), Value: record.Value()})
+		}
+		response.ContractStateDiffs = append(response.ContractStateDiffs, &ContractStateDiff{
+			ContractName: string(contractStateDiff.ContractName()),
+			StateDiffs:   records,
+		})
+	}
+
+	return &DryRunTransactionOutput{RequestStatus: requestStatus, ClientResponse: response}
+}
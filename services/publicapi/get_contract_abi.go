@@ -0,0 +1,140 @@
+package publicapi
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// contract abis are not part of the pinned spec so the http server reaches them through this interface,
+// requests and responses are plain json
+type ContractAbiReader interface {
+	GetContractAbi(ctx context.Context, input *GetContractAbiInput) (*GetContractAbiOutput, error)
+}
+
+type GetContractAbiRequest struct {
+	VirtualChainId uint32 `json:"virtualChainId"`
+	ContractName   string `json:"contractName"`
+}
+
+type GetContractAbiInput struct {
+	ClientRequest *GetContractAbiRequest
+}
+
+type GetContractAbiOutput struct {
+	RequestStatus  protocol.RequestStatus
+	ClientResponse *GetContractAbiResponse
+}
+
+// contracts that can't be described (like untyped javascript contracts) have no methods and must not be validated against
+type GetContractAbiResponse struct {
+	RequestStatus   string               `json:"requestStatus"`
+	ContractName    string               `json:"contractName"`
+	PermissionScope string               `json:"permissionScope,omitempty"`
+	Described       bool                 `json:"described"`
+	Methods         []*ContractAbiMethod `json:"methods"`
+	BlockHeight     uint64               `json:"blockHeight"`
+	BlockTimestamp  uint64               `json:"blockTimestamp"`
+}
+
+// types are named like the argument types of gamma-cli json transactions
+type ContractAbiMethod struct {
+	Name        string   `json:"name"`
+	Access      string   `json:"access"`
+	InputTypes  []string `json:"inputTypes"`
+	OutputTypes []string `json:"outputTypes"`
+}
+
+// describes the external methods of a contract so clients can validate calls before sending them
+func (s *service) GetContractAbi(parentCtx context.Context, input *GetContractAbiInput) (*GetContractAbiOutput, error) {
+	if input.ClientRequest == nil {
+		err := errors.Errorf("error: missing input (client request is nil)")
+		s.logger.Info("get contract abi received via public api failed", log.Error(err))
+		return nil, err
+	}
+
+	ctx := trace.NewContext(parentCtx, "PublicApi.GetContractAbi")
+	request := input.ClientRequest
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.String("contract", request.ContractName))
+
+	if s.config.VirtualChainId() != primitives.VirtualChainId(request.VirtualChainId) {
+		err := errors.Errorf("error input %s", protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_MISMATCH)
+		logger.Info("get contract abi received via public api", log.Error(err))
+		return toGetContractAbiOutput(protocol.REQUEST_STATUS_REJECTED, request.ContractName, nil), err
+	}
+	logger.Info("get contract abi request received via public api")
+
+	abiReader, ok := s.virtualMachine.(virtualmachine.ContractAbiReader)
+	if !ok {
+		err := errors.New("virtual machine does not support describing contracts")
+		logger.Info("get contract abi request failed", log.Error(err))
+		return nil, err
+	}
+
+	result, err := abiReader.GetLocalContractAbi(ctx, &virtualmachine.GetLocalContractAbiInput{
+		ContractName: primitives.ContractName(request.ContractName),
+	})
+	if err != nil {
+		logger.Info("get contract abi request failed", log.Error(err))
+		return toGetContractAbiOutput(protocol.REQUEST_STATUS_NOT_FOUND, request.ContractName, nil), err
+	}
+
+	return toGetContractAbiOutput(protocol.REQUEST_STATUS_COMPLETED, request.ContractName, result), nil
+}
+
+func toGetContractAbiOutput(requestStatus protocol.RequestStatus, contractName string, abi *virtualmachine.GetLocalContractAbiOutput) *GetContractAbiOutput {
+	response := &GetContractAbiResponse{
+		RequestStatus: requestStatus.String(),
+		ContractName:  contractName,
+		Methods:       []*ContractAbiMethod{},
+	}
+	if abi != nil {
+		response.PermissionScope = abi.PermissionScope.String()
+		response.Described = abi.Described
+		response.BlockHeight = uint64(abi.ReferenceBlockHeight)
+		response.BlockTimestamp = uint64(abi.ReferenceBlockTimestamp)
+		for _, method := range abi.Methods {
+			response.Methods = append(response.Methods, toContractAbiMethod(method))
+		}
+	}
+
+	return &GetContractAbiOutput{RequestStatus: requestStatus, ClientResponse: response}
+}
+
+func toContractAbiMethod(method *native.ContractMethod) *ContractAbiMethod {
+	res := &ContractAbiMethod{
+		Name:        string(method.MethodName),
+		Access:      "read-only",
+		InputTypes:  []string{},
+		OutputTypes: []string{},
+	}
+	if method.AccessScope == protocol.ACCESS_SCOPE_READ_WRITE {
+		res.Access = "read-write"
+	}
+	for _, argType := range method.InputArgumentTypes {
+		res.InputTypes = append(res.InputTypes, methodArgumentTypeName(argType))
+	}
+	for _, argType := range method.OutputArgumentTypes {
+		res.OutputTypes = append(res.OutputTypes, methodArgumentTypeName(argType))
+	}
+	return res
+}
+
+func methodArgumentTypeName(argType protocol.MethodArgumentType) string {
+	switch argType {
+	case protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE:
+		return "uint32"
+	case protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE:
+		return "uint64"
+	case protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE:
+		return "string"
+	case protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE:
+		return "bytes"
+	}
+	return argType.String()
+}
@@ -29,7 +29,8 @@ type service struct {
 	blockStorage    services.BlockStorage
 	logger          log.BasicLogger
 
-	waiter *waiter
+	waiter       *waiter
+	tracingSlots chan struct{}
 
 	metrics *metrics
 }
@@ -63,8 +64,9 @@ func NewPublicApi(
 		blockStorage:    blockStorage,
 		logger:          logger.WithTags(LogTag),
 
-		waiter:  newWaiter(),
-		metrics: newMetrics(metricFactory, config.SendTransactionTimeout(), 2*time.Second, 1*time.Second),
+		waiter:       newWaiter(),
+		tracingSlots: make(chan struct{}, config.PublicApiTracingConcurrency()),
+		metrics:      newMetrics(metricFactory, config.SendTransactionTimeout(), 2*time.Second, 1*time.Second),
 	}
 
 	transactionPool.RegisterTransactionResultsHandler(s)
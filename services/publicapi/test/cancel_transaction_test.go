@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+type cancellerTxPoolMock struct {
+	services.MockTransactionPool
+}
+
+func (m *cancellerTxPoolMock) CancelTransaction(ctx context.Context, input *transactionpool.CancelTransactionInput) (*transactionpool.CancelTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*transactionpool.CancelTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func newPublicApiWithCancellerTxPool(txpMock *cancellerTxPoolMock, txTimeout time.Duration) services.PublicApi {
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+	cfg := config.ForPublicApiTests(uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID), txTimeout)
+	txpMock.When("RegisterTransactionResultsHandler", mock.Any).Return(nil)
+	return publicapi.NewPublicApi(cfg, txpMock, &services.MockVirtualMachine{}, &services.MockBlockStorage{}, logger, metric.NewRegistry())
+}
+
+func TestCancelTransaction_ReturnsCancellationStatusFromPool(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		txpMock := &cancellerTxPoolMock{}
+		papi := newPublicApiWithCancellerTxPool(txpMock, 1*time.Millisecond)
+
+		cancelledTxHash := digest.CalcTxHash(builders.Transaction().Build().Transaction())
+		txpMock.When("CancelTransaction", mock.Any, mock.Any).Return(&transactionpool.CancelTransactionOutput{
+			CancellationStatus: transactionpool.CANCELLATION_STATUS_CANCELLED,
+			CancelledTxhash:    cancelledTxHash,
+		}, nil).Times(1)
+
+		result, err := papi.(transactionpool.TransactionCanceller).CancelTransaction(ctx, &transactionpool.CancelTransactionInput{
+			SignedTransaction: builders.TransferTransaction().WithCancellationOf(cancelledTxHash).Build(),
+		})
+
+		ok, verifyErr := txpMock.Verify()
+		require.True(t, ok, "transaction pool should be asked to cancel the transaction: %v", verifyErr)
+
+		require.NoError(t, err, "error happened when it should not")
+		requestStatus, response := publicapi.ToCancelTransactionResponse(result, err)
+		require.Equal(t, protocol.REQUEST_STATUS_COMPLETED, requestStatus, "got wrong status")
+		require.Equal(t, transactionpool.CANCELLATION_STATUS_CANCELLED.String(), response.CancellationStatus, "got wrong cancellation status")
+		require.EqualValues(t, cancelledTxHash, response.CancelledTxhash, "got wrong cancelled txhash")
+	})
+}
+
+func TestCancelTransaction_RejectsCancellationForOtherVirtualChain(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		txpMock := &cancellerTxPoolMock{}
+		papi := newPublicApiWithCancellerTxPool(txpMock, 1*time.Millisecond)
+
+		txpMock.Never("CancelTransaction", mock.Any, mock.Any)
+
+		cancelledTxHash := digest.CalcTxHash(builders.Transaction().Build().Transaction())
+		result, err := papi.(transactionpool.TransactionCanceller).CancelTransaction(ctx, &transactionpool.CancelTransactionInput{
+			SignedTransaction: builders.TransferTransaction().WithVirtualChainId(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID + 1).WithCancellationOf(cancelledTxHash).Build(),
+		})
+
+		ok, verifyErr := txpMock.Verify()
+		require.True(t, ok, "transaction pool should not be asked to cancel the transaction: %v", verifyErr)
+
+		require.Error(t, err, "cancellation for another virtual chain should be rejected")
+		requestStatus, response := publicapi.ToCancelTransactionResponse(result, err)
+		require.Equal(t, protocol.REQUEST_STATUS_REJECTED, requestStatus, "got wrong status")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_MISMATCH.String(), response.TransactionStatus, "got wrong transaction status")
+	})
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+type simulatorVmMock struct {
+	services.MockVirtualMachine
+}
+
+func (m *simulatorVmMock) SimulateTransaction(ctx context.Context, input *virtualmachine.SimulateTransactionInput) (*virtualmachine.SimulateTransactionOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*virtualmachine.SimulateTransactionOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func newPublicApiWithSimulatorVm(vmMock *simulatorVmMock) publicapi.DryRunner {
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+	cfg := config.ForPublicApiTests(uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID), 1*time.Millisecond)
+	papi := publicapi.NewPublicApi(cfg, makeTxMock(), vmMock, &services.MockBlockStorage{}, logger, metric.NewRegistry())
+	return papi.(publicapi.DryRunner)
+}
+
+func TestDryRunTransaction_ReturnsReceiptAndStateDiffFromVm(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		vmMock := &simulatorVmMock{}
+		papi := newPublicApiWithSimulatorVm(vmMock)
+
+		vmMock.When("SimulateTransaction", mock.Any, mock.Any).Times(1).Call(func(ctx context.Context, input *virtualmachine.SimulateTransactionInput) (*virtualmachine.SimulateTransactionOutput, error) {
+			require.EqualValues(t, 10, input.BlockHeight, "vm should simulate on the requested block height")
+			require.Len(t, input.StateOverrides, 1, "vm should get the requested state overrides")
+			require.Equal(t, primitives.ContractName("Contract1"), input.StateOverrides[0].ContractName(), "vm should get the requested state overrides")
+			return &virtualmachine.SimulateTransactionOutput{
+				TransactionReceipt: builders.TransactionReceipt().Build(),
+				ContractStateDiffs: []*protocol.ContractStateDiff{(&protocol.ContractStateDiffBuilder{
+					ContractName: "Contract1",
+					StateDiffs:   []*protocol.StateRecordBuilder{{Key: []byte{0x03}, Value: []byte{0x33}}},
+				}).Build()},
+				ReferenceBlockHeight: 10,
+			}, nil
+		})
+
+		result, err := papi.DryRunTransaction(ctx, &publicapi.DryRunTransactionInput{
+			ClientRequest: &publicapi.DryRunTransactionRequest{
+				Transaction: builders.NonSignedTransaction().Build().Raw(),
+				BlockHeight: 10,
+				StateOverrides: []*publicapi.ContractStateDiff{
+					{ContractName: "Contract1", StateDiffs: []*publicapi.StateRecord{{Key: []byte{0x01}, Value: []byte{0x11}}}},
+				},
+			},
+		})
+
+		ok, verifyErr := vmMock.Verify()
+		require.True(t, ok, "vm should be asked to simulate the transaction: %v", verifyErr)
+
+		require.NoError(t, err, "error happened when it should not")
+		require.Equal(t, protocol.REQUEST_STATUS_COMPLETED, result.RequestStatus, "got wrong status")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS.String(), result.ClientResponse.ExecutionResult, "got wrong execution result")
+		require.EqualValues(t, 10, result.ClientResponse.BlockHeight, "got wrong block height")
+		require.Equal(t, []*publicapi.ContractStateDiff{
+			{ContractName: "Contract1", StateDiffs: []*publicapi.StateRecord{{Key: []byte{0x03}, Value: []byte{0x33}}}},
+		}, result.ClientResponse.ContractStateDiffs, "state diff should be returned as simulated by the vm")
+	})
+}
+
+func TestDryRunTransaction_RejectsInvalidTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		vmMock := &simulatorVmMock{}
+		papi := newPublicApiWithSimulatorVm(vmMock)
+
+		vmMock.Never("SimulateTransaction", mock.Any, mock.Any)
+
+		result, err := papi.DryRunTransaction(ctx, &publicapi.DryRunTransactionInput{
+			ClientRequest: &publicapi.DryRunTransactionRequest{
+				Transaction: []byte{0x01, 0x02},
+			},
+		})
+
+		ok, verifyErr := vmMock.Verify()
+		require.True(t, ok, "vm should not be asked to simulate the transaction: %v", verifyErr)
+
+		require.Error(t, err, "invalid transaction should fail")
+		require.Equal(t, protocol.REQUEST_STATUS_REJECTED, result.RequestStatus, "got wrong status")
+	})
+}
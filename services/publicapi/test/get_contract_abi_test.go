@@ -0,0 +1,101 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/publicapi"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"os"
+	"testing"
+	"time"
+)
+
+type contractAbiVmMock struct {
+	services.MockVirtualMachine
+}
+
+func (m *contractAbiVmMock) GetLocalContractAbi(ctx context.Context, input *virtualmachine.GetLocalContractAbiInput) (*virtualmachine.GetLocalContractAbiOutput, error) {
+	ret := m.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*virtualmachine.GetLocalContractAbiOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
+func newPublicApiWithContractAbiVm(vmMock *contractAbiVmMock) publicapi.ContractAbiReader {
+	logger := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+	cfg := config.ForPublicApiTests(uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID), 1*time.Millisecond)
+	papi := publicapi.NewPublicApi(cfg, makeTxMock(), vmMock, &services.MockBlockStorage{}, logger, metric.NewRegistry())
+	return papi.(publicapi.ContractAbiReader)
+}
+
+func TestGetContractAbi_ReturnsMethodsFromVm(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		vmMock := &contractAbiVmMock{}
+		papi := newPublicApiWithContractAbiVm(vmMock)
+
+		vmMock.When("GetLocalContractAbi", mock.Any, mock.Any).Times(1).Return(&virtualmachine.GetLocalContractAbiOutput{
+			PermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+			Described:       true,
+			Methods: []*native.ContractMethod{
+				{
+					MethodName:          "add",
+					AccessScope:         protocol.ACCESS_SCOPE_READ_ONLY,
+					InputArgumentTypes:  []protocol.MethodArgumentType{protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE},
+					OutputArgumentTypes: []protocol.MethodArgumentType{protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE},
+				},
+			},
+			ReferenceBlockHeight: 12,
+		}, nil)
+
+		result, err := papi.GetContractAbi(ctx, &publicapi.GetContractAbiInput{
+			ClientRequest: &publicapi.GetContractAbiRequest{
+				VirtualChainId: uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID),
+				ContractName:   "BenchmarkContract",
+			},
+		})
+
+		ok, verifyErr := vmMock.Verify()
+		require.True(t, ok, "vm should be asked for the abi: %v", verifyErr)
+
+		require.NoError(t, err, "error happened when it should not")
+		require.Equal(t, protocol.REQUEST_STATUS_COMPLETED, result.RequestStatus, "got wrong status")
+		require.EqualValues(t, 12, result.ClientResponse.BlockHeight, "got wrong block height")
+		require.True(t, result.ClientResponse.Described, "contract should be described")
+		require.Equal(t, []*publicapi.ContractAbiMethod{
+			{Name: "add", Access: "read-only", InputTypes: []string{"uint64", "bytes"}, OutputTypes: []string{"uint64"}},
+		}, result.ClientResponse.Methods, "methods should be returned as described by the vm")
+	})
+}
+
+func TestGetContractAbi_RejectsVirtualChainMismatch(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		vmMock := &contractAbiVmMock{}
+		papi := newPublicApiWithContractAbiVm(vmMock)
+
+		vmMock.Never("GetLocalContractAbi", mock.Any, mock.Any)
+
+		result, err := papi.GetContractAbi(ctx, &publicapi.GetContractAbiInput{
+			ClientRequest: &publicapi.GetContractAbiRequest{
+				VirtualChainId: uint32(builders.DEFAULT_TEST_VIRTUAL_CHAIN_ID) + 1,
+				ContractName:   "BenchmarkContract",
+			},
+		})
+
+		ok, verifyErr := vmMock.Verify()
+		require.True(t, ok, "vm should not be asked for the abi: %v", verifyErr)
+
+		require.Error(t, err, "virtual chain mismatch should fail")
+		require.Equal(t, protocol.REQUEST_STATUS_REJECTED, result.RequestStatus, "got wrong status")
+	})
+}
@@ -0,0 +1,248 @@
+package publicapi
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/blockstorage"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/protocol/client"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+)
+
+// tracing is not part of the pinned spec so the http server reaches it through this interface,
+// requests reuse the call method and get transaction status client messages and responses are plain json
+type Tracer interface {
+	TraceCallMethod(ctx context.Context, input *TraceCallMethodInput) (*TraceCallMethodOutput, error)
+	TraceTransaction(ctx context.Context, input *TraceTransactionInput) (*TraceTransactionOutput, error)
+}
+
+type TraceCallMethodInput struct {
+	ClientRequest *client.CallMethodRequest
+}
+
+type TraceCallMethodOutput struct {
+	RequestStatus  protocol.RequestStatus
+	ClientResponse *TraceCallMethodResponse
+}
+
+type TraceCallMethodResponse struct {
+	RequestStatus       string          `json:"requestStatus"`
+	CallMethodResult    string          `json:"callMethodResult"`
+	OutputArgumentArray []byte          `json:"outputArgumentArray"`
+	BlockHeight         uint64          `json:"blockHeight"`
+	BlockTimestamp      uint64          `json:"blockTimestamp"`
+	Trace               json.RawMessage `json:"trace"`
+}
+
+type TraceTransactionInput struct {
+	ClientRequest *client.GetTransactionStatusRequest
+}
+
+type TraceTransactionOutput struct {
+	RequestStatus  protocol.RequestStatus
+	ClientResponse *TraceTransactionResponse
+}
+
+type TraceTransactionResponse struct {
+	RequestStatus       string          `json:"requestStatus"`
+	TransactionStatus   string          `json:"transactionStatus"`
+	ExecutionResult     string          `json:"executionResult,omitempty"`
+	OutputArgumentArray []byte          `json:"outputArgumentArray,omitempty"`
+	BlockHeight         uint64          `json:"blockHeight"`
+	BlockTimestamp      uint64          `json:"blockTimestamp"`
+	Trace               json.RawMessage `json:"trace"`
+}
+
+type ErrTracingUnavailable struct {
+	RequestStatus protocol.RequestStatus
+	Reason        string
+}
+
+func (e *ErrTracingUnavailable) Error() string {
+	return e.Reason
+}
+
+// tracing re-executes contracts on demand so it must be enabled explicitly and only a few traces may run at once
+func (s *service) acquireTracingSlot() *ErrTracingUnavailable {
+	if !s.config.PublicApiTracingEnabled() {
+		return &ErrTracingUnavailable{protocol.REQUEST_STATUS_REJECTED, "tracing is disabled on this node"}
+	}
+	select {
+	case s.tracingSlots <- struct{}{}:
+		return nil
+	default:
+		return &ErrTracingUnavailable{protocol.REQUEST_STATUS_CONGESTION, "too many traces are running on this node"}
+	}
+}
+
+func (s *service) releaseTracingSlot() {
+	<-s.tracingSlots
+}
+
+// dry runs a method like CallMethod and returns the execution trace (json) along with the result
+func (s *service) TraceCallMethod(parentCtx context.Context, input *TraceCallMethodInput) (*TraceCallMethodOutput, error) {
+	if input.ClientRequest == nil {
+		err := errors.Errorf("error: missing input (client request is nil)")
+		s.logger.Info("trace call method received via public api failed", log.Error(err))
+		return nil, err
+	}
+
+	ctx := trace.NewContext(parentCtx, "PublicApi.TraceCallMethod")
+	tx := input.ClientRequest.Transaction()
+	txHash := digest.CalcTxHash(tx)
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.Transaction(txHash))
+
+	if err := s.acquireTracingSlot(); err != nil {
+		logger.Info("trace call method received via public api", log.Error(err))
+		return toTraceCallMethodOutput(err.RequestStatus, &virtualmachine.TraceLocalMethodOutput{CallResult: protocol.EXECUTION_RESULT_RESERVED}), err
+	}
+	defer s.releaseTracingSlot()
+
+	if txStatus := isTransactionRequestValid(s.config, tx); txStatus != protocol.TRANSACTION_STATUS_RESERVED {
+		err := errors.Errorf("error input %s", txStatus.String())
+		logger.Info("trace call method received via public api", log.Error(err))
+		return toTraceCallMethodOutput(protocol.REQUEST_STATUS_REJECTED, &virtualmachine.TraceLocalMethodOutput{CallResult: protocol.EXECUTION_RESULT_ERROR_INPUT}), err
+	}
+	logger.Info("trace call method request received via public api")
+
+	tracer, ok := s.virtualMachine.(virtualmachine.Tracer)
+	if !ok {
+		err := errors.New("virtual machine does not support tracing")
+		logger.Info("trace call method request failed", log.Error(err))
+		return nil, err
+	}
+
+	result, err := tracer.TraceLocalMethod(ctx, &virtualmachine.TraceLocalMethodInput{
+		Transaction: tx,
+	})
+	if result == nil {
+		result = &virtualmachine.TraceLocalMethodOutput{CallResult: protocol.EXECUTION_RESULT_ERROR_UNEXPECTED}
+	}
+	if err != nil {
+		logger.Info("trace call method request failed", log.Error(err))
+	}
+
+	return toTraceCallMethodOutput(translateExecutionStatusToResponseCode(result.CallResult), result), err
+}
+
+func toTraceCallMethodOutput(requestStatus protocol.RequestStatus, output *virtualmachine.TraceLocalMethodOutput) *TraceCallMethodOutput {
+	response := &TraceCallMethodResponse{
+		RequestStatus:       requestStatus.String(),
+		CallMethodResult:    output.CallResult.String(),
+		OutputArgumentArray: output.OutputArgumentArray,
+		BlockHeight:         uint64(output.ReferenceBlockHeight),
+		BlockTimestamp:      uint64(output.ReferenceBlockTimestamp),
+		Trace:               toJsonTrace(output.Trace),
+	}
+
+	return &TraceCallMethodOutput{RequestStatus: requestStatus, ClientResponse: response}
+}
+
+// re-executes the block of a committed transaction against the state it was executed on and returns its execution trace (json),
+// only blocks whose previous state is still held by state storage can be traced
+func (s *service) TraceTransaction(parentCtx context.Context, input *TraceTransactionInput) (*TraceTransactionOutput, error) {
+	if input.ClientRequest == nil {
+		err := errors.Errorf("error: missing input (client request is nil)")
+		s.logger.Info("trace transaction received via public api failed", log.Error(err))
+		return nil, err
+	}
+
+	ctx := trace.NewContext(parentCtx, "PublicApi.TraceTransaction")
+	request := input.ClientRequest
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx), log.Transaction(request.Txhash()))
+
+	if err := s.acquireTracingSlot(); err != nil {
+		logger.Info("trace transaction received via public api", log.Error(err))
+		return toTraceTransactionOutput(err.RequestStatus, protocol.TRANSACTION_STATUS_RESERVED, nil, 0, 0, nil), err
+	}
+	defer s.releaseTracingSlot()
+
+	if s.config.VirtualChainId() != request.VirtualChainId() {
+		err := errors.Errorf("error input %s", protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_MISMATCH)
+		logger.Info("trace transaction received via public api", log.Error(err))
+		return toTraceTransactionOutput(protocol.REQUEST_STATUS_REJECTED, protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_MISMATCH, nil, 0, 0, nil), err
+	}
+	logger.Info("trace transaction request received via public api")
+
+	committed, err := s.getFromBlockStorage(ctx, request.Txhash(), request.TransactionTimestamp())
+	if err != nil {
+		return nil, err
+	}
+	if committed.transactionReceipt == nil {
+		return toTraceTransactionOutput(protocol.REQUEST_STATUS_NOT_FOUND, protocol.TRANSACTION_STATUS_NO_RECORD_FOUND, nil, committed.blockHeight, committed.blockTimestamp, nil), nil
+	}
+
+	lastCommitted, err := s.blockStorage.GetLastCommittedBlockHeight(ctx, &services.GetLastCommittedBlockHeightInput{})
+	if err != nil {
+		return nil, err
+	}
+	if !isStateOfBlockRetained(committed.blockHeight, lastCommitted.LastCommittedBlockHeight, s.config.StateStorageHistorySnapshotNum()) {
+		logger.Info("trace transaction rejected, the state its block was executed on is no longer retained", log.BlockHeight(committed.blockHeight), log.Uint64("last-committed-block-height", uint64(lastCommitted.LastCommittedBlockHeight)))
+		return toTraceTransactionOutput(protocol.REQUEST_STATUS_REJECTED, protocol.TRANSACTION_STATUS_COMMITTED, committed.transactionReceipt, committed.blockHeight, committed.blockTimestamp, nil), nil
+	}
+
+	blockReader, ok := s.blockStorage.(blockstorage.BlockPairReader)
+	if !ok {
+		return nil, errors.New("block storage does not support reading block pairs")
+	}
+	tracer, ok := s.virtualMachine.(virtualmachine.Tracer)
+	if !ok {
+		return nil, errors.New("virtual machine does not support tracing")
+	}
+
+	block, err := blockReader.GetBlockPair(ctx, &blockstorage.GetBlockPairInput{
+		BlockHeight: committed.blockHeight,
+	})
+	if err != nil {
+		logger.Info("trace transaction failed to read block", log.Error(err), log.BlockHeight(committed.blockHeight))
+		return nil, err
+	}
+
+	result, err := tracer.TraceTransactionSet(ctx, &virtualmachine.TraceTransactionSetInput{
+		BlockHeight:        committed.blockHeight,
+		BlockTimestamp:     block.BlockPair.TransactionsBlock.Header.Timestamp(),
+		SignedTransactions: block.BlockPair.TransactionsBlock.SignedTransactions,
+		Txhash:             request.Txhash(),
+	})
+	if err != nil {
+		logger.Info("trace transaction failed to re-execute block", log.Error(err), log.BlockHeight(committed.blockHeight))
+		return nil, err
+	}
+
+	return toTraceTransactionOutput(protocol.REQUEST_STATUS_COMPLETED, protocol.TRANSACTION_STATUS_COMMITTED, result.TransactionReceipt, committed.blockHeight, committed.blockTimestamp, result.Trace), nil
+}
+
+// the block is re-executed on the state of the previous block which state storage keeps only for its last few snapshots
+func isStateOfBlockRetained(blockHeight primitives.BlockHeight, lastCommittedBlockHeight primitives.BlockHeight, historySnapshotNum uint32) bool {
+	return uint64(blockHeight)-1+uint64(historySnapshotNum) >= uint64(lastCommittedBlockHeight)
+}
+
+func toTraceTransactionOutput(requestStatus protocol.RequestStatus, transactionStatus protocol.TransactionStatus, receipt *protocol.TransactionReceipt, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, executionTrace []byte) *TraceTransactionOutput {
+	response := &TraceTransactionResponse{
+		RequestStatus:     requestStatus.String(),
+		TransactionStatus: transactionStatus.String(),
+		BlockHeight:       uint64(blockHeight),
+		BlockTimestamp:    uint64(blockTimestamp),
+		Trace:             toJsonTrace(executionTrace),
+	}
+	if receipt != nil {
+		response.ExecutionResult = receipt.ExecutionResult().String()
+		response.OutputArgumentArray = receipt.OutputArgumentArray()
+	}
+
+	return &TraceTransactionOutput{RequestStatus: requestStatus, ClientResponse: response}
+}
+
+// an empty trace is reported as null, raw json must not be empty
+func toJsonTrace(executionTrace []byte) json.RawMessage {
+	if len(executionTrace) == 0 {
+		return nil
+	}
+	return executionTrace
+}
@@ -0,0 +1,31 @@
+package publicapi
+
+import (
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPublicApiTrace_OnlyConfiguredNumberOfTracesRunAtOnce(t *testing.T) {
+	s := &service{
+		config:       config.ForPublicApiTests(42, 1*time.Second),
+		tracingSlots: make(chan struct{}, 1),
+	}
+
+	require.Nil(t, s.acquireTracingSlot(), "first trace should run")
+
+	err := s.acquireTracingSlot()
+	require.NotNil(t, err, "second concurrent trace should be rejected")
+	require.Equal(t, protocol.REQUEST_STATUS_CONGESTION, err.RequestStatus, "second concurrent trace should report congestion")
+
+	s.releaseTracingSlot()
+	require.Nil(t, s.acquireTracingSlot(), "trace should run once the previous one is done")
+}
+
+func TestPublicApiTrace_StateOfBlockRetained(t *testing.T) {
+	require.True(t, isStateOfBlockRetained(10, 10, 5), "last committed block should be traceable")
+	require.True(t, isStateOfBlockRetained(6, 10, 5), "block whose previous state is the oldest snapshot should be traceable")
+	require.False(t, isStateOfBlockRetained(5, 10, 5), "block whose previous state is no longer retained should not be traceable")
+}
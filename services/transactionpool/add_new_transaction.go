@@ -22,11 +22,27 @@ func (s *service) AddNewTransaction(ctx context.Context, input *services.AddNewT
 		return s.addTransactionOutputFor(nil, err.TransactionStatus), err
 	}
 
+	// cancellations are not transactions, they are sent through CancelTransaction
+	if isCancellation(input.SignedTransaction) {
+		err := &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, log.String("contract", "not reserved"), log.Stringable("contract", CANCELLATION_CONTRACT_NAME)}
+		logger.Info("cancellation sent as a new transaction", log.Error(err))
+		return s.addTransactionOutputFor(nil, err.TransactionStatus), err
+	}
+
+	// a transaction sent again after its cancellation was recorded is sent deliberately
+	s.cancelledPool.remove(txHash)
+
 	if alreadyCommitted := s.committedPool.get(digest.CalcTxHash(input.SignedTransaction.Transaction())); alreadyCommitted != nil {
 		logger.Info("transaction already committed")
 		return s.addTransactionOutputFor(alreadyCommitted.receipt, protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_COMMITTED), nil
 	}
 
+	if s.isSentToBlacklistedContract(input.SignedTransaction) {
+		err := &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, log.String("contract", "not blacklisted"), log.Stringable("contract", input.SignedTransaction.Transaction().ContractName())}
+		logger.Info("transaction sent to a contract blacklisted as non-deterministic", log.Error(err))
+		return s.addTransactionOutputFor(nil, err.TransactionStatus), err
+	}
+
 	if err := s.validateSingleTransactionForPreOrder(ctx, input.SignedTransaction); err != nil {
 		status := protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER
 		logger.Error("error validating transaction for preorder", log.Error(err))
@@ -45,11 +61,12 @@ func (s *service) AddNewTransaction(ctx context.Context, input *services.AddNewT
 }
 
 func (s *service) validateSingleTransactionForPreOrder(ctx context.Context, transaction *protocol.SignedTransaction) error {
-	bh, _ := s.currentBlockHeightAndTime()
+	bh, ts := s.currentBlockHeightAndTime()
 	//TODO handle error from vm call
 	preOrderCheckResults, _ := s.virtualMachine.TransactionSetPreOrder(ctx, &services.TransactionSetPreOrderInput{
 		SignedTransactions: Transactions{transaction},
 		BlockHeight:        bh,
+		BlockTimestamp:     ts,
 	})
 
 	if len(preOrderCheckResults.PreOrderResults) != 1 {
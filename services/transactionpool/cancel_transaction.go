@@ -0,0 +1,162 @@
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/crypto/signature"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+)
+
+// a cancellation is a regular signed transaction addressed to this reserved contract name, it never enters
+// the pending pool or a block, its only input argument is the txhash of the pending transaction to drop
+// (to replace a transaction, the signer cancels it and sends the replacement as a new transaction)
+const CANCELLATION_CONTRACT_NAME = primitives.ContractName("_TransactionPool")
+const CANCELLATION_METHOD_NAME = primitives.MethodName("cancelTransaction")
+
+// cancellations have no request in the pinned spec, so the public api and the transaction pool both serve them through
+// this interface (the public api validates the request and passes it on to the pool)
+type TransactionCanceller interface {
+	CancelTransaction(ctx context.Context, input *CancelTransactionInput) (*CancelTransactionOutput, error)
+}
+
+type CancelTransactionInput struct {
+	SignedTransaction *protocol.SignedTransaction
+}
+
+type CancelTransactionOutput struct {
+	CancellationStatus CancellationStatus
+	CancelledTxhash    primitives.Sha256
+	BlockHeight        primitives.BlockHeight
+	BlockTimestamp     primitives.TimestampNano
+}
+
+type CancellationStatus uint16
+
+const (
+	CANCELLATION_STATUS_RESERVED  CancellationStatus = 0
+	CANCELLATION_STATUS_CANCELLED CancellationStatus = 1 // the pending transaction was removed
+	CANCELLATION_STATUS_RECORDED  CancellationStatus = 2 // the transaction was not seen yet, it is dropped if it arrives later
+)
+
+func (s CancellationStatus) String() string {
+	switch s {
+	case CANCELLATION_STATUS_CANCELLED:
+		return "CANCELLATION_STATUS_CANCELLED"
+	case CANCELLATION_STATUS_RECORDED:
+		return "CANCELLATION_STATUS_RECORDED"
+	}
+	return "CANCELLATION_STATUS_RESERVED"
+}
+
+func isCancellation(transaction *protocol.SignedTransaction) bool {
+	tx := transaction.Transaction()
+	return tx.ContractName() == CANCELLATION_CONTRACT_NAME && tx.MethodName() == CANCELLATION_METHOD_NAME
+}
+
+func cancelledTxHash(transaction *protocol.SignedTransaction) (primitives.Sha256, *ErrTransactionRejected) {
+	argsIterator := protocol.MethodArgumentArrayReader(transaction.Transaction().RawInputArgumentArrayWithHeader()).ArgumentsIterator()
+	if !argsIterator.HasNext() {
+		return nil, &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, log.String("cancellation-args", "txhash"), log.String("cancellation-args", "none")}
+	}
+	arg := argsIterator.NextArguments()
+	if !arg.IsTypeBytesValue() || len(arg.BytesValue()) != hash.SHA256_HASH_SIZE_BYTES || argsIterator.HasNext() {
+		return nil, &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, log.String("cancellation-args", "txhash"), log.Stringable("cancellation-args", arg)}
+	}
+	return primitives.Sha256(arg.BytesValue()), nil
+}
+
+func verifyCancellationSignature(transaction *protocol.SignedTransaction) *ErrTransactionRejected {
+	if !transaction.Transaction().Signer().IsSchemeEddsa() {
+		return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME, log.String("signer-scheme", "Eddsa"), log.Stringable("signer", transaction.Transaction().Signer())}
+	}
+	signerPublicKey := transaction.Transaction().Signer().Eddsa().SignerPublicKey()
+	txHash := digest.CalcTxHash(transaction.Transaction())
+	if !signature.VerifyEd25519(signerPublicKey, txHash, transaction.Signature()) {
+		return &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, log.String("signature", "valid"), log.Bytes("signature", transaction.Signature())}
+	}
+	return nil
+}
+
+func (s *service) CancelTransaction(ctx context.Context, input *CancelTransactionInput) (*CancelTransactionOutput, error) {
+	cancellation := input.SignedTransaction
+	logger := s.logger.WithTags(log.Transaction(digest.CalcTxHash(cancellation.Transaction())), trace.LogFieldFrom(ctx))
+
+	if !isCancellation(cancellation) {
+		err := &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, log.Stringable("contract", CANCELLATION_CONTRACT_NAME), log.Stringable("contract", cancellation.Transaction().ContractName())}
+		logger.Info("transaction cancellation rejected", log.Error(err))
+		return s.cancelTransactionOutputFor(nil, CANCELLATION_STATUS_RESERVED), err
+	}
+
+	if err := s.createValidationContext().validateTransaction(cancellation); err != nil {
+		logger.Info("transaction cancellation rejected", log.Error(err))
+		return s.cancelTransactionOutputFor(nil, CANCELLATION_STATUS_RESERVED), err
+	}
+
+	status, cancelledTxHash, err := s.cancelPendingTransaction(ctx, cancellation)
+	if err != nil {
+		logger.Info("transaction cancellation rejected", log.Error(err))
+		return s.cancelTransactionOutputFor(cancelledTxHash, CANCELLATION_STATUS_RESERVED), err
+	}
+
+	logger.Info("cancelled transaction", log.String("flow", "checkpoint"), log.Stringable("cancelled-txhash", cancelledTxHash), log.Stringable("cancellation-status", status))
+
+	s.transactionForwarder.submit(cancellation)
+
+	return s.cancelTransactionOutputFor(cancelledTxHash, status), nil
+}
+
+// removes the referenced transaction from the pending pool if it was signed by the same signer, a transaction
+// that is not pending yet is dropped when it is forwarded to us later
+func (s *service) cancelPendingTransaction(ctx context.Context, cancellation *protocol.SignedTransaction) (CancellationStatus, primitives.Sha256, *ErrTransactionRejected) {
+	if err := verifyCancellationSignature(cancellation); err != nil {
+		return CANCELLATION_STATUS_RESERVED, nil, err
+	}
+
+	targetTxHash, err := cancelledTxHash(cancellation)
+	if err != nil {
+		return CANCELLATION_STATUS_RESERVED, nil, err
+	}
+
+	if alreadyCommitted := s.committedPool.get(targetTxHash); alreadyCommitted != nil {
+		return CANCELLATION_STATUS_RESERVED, targetTxHash, &ErrTransactionRejected{protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_COMMITTED, log.String("cancelled-transaction", "pending"), log.String("cancelled-transaction", "committed")}
+	}
+
+	cancellingSigner := cancellation.Transaction().Signer().Eddsa().SignerPublicKey()
+
+	target := s.pendingPool.get(targetTxHash)
+	if target == nil {
+		if err := s.cancelledPool.add(targetTxHash, cancellingSigner, cancellation.Transaction().Timestamp()); err != nil {
+			return CANCELLATION_STATUS_RESERVED, targetTxHash, err
+		}
+		return CANCELLATION_STATUS_RECORDED, targetTxHash, nil
+	}
+
+	if !target.Transaction().Signer().IsSchemeEddsa() {
+		return CANCELLATION_STATUS_RESERVED, targetTxHash, &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME, log.String("signer-scheme", "Eddsa"), log.Stringable("signer", target.Transaction().Signer())}
+	}
+
+	originalSigner := target.Transaction().Signer().Eddsa().SignerPublicKey()
+	if !originalSigner.Equal(cancellingSigner) {
+		return CANCELLATION_STATUS_RESERVED, targetTxHash, &ErrTransactionRejected{protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, log.Stringable("signer-public-key", originalSigner), log.Stringable("signer-public-key", cancellingSigner)}
+	}
+
+	// the pinned spec has no status for cancellations, transaction results handlers are told it was rejected like any
+	// transaction the pool refuses on behalf of a contract, the cancellation itself is in the logs
+	s.pendingPool.remove(ctx, targetTxHash, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER)
+
+	return CANCELLATION_STATUS_CANCELLED, targetTxHash, nil
+}
+
+func (s *service) cancelTransactionOutputFor(cancelledTxHash primitives.Sha256, status CancellationStatus) *CancelTransactionOutput {
+	bh, ts := s.currentBlockHeightAndTime()
+	return &CancelTransactionOutput{
+		CancellationStatus: status,
+		CancelledTxhash:    cancelledTxHash,
+		BlockHeight:        bh,
+		BlockTimestamp:     ts,
+	}
+}
@@ -0,0 +1,74 @@
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"sync"
+	"time"
+)
+
+// cancellations of transactions this node has not seen yet (like a forwarded cancellation that overtook its
+// transaction), the transaction is dropped if it is forwarded to us before the cancellation expires,
+// anyone can cancel a txhash that was never sent so the number of cancellations kept is capped
+type cancelledTxPool struct {
+	cancelledPoolSize func() uint32
+	cancellations     map[string]*cancelledTransaction
+	lock              *sync.RWMutex
+}
+
+type cancelledTransaction struct {
+	signerPublicKey primitives.Ed25519PublicKey
+	timestamp       primitives.TimestampNano
+}
+
+func NewCancelledPool(cancelledPoolSize func() uint32) *cancelledTxPool {
+	return &cancelledTxPool{
+		cancelledPoolSize: cancelledPoolSize,
+		cancellations:     make(map[string]*cancelledTransaction),
+		lock:              &sync.RWMutex{},
+	}
+}
+
+func (p *cancelledTxPool) add(txHash primitives.Sha256, signerPublicKey primitives.Ed25519PublicKey, ts primitives.TimestampNano) *ErrTransactionRejected {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	key := txHash.KeyForMap()
+	if _, exists := p.cancellations[key]; !exists && uint32(len(p.cancellations)) >= p.cancelledPoolSize() {
+		return &ErrTransactionRejected{TransactionStatus: protocol.TRANSACTION_STATUS_REJECTED_CONGESTION}
+	}
+
+	p.cancellations[key] = &cancelledTransaction{
+		signerPublicKey: signerPublicKey,
+		timestamp:       ts,
+	}
+	return nil
+}
+
+func (p *cancelledTxPool) remove(txHash primitives.Sha256) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	delete(p.cancellations, txHash.KeyForMap())
+}
+
+// a recorded cancellation only applies to a transaction of the signer who sent it
+func (p *cancelledTxPool) cancels(txHash primitives.Sha256, signerPublicKey primitives.Ed25519PublicKey) bool {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+
+	cancellation, found := p.cancellations[txHash.KeyForMap()]
+	return found && cancellation.signerPublicKey.Equal(signerPublicKey)
+}
+
+func (p *cancelledTxPool) clearTransactionsOlderThan(ctx context.Context, time time.Time) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	for key, cancellation := range p.cancellations {
+		if int64(cancellation.timestamp) < time.UnixNano() {
+			delete(p.cancellations, key)
+		}
+	}
+}
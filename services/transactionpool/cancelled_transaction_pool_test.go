@@ -0,0 +1,32 @@
+package transactionpool
+
+import (
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCancelledTransactionPoolRejectsCancellationsBeyondItsSize(t *testing.T) {
+	t.Parallel()
+	p := NewCancelledPool(func() uint32 { return 2 })
+	ts := primitives.TimestampNano(time.Now().UnixNano())
+
+	r1 := builders.TransactionReceipt().WithRandomHash().Build()
+	r2 := builders.TransactionReceipt().WithRandomHash().Build()
+	r3 := builders.TransactionReceipt().WithRandomHash().Build()
+	require.Nil(t, p.add(r1.Txhash(), pk, ts), "cancellation within the pool size was rejected")
+	require.Nil(t, p.add(r2.Txhash(), pk, ts), "cancellation within the pool size was rejected")
+
+	err := p.add(r3.Txhash(), pk, ts)
+	require.NotNil(t, err, "cancellation beyond the pool size was recorded")
+	require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION, err.TransactionStatus, "cancellation beyond the pool size was not rejected as congestion")
+	require.False(t, p.cancels(r3.Txhash(), pk), "cancellation beyond the pool size was recorded")
+
+	require.Nil(t, p.add(r1.Txhash(), pk, ts), "recording an existing cancellation again was rejected")
+
+	p.remove(r1.Txhash())
+	require.Nil(t, p.add(r3.Txhash(), pk, ts), "cancellation was rejected after room was made in the pool")
+}
@@ -0,0 +1,13 @@
+package transactionpool
+
+import (
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+)
+
+// contracts the determinism self check of this node found non-deterministic are only kept out of what this node admits and
+// proposes, ValidateTransactionsForOrdering ignores them so this node still accepts the blocks of other leaders
+func (s *service) isSentToBlacklistedContract(transaction *protocol.SignedTransaction) bool {
+	blacklist, ok := s.virtualMachine.(virtualmachine.ContractBlacklist)
+	return ok && blacklist.IsContractBlacklisted(transaction.Transaction().ContractName())
+}
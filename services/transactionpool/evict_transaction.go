@@ -0,0 +1,35 @@
+package transactionpool
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+)
+
+// the block producer drops a pending transaction through this interface when this node keeps failing to execute it
+// (e.g. its ethereum endpoint is down), so one transaction can't hold back every following block, the pinned spec has no such request
+type TransactionEvictor interface {
+	EvictTransaction(ctx context.Context, input *EvictTransactionInput) (*EvictTransactionOutput, error)
+}
+
+type EvictTransactionInput struct {
+	Txhash primitives.Sha256
+}
+
+type EvictTransactionOutput struct {
+	Evicted bool // false if the transaction was no longer pending
+}
+
+func (s *service) EvictTransaction(ctx context.Context, input *EvictTransactionInput) (*EvictTransactionOutput, error) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	// the transaction was never executed so the signer may send it again, the pinned spec tells clients so with congestion
+	removed := s.pendingPool.remove(ctx, input.Txhash, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION)
+	if removed != nil {
+		logger.Info("evicted transaction this node failed to execute", log.String("flow", "checkpoint"), log.Transaction(input.Txhash))
+	}
+
+	return &EvictTransactionOutput{Evicted: removed != nil}, nil
+}
@@ -34,18 +34,31 @@ func (s *service) HandleForwardedTransactions(ctx context.Context, input *gossip
 	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
 
 	sender := input.Message.Sender
-	oneBigHash, _ , err:= HashTransactions(input.Message.SignedTransactions...)
+	oneBigHash, _, err := HashTransactions(input.Message.SignedTransactions...)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not create one hash, invalid signature in relay message from sender %s", sender.SenderPublicKey())
 	}
 
-
 	if !signature.VerifyEd25519(sender.SenderPublicKey(), oneBigHash, sender.Signature()) {
 		return nil, errors.Errorf("invalid signature in relay message from sender %s", sender.SenderPublicKey())
 	}
 
 	for _, tx := range input.Message.SignedTransactions {
 		txHash := digest.CalcTxHash(tx.Transaction())
+		if isCancellation(tx) {
+			if status, cancelledTxHash, err := s.cancelPendingTransaction(ctx, tx); err != nil {
+				logger.Info("forwarded transaction cancellation rejected", log.Error(err), log.Transaction(txHash))
+			} else {
+				logger.Info("cancelled transaction by forwarded cancellation", log.String("flow", "checkpoint"), log.Transaction(txHash), log.Stringable("cancelled-txhash", cancelledTxHash), log.Stringable("cancellation-status", status))
+			}
+			continue
+		}
+
+		if tx.Transaction().Signer().IsSchemeEddsa() && s.cancelledPool.cancels(txHash, tx.Transaction().Signer().Eddsa().SignerPublicKey()) {
+			logger.Info("dropping forwarded transaction that was already cancelled", log.String("flow", "checkpoint"), log.Transaction(txHash))
+			continue
+		}
+
 		logger.Info("adding forwarded transaction to the pool", log.String("flow", "checkpoint"), log.Stringable("transaction", tx), log.Transaction(txHash))
 		if _, err := s.pendingPool.add(tx, sender.SenderPublicKey()); err != nil {
 			logger.Error("error adding forwarded transaction to pending pool", log.Error(err), log.Stringable("transaction", tx), log.Transaction(txHash))
@@ -30,24 +30,30 @@ func (s *service) GetTransactionsForOrdering(ctx context.Context, input *service
 		} else if alreadyCommitted := s.committedPool.get(txHash); alreadyCommitted != nil {
 			s.logger.Info("dropping committed transaction", log.String("flow", "checkpoint"), log.Transaction(txHash))
 			s.pendingPool.remove(ctx, txHash, protocol.TRANSACTION_STATUS_DUPLICATE_TRANSACTION_ALREADY_COMMITTED)
-
+		} else if s.isSentToBlacklistedContract(tx) {
+			s.logger.Info("dropping transaction sent to a contract blacklisted as non-deterministic", log.String("flow", "checkpoint"), log.Transaction(txHash))
+			s.pendingPool.remove(ctx, txHash, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER)
 		} else {
 			transactionsForPreOrder = append(transactionsForPreOrder, tx)
 		}
 	}
 
 	//TODO handle error from vm
-	bh, _ := s.currentBlockHeightAndTime()
+	bh, ts := s.currentBlockHeightAndTime()
 	preOrderResults, _ := s.virtualMachine.TransactionSetPreOrder(ctx, &services.TransactionSetPreOrderInput{
 		SignedTransactions: transactionsForPreOrder,
 		BlockHeight:        bh,
+		BlockTimestamp:     ts,
 	})
 
 	for i := range transactionsForPreOrder {
 		tx := transactionsForPreOrder[i]
-		if preOrderResults.PreOrderResults[i] == protocol.TRANSACTION_STATUS_PRE_ORDER_VALID {
+		switch preOrderResults.PreOrderResults[i] {
+		case protocol.TRANSACTION_STATUS_PRE_ORDER_VALID:
 			out.SignedTransactions = append(out.SignedTransactions, tx)
-		} else {
+		case protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER:
+			// not allowed by the subscription in this block, stays pending until a later block has room for it or it expires
+		default:
 			txHash := digest.CalcTxHash(tx.Transaction()) //TODO we calculate TX hash again even though we calculated it above while iterating. Consider memoization.
 			s.logger.Info("dropping transaction that failed pre-order validation", log.String("flow", "checkpoint"), log.Transaction(txHash))
 			s.pendingPool.remove(ctx, txHash, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER)
@@ -23,6 +23,7 @@ func NewTransactionPool(ctx context.Context,
 
 	pendingPool := NewPendingPool(config.TransactionPoolPendingPoolSizeInBytes, metricFactory)
 	committedPool := NewCommittedPool(metricFactory)
+	cancelledPool := NewCancelledPool(config.TransactionPoolCancelledPoolSize)
 
 	txForwarder := NewTransactionForwarder(ctx, logger, config, gossip)
 
@@ -34,6 +35,7 @@ func NewTransactionPool(ctx context.Context,
 
 		pendingPool:          pendingPool,
 		committedPool:        committedPool,
+		cancelledPool:        cancelledPool,
 		blockTracker:         synchronization.NewBlockTracker(0, uint16(config.BlockTrackerGraceDistance())),
 		transactionForwarder: txForwarder,
 	}
@@ -45,6 +47,7 @@ func NewTransactionPool(ctx context.Context,
 
 	startCleaningProcess(ctx, config.TransactionPoolCommittedPoolClearExpiredInterval, config.TransactionPoolTransactionExpirationWindow, s.committedPool, logger)
 	startCleaningProcess(ctx, config.TransactionPoolPendingPoolClearExpiredInterval, config.TransactionPoolTransactionExpirationWindow, s.pendingPool, logger)
+	startCleaningProcess(ctx, config.TransactionPoolPendingPoolClearExpiredInterval, config.TransactionPoolTransactionExpirationWindow, s.cancelledPool, logger)
 
 	return s
 }
@@ -28,6 +28,7 @@ type service struct {
 
 	pendingPool          *pendingTxPool
 	committedPool        *committedTxPool
+	cancelledPool        *cancelledTxPool
 	blockTracker         *synchronization.BlockTracker
 	transactionForwarder *transactionForwarder
 }
@@ -75,6 +75,21 @@ func TestDoesNotAddTransactionsThatFailedPreOrderChecks(t *testing.T) {
 	})
 }
 
+func TestDoesNotAddTransactionsSentToBlacklistedContracts(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		tx := builders.TransferTransaction().WithContract("NonDeterministicContract").Build()
+		h.blacklistContract("NonDeterministicContract")
+
+		_, err := h.addNewTransaction(ctx, tx)
+
+		require.Error(t, err, "a transaction sent to a blacklisted contract was added to the pool")
+		require.IsType(t, &transactionpool.ErrTransactionRejected{}, err, "error was not of the expected type")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, err.(*transactionpool.ErrTransactionRejected).TransactionStatus, "error did not contain expected transaction status")
+		require.NoError(t, test.ConsistentlyVerify(10*time.Millisecond, h.gossip), "a transaction sent to a blacklisted contract was forwarded")
+	})
+}
+
 func TestDoesNotAddTheSameTransactionTwice(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
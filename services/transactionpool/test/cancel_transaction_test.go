@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-network-go/services/transactionpool"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestCancellationBySignerRemovesPendingTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		h.addNewTransaction(ctx, tx)
+
+		h.expectTransactionCancelledCallbackFor(tx)
+
+		cancellation := builders.TransferTransaction().WithCancellationOf(digest.CalcTxHash(tx.Transaction())).Build()
+		out, err := h.cancelTransaction(ctx, cancellation)
+		require.NoError(t, err, "cancellation by the original signer was rejected")
+		require.Equal(t, transactionpool.CANCELLATION_STATUS_CANCELLED, out.CancellationStatus, "cancellation did not return the expected status")
+		require.Equal(t, digest.CalcTxHash(tx.Transaction()), out.CancelledTxhash, "cancellation did not return the cancelled txhash")
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, txSet.SignedTransactions, "cancelled transaction or cancellation were returned for ordering")
+
+		require.NoError(t, h.verifyMocks(), "mocks were not called as expected")
+	})
+}
+
+func TestCancellationByDifferentSignerIsRejected(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		h.addNewTransaction(ctx, tx)
+
+		cancellation := builders.TransferTransaction().WithEd25519Signer(testKeys.Ed25519KeyPairForTests(5)).WithCancellationOf(digest.CalcTxHash(tx.Transaction())).Build()
+		_, err := h.cancelTransaction(ctx, cancellation)
+		require.Error(t, err, "cancellation by a different signer was accepted")
+		require.IsType(t, &transactionpool.ErrTransactionRejected{}, err, "error was not of the expected type")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_SIGNATURE_MISMATCH, err.(*transactionpool.ErrTransactionRejected).TransactionStatus, "error did not contain expected transaction status")
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, []*protocol.SignedTransaction{tx}, txSet.SignedTransactions, "transaction was removed by a foreign cancellation")
+	})
+}
+
+func TestCancellationWithMalformedArgumentsIsRejected(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		cancellation := builders.TransferTransaction().WithMethod("_TransactionPool", "cancelTransaction").WithArgs(uint64(17)).Build()
+		_, err := h.cancelTransaction(ctx, cancellation)
+		require.Error(t, err, "cancellation with malformed arguments was accepted")
+		require.IsType(t, &transactionpool.ErrTransactionRejected{}, err, "error was not of the expected type")
+		require.Equal(t, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER, err.(*transactionpool.ErrTransactionRejected).TransactionStatus, "error did not contain expected transaction status")
+	})
+}
+
+func TestForwardedCancellationRemovesPendingTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		h.addNewTransaction(ctx, tx)
+
+		h.expectTransactionCancelledCallbackFor(tx)
+
+		cancellation := builders.TransferTransaction().WithCancellationOf(digest.CalcTxHash(tx.Transaction())).Build()
+		h.handleForwardFrom(ctx, otherNodeKeyPair, cancellation)
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, txSet.SignedTransactions, "cancelled transaction or cancellation were returned for ordering")
+
+		require.NoError(t, h.verifyMocks(), "mocks were not called as expected")
+	})
+}
+
+func TestForwardedCancellationArrivingBeforeItsTransactionDropsIt(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		cancellation := builders.TransferTransaction().WithCancellationOf(digest.CalcTxHash(tx.Transaction())).Build()
+		h.handleForwardFrom(ctx, otherNodeKeyPair, cancellation)
+		h.handleForwardFrom(ctx, otherNodeKeyPair, tx)
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, txSet.SignedTransactions, "transaction forwarded after its cancellation was returned for ordering")
+	})
+}
+
+func TestCancellationSentAsNewTransactionIsRejected(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		h.addNewTransaction(ctx, tx)
+
+		cancellation := builders.TransferTransaction().WithCancellationOf(digest.CalcTxHash(tx.Transaction())).Build()
+		_, err := h.addNewTransaction(ctx, cancellation)
+		require.Error(t, err, "cancellation sent as a new transaction was accepted")
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Equal(t, []*protocol.SignedTransaction{tx}, txSet.SignedTransactions, "transaction was removed by a cancellation sent as a new transaction")
+	})
+}
@@ -0,0 +1,42 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEvictionRemovesPendingTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx := builders.TransferTransaction().Build()
+		h.addNewTransaction(ctx, tx)
+
+		h.expectTransactionErrorCallbackFor(tx, protocol.TRANSACTION_STATUS_REJECTED_CONGESTION)
+
+		out, err := h.evictTransaction(ctx, tx)
+		require.NoError(t, err, "eviction of a pending transaction failed")
+		require.True(t, out.Evicted, "pending transaction was not evicted")
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err)
+		require.Empty(t, txSet.SignedTransactions, "evicted transaction was returned for ordering")
+
+		require.NoError(t, h.verifyMocks(), "mocks were not called as expected")
+	})
+}
+
+func TestEvictionOfTransactionNoLongerPendingDoesNothing(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		out, err := h.evictTransaction(ctx, builders.TransferTransaction().Build())
+		require.NoError(t, err, "eviction of a transaction that is not pending failed")
+		require.False(t, out.Evicted, "transaction that is not pending was reported as evicted")
+	})
+}
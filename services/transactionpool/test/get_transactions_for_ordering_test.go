@@ -56,6 +56,52 @@ func TestGetTransactionsForOrderingDropTransactionsThatFailPreOrderValidation(t
 	})
 }
 
+func TestGetTransactionsForOrderingDropsTransactionsSentToBlacklistedContracts(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx1 := builders.TransferTransaction().Build()
+		tx2 := builders.TransferTransaction().WithContract("NonDeterministicContract").Build()
+
+		h.addTransactions(ctx, tx1, tx2)
+		h.blacklistContract("NonDeterministicContract")
+
+		h.ignoringTransactionResults()
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+
+		require.NoError(t, err, "expected transaction set but got an error")
+		require.Equal(t, []*protocol.SignedTransaction{tx1}, txSet.SignedTransactions, "got a transaction sent to a blacklisted contract")
+	})
+}
+
+func TestGetTransactionsForOrderingKeepsTransactionsRejectedByGlobalPreOrderPending(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.ignoringForwardMessages()
+
+		tx1 := builders.TransferTransaction().Build()
+		tx2 := builders.TransferTransaction().Build()
+
+		h.addTransactions(ctx, tx1, tx2)
+
+		h.rejectPreOrderCheckFor(func(tx *protocol.SignedTransaction) bool {
+			return tx == tx2
+		}, protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER)
+
+		txSet, err := h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err, "expected transaction set but got an error")
+		require.ElementsMatch(t, transactionpool.Transactions{tx1}, txSet.SignedTransactions, "got a transaction beyond the subscription allowance")
+
+		h.passAllPreOrderChecks()
+
+		txSet, err = h.getTransactionsForOrdering(ctx, 2)
+		require.NoError(t, err, "expected transaction set but got an error")
+		require.ElementsMatch(t, transactionpool.Transactions{tx1, tx2}, txSet.SignedTransactions, "transaction rejected by the subscription was dropped from the pending pool")
+	})
+}
+
 func TestGetTransactionsForOrderingDropsTransactionsThatAreAlreadyCommitted(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
@@ -23,13 +23,23 @@ import (
 type harness struct {
 	txpool             services.TransactionPool
 	gossip             *gossiptopics.MockTransactionRelay
-	vm                 *services.MockVirtualMachine
+	vm                 *virtualMachineMock
 	trh                *handlers.MockTransactionResultsHandler
 	lastBlockHeight    primitives.BlockHeight
 	lastBlockTimestamp primitives.TimestampNano
 	config             config.TransactionPoolConfig
 }
 
+// the virtual machine of a node also keeps the contracts its determinism self check blacklisted
+type virtualMachineMock struct {
+	services.MockVirtualMachine
+	blacklistedContracts map[primitives.ContractName]bool
+}
+
+func (m *virtualMachineMock) IsContractBlacklisted(contractName primitives.ContractName) bool {
+	return m.blacklistedContracts[contractName]
+}
+
 var (
 	thisNodeKeyPair  = testKeys.Ed25519KeyPairForTests(8)
 	otherNodeKeyPair = testKeys.Ed25519KeyPairForTests(9)
@@ -64,6 +74,18 @@ func (h *harness) addNewTransaction(ctx context.Context, tx *protocol.SignedTran
 	return out, err
 }
 
+func (h *harness) cancelTransaction(ctx context.Context, cancellation *protocol.SignedTransaction) (*transactionpool.CancelTransactionOutput, error) {
+	return h.txpool.(transactionpool.TransactionCanceller).CancelTransaction(ctx, &transactionpool.CancelTransactionInput{
+		SignedTransaction: cancellation,
+	})
+}
+
+func (h *harness) evictTransaction(ctx context.Context, tx *protocol.SignedTransaction) (*transactionpool.EvictTransactionOutput, error) {
+	return h.txpool.(transactionpool.TransactionEvictor).EvictTransaction(ctx, &transactionpool.EvictTransactionInput{
+		Txhash: digest.CalcTxHash(tx.Transaction()),
+	})
+}
+
 func (h *harness) addTransactions(ctx context.Context, txs ...*protocol.SignedTransaction) {
 	for _, tx := range txs {
 		h.addNewTransaction(ctx, tx)
@@ -129,6 +151,10 @@ func (h *harness) expectTransactionErrorCallbackFor(tx *protocol.SignedTransacti
 	})).Return(&handlers.HandleTransactionErrorOutput{}).Times(1)
 }
 
+func (h *harness) expectTransactionCancelledCallbackFor(tx *protocol.SignedTransaction) {
+	h.expectTransactionErrorCallbackFor(tx, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER)
+}
+
 func (h *harness) ignoringTransactionResults() {
 	h.trh.When("HandleTransactionResults", mock.Any, mock.Any)
 	h.trh.When("HandleTransactionError", mock.Any, mock.Any)
@@ -146,6 +172,10 @@ func (h *harness) getTransactionsForOrdering(ctx context.Context, maxNumOfTransa
 }
 
 func (h *harness) failPreOrderCheckFor(failOn func(tx *protocol.SignedTransaction) bool) {
+	h.rejectPreOrderCheckFor(failOn, protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER)
+}
+
+func (h *harness) rejectPreOrderCheckFor(failOn func(tx *protocol.SignedTransaction) bool, status protocol.TransactionStatus) {
 	h.vm.Reset().When("TransactionSetPreOrder", mock.Any, mock.Any).Call(func(ctx context.Context, input *services.TransactionSetPreOrderInput) (*services.TransactionSetPreOrderOutput, error) {
 		if input.BlockHeight != h.lastBlockHeight {
 			log.GetLogger().Error("Invalid block height", log.Uint64("expected-block-height", h.lastBlockHeight.KeyForMap()), log.Uint64("actual-block-height", input.BlockHeight.KeyForMap()))
@@ -154,7 +184,7 @@ func (h *harness) failPreOrderCheckFor(failOn func(tx *protocol.SignedTransactio
 		statuses := make([]protocol.TransactionStatus, len(input.SignedTransactions))
 		for i, tx := range input.SignedTransactions {
 			if failOn(tx) {
-				statuses[i] = protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER
+				statuses[i] = status
 			} else {
 				statuses[i] = protocol.TRANSACTION_STATUS_PRE_ORDER_VALID
 			}
@@ -165,6 +195,10 @@ func (h *harness) failPreOrderCheckFor(failOn func(tx *protocol.SignedTransactio
 	})
 }
 
+func (h *harness) blacklistContract(contractName primitives.ContractName) {
+	h.vm.blacklistedContracts[contractName] = true
+}
+
 func (h *harness) passAllPreOrderChecks() {
 	h.failPreOrderCheckFor(func(tx *protocol.SignedTransaction) bool {
 		return false
@@ -202,7 +236,7 @@ func newHarnessWithSizeLimit(sizeLimit uint32) *harness {
 	gossip := &gossiptopics.MockTransactionRelay{}
 	gossip.When("RegisterTransactionRelayHandler", mock.Any).Return()
 
-	virtualMachine := &services.MockVirtualMachine{}
+	virtualMachine := &virtualMachineMock{blacklistedContracts: make(map[primitives.ContractName]bool)}
 
 	cfg := config.ForTransactionPoolTests(sizeLimit, thisNodeKeyPair)
 	metricFactory := metric.NewRegistry()
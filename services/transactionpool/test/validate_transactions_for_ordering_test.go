@@ -21,6 +21,17 @@ func TestValidateTransactionsForOrderingAcceptsOkTransactions(t *testing.T) {
 	})
 }
 
+func TestValidateTransactionsForOrderingAcceptsTransactionsSentToBlacklistedContracts(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.blacklistContract("NonDeterministicContract")
+
+		require.NoError(t,
+			h.validateTransactionsForOrdering(ctx, 0, builders.Transaction().WithContract("NonDeterministicContract").Build()),
+			"rejected the block of another leader because it calls a contract this node blacklisted")
+	})
+}
+
 func TestValidateTransactionsForOrderingRejectsCommittedTransactions(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
@@ -25,16 +25,21 @@ func (s *service) ValidateTransactionsForOrdering(ctx context.Context, input *se
 			return nil, errors.Errorf("transaction with hash %s already committed", txHash)
 		}
 
+		if isCancellation(tx) {
+			return nil, errors.Errorf("transaction with hash %s is a cancellation and cannot be ordered", txHash)
+		}
+
 		if err := vctx.validateTransaction(tx); err != nil {
 			return nil, errors.Wrap(err, fmt.Sprintf("transaction with hash %s is invalid", txHash))
 		}
 	}
 
 	//TODO handle error from vm
-	bh, _ := s.currentBlockHeightAndTime()
+	bh, ts := s.currentBlockHeightAndTime()
 	preOrderResults, _ := s.virtualMachine.TransactionSetPreOrder(ctx, &services.TransactionSetPreOrderInput{
 		SignedTransactions: input.SignedTransactions,
 		BlockHeight:        bh,
+		BlockTimestamp:     ts,
 	})
 
 	for i, tx := range input.SignedTransactions {
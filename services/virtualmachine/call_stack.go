@@ -0,0 +1,38 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+)
+
+var ErrCallDepthExceeded = errors.New("maximum call depth exceeded")
+var ErrReentrantCall = errors.New("reentrant call to a non-reentrant contract")
+
+// like an exceeded budget, a violation aborts the transaction even if the calling contract swallows the sdk error
+func (s *service) verifyServiceCallAllowed(ctx context.Context, executionContext *executionContext, serviceName primitives.ContractName) error {
+	if uint32(executionContext.serviceStackDepth()) >= s.config.VirtualMachineMaximumCallDepth() {
+		return executionContext.abortCallStack(ErrCallDepthExceeded)
+	}
+
+	// reentrancy is only needed when the callee is already running, so most calls don't pay for it
+	if executionContext.serviceStackContains(serviceName) && s.isNonReentrantContract(ctx, executionContext, serviceName) {
+		return executionContext.abortCallStack(ErrReentrantCall)
+	}
+
+	return nil
+}
+
+// contracts are reentrant unless their owner marked them in _Deployments (the sdk contract info has no reentrancy)
+func (s *service) isNonReentrantContract(ctx context.Context, executionContext *executionContext, serviceName primitives.ContractName) bool {
+	nonReentrant, err := s.callIsNonReentrantOfDeploymentSystemContract(ctx, executionContext, serviceName)
+	return err == nil && nonReentrant
+}
+
+// the first violation is kept, later ones are a consequence of it
+func (c *executionContext) abortCallStack(violation error) error {
+	if c.callStackViolation == nil {
+		c.callStackViolation = violation
+	}
+	return violation
+}
@@ -0,0 +1,103 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+	"sync"
+)
+
+// code hashes stored by _Deployments as of a committed block, an upgrade changes the hash in the state of the block
+// that commits it so the entry of a contract is replaced as soon as a context reads a newer block
+type codeHashCache struct {
+	mutex  *sync.RWMutex
+	hashes map[primitives.ContractName]*cachedCodeHash
+}
+
+type cachedCodeHash struct {
+	blockHeight primitives.BlockHeight
+	codeHash    primitives.Sha256
+}
+
+func newCodeHashCache() *codeHashCache {
+	return &codeHashCache{
+		mutex:  &sync.RWMutex{},
+		hashes: make(map[primitives.ContractName]*cachedCodeHash),
+	}
+}
+
+func (c *codeHashCache) get(contractName primitives.ContractName, blockHeight primitives.BlockHeight) (primitives.Sha256, bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	cached, found := c.hashes[contractName]
+	if !found || cached.blockHeight != blockHeight {
+		return nil, false
+	}
+	return cached.codeHash, true
+}
+
+// reads of older blocks (like local method calls on a lagging height) don't replace the entry of a newer one
+func (c *codeHashCache) set(contractName primitives.ContractName, blockHeight primitives.BlockHeight, codeHash primitives.Sha256) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if cached, found := c.hashes[contractName]; found && cached.blockHeight > blockHeight {
+		return
+	}
+	c.hashes[contractName] = &cachedCodeHash{blockHeight: blockHeight, codeHash: codeHash}
+}
+
+// called by processors (never by contracts) before every call to a deployed contract so it isn't metered, returns nil
+// when _Deployments has no hash stored for the contract (not deployed with code, or deployed before hashes were stored)
+func (s *service) GetDeployedCodeHash(ctx context.Context, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName) (primitives.Sha256, error) {
+	executionContext := s.contexts.loadExecutionContext(executionContextId)
+	if executionContext == nil {
+		return nil, errors.Errorf("invalid execution context %s", executionContextId)
+	}
+	if !executionContext.sdkCalls.enter() {
+		return nil, ErrExecutionContextAbandoned
+	}
+	defer executionContext.sdkCalls.exit()
+
+	systemContractName := primitives.ContractName(deployments_systemcontract.CONTRACT.Name)
+	key := hash.CalcRipmd160Sha256([]byte(string(contractName) + ".CodeHash"))
+
+	// upgrades of the block being executed are not committed yet
+	if codeHash, found := executionContext.transientState.getValue(systemContractName, key); found {
+		return codeHash, nil
+	}
+	if executionContext.batchTransientState != nil {
+		if codeHash, found := executionContext.batchTransientState.getValue(systemContractName, key); found {
+			executionContext.readSet.setValue(systemContractName, key, codeHash, false)
+			return codeHash, nil
+		}
+	}
+
+	codeHash, found := s.codeHashes.get(contractName, executionContext.blockHeight)
+	if !found {
+		output, err := s.stateStorage.ReadKeys(ctx, &services.ReadKeysInput{
+			BlockHeight:  executionContext.blockHeight,
+			ContractName: systemContractName,
+			Keys:         []primitives.Ripmd160Sha256{key},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(output.StateRecords) == 0 {
+			return nil, errors.Errorf("state read returned no value")
+		}
+		codeHash = output.StateRecords[0].Value()
+		if len(codeHash) == 0 {
+			return nil, nil
+		}
+		s.codeHashes.set(contractName, executionContext.blockHeight, codeHash)
+	}
+
+	// reads from outside the transaction are remembered to detect conflicts with other transactions
+	executionContext.readSet.setValue(systemContractName, key, codeHash, false)
+	return codeHash, nil
+}
@@ -4,6 +4,7 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"sync"
+	"time"
 )
 
 type executionContext struct {
@@ -11,9 +12,23 @@ type executionContext struct {
 	blockHeight         primitives.BlockHeight
 	serviceStack        []primitives.ContractName
 	transientState      *transientState
+	readSet             *transientState
 	accessScope         protocol.ExecutionAccessScope
 	batchTransientState *transientState
 	transaction         *protocol.Transaction
+	meter               *executionMeter
+	tracer              *executionTracer
+	callStackViolation  error
+	rejectedUpgrade     error     // the processor did not accept the code of an upgrade made by the transaction
+	deadline            time.Time // of the whole transaction, zero when the execution isn't timed (like in a block)
+	timedOut            bool
+	nodeFault           error // the node failed to run a call, the transaction has no result
+	simulated           bool  // nothing the execution does is committed, see SimulateTransaction
+	sdkCalls            *sdkCallTracker
+
+	// the block the call executes in, exposed to contracts by Sdk.Env
+	currentBlockHeight    primitives.BlockHeight
+	currentBlockTimestamp primitives.TimestampNano
 }
 
 func (c *executionContext) serviceStackTop() primitives.ContractName {
@@ -35,6 +50,15 @@ func (c *executionContext) serviceStackPop() {
 	c.serviceStack = c.serviceStack[0 : len(c.serviceStack)-1]
 }
 
+func (c *executionContext) serviceStackContains(service primitives.ContractName) bool {
+	for _, s := range c.serviceStack {
+		if s == service {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *executionContext) serviceStackDepth() int {
 	return len(c.serviceStack)
 }
@@ -66,7 +90,7 @@ func newExecutionContextProvider() *executionContextProvider {
 	}
 }
 
-func (cp *executionContextProvider) allocateExecutionContext(blockHeight primitives.BlockHeight, accessScope protocol.ExecutionAccessScope, transaction *protocol.Transaction) (primitives.ExecutionContextId, *executionContext) {
+func (cp *executionContextProvider) allocateExecutionContext(blockHeight primitives.BlockHeight, accessScope protocol.ExecutionAccessScope, transaction *protocol.Transaction, executionBudget uint64) (primitives.ExecutionContextId, *executionContext) {
 	cp.mutex.Lock()
 	defer cp.mutex.Unlock()
 
@@ -74,8 +98,11 @@ func (cp *executionContextProvider) allocateExecutionContext(blockHeight primiti
 		blockHeight:    blockHeight,
 		serviceStack:   []primitives.ContractName{},
 		transientState: newTransientState(),
+		readSet:        newTransientState(),
 		accessScope:    accessScope,
 		transaction:    transaction,
+		meter:          newExecutionMeter(executionBudget),
+		sdkCalls:       newSdkCallTracker(),
 	}
 
 	// TODO: improve this mechanism because it wraps around on overflow
@@ -9,10 +9,10 @@ import (
 func TestContext_Load(t *testing.T) {
 	cp := newExecutionContextProvider()
 
-	contextId1, _ := cp.allocateExecutionContext(1, protocol.ACCESS_SCOPE_READ_ONLY, nil)
+	contextId1, _ := cp.allocateExecutionContext(1, protocol.ACCESS_SCOPE_READ_ONLY, nil, 0)
 	defer cp.destroyExecutionContext(contextId1)
 
-	contextId2, _ := cp.allocateExecutionContext(2, protocol.ACCESS_SCOPE_READ_ONLY, nil)
+	contextId2, _ := cp.allocateExecutionContext(2, protocol.ACCESS_SCOPE_READ_ONLY, nil, 0)
 	defer cp.destroyExecutionContext(contextId1)
 
 	require.NotEqual(t, contextId1, contextId2, "contextId1 should be different from contextId2")
@@ -26,7 +26,7 @@ func TestContext_Load(t *testing.T) {
 
 func TestContext_ServiceStack(t *testing.T) {
 	cp := newExecutionContextProvider()
-	executionContextId, c := cp.allocateExecutionContext(1, protocol.ACCESS_SCOPE_READ_ONLY, nil)
+	executionContextId, c := cp.allocateExecutionContext(1, protocol.ACCESS_SCOPE_READ_ONLY, nil, 0)
 	defer cp.destroyExecutionContext(executionContextId)
 
 	c.serviceStackPush("Service1")
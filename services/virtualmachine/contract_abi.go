@@ -0,0 +1,83 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+)
+
+// contract abis are not part of the pinned spec so the public api reaches them through this interface
+type ContractAbiReader interface {
+	GetLocalContractAbi(ctx context.Context, input *GetLocalContractAbiInput) (*GetLocalContractAbiOutput, error)
+}
+
+type GetLocalContractAbiInput struct {
+	ContractName primitives.ContractName
+}
+
+// contracts of processors that can't describe their methods (see native.ContractDescriber) are not described
+type GetLocalContractAbiOutput struct {
+	PermissionScope         protocol.ExecutionPermissionScope
+	Described               bool
+	Methods                 []*native.ContractMethod
+	ReferenceBlockHeight    primitives.BlockHeight
+	ReferenceBlockTimestamp primitives.TimestampNano
+}
+
+// describes the external methods of a contract as of the last committed block
+func (s *service) GetLocalContractAbi(ctx context.Context, input *GetLocalContractAbiInput) (*GetLocalContractAbiOutput, error) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	blockHeight, blockTimestamp, err := s.getRecentBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil, s.transactionExecutionBudget(nil))
+	defer s.contexts.destroyExecutionContext(executionContextId)
+
+	// pre-built native contracts can be described before their first call auto deploys them
+	processor := s.processors[protocol.PROCESSOR_TYPE_NATIVE]
+	processorType, err := s.callGetInfoOfDeploymentSystemContract(ctx, executionContext, input.ContractName)
+	if err == nil {
+		var found bool
+		processor, found = s.processors[processorType]
+		if !found {
+			return nil, errors.Errorf("_Deployments.getInfo contract returned unknown processor type: %s", processorType)
+		}
+	}
+
+	contractInput := &services.GetContractInfoInput{
+		ContextId:    executionContextId,
+		ContractName: input.ContractName,
+	}
+	output := &GetLocalContractAbiOutput{
+		ReferenceBlockHeight:    blockHeight,
+		ReferenceBlockTimestamp: blockTimestamp,
+	}
+
+	if describer, ok := processor.(native.ContractDescriber); ok {
+		description, err := describer.DescribeContract(ctx, contractInput)
+		if err != nil {
+			logger.Info("get contract abi failed", log.Error(err), log.Stringable("contract", input.ContractName), log.BlockHeight(blockHeight))
+			return nil, err
+		}
+		output.PermissionScope = description.PermissionScope
+		output.Described = true
+		output.Methods = description.Methods
+		return output, nil
+	}
+
+	info, err := processor.GetContractInfo(ctx, contractInput)
+	if err != nil {
+		logger.Info("get contract abi failed", log.Error(err), log.Stringable("contract", input.ContractName), log.BlockHeight(blockHeight))
+		return nil, err
+	}
+	output.PermissionScope = info.PermissionScope
+	return output, nil
+}
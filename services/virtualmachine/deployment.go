@@ -2,6 +2,7 @@ package virtualmachine
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
@@ -21,13 +22,12 @@ func (s *service) getServiceDeployment(ctx context.Context, executionContext *ex
 		}
 	}
 
-	// return according to processor
-	switch processorType {
-	case protocol.PROCESSOR_TYPE_NATIVE:
-		return s.processors[protocol.PROCESSOR_TYPE_NATIVE], nil
-	default:
+	// return according to processor (only processors enabled on this node are registered)
+	processor, found := s.processors[processorType]
+	if !found {
 		return nil, errors.Errorf("_Deployments.getInfo contract returned unknown processor type: %s", processorType)
 	}
+	return processor, nil
 }
 
 func (s *service) attemptToAutoDeployNativeContract(ctx context.Context, executionContext *executionContext, serviceName primitives.ContractName) (protocol.ProcessorType, error) {
@@ -72,6 +72,7 @@ func (s *service) callGetInfoOfDeploymentSystemContract(ctx context.Context, exe
 			},
 		},
 	}).Build()
+	executionContext.tracer.enterCall(systemContractName, systemMethodName, inputArgs)
 	output, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
 		ContextId:              executionContext.contextId,
 		ContractName:           systemContractName,
@@ -81,6 +82,7 @@ func (s *service) callGetInfoOfDeploymentSystemContract(ctx context.Context, exe
 		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
 		CallingService:         systemContractName,
 	})
+	executionContext.tracer.exitProcessCall(output, err)
 	if err != nil {
 		return 0, err
 	}
@@ -95,6 +97,49 @@ func (s *service) callGetInfoOfDeploymentSystemContract(ctx context.Context, exe
 	return protocol.ProcessorType(outputArg0.Uint32Value()), nil
 }
 
+func (s *service) callIsNonReentrantOfDeploymentSystemContract(ctx context.Context, executionContext *executionContext, serviceName primitives.ContractName) (bool, error) {
+	systemContractName := primitives.ContractName(deployments_systemcontract.CONTRACT.Name)
+	systemMethodName := primitives.MethodName(deployments_systemcontract.METHOD_IS_NON_REENTRANT.Name)
+
+	// modify execution context
+	executionContext.serviceStackPush(systemContractName)
+	defer executionContext.serviceStackPop()
+
+	// execute the call
+	inputArgs := (&protocol.MethodArgumentArrayBuilder{
+		Arguments: []*protocol.MethodArgumentBuilder{
+			{
+				Name:        "serviceName",
+				Type:        protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE,
+				StringValue: string(serviceName),
+			},
+		},
+	}).Build()
+	executionContext.tracer.enterCall(systemContractName, systemMethodName, inputArgs)
+	output, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
+		ContextId:              executionContext.contextId,
+		ContractName:           systemContractName,
+		MethodName:             systemMethodName,
+		InputArgumentArray:     inputArgs,
+		AccessScope:            executionContext.accessScope,
+		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+		CallingService:         systemContractName,
+	})
+	executionContext.tracer.exitProcessCall(output, err)
+	if err != nil {
+		return false, err
+	}
+	outputArgsIterator := output.OutputArgumentArray.ArgumentsIterator()
+	if !outputArgsIterator.HasNext() {
+		return false, errors.Errorf("_Deployments.isNonReentrant contract returned corrupt output value")
+	}
+	outputArg0 := outputArgsIterator.NextArguments()
+	if !outputArg0.IsTypeUint32Value() {
+		return false, errors.Errorf("_Deployments.isNonReentrant contract returned corrupt output value")
+	}
+	return outputArg0.Uint32Value() != 0, nil
+}
+
 func (s *service) callDeployServiceOfDeploymentSystemContract(ctx context.Context, executionContext *executionContext, serviceName primitives.ContractName) error {
 	systemContractName := primitives.ContractName(deployments_systemcontract.CONTRACT.Name)
 	systemMethodName := primitives.MethodName(deployments_systemcontract.METHOD_DEPLOY_SERVICE.Name)
@@ -123,7 +168,8 @@ func (s *service) callDeployServiceOfDeploymentSystemContract(ctx context.Contex
 			},
 		},
 	}).Build()
-	_, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
+	executionContext.tracer.enterCall(systemContractName, systemMethodName, inputArgs)
+	output, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
 		ContextId:              executionContext.contextId,
 		ContractName:           systemContractName,
 		MethodName:             systemMethodName,
@@ -132,8 +178,61 @@ func (s *service) callDeployServiceOfDeploymentSystemContract(ctx context.Contex
 		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
 		CallingService:         systemContractName,
 	})
+	executionContext.tracer.exitProcessCall(output, err)
 	if err != nil {
 		return err
 	}
 	return nil
 }
+
+// an upgrade is only kept if the processor of the contract accepts the upgraded code (as the execution context sees it),
+// otherwise the contract would be left with code that fails on every call. the verdict is part of the receipt so it must
+// not depend on the node, native code is audited instead of compiled since compilation depends on the local toolchain.
+// like a call stack violation, a rejected upgrade aborts the transaction even if the calling contract swallows the sdk error
+func (s *service) validateUpgradedService(ctx context.Context, executionContext *executionContext, contractName primitives.ContractName, methodName primitives.MethodName, inputArgs *protocol.MethodArgumentArray) error {
+	if contractName != primitives.ContractName(deployments_systemcontract.CONTRACT.Name) || methodName != primitives.MethodName(deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name) {
+		return nil
+	}
+	if err := s.validateUpgradedCode(ctx, executionContext, inputArgs); err != nil {
+		return executionContext.rejectUpgrade(err)
+	}
+	return nil
+}
+
+func (s *service) validateUpgradedCode(ctx context.Context, executionContext *executionContext, inputArgs *protocol.MethodArgumentArray) error {
+	inputArgsIterator := inputArgs.ArgumentsIterator()
+	if !inputArgsIterator.HasNext() {
+		return errors.Errorf("_Deployments.upgradeService called with corrupt input value")
+	}
+	inputArg0 := inputArgsIterator.NextArguments()
+	if !inputArg0.IsTypeStringValue() {
+		return errors.Errorf("_Deployments.upgradeService called with corrupt input value")
+	}
+	serviceName := primitives.ContractName(inputArg0.StringValue())
+
+	processor, err := s.getServiceDeployment(ctx, executionContext, serviceName)
+	if err != nil {
+		return err
+	}
+	input := &services.GetContractInfoInput{
+		ContextId:    executionContext.contextId,
+		ContractName: serviceName,
+	}
+	if validator, ok := processor.(native.DeployedCodeValidator); ok {
+		err = validator.ValidateDeployedCode(ctx, input)
+	} else {
+		_, err = processor.GetContractInfo(ctx, input)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "upgraded code of contract '%s' is not accepted", serviceName)
+	}
+	return nil
+}
+
+// the first rejected upgrade is kept, the transaction is aborted because of it
+func (c *executionContext) rejectUpgrade(err error) error {
+	if c.rejectedUpgrade == nil {
+		c.rejectedUpgrade = err
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+package virtualmachine
+
+import (
+	"bytes"
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// the blacklist is local to this node, so the transaction pool consults it when it admits transactions and when this node
+// proposes a block, but never when it validates the block of another leader (rejecting it would fork this node off)
+type ContractBlacklist interface {
+	IsContractBlacklisted(contractName primitives.ContractName) bool
+}
+
+// contracts found to be non-deterministic by the self check, this node stops ordering transactions to them
+type contractBlacklist struct {
+	mutex     *sync.RWMutex
+	contracts map[primitives.ContractName]bool
+}
+
+func newContractBlacklist() *contractBlacklist {
+	return &contractBlacklist{
+		mutex:     &sync.RWMutex{},
+		contracts: make(map[primitives.ContractName]bool),
+	}
+}
+
+func (b *contractBlacklist) add(contractName primitives.ContractName) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.contracts[contractName] = true
+}
+
+func (b *contractBlacklist) contains(contractName primitives.ContractName) bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	return b.contracts[contractName]
+}
+
+// executes the set again serially in an isolated transient state and compares every transaction to its first execution,
+// a contract that reads the clock or iterates a map would otherwise silently fork the state of nodes,
+// transactions after the first divergence ran on diverged state so only the first one is reported
+func (s *service) verifyDeterministicExecution(
+	ctx context.Context,
+	blockHeight primitives.BlockHeight,
+	blockTimestamp primitives.TimestampNano,
+	signedTransactions []*protocol.SignedTransaction,
+	executions []*methodExecution,
+) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	verifications, _, err := s.executeTransactionSet(ctx, blockHeight, blockTimestamp, signedTransactions, 1)
+	if err != nil {
+		logger.Error("determinism self check could not execute the transaction set again", log.Error(err), log.BlockHeight(blockHeight))
+		return
+	}
+
+	for i, signedTransaction := range signedTransactions {
+		transaction := signedTransaction.Transaction()
+		if i >= len(verifications) {
+			// every transaction before it used the same budget, so it ran out of the block budget by using more of it
+			s.reportNonDeterministicExecution(ctx, blockHeight, transaction, transaction.ContractName())
+			return
+		}
+		if !s.isSameExecution(transaction, executions[i], verifications[i]) {
+			s.reportNonDeterministicExecution(ctx, blockHeight, transaction, divergingContractName(transaction, executions[i], verifications[i]))
+			return
+		}
+	}
+}
+
+func (s *service) reportNonDeterministicExecution(ctx context.Context, blockHeight primitives.BlockHeight, transaction *protocol.Transaction, contractName primitives.ContractName) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	s.metrics.nonDeterministicExecutions.Inc()
+	logger.Error("non-deterministic execution detected", log.Stringable("contract", contractName), log.Stringable("transaction-contract", transaction.ContractName()), log.Stringable("transaction-method", transaction.MethodName()), log.BlockHeight(blockHeight))
+	if s.config.VirtualMachineDeterminismSelfCheckBlacklist() {
+		s.blacklist.add(contractName)
+		logger.Info("contract blacklisted", log.Stringable("contract", contractName))
+	}
+}
+
+// receipts, used budget and state diffs are compared byte by byte, the state diffs are sorted since the transient state is a map,
+// the used budget moves where the block budget cuts the set so a contract spending it differently diverges even with the same results
+func (s *service) isSameExecution(transaction *protocol.Transaction, execution *methodExecution, other *methodExecution) bool {
+	receipt := s.encodeTransactionReceiptOfExecution(transaction, execution)
+	otherReceipt := s.encodeTransactionReceiptOfExecution(transaction, other)
+	if !bytes.Equal(receipt.Raw(), otherReceipt.Raw()) {
+		return false
+	}
+	if execution.meter.used != other.meter.used {
+		return false
+	}
+
+	stateDiffs := encodeTransientStateToSortedStateDiffs(execution.transientState)
+	otherStateDiffs := encodeTransientStateToSortedStateDiffs(other.transientState)
+	if len(stateDiffs) != len(otherStateDiffs) {
+		return false
+	}
+	for i := range stateDiffs {
+		if !bytes.Equal(stateDiffs[i].Raw(), otherStateDiffs[i].Raw()) {
+			return false
+		}
+	}
+	return true
+}
+
+// the transaction may only have diverged because a contract it called returned something else, so the call trees of both
+// executions are walked to the innermost call that behaved differently when given the same input
+func divergingContractName(transaction *protocol.Transaction, execution *methodExecution, other *methodExecution) primitives.ContractName {
+	if execution.tracer == nil || execution.tracer.root == nil || other.tracer == nil || other.tracer.root == nil {
+		return transaction.ContractName()
+	}
+	if call := findDivergingCall(execution.tracer.root, other.tracer.root); call != nil {
+		return primitives.ContractName(call.ContractName)
+	}
+	return transaction.ContractName() // the calls did the same, the difference is in budget spent outside of them (like sdk calls)
+}
+
+// returns nil if both calls did the same, a nested call given different input leaves the divergence to its caller
+func findDivergingCall(call *callTrace, other *callTrace) *callTrace {
+	steps := call.steps()
+	otherSteps := other.steps()
+	for i := 0; i < len(steps) && i < len(otherSteps); i++ {
+		nested, otherNested := steps[i].call, otherSteps[i].call
+		if nested != nil && otherNested != nil && nested.isCalledLike(otherNested) {
+			if diverging := findDivergingCall(nested, otherNested); diverging != nil {
+				return diverging
+			}
+			continue
+		}
+		if nested != nil || otherNested != nil || !steps[i].stateAccess.isSameAs(otherSteps[i].stateAccess) {
+			return call
+		}
+	}
+	if len(steps) != len(otherSteps) ||
+		call.CallResult != other.CallResult ||
+		call.Error != other.Error ||
+		!reflect.DeepEqual(call.OutputArguments, other.OutputArguments) {
+		return call
+	}
+	return nil
+}
+
+// a step of a call is either one of its own state accesses or a nested call, in the order they were made
+type callTraceStep struct {
+	stateAccess *stateAccessTrace
+	call        *callTrace
+}
+
+func (c *callTrace) steps() []*callTraceStep {
+	res := make([]*callTraceStep, 0, len(c.StateAccesses)+len(c.Calls))
+	for _, stateAccess := range c.StateAccesses {
+		res = append(res, &callTraceStep{stateAccess: stateAccess})
+	}
+	for _, call := range c.Calls {
+		res = append(res, &callTraceStep{call: call})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].sequence() < res[j].sequence()
+	})
+	return res
+}
+
+func (s *callTraceStep) sequence() int {
+	if s.call != nil {
+		return s.call.Sequence
+	}
+	return s.stateAccess.Sequence
+}
+
+func (c *callTrace) isCalledLike(other *callTrace) bool {
+	return c.ContractName == other.ContractName &&
+		c.MethodName == other.MethodName &&
+		reflect.DeepEqual(c.InputArguments, other.InputArguments)
+}
+
+func (a *stateAccessTrace) isSameAs(other *stateAccessTrace) bool {
+	return a.Operation == other.Operation &&
+		a.ContractName == other.ContractName &&
+		a.Key == other.Key &&
+		a.Value == other.Value
+}
+
+func encodeTransientStateToSortedStateDiffs(transientState *transientState) []*protocol.ContractStateDiff {
+	contractNames := make([]string, 0, len(transientState.contracts))
+	for contractName := range transientState.contracts {
+		contractNames = append(contractNames, string(contractName))
+	}
+	sort.Strings(contractNames)
+
+	res := []*protocol.ContractStateDiff{}
+	for _, contractName := range contractNames {
+		stateDiffs := []*protocol.StateRecordBuilder{}
+		transientState.forDirty(primitives.ContractName(contractName), func(key []byte, value []byte) {
+			stateDiffs = append(stateDiffs, &protocol.StateRecordBuilder{
+				Key:   key,
+				Value: value,
+			})
+		})
+		if len(stateDiffs) == 0 {
+			continue
+		}
+		sort.Slice(stateDiffs, func(i, j int) bool {
+			return bytes.Compare(stateDiffs[i].Key, stateDiffs[j].Key) < 0
+		})
+		res = append(res, (&protocol.ContractStateDiffBuilder{
+			ContractName: primitives.ContractName(contractName),
+			StateDiffs:   stateDiffs,
+		}).Build())
+	}
+	return res
+}
+
+func (s *service) IsContractBlacklisted(contractName primitives.ContractName) bool {
+	return s.blacklist.contains(contractName)
+}
@@ -2,31 +2,107 @@ package virtualmachine
 
 import (
 	"context"
+	"fmt"
 	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"time"
 )
 
+type methodExecution struct {
+	callResult protocol.ExecutionResult
+	outputArgs *protocol.MethodArgumentArray
+	err        error
+	nodeFault  error
+
+	executionBudget uint64
+	meter           *executionMeter
+	transientState  *transientState
+	readSet         *transientState
+	tracer          *executionTracer
+}
+
 func (s *service) runMethod(
 	ctx context.Context,
 	blockHeight primitives.BlockHeight,
+	blockTimestamp primitives.TimestampNano,
 	transaction *protocol.Transaction,
 	accessScope protocol.ExecutionAccessScope,
 	batchTransientState *transientState,
+	blockMeter *executionMeter,
+	timed bool,
 ) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
 
+	execution := s.executeMethod(ctx, blockHeight, blockHeight, blockTimestamp, transaction, accessScope, batchTransientState, s.transactionExecutionBudget(blockMeter), false, timed, false)
+	execution.commit(batchTransientState, blockMeter)
+	return execution.callResult, execution.outputArgs, execution.err
+}
+
+// the transaction budget is capped by what is left of the block budget
+func (s *service) transactionExecutionBudget(blockMeter *executionMeter) uint64 {
+	executionBudget := uint64(s.config.VirtualMachineTransactionExecutionBudget())
+	if blockMeter != nil && blockMeter.remaining() < executionBudget {
+		executionBudget = blockMeter.remaining()
+	}
+	return executionBudget
+}
+
+// runs the method without touching the batch transient state, the caller decides whether to commit the execution,
+// state is read as of blockHeight while contracts see the current block (which differs when building a new block),
+// only executions outside of blocks are timed since a time limit can't give every node the same receipt
+func (s *service) executeMethod(
+	ctx context.Context,
+	blockHeight primitives.BlockHeight,
+	currentBlockHeight primitives.BlockHeight,
+	currentBlockTimestamp primitives.TimestampNano,
+	transaction *protocol.Transaction,
+	accessScope protocol.ExecutionAccessScope,
+	batchTransientState *transientState,
+	executionBudget uint64,
+	traced bool,
+	timed bool,
+	simulated bool,
+) *methodExecution {
+
 	// create execution context
-	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, accessScope, transaction)
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, accessScope, transaction, executionBudget)
 	defer s.contexts.destroyExecutionContext(executionContextId)
+	executionContext.currentBlockHeight = currentBlockHeight
+	executionContext.currentBlockTimestamp = currentBlockTimestamp
+	if timed {
+		executionContext.deadline = time.Now().Add(s.config.VirtualMachineTransactionExecutionTimeout())
+	}
+	if traced {
+		executionContext.tracer = newExecutionTracer()
+	}
+	executionContext.simulated = simulated
+
+	execution := &methodExecution{
+		executionBudget: executionBudget,
+		meter:           executionContext.meter,
+		transientState:  executionContext.transientState,
+		readSet:         executionContext.readSet,
+		tracer:          executionContext.tracer,
+	}
+
+	// the transaction itself is the root of the call tree
+	inputArgs := protocol.MethodArgumentArrayReader(transaction.RawInputArgumentArrayWithHeader())
+	executionContext.tracer.enterCall(transaction.ContractName(), transaction.MethodName(), inputArgs)
+	defer func() { executionContext.tracer.exitCall(execution.callResult, execution.outputArgs, execution.err) }()
 
 	// get deployment info
 	processor, err := s.getServiceDeployment(ctx, executionContext, transaction.ContractName())
 	if err != nil {
 		s.logger.Info("get deployment info for contract failed", log.Error(err), log.Stringable("transaction", transaction))
-		return protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, nil, err
+		if executionContext.meter.exceeded() {
+			return execution.withAborted(ErrExecutionBudgetExceeded)
+		}
+		return execution.withResult(protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, nil, err)
 	}
 
 	// modify execution context
@@ -35,8 +111,14 @@ func (s *service) runMethod(
 	executionContext.batchTransientState = batchTransientState
 
 	// execute the call
-	inputArgs := protocol.MethodArgumentArrayReader(transaction.RawInputArgumentArrayWithHeader())
-	output, err := processor.ProcessCall(ctx, &services.ProcessCallInput{
+	if err := executionContext.meter.charge(EXECUTION_COST_PROCESSOR_CALL); err != nil {
+		s.logger.Info("transaction execution budget exceeded", log.Stringable("transaction", transaction))
+		return execution.withAborted(ErrExecutionBudgetExceeded)
+	}
+	callCtx, cancel := s.callContext(ctx, executionContext)
+	defer cancel()
+	activeSdkCalls := executionContext.sdkCalls.activeCalls()
+	output, err := processor.ProcessCall(callCtx, &services.ProcessCallInput{
 		ContextId:              executionContextId,
 		ContractName:           transaction.ContractName(),
 		MethodName:             transaction.MethodName(),
@@ -45,44 +127,175 @@ func (s *service) runMethod(
 		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
 		CallingService:         transaction.ContractName(),
 	})
+	executionContext.abandonCallIfDone(callCtx, activeSdkCalls)
 	if err != nil {
 		s.logger.Info("transaction execution failed", log.Stringable("result", output.CallResult), log.Error(err), log.Stringable("transaction", transaction))
 	}
+	executionContext.recordCallTimeout(callCtx, err)
+	executionContext.recordNodeFault(err)
+	if err == nil {
+		s.validateUpgradedService(ctx, executionContext, transaction.ContractName(), transaction.MethodName(), inputArgs)
+	}
+
+	// the contract may have swallowed the sdk error, the transaction is aborted regardless
+	if executionContext.nodeFault != nil {
+		s.logger.Error("transaction execution failed on this node", log.Error(executionContext.nodeFault), log.Stringable("transaction", transaction))
+		return execution.withNodeFault(executionContext.nodeFault)
+	}
+	if executionContext.meter.exceeded() {
+		s.logger.Info("transaction execution budget exceeded", log.Stringable("transaction", transaction))
+		return execution.withAborted(ErrExecutionBudgetExceeded)
+	}
+	if executionContext.timedOut {
+		s.logger.Info("transaction execution timed out", log.Stringable("transaction", transaction))
+		return execution.withAborted(ErrExecutionTimeout)
+	}
+	if violation := executionContext.callStackViolation; violation != nil {
+		s.logger.Info("transaction call stack violation", log.Error(violation), log.Stringable("transaction", transaction))
+		return execution.withAborted(violation)
+	}
+	if rejected := executionContext.rejectedUpgrade; rejected != nil {
+		s.logger.Info("contract upgrade failed", log.Error(rejected), log.Stringable("transaction", transaction))
+		return execution.withAborted(rejected)
+	}
+
+	return execution.withResult(output.CallResult, output.OutputArgumentArray, err)
+}
+
+func (e *methodExecution) withResult(callResult protocol.ExecutionResult, outputArgs *protocol.MethodArgumentArray, err error) *methodExecution {
+	e.callResult = callResult
+	e.outputArgs = outputArgs
+	e.err = err
+	return e
+}
+
+// transactions the virtual machine aborts (like an exceeded budget, a timeout or a call stack violation) fail like contract
+// errors, the pinned spec has no execution results of their own so the reason is only in the output args
+func (e *methodExecution) withAborted(reason error) *methodExecution {
+	return e.withResult(protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, outputArgsWithString(reason.Error()), reason)
+}
+
+// the transaction has no result on this node, whatever ran it fails instead of producing a receipt
+func (e *methodExecution) withNodeFault(fault error) *methodExecution {
+	e.nodeFault = fault
+	return e.withResult(protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, outputArgsWithString(fault.Error()), fault)
+}
+
+// processors and connectors report calls they could not complete (like a lost executor process or an unreachable
+// ethereum endpoint) as faults of the node, they abort the transaction even if the calling contract swallows the sdk error
+func (c *executionContext) recordNodeFault(err error) {
+	if (native.IsNodeFault(err) || ethereum.IsNodeFault(err)) && c.nodeFault == nil {
+		c.nodeFault = err
+	}
+}
+
+func outputArgsWithString(str string) *protocol.MethodArgumentArray {
+	return (&protocol.MethodArgumentArrayBuilder{
+		Arguments: []*protocol.MethodArgumentBuilder{
+			{Name: "string", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: str},
+		},
+	}).Build()
+}
 
-	if batchTransientState != nil && output.CallResult == protocol.EXECUTION_RESULT_SUCCESS {
-		executionContext.transientState.mergeIntoTransientState(batchTransientState)
+// charges the block and merges the state changes of a successful execution into the batch
+func (e *methodExecution) commit(batchTransientState *transientState, blockMeter *executionMeter) {
+	if blockMeter != nil {
+		blockMeter.charge(e.meter.used)
+	}
+	if e.callResult == protocol.EXECUTION_RESULT_SUCCESS && batchTransientState != nil {
+		e.transientState.mergeIntoTransientState(batchTransientState)
 	}
+}
 
-	return output.CallResult, output.OutputArgumentArray, err
+// this node could not execute a transaction of the set (e.g. its ethereum endpoint is down), which says nothing about the transaction
+type TransactionSetNodeFault struct {
+	Index int
+	Cause error
+}
+
+func (f *TransactionSetNodeFault) Error() string {
+	return fmt.Sprintf("failed to execute transaction %d of the set: %s", f.Index, f.Cause.Error())
 }
 
 func (s *service) processTransactionSet(
 	ctx context.Context,
 	blockHeight primitives.BlockHeight,
+	blockTimestamp primitives.TimestampNano,
 	signedTransactions []*protocol.SignedTransaction,
-) ([]*protocol.TransactionReceipt, []*protocol.ContractStateDiff) {
+) ([]*protocol.TransactionReceipt, []*protocol.ContractStateDiff, error) {
+
+	executions, batchTransientState, err := s.executeTransactionSet(ctx, blockHeight, blockTimestamp, signedTransactions, s.config.VirtualMachineExecutionConcurrency())
+	if err != nil {
+		return nil, nil, err
+	}
+	executedTransactions := signedTransactions[:len(executions)]
+	if s.config.VirtualMachineDeterminismSelfCheckEnabled() {
+		s.verifyDeterministicExecution(ctx, blockHeight, blockTimestamp, executedTransactions, executions)
+	}
+
+	// receipts for result
+	receipts := make([]*protocol.TransactionReceipt, 0, len(executions))
+	for i, signedTransaction := range executedTransactions {
+		receipt := s.encodeTransactionReceiptOfExecution(signedTransaction.Transaction(), executions[i])
+		receipts = append(receipts, receipt)
+	}
+
+	stateDiffs := s.encodeBatchTransientStateToStateDiffs(batchTransientState)
+	return receipts, stateDiffs, nil
+}
+
+// the set is cut at the first transaction the block budget aborted (it had less than a full transaction budget left),
+// that transaction and the ones after it get no receipt so the leader leaves them in the pool for the next block,
+// transactions that exceed their own budget still belong in the block, a transaction this node failed to run fails the set
+// with a TransactionSetNodeFault so the block producer can leave it out
+func (s *service) executeTransactionSet(
+	ctx context.Context,
+	blockHeight primitives.BlockHeight,
+	blockTimestamp primitives.TimestampNano,
+	signedTransactions []*protocol.SignedTransaction,
+	concurrency uint32,
+) ([]*methodExecution, *transientState, error) {
 	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
 
+	previousBlockHeight := blockHeight - 1 // our contracts rely on this block's state for execution
+
 	// create batch transient state
 	batchTransientState := newTransientState()
+	blockMeter := newExecutionMeter(uint64(s.config.VirtualMachineBlockExecutionBudget()))
 
-	// receipts for result
-	receipts := make([]*protocol.TransactionReceipt, 0, len(signedTransactions))
+	// optimistic executions (when enabled) are only taken if serial execution would have produced the same result
+	var optimisticExecutions []*methodExecution
+	if concurrency > 1 && len(signedTransactions) > 1 {
+		optimisticExecutions = s.executeTransactionSetInParallel(ctx, blockHeight, blockTimestamp, signedTransactions, int(concurrency))
+	}
+
+	executions := make([]*methodExecution, 0, len(signedTransactions))
+	transactionBudget := uint64(s.config.VirtualMachineTransactionExecutionBudget())
+	traced := s.config.VirtualMachineDeterminismSelfCheckEnabled() // see verifyDeterministicExecution
 
-	for _, signedTransaction := range signedTransactions {
+	for i, signedTransaction := range signedTransactions {
 
-		logger.Info("processing transaction", log.Stringable("contract", signedTransaction.Transaction().ContractName()), log.Stringable("method", signedTransaction.Transaction().MethodName()), log.BlockHeight(blockHeight))
-		callResult, outputArgs, _ := s.runMethod(ctx, blockHeight, signedTransaction.Transaction(), protocol.ACCESS_SCOPE_READ_WRITE, batchTransientState)
-		if outputArgs == nil {
-			outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
+		logger.Info("processing transaction", log.Stringable("contract", signedTransaction.Transaction().ContractName()), log.Stringable("method", signedTransaction.Transaction().MethodName()), log.BlockHeight(previousBlockHeight))
+		executionBudget := s.transactionExecutionBudget(blockMeter)
+		var execution *methodExecution
+		if optimisticExecutions != nil && optimisticExecutions[i].isEquivalentToSerial(batchTransientState, executionBudget) {
+			execution = optimisticExecutions[i]
+		} else {
+			execution = s.executeMethod(ctx, previousBlockHeight, blockHeight, blockTimestamp, signedTransaction.Transaction(), protocol.ACCESS_SCOPE_READ_WRITE, batchTransientState, executionBudget, traced, false, false)
+		}
+		if execution.nodeFault != nil {
+			return nil, nil, &TransactionSetNodeFault{Index: i, Cause: execution.nodeFault}
 		}
+		if execution.meter.exceeded() && executionBudget < transactionBudget {
+			logger.Info("transaction set exceeds the block execution budget", log.Int("num-transactions", len(signedTransactions)), log.Int("num-transactions-in-block", i))
+			break
+		}
+		execution.commit(batchTransientState, blockMeter)
 
-		receipt := s.encodeTransactionReceipt(signedTransaction.Transaction(), callResult, outputArgs)
-		receipts = append(receipts, receipt)
+		executions = append(executions, execution)
 	}
 
-	stateDiffs := s.encodeBatchTransientStateToStateDiffs(batchTransientState)
-	return receipts, stateDiffs
+	return executions, batchTransientState, nil
 }
 
 func (s *service) getRecentBlockHeight(ctx context.Context) (primitives.BlockHeight, primitives.TimestampNano, error) {
@@ -101,6 +314,14 @@ func (s *service) encodeTransactionReceipt(transaction *protocol.Transaction, re
 	}).Build()
 }
 
+func (s *service) encodeTransactionReceiptOfExecution(transaction *protocol.Transaction, execution *methodExecution) *protocol.TransactionReceipt {
+	outputArgs := execution.outputArgs
+	if outputArgs == nil {
+		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
+	}
+	return s.encodeTransactionReceipt(transaction, execution.callResult, outputArgs)
+}
+
 func (s *service) encodeBatchTransientStateToStateDiffs(batchTransientState *transientState) []*protocol.ContractStateDiff {
 	res := []*protocol.ContractStateDiff{}
 	for contractName, _ := range batchTransientState.contracts {
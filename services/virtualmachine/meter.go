@@ -0,0 +1,47 @@
+package virtualmachine
+
+import (
+	"github.com/pkg/errors"
+)
+
+// execution costs are fixed per operation (and not measured in wall time) so all validators reach the same result
+const (
+	EXECUTION_COST_PROCESSOR_CALL = 1000 // entering a processor, charged on every contract call including nested ones
+	EXECUTION_COST_SDK_CALL       = 10
+	EXECUTION_COST_STATE_READ     = 100
+	EXECUTION_COST_STATE_WRITE    = 500
+	EXECUTION_COST_ETHEREUM_CALL  = 1000
+	EXECUTION_COST_PER_STATE_BYTE = 1
+)
+
+var ErrExecutionBudgetExceeded = errors.New("execution budget exceeded")
+
+type executionMeter struct {
+	budget  uint64
+	used    uint64
+	overrun bool
+}
+
+func newExecutionMeter(budget uint64) *executionMeter {
+	return &executionMeter{
+		budget: budget,
+	}
+}
+
+func (m *executionMeter) charge(units uint64) error {
+	if units > m.remaining() {
+		m.used = m.budget
+		m.overrun = true
+		return ErrExecutionBudgetExceeded
+	}
+	m.used += units
+	return nil
+}
+
+func (m *executionMeter) remaining() uint64 {
+	return m.budget - m.used
+}
+
+func (m *executionMeter) exceeded() bool {
+	return m.overrun
+}
@@ -0,0 +1,62 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"sync"
+)
+
+// optimistic concurrency: every transaction of the set runs in parallel against the state of the previous block alone,
+// the executions are then committed in order by processTransactionSet which re-executes the ones that conflict
+func (s *service) executeTransactionSetInParallel(
+	ctx context.Context,
+	blockHeight primitives.BlockHeight,
+	blockTimestamp primitives.TimestampNano,
+	signedTransactions []*protocol.SignedTransaction,
+	concurrency int,
+) []*methodExecution {
+
+	executionBudget := uint64(s.config.VirtualMachineTransactionExecutionBudget())
+	traced := s.config.VirtualMachineDeterminismSelfCheckEnabled() // see verifyDeterministicExecution
+	executions := make([]*methodExecution, len(signedTransactions))
+
+	var wg sync.WaitGroup
+	pending := make(chan int, len(signedTransactions))
+	for i := range signedTransactions {
+		pending <- i
+	}
+	close(pending)
+
+	if concurrency > len(signedTransactions) {
+		concurrency = len(signedTransactions)
+	}
+	for worker := 0; worker < concurrency; worker++ {
+		wg.Add(1)
+		supervised.GoOnce(s.logger, func() {
+			defer wg.Done()
+			for i := range pending {
+				executions[i] = s.executeMethod(ctx, blockHeight-1, blockHeight, blockTimestamp, signedTransactions[i].Transaction(), protocol.ACCESS_SCOPE_READ_WRITE, nil, executionBudget, traced, false, false)
+			}
+		})
+	}
+	wg.Wait()
+
+	return executions
+}
+
+// an optimistic execution gives the same result as a serial one if it read nothing that earlier transactions of the
+// set wrote (the batch transient state only holds their writes), and the budget it ran with made no difference
+func (e *methodExecution) isEquivalentToSerial(batchTransientState *transientState, executionBudget uint64) bool {
+	if e == nil || e.nodeFault != nil { // the execution did not complete
+		return false
+	}
+	if e.readSet.intersects(batchTransientState) {
+		return false
+	}
+	if e.executionBudget == executionBudget {
+		return true
+	}
+	return !e.meter.exceeded() && e.meter.used <= executionBudget
+}
@@ -1,35 +1,253 @@
 package virtualmachine
 
 import (
+	"bytes"
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_GlobalPreOrder"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_VirtualChainPreOrder"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
 )
 
-func (s *service) callGlobalPreOrderSystemContract(ctx context.Context, blockHeight primitives.BlockHeight) error {
+// returns how many transactions the subscription allows in the block being ordered
+func (s *service) callGlobalPreOrderSystemContract(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano) (uint32, error) {
+	inputArgs := (&protocol.MethodArgumentArrayBuilder{
+		Arguments: []*protocol.MethodArgumentBuilder{
+			{Name: "blockTimestamp", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: uint64(blockTimestamp)},
+		},
+	}).Build()
+	output, err := s.callGlobalPreOrderMethod(ctx, blockHeight, blockTimestamp, primitives.MethodName(globalpreorder_systemcontract.METHOD_APPROVE.Name), inputArgs)
+	if err != nil {
+		return 0, err
+	}
+	outputArgsIterator := output.ArgumentsIterator()
+	if !outputArgsIterator.HasNext() {
+		return 0, errors.Errorf("_GlobalPreOrder.approve contract returned corrupt output value")
+	}
+	outputArg0 := outputArgsIterator.NextArguments()
+	if !outputArg0.IsTypeUint32Value() {
+		return 0, errors.Errorf("_GlobalPreOrder.approve contract returned corrupt output value")
+	}
+	return outputArg0.Uint32Value(), nil
+}
+
+// until _GlobalPreOrder is deployed and initialized the admin is the genesis admin in the config
+func (s *service) getGlobalPreOrderAdmin(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano) primitives.Ripmd160Sha256 {
+	output, err := s.callGlobalPreOrderMethod(ctx, blockHeight, blockTimestamp, primitives.MethodName(globalpreorder_systemcontract.METHOD_GET_ADMIN.Name), (&protocol.MethodArgumentArrayBuilder{}).Build())
+	if err != nil {
+		return s.config.VirtualMachineSubscriptionGenesisAdmin()
+	}
+	outputArgsIterator := output.ArgumentsIterator()
+	if !outputArgsIterator.HasNext() {
+		return nil
+	}
+	outputArg0 := outputArgsIterator.NextArguments()
+	if !outputArg0.IsTypeBytesValue() {
+		return nil
+	}
+	return outputArg0.BytesValue()
+}
+
+func (s *service) callGlobalPreOrderMethod(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, systemMethodName primitives.MethodName, inputArgs *protocol.MethodArgumentArray) (*protocol.MethodArgumentArray, error) {
 	systemContractName := primitives.ContractName(globalpreorder_systemcontract.CONTRACT.Name)
-	systemMethodName := primitives.MethodName(globalpreorder_systemcontract.METHOD_APPROVE.Name)
 
 	// create execution context
-	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil)
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil, uint64(s.config.VirtualMachineTransactionExecutionBudget()))
 	defer s.contexts.destroyExecutionContext(executionContextId)
+	executionContext.currentBlockHeight = blockHeight + 1   // the block being ordered has no timestamp yet
+	executionContext.currentBlockTimestamp = blockTimestamp // so subscriptions expire by the last committed block
 
 	// modify execution context
 	executionContext.serviceStackPush(systemContractName)
 	defer executionContext.serviceStackPop()
 
 	// execute the call
-	_, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
+	output, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
 		ContextId:              executionContextId,
 		ContractName:           systemContractName,
 		MethodName:             systemMethodName,
-		InputArgumentArray:     (&protocol.MethodArgumentArrayBuilder{}).Build(),
+		InputArgumentArray:     inputArgs,
 		AccessScope:            protocol.ACCESS_SCOPE_READ_ONLY,
 		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
 		CallingService:         systemContractName,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return output.OutputArgumentArray, nil
+}
+
+// valid transactions are approved in order until the allowance runs out, the rest wait for a later block,
+// transactions of the admin to _GlobalPreOrder are never gated so it can always renew an expired subscription
+func (s *service) rejectTransactionsBeyondAllowance(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, signedTransactions []*protocol.SignedTransaction, statuses []protocol.TransactionStatus, allowance uint32) (rejected int) {
+	var admin primitives.Ripmd160Sha256
+	adminRead := false
+
+	approved := uint32(0)
+	for i, signedTransaction := range signedTransactions {
+		if statuses[i] != protocol.TRANSACTION_STATUS_PRE_ORDER_VALID {
+			continue
+		}
+		if isGlobalPreOrderTransaction(signedTransaction) {
+			if !adminRead {
+				admin, adminRead = s.getGlobalPreOrderAdmin(ctx, blockHeight, blockTimestamp), true
+			}
+			if s.isSignedBy(signedTransaction, admin) {
+				continue
+			}
+		}
+		if approved < allowance {
+			approved++
+		} else {
+			statuses[i] = protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER
+			rejected++
+		}
+	}
+	return rejected
+}
+
+func isGlobalPreOrderTransaction(signedTransaction *protocol.SignedTransaction) bool {
+	return signedTransaction.Transaction().ContractName() == primitives.ContractName(globalpreorder_systemcontract.CONTRACT.Name)
+}
+
+func (s *service) isSignedBy(signedTransaction *protocol.SignedTransaction, address primitives.Ripmd160Sha256) bool {
+	if len(address) == 0 {
+		return false
+	}
+	signerAddress, err := s.getSignerAddress(signedTransaction.Transaction().Signer())
+	return err == nil && bytes.Equal(signerAddress, address)
+}
+
+// _Deployments.deployService calls _init without args, so _GlobalPreOrder is given the admin and
+// subscription the virtual chain starts with from the config instead
+func (s *service) globalPreOrderInitArgs(callingService primitives.ContractName, serviceName primitives.ContractName, methodName primitives.MethodName, inputArgs *protocol.MethodArgumentArray) *protocol.MethodArgumentArray {
+	if callingService != primitives.ContractName(deployments_systemcontract.CONTRACT.Name) ||
+		serviceName != primitives.ContractName(globalpreorder_systemcontract.CONTRACT.Name) ||
+		methodName != primitives.MethodName(globalpreorder_systemcontract.METHOD_INIT.Name) {
+		return inputArgs
+	}
+	return (&protocol.MethodArgumentArrayBuilder{
+		Arguments: []*protocol.MethodArgumentBuilder{
+			{Name: "admin", Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: s.config.VirtualMachineSubscriptionGenesisAdmin()},
+			{Name: "plan", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: s.config.VirtualMachineSubscriptionGenesisPlan()},
+			{Name: "expiry", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE, Uint64Value: uint64(s.config.VirtualMachineSubscriptionGenesisExpiry())},
+			{Name: "transactionsPerBlock", Type: protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE, Uint32Value: s.config.VirtualMachineSubscriptionGenesisTransactionsPerBlock()},
+		},
+	}).Build()
+}
+
+// the virtual chain hook is optional, it only gates ordering once _VirtualChainPreOrder is deployed
+func (s *service) callVirtualChainPreOrderSystemContract(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, signedTransactions []*protocol.SignedTransaction, statuses []protocol.TransactionStatus) (err error) {
+	systemContractName := primitives.ContractName(virtualchainpreorder_systemcontract.CONTRACT.Name)
+	systemMethodName := primitives.MethodName(virtualchainpreorder_systemcontract.METHOD_APPROVE.Name)
+
+	processor := s.getDeployedPreOrderProcessor(ctx, blockHeight, systemContractName)
+	if processor == nil {
+		return nil
+	}
+
+	for i, signedTransaction := range signedTransactions {
+		if statuses[i] != protocol.TRANSACTION_STATUS_PRE_ORDER_VALID {
+			continue
+		}
+		inputArgs := (&protocol.MethodArgumentArrayBuilder{
+			Arguments: []*protocol.MethodArgumentBuilder{
+				{Name: "contractName", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: string(signedTransaction.Transaction().ContractName())},
+				{Name: "methodName", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: string(signedTransaction.Transaction().MethodName())},
+			},
+		}).Build()
+		if callErr := s.processPreOrderCall(ctx, processor, blockHeight, blockTimestamp, signedTransaction.Transaction(), systemContractName, systemMethodName, inputArgs); callErr != nil {
+			statuses[i] = protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_PRE_ORDER
+			err = errors.Wrap(callErr, "not all transactions passed the virtual chain pre order check")
+		}
+	}
+	return err
+}
+
+// contracts opt in by defining _preOrder, it receives the method name and the raw input arguments of the transaction
+func (s *service) callContractPreOrderMethods(ctx context.Context, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, signedTransactions []*protocol.SignedTransaction, statuses []protocol.TransactionStatus) (err error) {
+	processors := make(map[primitives.ContractName]services.Processor)
+
+	for i, signedTransaction := range signedTransactions {
+		if statuses[i] != protocol.TRANSACTION_STATUS_PRE_ORDER_VALID {
+			continue
+		}
+		transaction := signedTransaction.Transaction()
+		contractName := transaction.ContractName()
+
+		processor, found := processors[contractName]
+		if !found {
+			processor = s.getDeployedPreOrderProcessor(ctx, blockHeight, contractName)
+			if processor == nil && s.isPreBuiltNativeContract(ctx, contractName) {
+				processor = s.processors[protocol.PROCESSOR_TYPE_NATIVE] // will be auto deployed by its first transaction
+			}
+			processors[contractName] = processor
+		}
+		if processor == nil {
+			continue // execution will fail on its own
+		}
 
+		inputArgs := (&protocol.MethodArgumentArrayBuilder{
+			Arguments: []*protocol.MethodArgumentBuilder{
+				{Name: "methodName", Type: protocol.METHOD_ARGUMENT_TYPE_STRING_VALUE, StringValue: string(transaction.MethodName())},
+				{Name: "inputArguments", Type: protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE, BytesValue: protocol.MethodArgumentArrayReader(transaction.RawInputArgumentArrayWithHeader()).Raw()},
+			},
+		}).Build()
+		if callErr := s.processPreOrderCall(ctx, processor, blockHeight, blockTimestamp, transaction, contractName, native.METHOD_NAME_PRE_ORDER, inputArgs); callErr != nil {
+			statuses[i] = protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER
+			err = errors.Wrap(callErr, "not all transactions passed the contract pre order check")
+		}
+	}
+	return err
+}
+
+// pre order is read only so contracts can't be auto deployed here, a nil processor means the contract isn't deployed
+func (s *service) getDeployedPreOrderProcessor(ctx context.Context, blockHeight primitives.BlockHeight, contractName primitives.ContractName) services.Processor {
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil, uint64(s.config.VirtualMachineTransactionExecutionBudget()))
+	defer s.contexts.destroyExecutionContext(executionContextId)
+
+	processorType, err := s.callGetInfoOfDeploymentSystemContract(ctx, executionContext, contractName)
+	if err != nil {
+		return nil
+	}
+	return s.processors[processorType]
+}
+
+func (s *service) isPreBuiltNativeContract(ctx context.Context, contractName primitives.ContractName) bool {
+	_, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].GetContractInfo(ctx, &services.GetContractInfoInput{
+		ContractName: contractName,
+	})
+	return err == nil
+}
+
+// runs a read only hook on behalf of the transaction so the hook can check its signer, it's untimed (bounded by the
+// meter) since validators reach the same statuses when they validate the block
+func (s *service) processPreOrderCall(ctx context.Context, processor services.Processor, blockHeight primitives.BlockHeight, blockTimestamp primitives.TimestampNano, transaction *protocol.Transaction, contractName primitives.ContractName, methodName primitives.MethodName, inputArgs *protocol.MethodArgumentArray) error {
+	// create execution context
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, transaction, uint64(s.config.VirtualMachineTransactionExecutionBudget()))
+	defer s.contexts.destroyExecutionContext(executionContextId)
+	executionContext.currentBlockHeight = blockHeight + 1 // the block being ordered has no timestamp yet
+	executionContext.currentBlockTimestamp = blockTimestamp
+
+	// modify execution context
+	executionContext.serviceStackPush(contractName)
+	defer executionContext.serviceStackPop()
+
+	// execute the call
+	callCtx, cancel := s.callContext(ctx, executionContext)
+	defer cancel()
+	_, err := processor.ProcessCall(callCtx, &services.ProcessCallInput{
+		ContextId:              executionContextId,
+		ContractName:           contractName,
+		MethodName:             methodName,
+		InputArgumentArray:     inputArgs,
+		AccessScope:            protocol.ACCESS_SCOPE_READ_ONLY,
+		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+		CallingService:         contractName,
+	})
 	return err
 }
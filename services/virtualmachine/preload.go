@@ -0,0 +1,146 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/synchronization/supervised"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+)
+
+// compiles every deployed contract in the background (until the node shuts down) so the first transactions calling them do not wait for compilation,
+// contracts deployed before _Deployments listed them are not found here but the native compiler rebuilds them from the sources it kept
+func (s *service) startPreloadingDeployedContracts(ctx context.Context) {
+	timeout := s.config.VirtualMachinePreloadDeployedContractsTimeout()
+	if timeout == 0 {
+		return
+	}
+
+	supervised.GoOnce(s.logger, func() {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+
+		s.preloadDeployedContracts(ctx)
+	})
+}
+
+func (s *service) preloadDeployedContracts(ctx context.Context) {
+	blockHeight, _, err := s.getRecentBlockHeight(ctx)
+	if err != nil {
+		s.logger.Info("failed to preload deployed contracts", log.Error(err))
+		return
+	}
+
+	serviceNames, err := s.listDeployedServices(ctx, blockHeight)
+	if err != nil {
+		s.logger.Info("failed to list deployed contracts for preloading", log.Error(err), log.BlockHeight(blockHeight))
+		return
+	}
+
+	preloaded := 0
+	for _, serviceName := range serviceNames {
+		if ctx.Err() != nil {
+			s.logger.Info("preloading deployed contracts timed out", log.Int("preloaded", preloaded), log.Int("deployed", len(serviceNames)))
+			return
+		}
+		err := s.preloadDeployedContract(ctx, blockHeight, serviceName)
+		if err != nil {
+			s.logger.Info("failed to preload deployed contract", log.Error(err), log.Stringable("contract", serviceName))
+			continue
+		}
+		preloaded++
+	}
+
+	s.logger.Info("preloaded deployed contracts", log.Int("preloaded", preloaded), log.Int("deployed", len(serviceNames)), log.BlockHeight(blockHeight))
+}
+
+func (s *service) listDeployedServices(ctx context.Context, blockHeight primitives.BlockHeight) ([]primitives.ContractName, error) {
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil, s.transactionExecutionBudget(nil))
+	defer s.contexts.destroyExecutionContext(executionContextId)
+
+	countArg, err := s.callDeploymentSystemContract(ctx, executionContext, deployments_systemcontract.METHOD_GET_DEPLOYED_SERVICES_COUNT.Name, &protocol.MethodArgumentArrayBuilder{})
+	if err != nil {
+		return nil, err
+	}
+	if !countArg.IsTypeUint32Value() {
+		return nil, errors.Errorf("_Deployments.getDeployedServicesCount contract returned corrupt output value")
+	}
+
+	var serviceNames []primitives.ContractName
+	for i := uint32(0); i < countArg.Uint32Value(); i++ {
+		nameArg, err := s.callDeploymentSystemContract(ctx, executionContext, deployments_systemcontract.METHOD_GET_DEPLOYED_SERVICE_NAME.Name, &protocol.MethodArgumentArrayBuilder{
+			Arguments: []*protocol.MethodArgumentBuilder{
+				{
+					Name:        "index",
+					Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE,
+					Uint32Value: i,
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !nameArg.IsTypeStringValue() {
+			return nil, errors.Errorf("_Deployments.getDeployedServiceName contract returned corrupt output value")
+		}
+		serviceNames = append(serviceNames, primitives.ContractName(nameArg.StringValue()))
+	}
+	return serviceNames, nil
+}
+
+// every contract gets its own execution context so the budget spent fetching its code is not shared
+func (s *service) preloadDeployedContract(ctx context.Context, blockHeight primitives.BlockHeight, serviceName primitives.ContractName) error {
+	executionContextId, executionContext := s.contexts.allocateExecutionContext(blockHeight, protocol.ACCESS_SCOPE_READ_ONLY, nil, s.transactionExecutionBudget(nil))
+	defer s.contexts.destroyExecutionContext(executionContextId)
+
+	processorType, err := s.callGetInfoOfDeploymentSystemContract(ctx, executionContext, serviceName)
+	if err != nil {
+		return err
+	}
+	processor, found := s.processors[processorType]
+	if !found {
+		return errors.Errorf("_Deployments.getInfo contract returned unknown processor type: %s", processorType)
+	}
+
+	// the processor compiles and caches the contract when asked about it
+	_, err = processor.GetContractInfo(ctx, &services.GetContractInfoInput{
+		ContextId:    executionContextId,
+		ContractName: serviceName,
+	})
+	return err
+}
+
+// returns the first output argument of a read only method of the deployments system contract
+func (s *service) callDeploymentSystemContract(ctx context.Context, executionContext *executionContext, methodName string, inputArgsBuilder *protocol.MethodArgumentArrayBuilder) (*protocol.MethodArgument, error) {
+	systemContractName := primitives.ContractName(deployments_systemcontract.CONTRACT.Name)
+	systemMethodName := primitives.MethodName(methodName)
+
+	// modify execution context
+	executionContext.serviceStackPush(systemContractName)
+	defer executionContext.serviceStackPop()
+
+	// execute the call
+	inputArgs := inputArgsBuilder.Build()
+	executionContext.tracer.enterCall(systemContractName, systemMethodName, inputArgs)
+	output, err := s.processors[protocol.PROCESSOR_TYPE_NATIVE].ProcessCall(ctx, &services.ProcessCallInput{
+		ContextId:              executionContext.contextId,
+		ContractName:           systemContractName,
+		MethodName:             systemMethodName,
+		InputArgumentArray:     inputArgs,
+		AccessScope:            executionContext.accessScope,
+		CallingPermissionScope: protocol.PERMISSION_SCOPE_SERVICE,
+		CallingService:         systemContractName,
+	})
+	executionContext.tracer.exitProcessCall(output, err)
+	if err != nil {
+		return nil, err
+	}
+	outputArgsIterator := output.OutputArgumentArray.ArgumentsIterator()
+	if !outputArgsIterator.HasNext() {
+		return nil, errors.Errorf("_Deployments.%s contract returned corrupt output value", methodName)
+	}
+	return outputArgsIterator.NextArguments(), nil
+}
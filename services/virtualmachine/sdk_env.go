@@ -0,0 +1,53 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// every value here is agreed on by consensus, contracts must never see anything local to the node (like its clock)
+func (s *service) handleSdkEnvCall(ctx context.Context, executionContext *executionContext, methodName primitives.MethodName, args []*protocol.MethodArgument, permissionScope protocol.ExecutionPermissionScope) ([]*protocol.MethodArgument, error) {
+	if len(args) != 0 {
+		return nil, errors.Errorf("invalid SDK env %s args: %v", methodName, args)
+	}
+
+	switch methodName {
+
+	case "getBlockHeight":
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:        "value",
+			Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE,
+			Uint64Value: uint64(executionContext.currentBlockHeight),
+		}).Build()}, nil
+
+	case "getBlockTimestamp":
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:        "value",
+			Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE,
+			Uint64Value: uint64(executionContext.currentBlockTimestamp),
+		}).Build()}, nil
+
+	case "getVirtualChainId":
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:        "value",
+			Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_32_VALUE,
+			Uint32Value: uint32(s.config.VirtualChainId()),
+		}).Build()}, nil
+
+	case "getTxHash":
+		if executionContext.transaction == nil {
+			return nil, errors.New("operation does not contain a transaction")
+		}
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:       "value",
+			Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
+			BytesValue: digest.CalcTxHash(executionContext.transaction),
+		}).Build()}, nil
+
+	default:
+		return nil, errors.Errorf("unknown SDK env call method: %s", methodName)
+	}
+}
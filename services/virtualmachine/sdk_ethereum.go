@@ -0,0 +1,114 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
+)
+
+func (s *service) handleSdkEthereumCall(ctx context.Context, executionContext *executionContext, methodName primitives.MethodName, args []*protocol.MethodArgument, permissionScope protocol.ExecutionPermissionScope) ([]*protocol.MethodArgument, error) {
+	switch methodName {
+
+	case "callMethod":
+		value, err := s.handleSdkEthereumCallMethod(ctx, executionContext, args)
+		if err != nil {
+			return nil, err
+		}
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:       "outputArgs",
+			Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
+			BytesValue: value,
+		}).Build()}, nil
+
+	case "getTransactionLog":
+		value, ethereumBlockNumber, err := s.handleSdkEthereumGetTransactionLog(ctx, executionContext, args)
+		if err != nil {
+			return nil, err
+		}
+		return []*protocol.MethodArgument{(&protocol.MethodArgumentBuilder{
+			Name:        "ethereumBlockNumber",
+			Type:        protocol.METHOD_ARGUMENT_TYPE_UINT_64_VALUE,
+			Uint64Value: ethereumBlockNumber,
+		}).Build(), (&protocol.MethodArgumentBuilder{
+			Name:       "outputArgs",
+			Type:       protocol.METHOD_ARGUMENT_TYPE_BYTES_VALUE,
+			BytesValue: value,
+		}).Build()}, nil
+
+	default:
+		return nil, errors.Errorf("unknown SDK ethereum call method: %s", methodName)
+	}
+}
+
+// inputArg0: contractAddress (string)
+// inputArg1: jsonAbi (string)
+// inputArg2: methodName (string)
+// inputArg3: blockNumber (uint64) pinned by the caller so every validator queries the same ethereum state
+// inputArg4: inputArgs ([]byte of raw MethodArgumentArray)
+// outputArg0: outputArgs ([]byte of raw MethodArgumentArray)
+func (s *service) handleSdkEthereumCallMethod(ctx context.Context, executionContext *executionContext, args []*protocol.MethodArgument) ([]byte, error) {
+	if len(args) != 5 || !args[0].IsTypeStringValue() || !args[1].IsTypeStringValue() || !args[2].IsTypeStringValue() || !args[3].IsTypeUint64Value() || !args[4].IsTypeBytesValue() {
+		return nil, errors.Errorf("invalid SDK ethereum callMethod args: %v", args)
+	}
+
+	if err := executionContext.meter.charge(EXECUTION_COST_ETHEREUM_CALL); err != nil {
+		return nil, err
+	}
+
+	connector, found := s.crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM]
+	if !found {
+		return nil, errors.New("ethereum crosschain connector is not registered")
+	}
+
+	output, err := connector.EthereumCallContract(ctx, &services.EthereumCallContractInput{
+		EthereumContractAddress:    args[0].StringValue(),
+		EthereumJsonAbi:            args[1].StringValue(),
+		EthereumFunctionName:       args[2].StringValue(),
+		EthereumBlockNumber:        args[3].Uint64Value(),
+		EthereumInputArgumentArray: protocol.MethodArgumentArrayReader(args[4].BytesValue()),
+	})
+	if err != nil {
+		executionContext.recordNodeFault(err)
+		return nil, err
+	}
+	return output.EthereumOutputArgumentArray.Raw(), nil
+}
+
+// inputArg0: contractAddress (string) that emitted the log
+// inputArg1: jsonAbi (string)
+// inputArg2: eventName (string)
+// inputArg3: txHash (string)
+// inputArg4: logIndex (uint32) position of the log in its block
+// inputArg5: blockNumber (uint64) pinned by the caller, confirmations are counted up to it
+// outputArg0: ethereumBlockNumber (uint64) of the block containing the log
+// outputArg1: outputArgs ([]byte of raw MethodArgumentArray of the event params)
+func (s *service) handleSdkEthereumGetTransactionLog(ctx context.Context, executionContext *executionContext, args []*protocol.MethodArgument) ([]byte, uint64, error) {
+	if len(args) != 6 || !args[0].IsTypeStringValue() || !args[1].IsTypeStringValue() || !args[2].IsTypeStringValue() || !args[3].IsTypeStringValue() || !args[4].IsTypeUint32Value() || !args[5].IsTypeUint64Value() {
+		return nil, 0, errors.Errorf("invalid SDK ethereum getTransactionLog args: %v", args)
+	}
+
+	if err := executionContext.meter.charge(EXECUTION_COST_ETHEREUM_CALL); err != nil {
+		return nil, 0, err
+	}
+
+	if s.ethereumTransactionLogs == nil {
+		return nil, 0, errors.New("ethereum transaction logs are not available")
+	}
+
+	output, err := s.ethereumTransactionLogs.EthereumGetTransactionLog(ctx, &ethereum.EthereumGetTransactionLogInput{
+		EthereumContractAddress: args[0].StringValue(),
+		EthereumJsonAbi:         args[1].StringValue(),
+		EthereumEventName:       args[2].StringValue(),
+		EthereumTxhash:          args[3].StringValue(),
+		EthereumLogIndex:        args[4].Uint32Value(),
+		EthereumBlockNumber:     args[5].Uint64Value(),
+	})
+	if err != nil {
+		executionContext.recordNodeFault(err)
+		return nil, 0, err
+	}
+	return output.EthereumOutputArgumentArray.Raw(), output.EthereumBlockNumber, nil
+}
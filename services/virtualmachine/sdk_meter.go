@@ -0,0 +1,21 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/pkg/errors"
+)
+
+// called by processors (never by contracts) with the units they measured while running untrusted code
+func (s *service) ChargeExecutionUnits(ctx context.Context, executionContextId primitives.ExecutionContextId, units uint64) error {
+	executionContext := s.contexts.loadExecutionContext(executionContextId)
+	if executionContext == nil {
+		return errors.Errorf("invalid execution context %s", executionContextId)
+	}
+	if !executionContext.sdkCalls.enter() {
+		return ErrExecutionContextAbandoned
+	}
+	defer executionContext.sdkCalls.exit()
+
+	return executionContext.meter.charge(units)
+}
@@ -44,13 +44,33 @@ func (s *service) handleSdkServiceCallMethod(ctx context.Context, executionConte
 		return nil, err
 	}
 
+	if err := s.verifyServiceCallAllowed(ctx, executionContext, primitives.ContractName(serviceName)); err != nil {
+		s.logger.Info("Sdk.Service.CallMethod rejected", log.Error(err), log.Stringable("caller", executionContext.serviceStackTop()), log.String("callee", serviceName))
+		return nil, err
+	}
+	if executionContext.simulated {
+		if err := verifyNotDeploying(primitives.ContractName(serviceName), primitives.MethodName(methodName)); err != nil {
+			s.logger.Info("Sdk.Service.CallMethod rejected", log.Error(err), log.Stringable("caller", executionContext.serviceStackTop()), log.String("callee", serviceName))
+			return nil, err
+		}
+	}
+
+	if err := executionContext.meter.charge(EXECUTION_COST_PROCESSOR_CALL); err != nil {
+		return nil, err
+	}
+
 	// modify execution context
 	callingService := executionContext.serviceStackTop()
+	inputArgumentArray = s.globalPreOrderInitArgs(callingService, primitives.ContractName(serviceName), primitives.MethodName(methodName), inputArgumentArray)
 	executionContext.serviceStackPush(primitives.ContractName(serviceName))
 	defer executionContext.serviceStackPop()
 
 	// execute the call
-	output, err := processor.ProcessCall(ctx, &services.ProcessCallInput{
+	executionContext.tracer.enterCall(primitives.ContractName(serviceName), primitives.MethodName(methodName), inputArgumentArray)
+	callCtx, cancel := s.callContext(ctx, executionContext)
+	defer cancel()
+	activeSdkCalls := executionContext.sdkCalls.activeCalls()
+	output, err := processor.ProcessCall(callCtx, &services.ProcessCallInput{
 		ContextId:              executionContext.contextId,
 		ContractName:           primitives.ContractName(serviceName),
 		MethodName:             primitives.MethodName(methodName),
@@ -59,6 +79,13 @@ func (s *service) handleSdkServiceCallMethod(ctx context.Context, executionConte
 		CallingPermissionScope: permissionScope,
 		CallingService:         callingService,
 	})
+	executionContext.abandonCallIfDone(callCtx, activeSdkCalls)
+	executionContext.tracer.exitProcessCall(output, err)
+	executionContext.recordCallTimeout(callCtx, err)
+	executionContext.recordNodeFault(err)
+	if err == nil {
+		err = s.validateUpgradedService(ctx, executionContext, primitives.ContractName(serviceName), primitives.MethodName(methodName), inputArgumentArray)
+	}
 	if err != nil {
 		s.logger.Info("Sdk.Service.CallMethod failed", log.Error(err), log.Stringable("caller", callingService), log.Stringable("callee", primitives.ContractName(serviceName)))
 		return nil, err
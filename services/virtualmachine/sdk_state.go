@@ -42,20 +42,27 @@ func (s *service) handleSdkStateRead(ctx context.Context, executionContext *exec
 	}
 	key := args[0].BytesValue()
 
+	if err := executionContext.meter.charge(EXECUTION_COST_STATE_READ); err != nil {
+		return nil, err
+	}
+
 	// get current running service
 	currentService := executionContext.serviceStackTop()
 
 	// try from transient state first
 	value, found := executionContext.transientState.getValue(currentService, key)
 	if found {
-		return value, nil
+		executionContext.tracer.stateRead(currentService, key, value, STATE_READ_SOURCE_TRANSIENT)
+		return value, executionContext.meter.charge(EXECUTION_COST_PER_STATE_BYTE * uint64(len(value)))
 	}
 
 	// try from batch transient state first
 	if executionContext.batchTransientState != nil {
 		value, found = executionContext.batchTransientState.getValue(currentService, key)
 		if found {
-			return value, nil
+			executionContext.readSet.setValue(currentService, key, value, false)
+			executionContext.tracer.stateRead(currentService, key, value, STATE_READ_SOURCE_BATCH)
+			return value, executionContext.meter.charge(EXECUTION_COST_PER_STATE_BYTE * uint64(len(value)))
 		}
 	}
 
@@ -76,7 +83,11 @@ func (s *service) handleSdkStateRead(ctx context.Context, executionContext *exec
 	// store in transient state (cache)
 	executionContext.transientState.setValue(currentService, key, value, false)
 
-	return value, nil
+	// reads from outside the transaction are remembered to detect conflicts with other transactions
+	executionContext.readSet.setValue(currentService, key, value, false)
+	executionContext.tracer.stateRead(currentService, key, value, STATE_READ_SOURCE_STORAGE)
+
+	return value, executionContext.meter.charge(EXECUTION_COST_PER_STATE_BYTE * uint64(len(value)))
 }
 
 // inputArg0: key ([]byte)
@@ -92,12 +103,17 @@ func (s *service) handleSdkStateWrite(executionContext *executionContext, args [
 	key := args[0].BytesValue()
 	value := args[1].BytesValue()
 
+	if err := executionContext.meter.charge(EXECUTION_COST_STATE_WRITE + EXECUTION_COST_PER_STATE_BYTE*uint64(len(value))); err != nil {
+		return err
+	}
+
 	// get current running service
 	currentService := executionContext.serviceStackTop()
 
 	// write to transient state
 	// TODO: maybe compare with getValue to see the value actually changed
 	executionContext.transientState.setValue(currentService, key, value, true)
+	executionContext.tracer.stateWrite(currentService, key, value)
 
 	return nil
 }
@@ -2,8 +2,12 @@ package virtualmachine
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
 	"github.com/orbs-network/orbs-network-go/instrumentation/trace"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
 	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
@@ -17,31 +21,60 @@ type service struct {
 	stateStorage         services.StateStorage
 	processors           map[protocol.ProcessorType]services.Processor
 	crosschainConnectors map[protocol.CrosschainConnectorType]services.CrosschainConnector
+	config               config.VirtualMachineConfig
 	logger               log.BasicLogger
 
-	contexts *executionContextProvider
+	ethereumTransactionLogs ethereum.TransactionLogGetter
+
+	contexts   *executionContextProvider
+	blacklist  *contractBlacklist
+	codeHashes *codeHashCache
+
+	metrics *metrics
+}
+
+type metrics struct {
+	nonDeterministicExecutions *metric.Gauge
+}
+
+func getMetrics(m metric.Factory) *metrics {
+	return &metrics{
+		nonDeterministicExecutions: m.NewGauge("VirtualMachine.NonDeterministicExecutionsNumber"),
+	}
 }
 
 func NewVirtualMachine(
+	ctx context.Context,
 	stateStorage services.StateStorage,
 	processors map[protocol.ProcessorType]services.Processor,
 	crosschainConnectors map[protocol.CrosschainConnectorType]services.CrosschainConnector,
+	ethereumTransactionLogs ethereum.TransactionLogGetter,
+	config config.VirtualMachineConfig,
 	logger log.BasicLogger,
+	metricFactory metric.Factory,
 ) services.VirtualMachine {
 
 	s := &service{
 		processors:           processors,
 		crosschainConnectors: crosschainConnectors,
 		stateStorage:         stateStorage,
+		config:               config,
 		logger:               logger.WithTags(LogTag),
 
-		contexts: newExecutionContextProvider(),
+		ethereumTransactionLogs: ethereumTransactionLogs,
+
+		contexts:   newExecutionContextProvider(),
+		blacklist:  newContractBlacklist(),
+		codeHashes: newCodeHashCache(),
+		metrics:    getMetrics(metricFactory),
 	}
 
 	for _, processor := range processors {
 		processor.RegisterContractSdkCallHandler(s)
 	}
 
+	s.startPreloadingDeployedContracts(ctx)
+
 	return s
 }
 
@@ -59,7 +92,7 @@ func (s *service) RunLocalMethod(ctx context.Context, input *services.RunLocalMe
 	}
 
 	logger.Info("running local method", log.Stringable("contract", input.Transaction.ContractName()), log.Stringable("method", input.Transaction.MethodName()), log.BlockHeight(blockHeight))
-	callResult, outputArgs, err := s.runMethod(ctx, blockHeight, input.Transaction, protocol.ACCESS_SCOPE_READ_ONLY, nil)
+	callResult, outputArgs, err := s.runMethod(ctx, blockHeight, blockTimestamp, input.Transaction, protocol.ACCESS_SCOPE_READ_ONLY, nil, nil, true)
 	if outputArgs == nil {
 		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
 	}
@@ -72,12 +105,125 @@ func (s *service) RunLocalMethod(ctx context.Context, input *services.RunLocalMe
 	}, err
 }
 
-func (s *service) ProcessTransactionSet(ctx context.Context, input *services.ProcessTransactionSetInput) (*services.ProcessTransactionSetOutput, error) {
+func (s *service) TraceLocalMethod(ctx context.Context, input *TraceLocalMethodInput) (*TraceLocalMethodOutput, error) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	blockHeight, blockTimestamp, err := s.getRecentBlockHeight(ctx)
+	if err != nil {
+		return &TraceLocalMethodOutput{
+			CallResult:              protocol.EXECUTION_RESULT_ERROR_UNEXPECTED,
+			OutputArgumentArray:     []byte{},
+			ReferenceBlockHeight:    blockHeight,
+			ReferenceBlockTimestamp: blockTimestamp,
+			Trace:                   []byte{},
+		}, err
+	}
+
+	logger.Info("tracing local method", log.Stringable("contract", input.Transaction.ContractName()), log.Stringable("method", input.Transaction.MethodName()), log.BlockHeight(blockHeight))
+	execution := s.executeMethod(ctx, blockHeight, blockHeight, blockTimestamp, input.Transaction, protocol.ACCESS_SCOPE_READ_ONLY, nil, s.transactionExecutionBudget(nil), true, true, false)
+	outputArgs := execution.outputArgs
+	if outputArgs == nil {
+		outputArgs = (&protocol.MethodArgumentArrayBuilder{}).Build()
+	}
+
+	executionTrace, err := execution.tracer.toJson()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode execution trace")
+	}
+
+	return &TraceLocalMethodOutput{
+		CallResult:              execution.callResult,
+		OutputArgumentArray:     outputArgs.RawArgumentsArray(),
+		ReferenceBlockHeight:    blockHeight,
+		ReferenceBlockTimestamp: blockTimestamp,
+		Trace:                   executionTrace,
+	}, execution.err
+}
+
+// re-executes a committed block up to the given transaction and traces only that one, untimed like the block was
+func (s *service) TraceTransactionSet(ctx context.Context, input *TraceTransactionSetInput) (*TraceTransactionSetOutput, error) {
 	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
 	previousBlockHeight := input.BlockHeight - 1 // our contracts rely on this block's state for execution
 
+	batchTransientState := newTransientState()
+	blockMeter := newExecutionMeter(uint64(s.config.VirtualMachineBlockExecutionBudget()))
+
+	for _, signedTransaction := range input.SignedTransactions {
+		traced := digest.CalcTxHash(signedTransaction.Transaction()).Equal(input.Txhash)
+		execution := s.executeMethod(ctx, previousBlockHeight, input.BlockHeight, input.BlockTimestamp, signedTransaction.Transaction(), protocol.ACCESS_SCOPE_READ_WRITE, batchTransientState, s.transactionExecutionBudget(blockMeter), traced, false, false)
+		if execution.nodeFault != nil {
+			return nil, execution.nodeFault
+		}
+		execution.commit(batchTransientState, blockMeter)
+		if !traced {
+			continue
+		}
+
+		logger.Info("traced transaction", log.Transaction(input.Txhash), log.BlockHeight(input.BlockHeight))
+		executionTrace, err := execution.tracer.toJson()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encode execution trace")
+		}
+		return &TraceTransactionSetOutput{
+			TransactionReceipt: s.encodeTransactionReceiptOfExecution(signedTransaction.Transaction(), execution),
+			Trace:              executionTrace,
+		}, nil
+	}
+
+	return nil, errors.Errorf("transaction %s is not part of the transaction set", input.Txhash)
+}
+
+// executes a transaction read-write on top of a recent block (and optional state overrides) without committing anything,
+// the transaction is simulated as part of the following block with its own timestamp as the block timestamp
+func (s *service) SimulateTransaction(ctx context.Context, input *SimulateTransactionInput) (*SimulateTransactionOutput, error) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
+	lastCommittedBlockHeight, _, err := s.getRecentBlockHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	blockHeight := input.BlockHeight
+	if blockHeight == 0 {
+		blockHeight = lastCommittedBlockHeight
+	}
+	if err := s.validateSimulationBlockHeight(blockHeight, lastCommittedBlockHeight); err != nil {
+		return nil, err
+	}
+	if err := verifyNotDeploying(input.Transaction.ContractName(), input.Transaction.MethodName()); err != nil {
+		return nil, err
+	}
+	if err := verifyStateOverridesNotDeploying(input.StateOverrides); err != nil {
+		return nil, err
+	}
+
+	logger.Info("simulating transaction", log.Stringable("contract", input.Transaction.ContractName()), log.Stringable("method", input.Transaction.MethodName()), log.BlockHeight(blockHeight))
+	overrides := stateOverridesToTransientState(input.StateOverrides)
+	execution := s.executeMethod(ctx, blockHeight, blockHeight+1, input.Transaction.Timestamp(), input.Transaction, protocol.ACCESS_SCOPE_READ_WRITE, overrides, s.transactionExecutionBudget(nil), false, true, true)
+
+	if execution.nodeFault != nil {
+		return nil, execution.nodeFault
+	}
+
+	simulatedTransientState := newTransientState()
+	execution.commit(simulatedTransientState, nil)
+
+	return &SimulateTransactionOutput{
+		TransactionReceipt:   s.encodeTransactionReceiptOfExecution(input.Transaction, execution),
+		ContractStateDiffs:   s.encodeBatchTransientStateToStateDiffs(simulatedTransientState),
+		ReferenceBlockHeight: blockHeight,
+	}, nil
+}
+
+func (s *service) ProcessTransactionSet(ctx context.Context, input *services.ProcessTransactionSetInput) (*services.ProcessTransactionSetOutput, error) {
+	logger := s.logger.WithTags(trace.LogFieldFrom(ctx))
+
 	logger.Info("processing transaction set", log.Int("num-transactions", len(input.SignedTransactions)))
-	receipts, stateDiffs := s.processTransactionSet(ctx, previousBlockHeight, input.SignedTransactions)
+	receipts, stateDiffs, err := s.processTransactionSet(ctx, input.BlockHeight, input.BlockTimestamp, input.SignedTransactions)
+	if err != nil {
+		logger.Error("failed to process transaction set", log.Error(err))
+		return nil, err
+	}
 
 	return &services.ProcessTransactionSetOutput{
 		TransactionReceipts: receipts,
@@ -92,15 +238,29 @@ func (s *service) TransactionSetPreOrder(ctx context.Context, input *services.Tr
 	// FIXME sometimes we get value of ffffffffffffffff
 	previousBlockHeight := input.BlockHeight - 1 // our contracts rely on this block's state for execution
 
-	// check subscription
-	err := s.callGlobalPreOrderSystemContract(ctx, previousBlockHeight)
-	if err != nil {
-		for i := 0; i < len(statuses); i++ {
-			statuses[i] = protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER
+	// check signatures
+	err := s.verifyTransactionSignatures(input.SignedTransactions, statuses)
+
+	// check virtual chain and contract hooks
+	if hookErr := s.callVirtualChainPreOrderSystemContract(ctx, previousBlockHeight, input.BlockTimestamp, input.SignedTransactions, statuses); hookErr != nil && err == nil {
+		err = hookErr
+	}
+	if hookErr := s.callContractPreOrderMethods(ctx, previousBlockHeight, input.BlockTimestamp, input.SignedTransactions, statuses); hookErr != nil && err == nil {
+		err = hookErr
+	}
+
+	// check subscription (last, so rejected transactions don't use up the allowance)
+	if s.config.VirtualMachineSubscriptionEnforced() {
+		allowance, subscriptionErr := s.callGlobalPreOrderSystemContract(ctx, previousBlockHeight, input.BlockTimestamp)
+		if subscriptionErr != nil {
+			allowance = 0
+		}
+		if rejected := s.rejectTransactionsBeyondAllowance(ctx, previousBlockHeight, input.BlockTimestamp, input.SignedTransactions, statuses, allowance); rejected > 0 && err == nil {
+			err = subscriptionErr
+			if err == nil {
+				err = errors.Errorf("%d transactions exceed the subscription allowance of %d per block", rejected, allowance)
+			}
 		}
-	} else {
-		// check signatures
-		err = s.verifyTransactionSignatures(input.SignedTransactions, statuses)
 	}
 
 	if err != nil {
@@ -122,6 +282,14 @@ func (s *service) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCa
 	if executionContext == nil {
 		return nil, errors.Errorf("invalid execution context %s", input.ContextId)
 	}
+	if !executionContext.sdkCalls.enter() {
+		return nil, ErrExecutionContextAbandoned
+	}
+	defer executionContext.sdkCalls.exit()
+
+	if err := executionContext.meter.charge(EXECUTION_COST_SDK_CALL); err != nil {
+		return nil, err
+	}
 
 	switch input.OperationName {
 	case native.SDK_OPERATION_NAME_STATE:
@@ -130,6 +298,10 @@ func (s *service) HandleSdkCall(ctx context.Context, input *handlers.HandleSdkCa
 		output, err = s.handleSdkServiceCall(ctx, executionContext, input.MethodName, input.InputArguments, input.PermissionScope)
 	case native.SDK_OPERATION_NAME_ADDRESS:
 		output, err = s.handleSdkAddressCall(ctx, executionContext, input.MethodName, input.InputArguments, input.PermissionScope)
+	case native.SDK_OPERATION_NAME_ENV:
+		output, err = s.handleSdkEnvCall(ctx, executionContext, input.MethodName, input.InputArguments, input.PermissionScope)
+	case native.SDK_OPERATION_NAME_ETHEREUM:
+		output, err = s.handleSdkEthereumCall(ctx, executionContext, input.MethodName, input.InputArguments, input.PermissionScope)
 	default:
 		return nil, errors.Errorf("unknown SDK call operation: %s", input.OperationName)
 	}
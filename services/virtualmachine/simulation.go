@@ -0,0 +1,72 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+)
+
+// simulation is not part of the pinned spec, the public api reaches it on the virtual machine through this interface
+type Simulator interface {
+	SimulateTransaction(ctx context.Context, input *SimulateTransactionInput) (*SimulateTransactionOutput, error)
+}
+
+// a zero block height simulates on top of the last committed block
+type SimulateTransactionInput struct {
+	BlockHeight    primitives.BlockHeight
+	Transaction    *protocol.Transaction
+	StateOverrides []*protocol.ContractStateDiff
+}
+
+type SimulateTransactionOutput struct {
+	TransactionReceipt   *protocol.TransactionReceipt
+	ContractStateDiffs   []*protocol.ContractStateDiff
+	ReferenceBlockHeight primitives.BlockHeight
+}
+
+// state storage only keeps the last few revisions, older heights can't be read
+func (s *service) validateSimulationBlockHeight(blockHeight primitives.BlockHeight, lastCommittedBlockHeight primitives.BlockHeight) error {
+	if blockHeight > lastCommittedBlockHeight {
+		return errors.Errorf("unsupported block height: block %v is not yet committed, currently at %v", blockHeight, lastCommittedBlockHeight)
+	}
+	snapshotNum := primitives.BlockHeight(s.config.StateStorageHistorySnapshotNum())
+	if blockHeight+snapshotNum <= lastCommittedBlockHeight {
+		return errors.Errorf("unsupported block height: block %v too old, currently at %v, keeping %v back", blockHeight, lastCommittedBlockHeight, snapshotNum)
+	}
+	return nil
+}
+
+// overrides act as a batch that ran before the transaction, they are read before state storage but aren't part of the diff
+func stateOverridesToTransientState(stateOverrides []*protocol.ContractStateDiff) *transientState {
+	res := newTransientState()
+	for _, contractStateDiff := range stateOverrides {
+		for i := contractStateDiff.StateDiffsIterator(); i.HasNext(); {
+			record := i.NextStateDiffs()
+			res.setValue(contractStateDiff.ContractName(), record.Key(), record.Value(), false)
+		}
+	}
+	return res
+}
+
+// deploying or upgrading compiles native code, which is too costly to let anyone trigger with a dry run that nobody pays for,
+// so a simulation can't deploy or upgrade (directly or through another contract) or override the deployed code
+func verifyNotDeploying(contractName primitives.ContractName, methodName primitives.MethodName) error {
+	if contractName != primitives.ContractName(deployments_systemcontract.CONTRACT.Name) {
+		return nil
+	}
+	if methodName == primitives.MethodName(deployments_systemcontract.METHOD_DEPLOY_SERVICE.Name) || methodName == primitives.MethodName(deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name) {
+		return errors.Errorf("simulations can't deploy or upgrade contracts: %s.%s", contractName, methodName)
+	}
+	return nil
+}
+
+func verifyStateOverridesNotDeploying(stateOverrides []*protocol.ContractStateDiff) error {
+	for _, contractStateDiff := range stateOverrides {
+		if contractStateDiff.ContractName() == primitives.ContractName(deployments_systemcontract.CONTRACT.Name) {
+			return errors.Errorf("simulations can't override the state of %s", contractStateDiff.ContractName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/statestorage"
+	stateStorageAdapter "github.com/orbs-network/orbs-network-go/services/statestorage/adapter"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/test/harness/services/processor/native/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+)
+
+// runs the virtual machine with a real native processor and state storage so BenchmarkToken can be executed end to end
+type benchmarkTokenHarness struct {
+	stateStorage services.StateStorage
+	service      services.VirtualMachine
+}
+
+func newBenchmarkTokenHarness(executionConcurrency uint32, blockExecutionBudget uint32) *benchmarkTokenHarness {
+	logger := log.GetLogger().WithOutput() // a mute logger
+	registry := metric.NewRegistry()
+
+	stateStorage := statestorage.NewStateStorage(config.ForStateStorageTest(5, 0, 0), stateStorageAdapter.NewInMemoryStatePersistence(registry), logger, registry)
+
+	processors := make(map[protocol.ProcessorType]services.Processor)
+	processors[protocol.PROCESSOR_TYPE_NATIVE] = native.NewNativeProcessor(nativeProcessorAdapter.NewFakeCompiler(), logger, registry)
+
+	service := virtualmachine.NewVirtualMachine(
+		context.Background(),
+		stateStorage,
+		processors,
+		make(map[protocol.CrosschainConnectorType]services.CrosschainConnector),
+		nil,
+		config.ForVirtualMachineTests(1000000, blockExecutionBudget, executionConcurrency),
+		logger,
+		registry,
+	)
+
+	return &benchmarkTokenHarness{
+		stateStorage: stateStorage,
+		service:      service,
+	}
+}
+
+// deploys BenchmarkToken (owned by test key 0) in block 1
+func (h *benchmarkTokenHarness) deployBenchmarkToken(ctx context.Context) error {
+	output, err := h.service.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+		BlockHeight:        1,
+		SignedTransactions: []*protocol.SignedTransaction{transferTransaction(testKeys.Ed25519KeyPairForTests(0), 0, builders.AddressForEd25519SignerForTests(0))},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = h.stateStorage.CommitStateDiff(ctx, &services.CommitStateDiffInput{
+		ResultsBlockHeader: (&protocol.ResultsBlockHeaderBuilder{BlockHeight: 1}).Build(),
+		ContractStateDiffs: output.ContractStateDiffs,
+	})
+	return err
+}
+
+func (h *benchmarkTokenHarness) processTransactionSet(ctx context.Context, blockHeight primitives.BlockHeight, transactions []*protocol.SignedTransaction) (*services.ProcessTransactionSetOutput, error) {
+	return h.service.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+		BlockHeight:        blockHeight,
+		SignedTransactions: transactions,
+	})
+}
+
+func transferTransaction(signer *keys.Ed25519KeyPair, amount uint64, targetAddress primitives.Ripmd160Sha256) *protocol.SignedTransaction {
+	return builders.TransferTransaction().WithEd25519Signer(signer).WithAmountAndTargetAddress(amount, targetAddress).Build()
+}
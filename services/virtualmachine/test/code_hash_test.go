@@ -0,0 +1,40 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetDeployedCodeHash_ReadOncePerBlockHeight(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		codeHash := hash.CalcSha256([]byte("code of Contract2"))
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			res, err := h.service.(native.DeployedCodeHashGetter).GetDeployedCodeHash(ctx, executionContextId, "Contract2")
+			require.NoError(t, err, "GetDeployedCodeHash should not fail")
+			require.EqualValues(t, codeHash, res, "GetDeployedCodeHash should return the hash stored by _Deployments")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectStateStorageRead(11, deployments_systemcontract.CONTRACT.Name, hash.CalcRipmd160Sha256([]byte("Contract2.CodeHash")), codeHash)
+
+		results, _, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS, protocol.EXECUTION_RESULT_SUCCESS}, results, "processTransactionSet returned receipts should match")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+		h.verifyStateStorageRead(t)
+	})
+}
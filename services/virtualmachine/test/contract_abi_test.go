@@ -0,0 +1,46 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestGetLocalContractAbi_DescribesDeployedContract(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE))
+		h.expectNativeContractInfoRequested("Contract1", nil)
+
+		output, err := h.service.(virtualmachine.ContractAbiReader).GetLocalContractAbi(ctx, &virtualmachine.GetLocalContractAbiInput{ContractName: "Contract1"})
+		require.NoError(t, err, "get contract abi should succeed")
+		require.Equal(t, protocol.PERMISSION_SCOPE_SERVICE, output.PermissionScope, "permission scope should match")
+		require.EqualValues(t, 12, output.ReferenceBlockHeight, "reference block height should match")
+		require.False(t, output.Described, "contract of a processor that can't describe its methods should not be described")
+
+		h.verifyStateStorageBlockHeightRequested(t)
+		h.verifyNativeContractInfoRequested(t)
+	})
+}
+
+func TestGetLocalContractAbi_DescribesNativeContractNotDeployedYet(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, errors.New("not deployed"), uint32(0))
+		h.expectNativeContractInfoRequested("Contract1", nil)
+
+		_, err := h.service.(virtualmachine.ContractAbiReader).GetLocalContractAbi(ctx, &virtualmachine.GetLocalContractAbiInput{ContractName: "Contract1"})
+		require.NoError(t, err, "get contract abi should succeed")
+
+		h.verifyNativeContractInfoRequested(t)
+	})
+}
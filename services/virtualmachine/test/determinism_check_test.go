@@ -0,0 +1,172 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_VirtualChainPreOrder"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestDeterminismSelfCheck_DeterministicContractExecutesTwice(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithDeterminismSelfCheck(true)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes the same value every time it executes")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(uint32(17)), nil
+		})
+
+		results, _, sd := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS}, results, "processTransactionSet returned receipts should match")
+		require.ElementsMatch(t, sd["Contract1"], []*keyValuePair{
+			{[]byte{0x01}, []byte{0x02}},
+		}, "processTransactionSet returned contract state diffs should match")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestDeterminismSelfCheck_NonDeterministicContractIsBlacklisted(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithDeterminismSelfCheck(true)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		executions := byte(0)
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes a different value every time it executes")
+			executions++
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{executions})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		results, _, sd := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS}, results, "the self check should not change the result")
+		require.ElementsMatch(t, sd["Contract1"], []*keyValuePair{
+			{[]byte{0x01}, []byte{0x01}},
+		}, "state diffs should be of the first execution")
+
+		require.True(t, h.isContractBlacklisted("Contract1"), "the non-deterministic contract should be blacklisted")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestDeterminismSelfCheck_ContractSpendingDifferentBudgetIsBlacklisted(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithDeterminismSelfCheckAndExecutionBudget(10000, 5000, true)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		executions := 0
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes the same value but more times on every execution, spending more of the block budget")
+			executions++
+			for i := 0; i < executions*4-3; i++ {
+				_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+				require.NoError(t, err, "handleSdkCall should not fail")
+			}
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalledTimes("Contract2", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 2: deterministic, runs out of block budget when the set is executed again")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x03}, []byte{0x04})
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), err
+		})
+
+		results, _, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract2", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS, protocol.EXECUTION_RESULT_SUCCESS}, results, "the self check should not change the results")
+
+		require.True(t, h.isContractBlacklisted("Contract1"), "the contract that spent a different budget should be blacklisted")
+		require.False(t, h.isContractBlacklisted("Contract2"), "the contract left without block budget should not be blacklisted")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestDeterminismSelfCheck_NonDeterministicNestedContractIsBlacklisted(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithDeterminismSelfCheck(true)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes what the nested call returns")
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray().Raw())
+			require.NoError(t, err, "handleSdkCall should not fail")
+			value := protocol.MethodArgumentArrayReader(res[0].BytesValue()).ArgumentsIterator().NextArguments().BytesValue()
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, value)
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		executions := byte(0)
+		h.expectNativeContractMethodCalledTimes("Contract2", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Nested call: returns a different value every time it executes")
+			executions++
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray([]byte{executions}), nil
+		})
+
+		results, _, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS}, results, "the self check should not change the result")
+
+		require.True(t, h.isContractBlacklisted("Contract2"), "the nested contract that diverged should be blacklisted")
+		require.False(t, h.isContractBlacklisted("Contract1"), "the caller of the contract that diverged should not be blacklisted")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestDeterminismSelfCheck_BlacklistedContractDoesNotFailPreOrderOfBlocksBeingValidated(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithDeterminismSelfCheck(true)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		executions := byte(0)
+		h.expectNativeContractMethodCalledTimes("Contract1", "method1", 2, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes a different value every time it executes")
+			executions++
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{executions})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.True(t, h.isContractBlacklisted("Contract1"), "the non-deterministic contract should be blacklisted")
+
+		h.expectNativeContractMethodCalled(primitives.ContractName(virtualchainpreorder_systemcontract.CONTRACT.Name), primitives.MethodName(virtualchainpreorder_systemcontract.METHOD_APPROVE.Name), func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract1", native.METHOD_NAME_PRE_ORDER, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		statuses, err := h.transactionSetPreOrder(ctx, []*protocol.SignedTransaction{builders.Transaction().WithMethod("Contract1", "method1").Build()})
+		require.NoError(t, err, "pre order of a block proposed by another leader should not depend on the blacklist of this node")
+		require.Equal(t, []protocol.TransactionStatus{protocol.TRANSACTION_STATUS_PRE_ORDER_VALID}, statuses, "transaction to a contract blacklisted on this node should pass pre order")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
@@ -0,0 +1,66 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestExecutionMeter_TransactionExceedingBudgetIsAborted(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionBudget(virtualmachine.EXECUTION_COST_PROCESSOR_CALL+virtualmachine.EXECUTION_COST_STATE_WRITE, 100*1000000)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: keeps writing after the budget is exhausted and swallows the error")
+			for i := 0; i < 10; i++ {
+				h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			}
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			require.Equal(t, virtualmachine.ErrExecutionBudgetExceeded, err, "handleSdkCall should fail once the budget is exhausted")
+
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		results, outputArgs, sd := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT}, results, "transaction exceeding its budget should fail like a contract error")
+		require.Contains(t, string(outputArgs[0]), virtualmachine.ErrExecutionBudgetExceeded.Error(), "receipt should carry the reason the transaction was aborted")
+		require.Empty(t, sd["Contract1"], "state of an aborted transaction should not be written")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestExecutionMeter_TransactionsBeyondBlockBudgetAreLeftOut(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionBudget(1000000, virtualmachine.EXECUTION_COST_PROCESSOR_CALL+virtualmachine.EXECUTION_COST_PROCESSOR_CALL/2)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: successful")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodNotCalled("Contract1", "method2")
+
+		results, _, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract1", "method2"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{
+			protocol.EXECUTION_RESULT_SUCCESS,
+		}, results, "transactions beyond the block budget should get no receipt")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"testing"
 )
@@ -22,6 +26,10 @@ func (h *harness) verifyHandlerRegistrations(t *testing.T) {
 }
 
 func (h *harness) expectNativeContractMethodCalled(expectedContractName primitives.ContractName, expectedMethodName primitives.MethodName, contractFunction func(primitives.ExecutionContextId, *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error)) {
+	h.expectNativeContractMethodCalledTimes(expectedContractName, expectedMethodName, 1, contractFunction)
+}
+
+func (h *harness) expectNativeContractMethodCalledTimes(expectedContractName primitives.ContractName, expectedMethodName primitives.MethodName, times int, contractFunction func(primitives.ExecutionContextId, *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error)) {
 	contractMethodMatcher := func(i interface{}) bool {
 		input, ok := i.(*services.ProcessCallInput)
 		return ok &&
@@ -36,6 +44,53 @@ func (h *harness) expectNativeContractMethodCalled(expectedContractName primitiv
 			OutputArgumentArray: outputArgsArray,
 			CallResult:          callResult,
 		}, err
+	}).Times(times)
+}
+
+// like the native processor, the call is abandoned once its context is done and fails as timed out
+func (h *harness) expectNativeContractMethodLoopingForever(expectedContractName primitives.ContractName, expectedMethodName primitives.MethodName) {
+	contractMethodMatcher := func(i interface{}) bool {
+		input, ok := i.(*services.ProcessCallInput)
+		return ok &&
+			input.ContractName == expectedContractName &&
+			input.MethodName == expectedMethodName
+	}
+
+	h.processors[protocol.PROCESSOR_TYPE_NATIVE].When("ProcessCall", mock.Any, mock.AnyIf(fmt.Sprintf("Contract equals %s and Method %s", expectedContractName, expectedMethodName), contractMethodMatcher)).Call(func(ctx context.Context, input *services.ProcessCallInput) (*services.ProcessCallOutput, error) {
+		for ctx.Err() == nil {
+		}
+		return &services.ProcessCallOutput{
+			OutputArgumentArray: builders.MethodArgumentsArray(native.ErrContractTimeout.Error()),
+			CallResult:          protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT,
+		}, native.ErrContractTimeout
+	}).Times(1)
+}
+
+// like the native processor, the call returns as timed out once its context is done while the contract keeps running
+func (h *harness) expectNativeContractMethodAbandonedAtDeadline(expectedContractName primitives.ContractName, expectedMethodName primitives.MethodName, contractFunction func(primitives.ExecutionContextId, *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error)) {
+	contractMethodMatcher := func(i interface{}) bool {
+		input, ok := i.(*services.ProcessCallInput)
+		return ok &&
+			input.ContractName == expectedContractName &&
+			input.MethodName == expectedMethodName
+	}
+
+	h.processors[protocol.PROCESSOR_TYPE_NATIVE].When("ProcessCall", mock.Any, mock.AnyIf(fmt.Sprintf("Contract equals %s and Method %s", expectedContractName, expectedMethodName), contractMethodMatcher)).Call(func(ctx context.Context, input *services.ProcessCallInput) (*services.ProcessCallOutput, error) {
+		done := make(chan *services.ProcessCallOutput, 1)
+		go func() {
+			callResult, outputArgsArray, _ := contractFunction(input.ContextId, input.InputArgumentArray)
+			done <- &services.ProcessCallOutput{OutputArgumentArray: outputArgsArray, CallResult: callResult}
+		}()
+
+		select {
+		case output := <-done:
+			return output, nil
+		case <-ctx.Done():
+			return &services.ProcessCallOutput{
+				OutputArgumentArray: builders.MethodArgumentsArray(native.ErrContractTimeout.Error()),
+				CallResult:          protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT,
+			}, native.ErrContractTimeout
+		}
 	}).Times(1)
 }
 
@@ -98,6 +153,45 @@ func (h *harness) verifySystemContractCalled(t *testing.T) {
 	require.True(t, ok, "did not call processor for system contract: %v", err)
 }
 
+func (h *harness) expectDeploymentInfoRequested(expectedServiceName primitives.ContractName, returnError error, processorType protocol.ProcessorType) {
+	serviceMatcher := func(i interface{}) bool {
+		input, ok := i.(*services.ProcessCallInput)
+		if !ok || string(input.ContractName) != deployments_systemcontract.CONTRACT.Name || string(input.MethodName) != deployments_systemcontract.METHOD_GET_INFO.Name {
+			return false
+		}
+		argsIterator := input.InputArgumentArray.ArgumentsIterator()
+		return argsIterator.HasNext() && argsIterator.NextArguments().StringValue() == string(expectedServiceName)
+	}
+
+	callResult := protocol.EXECUTION_RESULT_SUCCESS
+	if returnError != nil {
+		callResult = protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT
+	}
+	outputToReturn := &services.ProcessCallOutput{
+		OutputArgumentArray: builders.MethodArgumentsArray(uint32(processorType)),
+		CallResult:          callResult,
+	}
+
+	h.processors[protocol.PROCESSOR_TYPE_NATIVE].When("ProcessCall", mock.Any, mock.AnyIf(fmt.Sprintf("Deployment info of %s", expectedServiceName), serviceMatcher)).Return(outputToReturn, returnError).AtLeast(1)
+}
+
+// the virtual chain and contract pre order hooks are looked up for every valid transaction
+func (h *harness) expectPreOrderHooksNotDeployed() {
+	deploymentInfoMatcher := func(i interface{}) bool {
+		input, ok := i.(*services.ProcessCallInput)
+		return ok &&
+			string(input.ContractName) == deployments_systemcontract.CONTRACT.Name &&
+			string(input.MethodName) == deployments_systemcontract.METHOD_GET_INFO.Name
+	}
+	outputToReturn := &services.ProcessCallOutput{
+		OutputArgumentArray: builders.MethodArgumentsArray(),
+		CallResult:          protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT,
+	}
+
+	h.processors[protocol.PROCESSOR_TYPE_NATIVE].When("ProcessCall", mock.Any, mock.AnyIf("Deployment info of any contract", deploymentInfoMatcher)).Return(outputToReturn, errors.New("contract not deployed")).AtLeast(0)
+	h.processors[protocol.PROCESSOR_TYPE_NATIVE].When("GetContractInfo", mock.Any, mock.Any).Return(nil, errors.New("contract not found")).AtLeast(0)
+}
+
 func (h *harness) expectNativeContractInfoRequested(expectedContractName primitives.ContractName, returnError error) {
 	contractMatcher := func(i interface{}) bool {
 		input, ok := i.(*services.GetContractInfoInput)
@@ -159,3 +253,45 @@ func (h *harness) verifyStateStorageRead(t *testing.T) {
 func (h *harness) expectStateStorageNotRead() {
 	h.stateStorage.When("ReadKeys", mock.Any, mock.Any).Return(&services.ReadKeysOutput{}, nil).Times(0)
 }
+
+func (h *harness) expectEthereumConnectorCalled(expectedContractAddress string, expectedBlockNumber uint64, returnOutputArgs *protocol.MethodArgumentArray, returnError error) {
+	ethereumCallMatcher := func(i interface{}) bool {
+		input, ok := i.(*services.EthereumCallContractInput)
+		return ok &&
+			input.EthereumContractAddress == expectedContractAddress &&
+			input.EthereumBlockNumber == expectedBlockNumber
+	}
+
+	var outputToReturn *services.EthereumCallContractOutput
+	if returnOutputArgs != nil {
+		outputToReturn = &services.EthereumCallContractOutput{
+			EthereumOutputArgumentArray: returnOutputArgs,
+		}
+	}
+
+	h.crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM].When("EthereumCallContract", mock.Any, mock.AnyIf("contract address and block number match", ethereumCallMatcher)).Return(outputToReturn, returnError).Times(1)
+}
+
+func (h *harness) verifyEthereumConnectorCalled(t *testing.T) {
+	ok, err := h.crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM].Verify()
+	require.True(t, ok, "did not call the ethereum connector: %v", err)
+}
+
+func (h *harness) expectEthereumConnectorLogRequested(expectedTxhash string, expectedBlockNumber uint64, returnBlockNumber uint64, returnOutputArgs *protocol.MethodArgumentArray, returnError error) {
+	ethereumLogMatcher := func(i interface{}) bool {
+		input, ok := i.(*ethereum.EthereumGetTransactionLogInput)
+		return ok &&
+			input.EthereumTxhash == expectedTxhash &&
+			input.EthereumBlockNumber == expectedBlockNumber
+	}
+
+	var outputToReturn *ethereum.EthereumGetTransactionLogOutput
+	if returnOutputArgs != nil {
+		outputToReturn = &ethereum.EthereumGetTransactionLogOutput{
+			EthereumOutputArgumentArray: returnOutputArgs,
+			EthereumBlockNumber:         returnBlockNumber,
+		}
+	}
+
+	h.crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM].When("EthereumGetTransactionLog", mock.Any, mock.AnyIf("txhash and block number match", ethereumLogMatcher)).Return(outputToReturn, returnError).Times(1)
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/orbs-network/go-mock"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/crypto/digest"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
+	"github.com/orbs-network/orbs-network-go/instrumentation/metric"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
 	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -12,18 +16,66 @@ import (
 	"github.com/orbs-network/orbs-spec/types/go/services"
 	"github.com/orbs-network/orbs-spec/types/go/services/handlers"
 	"os"
+	"time"
 )
 
 type harness struct {
 	blockStorage         *services.MockBlockStorage
 	stateStorage         *services.MockStateStorage
 	processors           map[protocol.ProcessorType]*services.MockProcessor
-	crosschainConnectors map[protocol.CrosschainConnectorType]*services.MockCrosschainConnector
+	crosschainConnectors map[protocol.CrosschainConnectorType]*crosschainConnectorMock
 	reporting            log.BasicLogger
 	service              services.VirtualMachine
 }
 
+// the generated connector mock with the transaction log lookups of ethereum.Connector
+type crosschainConnectorMock struct {
+	services.MockCrosschainConnector
+}
+
+func (s *crosschainConnectorMock) EthereumGetTransactionLog(ctx context.Context, input *ethereum.EthereumGetTransactionLogInput) (*ethereum.EthereumGetTransactionLogOutput, error) {
+	ret := s.Called(ctx, input)
+	if out := ret.Get(0); out != nil {
+		return out.(*ethereum.EthereumGetTransactionLogOutput), ret.Error(1)
+	} else {
+		return nil, ret.Error(1)
+	}
+}
+
 func newHarness() *harness {
+	return newHarnessWithExecutionBudget(1000000, 100*1000000)
+}
+
+func newHarnessWithExecutionBudget(transactionExecutionBudget uint32, blockExecutionBudget uint32) *harness {
+	h := newUnstartedHarness()
+	h.start(context.Background(), config.ForVirtualMachineTests(transactionExecutionBudget, blockExecutionBudget, 1))
+	return h
+}
+
+func newHarnessWithMaximumCallDepth(maximumCallDepth uint32) *harness {
+	h := newUnstartedHarness()
+	h.start(context.Background(), config.ForVirtualMachineCallDepthTests(maximumCallDepth))
+	return h
+}
+
+func newHarnessWithExecutionTimeout(transactionExecutionTimeout time.Duration, callExecutionTimeout time.Duration, blockCallExecutionTimeout time.Duration) *harness {
+	h := newUnstartedHarness()
+	h.start(context.Background(), config.ForVirtualMachineTimeoutTests(transactionExecutionTimeout, callExecutionTimeout, blockCallExecutionTimeout))
+	return h
+}
+
+func newHarnessWithDeterminismSelfCheck(blacklist bool) *harness {
+	return newHarnessWithDeterminismSelfCheckAndExecutionBudget(1000000, 100*1000000, blacklist)
+}
+
+func newHarnessWithDeterminismSelfCheckAndExecutionBudget(transactionExecutionBudget uint32, blockExecutionBudget uint32, blacklist bool) *harness {
+	h := newUnstartedHarness()
+	h.start(context.Background(), config.ForVirtualMachineDeterminismSelfCheckTests(transactionExecutionBudget, blockExecutionBudget, blacklist))
+	return h
+}
+
+// expectations of calls made when the service is created (like preloading) must be set before starting it
+func newUnstartedHarness() *harness {
 	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
 
 	blockStorage := &services.MockBlockStorage{}
@@ -33,34 +85,39 @@ func newHarness() *harness {
 	processors[protocol.PROCESSOR_TYPE_NATIVE] = &services.MockProcessor{}
 	processors[protocol.PROCESSOR_TYPE_NATIVE].When("RegisterContractSdkCallHandler", mock.Any).Return().Times(1)
 
-	crosschainConnectors := make(map[protocol.CrosschainConnectorType]*services.MockCrosschainConnector)
-	crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM] = &services.MockCrosschainConnector{}
+	crosschainConnectors := make(map[protocol.CrosschainConnectorType]*crosschainConnectorMock)
+	crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM] = &crosschainConnectorMock{}
+
+	return &harness{
+		blockStorage:         blockStorage,
+		stateStorage:         stateStorage,
+		processors:           processors,
+		crosschainConnectors: crosschainConnectors,
+		reporting:            log,
+	}
+}
 
+func (h *harness) start(ctx context.Context, cfg config.VirtualMachineConfig) {
 	processorsForService := make(map[protocol.ProcessorType]services.Processor)
-	for key, value := range processors {
+	for key, value := range h.processors {
 		processorsForService[key] = value
 	}
 
 	crosschainConnectorsForService := make(map[protocol.CrosschainConnectorType]services.CrosschainConnector)
-	for key, value := range crosschainConnectors {
+	for key, value := range h.crosschainConnectors {
 		crosschainConnectorsForService[key] = value
 	}
 
-	service := virtualmachine.NewVirtualMachine(
-		stateStorage,
+	h.service = virtualmachine.NewVirtualMachine(
+		ctx,
+		h.stateStorage,
 		processorsForService,
 		crosschainConnectorsForService,
-		log,
+		h.crosschainConnectors[protocol.CROSSCHAIN_CONNECTOR_TYPE_ETHEREUM],
+		cfg,
+		h.reporting,
+		metric.NewRegistry(),
 	)
-
-	return &harness{
-		blockStorage:         blockStorage,
-		stateStorage:         stateStorage,
-		processors:           processors,
-		crosschainConnectors: crosschainConnectors,
-		reporting:            log,
-		service:              service,
-	}
 }
 
 func (h *harness) handleSdkCall(ctx context.Context, executionContextId primitives.ExecutionContextId, contractName primitives.ContractName, methodName primitives.MethodName, args ...interface{}) ([]*protocol.MethodArgument, error) {
@@ -104,6 +161,29 @@ func (h *harness) runLocalMethod(ctx context.Context, contractName primitives.Co
 	return output.CallResult, output.OutputArgumentArray, output.ReferenceBlockHeight, err
 }
 
+func (h *harness) traceLocalMethod(ctx context.Context, contractName primitives.ContractName, methodName primitives.MethodName) (protocol.ExecutionResult, []byte, error) {
+	output, err := h.service.(virtualmachine.Tracer).TraceLocalMethod(ctx, &virtualmachine.TraceLocalMethodInput{
+		Transaction: (&protocol.TransactionBuilder{
+			Signer:             nil,
+			ContractName:       contractName,
+			MethodName:         methodName,
+			InputArgumentArray: []byte{},
+		}).Build(),
+	})
+	if output == nil {
+		return protocol.EXECUTION_RESULT_RESERVED, nil, err
+	}
+	return output.CallResult, output.Trace, err
+}
+
+func (h *harness) simulateTransaction(ctx context.Context, blockHeight primitives.BlockHeight, contractName primitives.ContractName, methodName primitives.MethodName, stateOverrides []*protocol.ContractStateDiff) (*virtualmachine.SimulateTransactionOutput, error) {
+	return h.service.(virtualmachine.Simulator).SimulateTransaction(ctx, &virtualmachine.SimulateTransactionInput{
+		BlockHeight:    blockHeight,
+		Transaction:    builders.Transaction().WithMethod(contractName, methodName).Build().Transaction(),
+		StateOverrides: stateOverrides,
+	})
+}
+
 type keyValuePair struct {
 	key   primitives.Ripmd160Sha256
 	value []byte
@@ -114,6 +194,21 @@ type contractAndMethod struct {
 	methodName   primitives.MethodName
 }
 
+// for sets the node fails to process, no receipts are expected
+func (h *harness) processTransactionSetFailing(ctx context.Context, contractAndMethods []*contractAndMethod) error {
+	transactions := []*protocol.SignedTransaction{}
+	for _, contractAndMethod := range contractAndMethods {
+		transactions = append(transactions, builders.Transaction().WithMethod(contractAndMethod.contractName, contractAndMethod.methodName).Build())
+	}
+
+	_, err := h.service.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
+		BlockHeight:        12,
+		BlockTimestamp:     5678,
+		SignedTransactions: transactions,
+	})
+	return err
+}
+
 func (h *harness) processTransactionSet(ctx context.Context, contractAndMethods []*contractAndMethod) ([]protocol.ExecutionResult, [][]byte, map[primitives.ContractName][]*keyValuePair) {
 	resultKeyValuePairsPerContract := make(map[primitives.ContractName][]*keyValuePair)
 
@@ -126,6 +221,7 @@ func (h *harness) processTransactionSet(ctx context.Context, contractAndMethods
 
 	output, _ := h.service.ProcessTransactionSet(ctx, &services.ProcessTransactionSetInput{
 		BlockHeight:        12,
+		BlockTimestamp:     5678,
 		SignedTransactions: transactions,
 	})
 
@@ -152,9 +248,32 @@ func (h *harness) processTransactionSet(ctx context.Context, contractAndMethods
 	return results, outputArgsOfAllTransactions, resultKeyValuePairsPerContract
 }
 
+func (h *harness) traceTransactionSet(ctx context.Context, contractAndMethods []*contractAndMethod, tracedIndex int) (protocol.ExecutionResult, []byte, error) {
+	transactions := []*protocol.SignedTransaction{}
+	for _, contractAndMethod := range contractAndMethods {
+		tx := builders.Transaction().WithMethod(contractAndMethod.contractName, contractAndMethod.methodName).Build()
+		transactions = append(transactions, tx)
+	}
+
+	output, err := h.service.(virtualmachine.Tracer).TraceTransactionSet(ctx, &virtualmachine.TraceTransactionSetInput{
+		BlockHeight:        12,
+		SignedTransactions: transactions,
+		Txhash:             digest.CalcTxHash(transactions[tracedIndex].Transaction()),
+	})
+	if err != nil {
+		return protocol.EXECUTION_RESULT_RESERVED, nil, err
+	}
+	return output.TransactionReceipt.ExecutionResult(), output.Trace, nil
+}
+
+func (h *harness) isContractBlacklisted(contractName primitives.ContractName) bool {
+	return h.service.(virtualmachine.ContractBlacklist).IsContractBlacklisted(contractName)
+}
+
 func (h *harness) transactionSetPreOrder(ctx context.Context, signedTransactions []*protocol.SignedTransaction) ([]protocol.TransactionStatus, error) {
 	output, err := h.service.TransactionSetPreOrder(ctx, &services.TransactionSetPreOrderInput{
 		BlockHeight:        12,
+		BlockTimestamp:     primitives.TimestampNano(time.Now().UnixNano()),
 		SignedTransactions: signedTransactions,
 	})
 	return output.PreOrderResults, err
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"github.com/stretchr/testify/require"
+	"runtime"
+	"testing"
+)
+
+func TestParallelExecution_MatchesSerialExecution(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		transactions := []*protocol.SignedTransaction{
+			transferTransaction(testKeys.Ed25519KeyPairForTests(0), 100, builders.AddressForEd25519SignerForTests(1)), // deploys BenchmarkToken
+			transferTransaction(testKeys.Ed25519KeyPairForTests(1), 50, builders.AddressForEd25519SignerForTests(2)),  // depends on the previous transfer
+			transferTransaction(testKeys.Ed25519KeyPairForTests(2), 60, builders.AddressForEd25519SignerForTests(3)),  // fails on insufficient balance
+			transferTransaction(testKeys.Ed25519KeyPairForTests(4), 0, builders.AddressForEd25519SignerForTests(5)),
+			transferTransaction(testKeys.Ed25519KeyPairForTests(0), 99999999, builders.AddressForEd25519SignerForTests(6)),
+			transferTransaction(testKeys.Ed25519KeyPairForTests(7), 0, builders.AddressForEd25519SignerForTests(8)),
+			transferTransaction(testKeys.Ed25519KeyPairForTests(0), 10, builders.AddressForEd25519SignerForTests(2)),
+			transferTransaction(testKeys.Ed25519KeyPairForTests(2), 60, builders.AddressForEd25519SignerForTests(9)), // succeeds now
+		}
+
+		serial, err := newBenchmarkTokenHarness(1, 100*1000000).processTransactionSet(ctx, 1, transactions)
+		require.NoError(t, err)
+		parallel, err := newBenchmarkTokenHarness(4, 100*1000000).processTransactionSet(ctx, 1, transactions)
+		require.NoError(t, err)
+
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, serial.TransactionReceipts[2].ExecutionResult(), "transfer over balance should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, serial.TransactionReceipts[7].ExecutionResult(), "transfer after a deposit should succeed")
+		requireSameTransactionSetOutput(t, serial, parallel)
+	})
+}
+
+func TestParallelExecution_MatchesSerialExecutionWhenBlockBudgetRunsOut(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		transactions := []*protocol.SignedTransaction{}
+		for i := 0; i < 10; i++ {
+			transactions = append(transactions, transferTransaction(testKeys.Ed25519KeyPairForTests(i), 0, builders.AddressForEd25519SignerForTests(i)))
+		}
+
+		serialHarness := newBenchmarkTokenHarness(1, 10000)
+		require.NoError(t, serialHarness.deployBenchmarkToken(ctx))
+		serial, err := serialHarness.processTransactionSet(ctx, 2, transactions)
+		require.NoError(t, err)
+
+		parallelHarness := newBenchmarkTokenHarness(4, 10000)
+		require.NoError(t, parallelHarness.deployBenchmarkToken(ctx))
+		parallel, err := parallelHarness.processTransactionSet(ctx, 2, transactions)
+		require.NoError(t, err)
+
+		require.Len(t, serial.TransactionReceipts, 9, "last transaction should be left out by the block budget")
+		requireSameTransactionSetOutput(t, serial, parallel)
+	})
+}
+
+func BenchmarkToken_SerialTransfers(b *testing.B) {
+	benchmarkTokenTransfers(b, 1, independentTransfers(b, 100))
+}
+
+func BenchmarkToken_ParallelTransfers(b *testing.B) {
+	benchmarkTokenTransfers(b, uint32(runtime.NumCPU()), independentTransfers(b, 100))
+}
+
+func BenchmarkToken_SerialConflictingTransfers(b *testing.B) {
+	benchmarkTokenTransfers(b, 1, conflictingTransfers(100))
+}
+
+func BenchmarkToken_ParallelConflictingTransfers(b *testing.B) {
+	benchmarkTokenTransfers(b, uint32(runtime.NumCPU()), conflictingTransfers(100))
+}
+
+func benchmarkTokenTransfers(b *testing.B, executionConcurrency uint32, transactions []*protocol.SignedTransaction) {
+	test.WithContext(func(ctx context.Context) {
+		h := newBenchmarkTokenHarness(executionConcurrency, 100*1000000)
+		require.NoError(b, h.deployBenchmarkToken(ctx))
+
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			_, err := h.processTransactionSet(ctx, 2, transactions)
+			require.NoError(b, err)
+		}
+	})
+}
+
+// every transfer has its own sender and recipient so nothing conflicts
+func independentTransfers(b *testing.B, count int) []*protocol.SignedTransaction {
+	transactions := make([]*protocol.SignedTransaction, 0, count)
+	for i := 0; i < count; i++ {
+		sender, err := keys.GenerateEd25519Key()
+		require.NoError(b, err)
+		recipient, err := keys.GenerateEd25519Key()
+		require.NoError(b, err)
+		transactions = append(transactions, transferTransaction(sender, 0, hash.CalcRipmd160Sha256(recipient.PublicKey())))
+	}
+	return transactions
+}
+
+// every transfer is sent by the token owner so each one conflicts with the one before it
+func conflictingTransfers(count int) []*protocol.SignedTransaction {
+	transactions := make([]*protocol.SignedTransaction, 0, count)
+	for i := 0; i < count; i++ {
+		transactions = append(transactions, transferTransaction(testKeys.Ed25519KeyPairForTests(0), 1, builders.AddressForEd25519SignerForTests(1+i%9)))
+	}
+	return transactions
+}
+
+func requireSameTransactionSetOutput(t *testing.T, expected *services.ProcessTransactionSetOutput, actual *services.ProcessTransactionSetOutput) {
+	require.Equal(t, len(expected.TransactionReceipts), len(actual.TransactionReceipts), "number of receipts should match")
+	for i := range expected.TransactionReceipts {
+		require.Equal(t, expected.TransactionReceipts[i].Raw(), actual.TransactionReceipts[i].Raw(), "receipt %d should match", i)
+	}
+	require.Equal(t, stateDiffsByContract(expected.ContractStateDiffs), stateDiffsByContract(actual.ContractStateDiffs), "state diffs should match")
+}
+
+func stateDiffsByContract(contractStateDiffs []*protocol.ContractStateDiff) map[primitives.ContractName]map[string][]byte {
+	res := make(map[primitives.ContractName]map[string][]byte)
+	for _, contractStateDiff := range contractStateDiffs {
+		res[contractStateDiff.ContractName()] = make(map[string][]byte)
+		for i := contractStateDiff.StateDiffsIterator(); i.HasNext(); {
+			sd := i.NextStateDiffs()
+			res[contractStateDiff.ContractName()][sd.Key().KeyForMap()] = sd.Value()
+		}
+	}
+	return res
+}
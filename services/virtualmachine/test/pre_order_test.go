@@ -2,10 +2,14 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_GlobalPreOrder"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_VirtualChainPreOrder"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
@@ -38,8 +42,9 @@ func TestPreOrder_DifferentSignerSchemes(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			test.WithContext(func(ctx context.Context) {
 				h := newHarness()
+				h.expectPreOrderHooksNotDeployed()
 
-				h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, nil)
+				h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, nil, uint32(10))
 
 				results, err := h.transactionSetPreOrder(ctx, []*protocol.SignedTransaction{tt.tx})
 				if tt.status == protocol.TRANSACTION_STATUS_PRE_ORDER_VALID {
@@ -58,6 +63,7 @@ func TestPreOrder_DifferentSignerSchemes(t *testing.T) {
 func TestPreOrder_GlobalSubscriptionContractNotApproved(t *testing.T) {
 	test.WithContext(func(ctx context.Context) {
 		h := newHarness()
+		h.expectPreOrderHooksNotDeployed()
 
 		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, errors.New("contract not approved"))
 
@@ -69,3 +75,98 @@ func TestPreOrder_GlobalSubscriptionContractNotApproved(t *testing.T) {
 		h.verifySystemContractCalled(t)
 	})
 }
+
+func TestPreOrder_GlobalSubscriptionAllowanceLimitsTransactions(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectPreOrderHooksNotDeployed()
+
+		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, nil, uint32(2))
+
+		txs := []*protocol.SignedTransaction{
+			builders.Transaction().Build(),
+			builders.Transaction().WithInvalidSignerScheme().Build(),
+			builders.Transaction().Build(),
+			builders.Transaction().Build(),
+		}
+		results, err := h.transactionSetPreOrder(ctx, txs)
+		require.Error(t, err, "transaction set pre order should fail")
+		require.Equal(t, []protocol.TransactionStatus{
+			protocol.TRANSACTION_STATUS_PRE_ORDER_VALID,
+			protocol.TRANSACTION_STATUS_REJECTED_UNKNOWN_SIGNER_SCHEME,
+			protocol.TRANSACTION_STATUS_PRE_ORDER_VALID,
+			protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER,
+		}, results, "transactionSetPreOrder returned statuses should match")
+
+		h.verifySystemContractCalled(t)
+	})
+}
+
+func TestPreOrder_GlobalSubscriptionNotApprovedStillAllowsAdminGlobalPreOrderTransactions(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectPreOrderHooksNotDeployed()
+
+		admin := keys.Ed25519KeyPairForTests(1)
+		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, errors.New("subscription expired"))
+		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_GET_ADMIN.Name, nil, []byte(hash.CalcRipmd160Sha256(admin.PublicKey())))
+
+		txs := []*protocol.SignedTransaction{
+			builders.Transaction().WithEd25519Signer(admin).Build(),
+			builders.Transaction().WithEd25519Signer(admin).WithContract(globalpreorder_systemcontract.CONTRACT.Name).Build(),
+			builders.Transaction().WithEd25519Signer(keys.Ed25519KeyPairForTests(2)).WithContract(globalpreorder_systemcontract.CONTRACT.Name).Build(),
+		}
+		results, err := h.transactionSetPreOrder(ctx, txs)
+		require.Error(t, err, "transaction set pre order should fail")
+		require.Equal(t, []protocol.TransactionStatus{
+			protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER,
+			protocol.TRANSACTION_STATUS_PRE_ORDER_VALID,
+			protocol.TRANSACTION_STATUS_REJECTED_GLOBAL_PRE_ORDER,
+		}, results, "transactionSetPreOrder returned statuses should match")
+
+		h.verifySystemContractCalled(t)
+	})
+}
+
+func TestPreOrder_VirtualChainHookRejectsTransactions(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		h.expectDeploymentInfoRequested(primitives.ContractName(virtualchainpreorder_systemcontract.CONTRACT.Name), nil, protocol.PROCESSOR_TYPE_NATIVE)
+		h.expectNativeContractMethodCalled(primitives.ContractName(virtualchainpreorder_systemcontract.CONTRACT.Name), primitives.MethodName(virtualchainpreorder_systemcontract.METHOD_APPROVE.Name), func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			return protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, builders.MethodArgumentsArray(), errors.New("signer is blocked")
+		})
+		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, nil, uint32(10))
+
+		tx := builders.Transaction().Build()
+		results, err := h.transactionSetPreOrder(ctx, []*protocol.SignedTransaction{tx})
+		require.Error(t, err, "transaction set pre order should fail")
+		require.Equal(t, []protocol.TransactionStatus{protocol.TRANSACTION_STATUS_REJECTED_VIRTUAL_CHAIN_PRE_ORDER}, results, "transactionSetPreOrder returned statuses should match")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestPreOrder_ContractHookRejectsTransactions(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		tx := builders.Transaction().Build()
+		contractName := tx.Transaction().ContractName()
+
+		h.expectDeploymentInfoRequested(primitives.ContractName(virtualchainpreorder_systemcontract.CONTRACT.Name), errors.New("contract not deployed"), 0)
+		h.expectDeploymentInfoRequested(contractName, nil, protocol.PROCESSOR_TYPE_NATIVE)
+		h.expectNativeContractMethodCalled(contractName, native.METHOD_NAME_PRE_ORDER, func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			argsIterator := inputArgs.ArgumentsIterator()
+			require.Equal(t, string(tx.Transaction().MethodName()), argsIterator.NextArguments().StringValue(), "pre order hook should receive the transaction method name")
+			return protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, builders.MethodArgumentsArray(), errors.New("malformed arguments")
+		})
+		h.expectSystemContractCalled(globalpreorder_systemcontract.CONTRACT.Name, globalpreorder_systemcontract.METHOD_APPROVE.Name, nil, uint32(10))
+
+		results, err := h.transactionSetPreOrder(ctx, []*protocol.SignedTransaction{tx})
+		require.Error(t, err, "transaction set pre order should fail")
+		require.Equal(t, []protocol.TransactionStatus{protocol.TRANSACTION_STATUS_REJECTED_SMART_CONTRACT_PRE_ORDER}, results, "transactionSetPreOrder returned statuses should match")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/config"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestPreloadsDeployedContractsOnStart(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newUnstartedHarness()
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_DEPLOYED_SERVICES_COUNT.Name, nil, uint32(1))
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_DEPLOYED_SERVICE_NAME.Name, nil, "Contract1")
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE))
+		h.expectNativeContractInfoRequested("Contract1", nil)
+
+		h.start(ctx, config.ForVirtualMachinePreloadTests(time.Second))
+
+		require.NoError(t, test.EventuallyVerify(test.EVENTUALLY_ACCEPTANCE_TIMEOUT, h.stateStorage, h.processors[protocol.PROCESSOR_TYPE_NATIVE]), "deployed contract should be preloaded")
+	})
+}
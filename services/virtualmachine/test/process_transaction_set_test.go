@@ -2,7 +2,9 @@ package test
 
 import (
 	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/adapter"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -75,3 +77,30 @@ func TestProcessTransactionSet_WithErrors(t *testing.T) {
 		h.verifyNativeContractMethodCalled(t)
 	})
 }
+
+func TestProcessTransactionSet_FailsWhenTheNodeCannotRunATransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: successful")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract1", "method2", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 2: the executor running the contract is lost")
+			return protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, builders.MethodArgumentsArray(), &adapter.ExecutorFailure{Cause: errors.New("signal: killed")}
+		})
+
+		err := h.processTransactionSetFailing(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract1", "method2"},
+		})
+		require.Error(t, err, "processTransactionSet should fail instead of giving the transaction a receipt")
+		require.IsType(t, &virtualmachine.TransactionSetNodeFault{}, err, "processTransactionSet should tell which transaction this node failed to execute")
+		require.Equal(t, 1, err.(*virtualmachine.TransactionSetNodeFault).Index, "the node fault should be of the transaction whose executor was lost")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
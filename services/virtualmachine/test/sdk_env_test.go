@@ -0,0 +1,77 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/crypto/hash"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSdkEnv_TransactionSetSeesTheNewBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getBlockHeight")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 12, res[0].Uint64Value(), "block height should be the height of the block being processed")
+
+			res, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getBlockTimestamp")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 5678, res[0].Uint64Value(), "block timestamp should be the timestamp of the block being processed")
+
+			res, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getVirtualChainId")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 42, res[0].Uint32Value(), "virtual chain id should be taken from config")
+
+			res, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getTxHash")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.Len(t, res[0].BytesValue(), hash.SHA256_HASH_SIZE_BYTES, "tx hash should be a valid hash")
+
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkEnv_LocalMethodSeesTheLastCommittedBlock(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getBlockHeight")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 12, res[0].Uint64Value(), "block height should be the last committed height")
+
+			res, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getBlockTimestamp")
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 1234, res[0].Uint64Value(), "block timestamp should be the last committed timestamp")
+
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ENV, "getBlockHeight", uint32(1))
+			require.Error(t, err, "handleSdkCall with args should fail")
+
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.runLocalMethod(ctx, "Contract1", "method1")
+
+		h.verifySystemContractCalled(t)
+		h.verifyStateStorageBlockHeightRequested(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
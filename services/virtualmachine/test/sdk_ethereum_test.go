@@ -0,0 +1,120 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/crosschainconnector/ethereum"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const EXAMPLE_ETHEREUM_ADDRESS = "0x00112233445566778899aabbccddeeff00112233"
+const EXAMPLE_ETHEREUM_TXHASH = "0x1122334455667788990011223344556677889900112233445566778899001122"
+const EXAMPLE_JSON_ABI = `[{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint64"}]}]`
+
+func TestSdkEthereum_CallMethod(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectEthereumConnectorCalled(EXAMPLE_ETHEREUM_ADDRESS, 400, builders.MethodArgumentsArray(uint64(1000)), nil)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Ethereum callMethod")
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "callMethod", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "balanceOf", uint64(400), builders.MethodArgumentsArray("0xffeeddccbbaa99887766554433221100ffeeddcc").Raw())
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.Equal(t, builders.MethodArgumentsArray(uint64(1000)).Raw(), res[0].BytesValue(), "handleSdkCall result should equal the connector output")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+
+		h.verifySystemContractCalled(t)
+		h.verifyEthereumConnectorCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkEthereum_CallMethodFailsWhenConnectorFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectEthereumConnectorCalled(EXAMPLE_ETHEREUM_ADDRESS, 400, nil, errors.New("ethereum json rpc request failed"))
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "callMethod", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "balanceOf", uint64(400), builders.MethodArgumentsArray().Raw())
+			require.Error(t, err, "handleSdkCall should fail")
+
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "callMethod", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "balanceOf")
+			require.Error(t, err, "handleSdkCall with missing args should fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+
+		h.verifySystemContractCalled(t)
+		h.verifyEthereumConnectorCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkEthereum_CallMethodFailsTheTransactionSetWhenEndpointFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectEthereumConnectorCalled(EXAMPLE_ETHEREUM_ADDRESS, 400, nil, &ethereum.EndpointFailure{Cause: errors.New("connection refused")})
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Ethereum callMethod fails and the contract swallows the error")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "callMethod", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "balanceOf", uint64(400), builders.MethodArgumentsArray().Raw())
+			require.Error(t, err, "handleSdkCall should fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		err := h.processTransactionSetFailing(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Error(t, err, "processTransactionSet should fail instead of giving the transaction a receipt")
+
+		h.verifySystemContractCalled(t)
+		h.verifyEthereumConnectorCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkEthereum_GetTransactionLog(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectEthereumConnectorLogRequested(EXAMPLE_ETHEREUM_TXHASH, 400, 390, builders.MethodArgumentsArray(uint64(500)), nil)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Ethereum getTransactionLog")
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "getTransactionLog", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "Locked", EXAMPLE_ETHEREUM_TXHASH, uint32(7), uint64(400))
+			require.NoError(t, err, "handleSdkCall should succeed")
+			require.EqualValues(t, 390, res[0].Uint64Value(), "handleSdkCall should return the block of the log")
+			require.Equal(t, builders.MethodArgumentsArray(uint64(500)).Raw(), res[1].BytesValue(), "handleSdkCall should return the event params")
+
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_ETHEREUM, "getTransactionLog", EXAMPLE_ETHEREUM_ADDRESS, EXAMPLE_JSON_ABI, "Locked", EXAMPLE_ETHEREUM_TXHASH, uint64(7), uint64(400))
+			require.Error(t, err, "handleSdkCall with a uint64 log index should fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+
+		h.verifySystemContractCalled(t)
+		h.verifyEthereumConnectorCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
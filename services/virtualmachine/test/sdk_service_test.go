@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/orbs-network/orbs-network-go/services/processor/native"
 	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
 	"github.com/orbs-network/orbs-network-go/test"
 	"github.com/orbs-network/orbs-network-go/test/builders"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
@@ -60,6 +61,7 @@ func TestSdkService_CallMethodMaintainsAddressSpaceUnderSameContract(t *testing.
 			require.Equal(t, []byte{0x02, 0x03}, res[0].BytesValue(), "handleSdkCall result should be equal")
 			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
 		})
+		h.expectNativeContractInfoRequested("Contract1", nil) // reentrant by default
 		h.expectStateStorageNotRead()
 
 		h.processTransactionSet(ctx, []*contractAndMethod{
@@ -165,3 +167,90 @@ func TestSdkService_CallMethodWithMultipleArguments(t *testing.T) {
 		h.verifyNativeContractMethodCalled(t)
 	})
 }
+
+func TestSdkService_CallMethodBeyondMaximumCallDepthFailsTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithMaximumCallDepth(2)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray().Raw())
+			require.NoError(t, err, "handleSdkCall should not fail within the maximum call depth")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract2", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("CallMethod beyond the maximum call depth, the error is swallowed by the contract")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract3", "method1", builders.MethodArgumentsArray().Raw())
+			require.Error(t, err, "handleSdkCall should fail beyond the maximum call depth")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodNotCalled("Contract3", "method1")
+
+		results, outputArgs, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT}, results, "transaction should fail even though the contract swallowed the error")
+		require.Contains(t, string(outputArgs[0]), virtualmachine.ErrCallDepthExceeded.Error(), "receipt should carry the reason the transaction was aborted")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkService_CallMethodReenteringNonReentrantContractFailsTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray().Raw())
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract2", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("CallMethod back into the calling contract, the error is swallowed by the contract")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract1", "method2", builders.MethodArgumentsArray().Raw())
+			require.Error(t, err, "handleSdkCall should fail when reentering a non-reentrant contract")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_IS_NON_REENTRANT.Name, nil, uint32(1))
+		h.expectNativeContractMethodNotCalled("Contract1", "method2")
+
+		results, outputArgs, _ := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT}, results, "transaction should fail even though the contract swallowed the error")
+		require.Contains(t, string(outputArgs[0]), virtualmachine.ErrReentrantCall.Error(), "receipt should carry the reason the transaction was aborted")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSdkService_CallMethodUpgradingToRejectedCodeFailsTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			t.Log("CallMethod upgrading a contract to code its processor rejects, the error is swallowed by the contract")
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name, builders.MethodArgumentsArray("Contract2", uint32(protocol.PROCESSOR_TYPE_NATIVE), []byte("rejected code")).Raw())
+			require.Error(t, err, "handleSdkCall should fail when the upgraded code is rejected")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name, nil)
+		h.expectNativeContractInfoRequested("Contract2", errors.New("rejected code"))
+
+		results, _, sd := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT}, results, "transaction should fail even though the contract swallowed the error")
+		require.Empty(t, sd, "the upgrade and every other state change of the transaction should be rolled back")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+		h.verifyNativeContractInfoRequested(t)
+	})
+}
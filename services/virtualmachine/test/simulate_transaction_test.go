@@ -0,0 +1,139 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSimulateTransaction_ReturnsStateDiffOnTopOfHistoricalStateAndOverrides(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Read an overridden key")
+			res, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "read", []byte{0x01})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			require.Equal(t, []byte{0x11}, res[0].BytesValue(), "overridden key should not be read from state storage")
+
+			t.Log("Read a key from the requested block height")
+			res, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "read", []byte{0x02})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			require.Equal(t, []byte{0x22}, res[0].BytesValue(), "key should be read from state storage")
+
+			t.Log("Write a key")
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x03}, []byte{0x33})
+			require.NoError(t, err, "handleSdkCall should not fail")
+
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(uint32(17)), nil
+		})
+		h.expectStateStorageRead(10, "Contract1", []byte{0x02}, []byte{0x22})
+
+		stateOverrides := []*protocol.ContractStateDiff{(&protocol.ContractStateDiffBuilder{
+			ContractName: "Contract1",
+			StateDiffs:   []*protocol.StateRecordBuilder{{Key: []byte{0x01}, Value: []byte{0x11}}},
+		}).Build()}
+
+		output, err := h.simulateTransaction(ctx, 10, "Contract1", "method1", stateOverrides)
+		require.NoError(t, err, "simulate transaction should not fail")
+		require.EqualValues(t, 10, output.ReferenceBlockHeight, "simulation should run on the requested block height")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, output.TransactionReceipt.ExecutionResult(), "simulate transaction should return successful result")
+		require.Equal(t, builders.MethodArgumentsArray(uint32(17)).RawArgumentsArray(), output.TransactionReceipt.OutputArgumentArray(), "simulate transaction should return output args")
+
+		require.Len(t, output.ContractStateDiffs, 1, "only the written contract should have a state diff")
+		require.Equal(t, primitives.ContractName("Contract1"), output.ContractStateDiffs[0].ContractName())
+		i := output.ContractStateDiffs[0].StateDiffsIterator()
+		record := i.NextStateDiffs()
+		require.Equal(t, []byte{0x03}, []byte(record.Key()), "state diff should hold the written key")
+		require.Equal(t, []byte{0x33}, record.Value(), "state diff should hold the written value")
+		require.False(t, i.HasNext(), "overrides should not be part of the state diff")
+
+		h.verifySystemContractCalled(t)
+		h.verifyStateStorageRead(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSimulateTransaction_FailsOnBlockHeightNotYetCommitted(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectNativeContractMethodNotCalled("Contract1", "method1")
+		h.expectStateStorageBlockHeightRequested(12)
+
+		_, err := h.simulateTransaction(ctx, 13, "Contract1", "method1", nil)
+		require.Error(t, err, "simulate transaction should fail on a block that is not yet committed")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSimulateTransaction_FailsOnBlockHeightOutsideSnapshotWindow(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectNativeContractMethodNotCalled("Contract1", "method1")
+		h.expectStateStorageBlockHeightRequested(12)
+
+		_, err := h.simulateTransaction(ctx, 7, "Contract1", "method1", nil)
+		require.Error(t, err, "simulate transaction should fail on a block whose state is no longer kept")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSimulateTransaction_FailsOnDeployingContract(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+
+		for _, methodName := range []string{deployments_systemcontract.METHOD_DEPLOY_SERVICE.Name, deployments_systemcontract.METHOD_UPGRADE_SERVICE.Name} {
+			h.expectStateStorageBlockHeightRequested(12)
+			_, err := h.simulateTransaction(ctx, 0, primitives.ContractName(deployments_systemcontract.CONTRACT.Name), primitives.MethodName(methodName), nil)
+			require.Error(t, err, "simulate transaction should fail on _Deployments.%s", methodName)
+		}
+	})
+}
+
+func TestSimulateTransaction_FailsContractCallThatDeploysContract(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Deploy a contract through _Deployments")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_DEPLOY_SERVICE.Name, builders.MethodArgumentsArray("Contract2", uint32(protocol.PROCESSOR_TYPE_NATIVE), []byte("code")).Raw())
+			require.Error(t, err, "handleSdkCall should fail in a simulation")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		_, err := h.simulateTransaction(ctx, 0, "Contract1", "method1", nil)
+		require.NoError(t, err, "simulate transaction should not fail")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestSimulateTransaction_FailsOnOverridingDeployedCode(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectNativeContractMethodNotCalled("Contract1", "method1")
+		h.expectStateStorageBlockHeightRequested(12)
+
+		stateOverrides := []*protocol.ContractStateDiff{(&protocol.ContractStateDiffBuilder{
+			ContractName: primitives.ContractName(deployments_systemcontract.CONTRACT.Name),
+			StateDiffs:   []*protocol.StateRecordBuilder{{Key: []byte("Contract1.Code"), Value: []byte("code")}},
+		}).Build()}
+
+		_, err := h.simulateTransaction(ctx, 0, "Contract1", "method1", stateOverrides)
+		require.Error(t, err, "simulate transaction should fail on state overrides of _Deployments")
+
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
@@ -0,0 +1,151 @@
+package test
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestExecutionTimeout_LocalCallExceedingTimeLimitFails(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionTimeout(1*time.Second, 10*time.Millisecond, 1*time.Second)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+		h.expectStateStorageBlockHeightRequested(12)
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Local call: runs past the time limit of the call")
+			time.Sleep(50 * time.Millisecond)
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		result, outputArgs, _, err := h.runLocalMethod(ctx, "Contract1", "method1")
+		require.Error(t, err, "run local method should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, result, "run local method should fail like a contract error")
+		require.Contains(t, string(outputArgs), virtualmachine.ErrExecutionTimeout.Error(), "run local method should time out")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestExecutionTimeout_LocalNestedCallsExceedingTransactionTimeLimitFail(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionTimeout(10*time.Millisecond, 1*time.Second, 1*time.Second)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+		h.expectStateStorageBlockHeightRequested(12)
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Local call: the nested call runs past the time limit of the transaction, the contract ignores it")
+			h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray().Raw())
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract2", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			time.Sleep(50 * time.Millisecond)
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		result, outputArgs, _, err := h.runLocalMethod(ctx, "Contract1", "method1")
+		require.Error(t, err, "run local method should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, result, "run local method should fail like a contract error")
+		require.Contains(t, string(outputArgs), virtualmachine.ErrExecutionTimeout.Error(), "call should time out even though the contract ignored the timeout")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestExecutionTimeout_BlockExecutionIgnoresTimeLimit(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionTimeout(10*time.Millisecond, 10*time.Millisecond, 1*time.Second)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: writes and then runs past the time limit, which only bounds calls outside of blocks")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			time.Sleep(50 * time.Millisecond)
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		results, _, sd := h.processTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+		})
+		require.Equal(t, []protocol.ExecutionResult{protocol.EXECUTION_RESULT_SUCCESS}, results, "processTransactionSet returned receipts should match")
+		require.ElementsMatch(t, sd["Contract1"], []*keyValuePair{
+			{[]byte{0x01}, []byte{0x02}},
+		}, "processTransactionSet returned contract state diffs should match")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+func TestExecutionTimeout_BlockCallLoopingForeverIsANodeFault(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionTimeout(10*time.Millisecond, 10*time.Millisecond, 50*time.Millisecond)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: successful")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodLoopingForever("Contract1", "method2")
+
+		err := h.processTransactionSetFailing(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract1", "method2"},
+		})
+		require.Error(t, err, "processTransactionSet should return instead of waiting on the loop")
+		require.IsType(t, &virtualmachine.TransactionSetNodeFault{}, err, "a block call this node abandoned should have no receipt")
+		require.Equal(t, 1, err.(*virtualmachine.TransactionSetNodeFault).Index, "the node fault should be of the looping transaction")
+		require.Equal(t, virtualmachine.ErrBlockCallTimeout, err.(*virtualmachine.TransactionSetNodeFault).Cause, "the node fault should tell the call did not return in time")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
+
+// meant to run with -race, the abandoned contract is still in the nested call when its processor returns and writes afterwards
+func TestExecutionTimeout_AbandonedCallCanNotTouchExecutionContext(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarnessWithExecutionTimeout(1*time.Second, 10*time.Millisecond, 1*time.Second)
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+		h.expectStateStorageBlockHeightRequested(12)
+
+		lateWrite := make(chan error, 1)
+		h.expectNativeContractMethodAbandonedAtDeadline("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Local call: abandoned in the middle of a nested call, keeps using the execution context after it")
+			h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray().Raw())
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			lateWrite <- err
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract2", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			time.Sleep(50 * time.Millisecond)
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+
+		result, outputArgs, _, err := h.runLocalMethod(ctx, "Contract1", "method1")
+		require.Error(t, err, "run local method should fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_ERROR_SMART_CONTRACT, result, "run local method should fail like a contract error")
+		require.Contains(t, string(outputArgs), virtualmachine.ErrExecutionTimeout.Error(), "run local method should time out")
+
+		select {
+		case err := <-lateWrite:
+			require.Error(t, err, "sdk call of the abandoned call should fail")
+		case <-time.After(1 * time.Second):
+			t.Fatal("abandoned call did not return")
+		}
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+	})
+}
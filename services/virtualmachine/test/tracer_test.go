@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/orbs-network/orbs-network-go/services/processor/native/repository/_Deployments"
+	"github.com/orbs-network/orbs-network-go/services/virtualmachine"
+	"github.com/orbs-network/orbs-network-go/test"
+	"github.com/orbs-network/orbs-network-go/test/builders"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+type tracedStateAccess struct {
+	Operation    string
+	ContractName string
+	Key          string
+	Value        string
+	Source       string
+}
+
+type tracedCall struct {
+	ContractName    string
+	MethodName      string
+	CallResult      string
+	OutputArguments []map[string]interface{}
+	StateAccesses   []*tracedStateAccess
+	Calls           []*tracedCall
+}
+
+func TestTraceLocalMethod_RecordsCallTreeAndStateReads(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectStateStorageBlockHeightRequested(12)
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Read the same key twice")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "read", []byte{0x01})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "read", []byte{0x01})
+			require.NoError(t, err, "handleSdkCall should not fail")
+
+			t.Log("CallMethod on a different contract")
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_SERVICE, "callMethod", "Contract2", "method1", builders.MethodArgumentsArray(uint64(5)).Raw())
+			require.NoError(t, err, "handleSdkCall should not fail")
+
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(uint32(17)), nil
+		})
+		h.expectNativeContractMethodCalled("Contract2", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray("ok"), nil
+		})
+		h.expectStateStorageRead(12, "Contract1", []byte{0x01}, []byte{0x77})
+
+		result, executionTrace, err := h.traceLocalMethod(ctx, "Contract1", "method1")
+		require.NoError(t, err, "trace local method should not fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, result, "trace local method should return successful result")
+
+		root := &tracedCall{}
+		require.NoError(t, json.Unmarshal(executionTrace, root), "trace should be valid json")
+		require.Equal(t, "Contract1", root.ContractName)
+		require.Equal(t, "method1", root.MethodName)
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS.String(), root.CallResult)
+		require.EqualValues(t, 17, root.OutputArguments[0]["value"])
+		require.Equal(t, []*tracedStateAccess{
+			{"read", "Contract1", "01", "77", virtualmachine.STATE_READ_SOURCE_STORAGE},
+			{"read", "Contract1", "01", "77", virtualmachine.STATE_READ_SOURCE_TRANSIENT},
+		}, root.StateAccesses, "state reads should be traced with their source")
+
+		require.Len(t, root.Calls, 3, "deployment lookups and the nested call should be traced")
+		require.Equal(t, deployments_systemcontract.CONTRACT.Name, root.Calls[0].ContractName)
+		require.Equal(t, deployments_systemcontract.CONTRACT.Name, root.Calls[1].ContractName)
+		require.Equal(t, "Contract2", root.Calls[2].ContractName)
+		require.Equal(t, "ok", root.Calls[2].OutputArguments[0]["value"])
+
+		h.verifySystemContractCalled(t)
+		h.verifyStateStorageBlockHeightRequested(t)
+		h.verifyNativeContractMethodCalled(t)
+		h.verifyStateStorageRead(t)
+	})
+}
+
+func TestTraceTransactionSet_ReExecutesUpToTracedTransaction(t *testing.T) {
+	test.WithContext(func(ctx context.Context) {
+		h := newHarness()
+		h.expectSystemContractCalled(deployments_systemcontract.CONTRACT.Name, deployments_systemcontract.METHOD_GET_INFO.Name, nil, uint32(protocol.PROCESSOR_TYPE_NATIVE)) // assume all contracts are deployed
+
+		h.expectNativeContractMethodCalled("Contract1", "method1", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 1: write a key")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x02})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodCalled("Contract1", "method2", func(executionContextId primitives.ExecutionContextId, inputArgs *protocol.MethodArgumentArray) (protocol.ExecutionResult, *protocol.MethodArgumentArray, error) {
+			t.Log("Transaction 2 (traced): read the key written by transaction 1 and overwrite it")
+			_, err := h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "read", []byte{0x01})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			_, err = h.handleSdkCall(ctx, executionContextId, native.SDK_OPERATION_NAME_STATE, "write", []byte{0x01}, []byte{0x03})
+			require.NoError(t, err, "handleSdkCall should not fail")
+			return protocol.EXECUTION_RESULT_SUCCESS, builders.MethodArgumentsArray(), nil
+		})
+		h.expectNativeContractMethodNotCalled("Contract1", "method3")
+		h.expectStateStorageNotRead()
+
+		result, executionTrace, err := h.traceTransactionSet(ctx, []*contractAndMethod{
+			{"Contract1", "method1"},
+			{"Contract1", "method2"},
+			{"Contract1", "method3"},
+		}, 1)
+		require.NoError(t, err, "trace transaction set should not fail")
+		require.Equal(t, protocol.EXECUTION_RESULT_SUCCESS, result, "traced transaction should succeed")
+
+		root := &tracedCall{}
+		require.NoError(t, json.Unmarshal(executionTrace, root), "trace should be valid json")
+		require.Equal(t, "method2", root.MethodName, "only the requested transaction should be traced")
+		require.Equal(t, []*tracedStateAccess{
+			{"read", "Contract1", "01", "02", virtualmachine.STATE_READ_SOURCE_BATCH},
+			{"write", "Contract1", "01", "03", ""},
+		}, root.StateAccesses, "state accesses should be traced")
+
+		h.verifySystemContractCalled(t)
+		h.verifyNativeContractMethodCalled(t)
+		h.verifyStateStorageRead(t)
+	})
+}
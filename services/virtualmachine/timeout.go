@@ -0,0 +1,124 @@
+package virtualmachine
+
+import (
+	"context"
+	"github.com/orbs-network/orbs-network-go/services/processor/native"
+	"github.com/pkg/errors"
+	"sync"
+	"time"
+)
+
+var ErrExecutionTimeout = errors.New("execution time limit exceeded")
+var ErrBlockCallTimeout = errors.New("contract call of block execution did not return in time, this node can't execute it")
+var ErrExecutionContextAbandoned = errors.New("execution context no longer accepts sdk calls since one of its calls was abandoned when it timed out")
+
+// processors see the time limit of a call as the deadline of its context, a call is bounded by its own limit and
+// by what is left of the transaction so a single call can't use up the whole transaction,
+// calls without a transaction deadline (of block execution) are bounded only by the block call limit of this node
+// (see meteredOnlyContext) which is well above the cpu limit of the executor running deployed contracts
+func (s *service) callContext(ctx context.Context, executionContext *executionContext) (context.Context, context.CancelFunc) {
+	if executionContext.deadline.IsZero() {
+		return context.WithTimeout(meteredOnlyContext{ctx}, s.config.VirtualMachineBlockCallExecutionTimeout())
+	}
+	deadline := time.Now().Add(s.config.VirtualMachineCallExecutionTimeout())
+	if executionContext.deadline.Before(deadline) {
+		deadline = executionContext.deadline
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// like an exceeded budget, a timeout aborts the transaction even if the calling contract swallows the sdk error,
+// a quarantined contract fails like it ran into the time limit again,
+// a block call running past the limit of this node (like a plugin contract stuck in a loop) is not something every node
+// agrees on, so it is a fault of the node and gets no receipt, the block producer leaves its transaction out instead
+func (c *executionContext) recordCallTimeout(callCtx context.Context, err error) {
+	if callCtx.Err() != context.DeadlineExceeded && err != native.ErrContractQuarantined {
+		return
+	}
+	if !c.deadline.IsZero() {
+		c.timedOut = true
+	} else if c.nodeFault == nil {
+		c.nodeFault = ErrBlockCallTimeout
+	}
+}
+
+// go can't stop a call its processor abandoned at the deadline (see the quarantine of the native processor), the call keeps
+// running and may still make sdk calls with its execution context while the virtual machine goes on using the context,
+// so once a call is abandoned the context takes no more sdk calls, and the ones the call is in the middle of are waited for
+type sdkCallTracker struct {
+	mutex     *sync.Mutex
+	exited    *sync.Cond
+	active    int
+	abandoned bool
+}
+
+func newSdkCallTracker() *sdkCallTracker {
+	mutex := &sync.Mutex{}
+	return &sdkCallTracker{
+		mutex:  mutex,
+		exited: sync.NewCond(mutex),
+	}
+}
+
+func (t *sdkCallTracker) enter() bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.abandoned {
+		return false
+	}
+	t.active++
+	return true
+}
+
+func (t *sdkCallTracker) exit() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.active--
+	t.exited.Broadcast()
+}
+
+func (t *sdkCallTracker) activeCalls() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.active
+}
+
+// the caller is blocked while its processor runs the call, so the sdk calls active beyond those of the caller are made by the call
+func (t *sdkCallTracker) abandon(activeCallsOfCaller int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.abandoned = true
+	for t.active > activeCallsOfCaller {
+		t.exited.Wait()
+	}
+}
+
+// must run as soon as the processor returns, before anything else touches the context,
+// a call whose context is done may have been abandoned by its processor (and the transaction is aborted anyway)
+func (c *executionContext) abandonCallIfDone(callCtx context.Context, activeSdkCallsOfCaller int) {
+	if callCtx.Err() != nil {
+		c.sdkCalls.abandon(activeSdkCallsOfCaller)
+	}
+}
+
+// every node must reach the same receipts for a block, so its calls are bounded by the meter (and the executor) alone
+// and ignore the deadline and cancellation of the caller (like the round timeout of consensus), the values of the context are kept
+type meteredOnlyContext struct {
+	context.Context
+}
+
+func (meteredOnlyContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (meteredOnlyContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (meteredOnlyContext) Err() error {
+	return nil
+}
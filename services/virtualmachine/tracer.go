@@ -0,0 +1,198 @@
+package virtualmachine
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
+	"github.com/orbs-network/orbs-spec/types/go/protocol"
+	"github.com/orbs-network/orbs-spec/types/go/services"
+	"time"
+)
+
+// tracing is not part of the pinned spec, the public api reaches it on the virtual machine through this interface
+type Tracer interface {
+	TraceLocalMethod(ctx context.Context, input *TraceLocalMethodInput) (*TraceLocalMethodOutput, error)
+	TraceTransactionSet(ctx context.Context, input *TraceTransactionSetInput) (*TraceTransactionSetOutput, error)
+}
+
+type TraceLocalMethodInput struct {
+	Transaction *protocol.Transaction
+}
+
+type TraceLocalMethodOutput struct {
+	CallResult              protocol.ExecutionResult
+	OutputArgumentArray     []byte
+	ReferenceBlockHeight    primitives.BlockHeight
+	ReferenceBlockTimestamp primitives.TimestampNano
+	Trace                   []byte
+}
+
+type TraceTransactionSetInput struct {
+	BlockHeight        primitives.BlockHeight
+	BlockTimestamp     primitives.TimestampNano
+	SignedTransactions []*protocol.SignedTransaction
+	Txhash             primitives.Sha256
+}
+
+type TraceTransactionSetOutput struct {
+	TransactionReceipt *protocol.TransactionReceipt
+	Trace              []byte
+}
+
+// where a state read was served from
+const (
+	STATE_READ_SOURCE_TRANSIENT = "transient"
+	STATE_READ_SOURCE_BATCH     = "batch"
+	STATE_READ_SOURCE_STORAGE   = "storage"
+)
+
+// records the call tree and state accesses of a single transaction, only allocated when tracing was requested
+// all methods are safe to call on a nil tracer so the execution code does not need to check whether tracing is on
+type executionTracer struct {
+	root     *callTrace
+	stack    []*callTrace
+	sequence int
+}
+
+type callTrace struct {
+	Sequence        int                 `json:"sequence"`
+	ContractName    string              `json:"contractName"`
+	MethodName      string              `json:"methodName"`
+	InputArguments  []*argumentTrace    `json:"inputArguments"`
+	OutputArguments []*argumentTrace    `json:"outputArguments"`
+	CallResult      string              `json:"callResult"`
+	Error           string              `json:"error,omitempty"`
+	StartTime       time.Time           `json:"startTime"`
+	DurationNano    int64               `json:"durationNano"`
+	StateAccesses   []*stateAccessTrace `json:"stateAccesses"`
+	Calls           []*callTrace        `json:"calls"`
+}
+
+type stateAccessTrace struct {
+	Sequence     int    `json:"sequence"`
+	Operation    string `json:"operation"`
+	ContractName string `json:"contractName"`
+	Key          string `json:"key"`
+	Value        string `json:"value"`
+	Source       string `json:"source,omitempty"`
+}
+
+type argumentTrace struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+func newExecutionTracer() *executionTracer {
+	return &executionTracer{}
+}
+
+func (t *executionTracer) enterCall(contractName primitives.ContractName, methodName primitives.MethodName, inputArgs *protocol.MethodArgumentArray) {
+	if t == nil {
+		return
+	}
+	call := &callTrace{
+		Sequence:        t.nextSequence(),
+		ContractName:    string(contractName),
+		MethodName:      string(methodName),
+		InputArguments:  argumentsForTrace(inputArgs),
+		OutputArguments: []*argumentTrace{},
+		StartTime:       time.Now(),
+		StateAccesses:   []*stateAccessTrace{},
+		Calls:           []*callTrace{},
+	}
+	if current := t.current(); current != nil {
+		current.Calls = append(current.Calls, call)
+	} else {
+		t.root = call
+	}
+	t.stack = append(t.stack, call)
+}
+
+func (t *executionTracer) exitCall(callResult protocol.ExecutionResult, outputArgs *protocol.MethodArgumentArray, err error) {
+	if t == nil || len(t.stack) == 0 {
+		return
+	}
+	call := t.current()
+	call.OutputArguments = argumentsForTrace(outputArgs)
+	call.CallResult = callResult.String()
+	if err != nil {
+		call.Error = err.Error()
+	}
+	call.DurationNano = time.Since(call.StartTime).Nanoseconds()
+	t.stack = t.stack[0 : len(t.stack)-1]
+}
+
+func (t *executionTracer) exitProcessCall(output *services.ProcessCallOutput, err error) {
+	if output == nil {
+		t.exitCall(protocol.EXECUTION_RESULT_ERROR_UNEXPECTED, nil, err)
+		return
+	}
+	t.exitCall(output.CallResult, output.OutputArgumentArray, err)
+}
+
+func (t *executionTracer) stateRead(contractName primitives.ContractName, key []byte, value []byte, source string) {
+	t.addStateAccess("read", contractName, key, value, source)
+}
+
+func (t *executionTracer) stateWrite(contractName primitives.ContractName, key []byte, value []byte) {
+	t.addStateAccess("write", contractName, key, value, "")
+}
+
+func (t *executionTracer) addStateAccess(operation string, contractName primitives.ContractName, key []byte, value []byte, source string) {
+	if t == nil {
+		return
+	}
+	current := t.current()
+	if current == nil {
+		return
+	}
+	current.StateAccesses = append(current.StateAccesses, &stateAccessTrace{
+		Sequence:     t.nextSequence(),
+		Operation:    operation,
+		ContractName: string(contractName),
+		Key:          hex.EncodeToString(key),
+		Value:        hex.EncodeToString(value),
+		Source:       source,
+	})
+}
+
+func (t *executionTracer) toJson() ([]byte, error) {
+	if t == nil || t.root == nil {
+		return []byte{}, nil
+	}
+	return json.Marshal(t.root)
+}
+
+func (t *executionTracer) current() *callTrace {
+	if len(t.stack) == 0 {
+		return nil
+	}
+	return t.stack[len(t.stack)-1]
+}
+
+func (t *executionTracer) nextSequence() int {
+	t.sequence++
+	return t.sequence
+}
+
+func argumentsForTrace(args *protocol.MethodArgumentArray) []*argumentTrace {
+	res := []*argumentTrace{}
+	if args == nil {
+		return res
+	}
+	for i := args.ArgumentsIterator(); i.HasNext(); {
+		arg := i.NextArguments()
+		switch {
+		case arg.IsTypeUint32Value():
+			res = append(res, &argumentTrace{"uint32", arg.Uint32Value()})
+		case arg.IsTypeUint64Value():
+			res = append(res, &argumentTrace{"uint64", arg.Uint64Value()})
+		case arg.IsTypeStringValue():
+			res = append(res, &argumentTrace{"string", arg.StringValue()})
+		case arg.IsTypeBytesValue():
+			res = append(res, &argumentTrace{"bytes", hex.EncodeToString(arg.BytesValue())})
+		}
+	}
+	return res
+}
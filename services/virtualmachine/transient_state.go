@@ -73,6 +73,22 @@ func (t *transientState) mergeIntoTransientState(masterTransientState *transient
 	}
 }
 
+// true if any key held here (read or written) also appears in the other state
+func (t *transientState) intersects(other *transientState) bool {
+	for contractName, c := range t.contracts {
+		otherContract, found := other.contracts[contractName]
+		if !found {
+			continue
+		}
+		for k := range c.pairs {
+			if _, found := otherContract.pairs[k]; found {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func keyForMap(key []byte) string {
 	return string(key) // TODO: improve to create a version without copy (unsafe cast)
 }
@@ -100,3 +100,17 @@ func TestMergeTransientState(t *testing.T) {
 		{[]byte{0x01}, []byte{0xaa}, true},
 	})
 }
+
+func TestTransientStateIntersects(t *testing.T) {
+	s1 := newTransientState()
+	s1.setValue("Contract1", []byte{0x01}, []byte{0x22}, false)
+	s1.setValue("Contract2", []byte{0x02}, []byte{0x33}, false)
+
+	s2 := newTransientState()
+	s2.setValue("Contract1", []byte{0x02}, []byte{0x44}, true)
+	s2.setValue("Contract3", []byte{0x01}, []byte{0x55}, true)
+	require.False(t, s1.intersects(s2), "states with no common keys should not intersect")
+
+	s2.setValue("Contract2", []byte{0x02}, []byte{0x66}, true)
+	require.True(t, s1.intersects(s2), "states with a common key should intersect")
+}
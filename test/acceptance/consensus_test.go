@@ -4,8 +4,10 @@ import (
 	"context"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	"github.com/orbs-network/orbs-network-go/services/blockstorage/sync"
+	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
 	"github.com/orbs-network/orbs-network-go/test/harness"
 	"github.com/orbs-network/orbs-network-go/test/harness/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 	"github.com/stretchr/testify/require"
@@ -14,8 +16,7 @@ import (
 )
 
 func TestLeanHelixLeaderGetsValidationsBeforeCommit(t *testing.T) {
-	t.Skip("putting lean helix on hold until external library is integrated")
-	harness.Network(t).WithConsensusAlgos(consensus.CONSENSUS_ALGO_TYPE_LEAN_HELIX).Start(func(ctx context.Context, network harness.TestNetworkDriver) {
+	harness.Network(t).WithNumNodes(4).WithConsensusAlgos(consensus.CONSENSUS_ALGO_TYPE_LEAN_HELIX).Start(func(ctx context.Context, network harness.TestNetworkDriver) {
 
 		contract := network.GetBenchmarkTokenContract()
 		contract.DeployBenchmarkToken(ctx, 5)
@@ -44,6 +45,32 @@ func TestLeanHelixLeaderGetsValidationsBeforeCommit(t *testing.T) {
 	})
 }
 
+func TestLeanHelixCommitsBlocksWhenLeaderFails(t *testing.T) {
+	harness.Network(t).
+		WithNumNodes(4).
+		WithConsensusAlgos(consensus.CONSENSUS_ALGO_TYPE_LEAN_HELIX).
+		Start(func(ctx context.Context, network harness.TestNetworkDriver) {
+
+			contract := network.GetBenchmarkTokenContract()
+			contract.DeployBenchmarkToken(ctx, 5)
+
+			// node 0 goes silent, the committee rotation makes it the leader of some of the views which must then be replaced
+			silentNode := testKeys.Ed25519KeyPairForTests(0).PublicKey()
+			network.TransportTamperer().Fail(adapter.HasHeader(adapter.ALeanHelixMessage).And(adapter.MessageFrom(silentNode)))
+
+			var txHash primitives.Sha256
+			for i := 0; i < 4; i++ { // enough blocks for every committee member to get its turn as leader
+				txHash = contract.SendTransferInBackground(ctx, 1, 10, 5, 6)
+				network.WaitForTransactionInNodeState(ctx, txHash, 1)
+			}
+
+			require.EqualValues(t, 40, <-contract.CallGetBalance(ctx, 1, 6), "getBalance result on node which saw the leader fail")
+
+			network.WaitForTransactionInNodeState(ctx, txHash, 2)
+			require.EqualValues(t, 40, <-contract.CallGetBalance(ctx, 2, 6), "getBalance result on another node which saw the leader fail")
+		})
+}
+
 func TestBenchmarkConsensusLeaderGetsVotesBeforeNextBlock(t *testing.T) {
 	harness.Network(t).
 		WithLogFilters(log.ExcludeField(sync.LogTag), log.ExcludeEntryPoint("BlockSync")).
@@ -82,3 +109,37 @@ func TestBenchmarkConsensusLeaderGetsVotesBeforeNextBlock(t *testing.T) {
 			blockSyncTamper.Release(ctx)
 		})
 }
+
+// failover is only meant for test and staging networks, the old leader keeps the block it closed on its own at the height
+// it failed on and stays forked (its block sync can't go on from it), but the nodes which followed the new leader don't go back
+func TestBenchmarkConsensusNetworkKeepsFollowingNewLeaderWhenOldLeaderComesBackMidHeight(t *testing.T) {
+	harness.Network(t).
+		WithNumNodes(4).
+		WithBenchmarkConsensusLeaderFailover(200*time.Millisecond).
+		AllowingErrors("failed to validate block received via sync", "failed to commit block received via sync").
+		Start(func(ctx context.Context, network harness.TestNetworkDriver) {
+
+			contract := network.GetBenchmarkTokenContract()
+			contract.DeployBenchmarkToken(ctx, 5)
+
+			t.Log("Leader goes silent, the network fails over to the next leader")
+
+			oldLeader := testKeys.Ed25519KeyPairForTests(0).PublicKey()
+			silentOldLeader := network.TransportTamperer().Fail(adapter.MessageFrom(oldLeader))
+
+			txHash := contract.SendTransferInBackground(ctx, 1, 10, 5, 6)
+			for i := 1; i < 4; i++ {
+				network.WaitForTransactionInNodeState(ctx, txHash, i)
+			}
+
+			t.Log("Old leader comes back in the middle of the height it failed on")
+
+			silentOldLeader.Release(ctx)
+
+			txHash = contract.SendTransferInBackground(ctx, 1, 10, 5, 6)
+			for i := 1; i < 4; i++ {
+				network.WaitForTransactionInNodeState(ctx, txHash, i)
+				require.EqualValues(t, 20, <-contract.CallGetBalance(ctx, i, 6), "getBalance result on node which follows the new leader")
+			}
+		})
+}
@@ -56,6 +56,9 @@ func BlockPair() *blockPair {
 }
 
 func (b *blockPair) Build() *protocol.BlockPairContainer {
+	b.txHeader.TransactionsRootHash = digest.CalcTransactionsRootHash(b.transactions)
+	b.rxHeader.ReceiptsRootHash = digest.CalcReceiptsRootHash(b.receipts)
+	b.rxHeader.StateDiffHash = digest.CalcStateDiffHash(b.sdiffs)
 	txHeaderBuilt := b.txHeader.Build()
 	rxHeaderBuilt := b.rxHeader.Build()
 
@@ -134,6 +134,10 @@ func (t *TransactionBuilder) WithArgs(args ...interface{}) *TransactionBuilder {
 	return t
 }
 
+func (t *TransactionBuilder) WithCancellationOf(txHash primitives.Sha256) *TransactionBuilder {
+	return t.WithMethod("_TransactionPool", "cancelTransaction").WithArgs([]byte(txHash))
+}
+
 func (t *TransactionBuilder) WithAmountAndTargetAddress(amount uint64, targetAddress []byte) *TransactionBuilder {
 	return t.WithArgs(amount, targetAddress)
 }
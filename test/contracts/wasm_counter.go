@@ -0,0 +1,137 @@
+package contracts
+
+// the wasm counter has the same methods as the native counter, it is assembled by hand since the repo has no wasm toolchain
+func WasmCodeForCounter(counterStart uint64) []byte {
+	const (
+		i32 = 0x7f
+		i64 = 0x7e
+	)
+	const (
+		stateReadUint64 = iota
+		stateWriteUint64
+		argCount
+		argUint64
+		returnUint64
+		fail
+	)
+	countKey := "count"
+	argsError := "add takes a single amount"
+
+	types := wasmVec(
+		wasmFuncType([]byte{i32, i32}, []byte{i64}),
+		wasmFuncType([]byte{i32, i32, i64}, nil),
+		wasmFuncType(nil, []byte{i32}),
+		wasmFuncType([]byte{i32}, []byte{i64}),
+		wasmFuncType([]byte{i64}, nil),
+		wasmFuncType([]byte{i32, i32}, nil),
+		wasmFuncType(nil, nil),
+	)
+	imports := wasmVec(
+		wasmImport("stateReadUint64", 0),
+		wasmImport("stateWriteUint64", 1),
+		wasmImport("argCount", 2),
+		wasmImport("argUint64", 3),
+		wasmImport("returnUint64", 4),
+		wasmImport("fail", 5),
+	)
+	functions := wasmVec(wasmU32(6), wasmU32(6), wasmU32(6), wasmU32(6))
+	exports := wasmVec(
+		wasmExport("_init", 6),
+		wasmExport("add", 7),
+		wasmExport("get", 8),
+		wasmExport("start", 9),
+	)
+
+	// the key is at 0 and the error message at 16
+	countKeyArgs := wasmCat([]byte{0x41}, wasmS64(0), []byte{0x41}, wasmS64(int64(len(countKey))))
+	code := wasmVec(
+		// _init
+		wasmBody(countKeyArgs, []byte{0x42}, wasmS64(int64(counterStart)), []byte{0x10, stateWriteUint64, 0x0b}),
+		// add
+		wasmBody(
+			[]byte{0x10, argCount, 0x41, 1, 0x47, 0x04, 0x40, 0x41, 16, 0x41}, wasmS64(int64(len(argsError))), []byte{0x10, fail, 0x0b},
+			countKeyArgs, countKeyArgs, []byte{0x10, stateReadUint64, 0x41, 0, 0x10, argUint64, 0x7c, 0x10, stateWriteUint64, 0x0b},
+		),
+		// get
+		wasmBody(countKeyArgs, []byte{0x10, stateReadUint64, 0x10, returnUint64, 0x0b}),
+		// start
+		wasmBody([]byte{0x42}, wasmS64(int64(counterStart)), []byte{0x10, returnUint64, 0x0b}),
+	)
+	data := wasmVec(
+		wasmCat(wasmU32(0), []byte{0x41, 0, 0x0b}, wasmName(countKey)),
+		wasmCat(wasmU32(0), []byte{0x41, 16, 0x0b}, wasmName(argsError)),
+	)
+
+	return wasmCat(
+		[]byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00},
+		wasmSection(1, types),
+		wasmSection(2, imports),
+		wasmSection(3, functions),
+		wasmSection(5, wasmVec([]byte{0, 1})), // one page of memory
+		wasmSection(7, exports),
+		wasmSection(10, code),
+		wasmSection(11, data),
+	)
+}
+
+func wasmSection(id byte, contents []byte) []byte {
+	return wasmCat([]byte{id}, wasmU32(uint32(len(contents))), contents)
+}
+
+func wasmVec(items ...[]byte) []byte {
+	return wasmCat(append([][]byte{wasmU32(uint32(len(items)))}, items...)...)
+}
+
+func wasmFuncType(params []byte, results []byte) []byte {
+	return wasmCat([]byte{0x60}, wasmU32(uint32(len(params))), params, wasmU32(uint32(len(results))), results)
+}
+
+func wasmImport(name string, typeIndex uint32) []byte {
+	return wasmCat(wasmName("orbs"), wasmName(name), []byte{0}, wasmU32(typeIndex))
+}
+
+func wasmExport(name string, functionIndex uint32) []byte {
+	return wasmCat(wasmName(name), []byte{0}, wasmU32(functionIndex))
+}
+
+// functions without locals
+func wasmBody(code ...[]byte) []byte {
+	contents := wasmCat(append([][]byte{{0}}, code...)...)
+	return wasmCat(wasmU32(uint32(len(contents))), contents)
+}
+
+func wasmName(s string) []byte {
+	return wasmCat(wasmU32(uint32(len(s))), []byte(s))
+}
+
+func wasmU32(v uint32) []byte {
+	var res []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if v == 0 {
+			return append(res, b)
+		}
+		res = append(res, b|0x80)
+	}
+}
+
+func wasmS64(v int64) []byte {
+	var res []byte
+	for {
+		b := byte(v & 0x7f)
+		v >>= 7
+		if (v == 0 && b&0x40 == 0) || (v == -1 && b&0x40 != 0) {
+			return append(res, b)
+		}
+		res = append(res, b|0x80)
+	}
+}
+
+func wasmCat(parts ...[]byte) []byte {
+	var res []byte
+	for _, part := range parts {
+		res = append(res, part...)
+	}
+	return res
+}
@@ -6,15 +6,16 @@ import (
 	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
 	"github.com/orbs-network/orbs-network-go/bootstrap/inmemory"
 	"github.com/orbs-network/orbs-network-go/config"
-	"github.com/orbs-network/orbs-network-go/test/harness/contracts"
 	"github.com/orbs-network/orbs-network-go/instrumentation/log"
 	gossipAdapter "github.com/orbs-network/orbs-network-go/services/gossip/adapter"
 	testKeys "github.com/orbs-network/orbs-network-go/test/crypto/keys"
+	"github.com/orbs-network/orbs-network-go/test/harness/contracts"
 	blockStorageAdapter "github.com/orbs-network/orbs-network-go/test/harness/services/blockstorage/adapter"
 	testGossipAdapter "github.com/orbs-network/orbs-network-go/test/harness/services/gossip/adapter"
 	nativeProcessorAdapter "github.com/orbs-network/orbs-network-go/test/harness/services/processor/native/adapter"
 	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
+	"time"
 )
 
 type TestNetworkDriver interface {
@@ -24,11 +25,11 @@ type TestNetworkDriver interface {
 	Description() string
 	BlockPersistence(nodeIndex int) blockStorageAdapter.InMemoryBlockPersistence
 	DumpState()
-	WaitForTransactionInNodeState(ctx context.Context, txhash primitives.Sha256, nodeIndex int,)
+	WaitForTransactionInNodeState(ctx context.Context, txhash primitives.Sha256, nodeIndex int)
 	MockContract(fakeContractInfo *sdk.ContractInfo, code string)
 }
 
-func NewAcceptanceTestNetwork(ctx context.Context, numNodes int, testLogger log.BasicLogger, consensusAlgo consensus.ConsensusAlgoType, maxTxPerBlock uint32) *acceptanceNetwork {
+func NewAcceptanceTestNetwork(ctx context.Context, numNodes int, testLogger log.BasicLogger, consensusAlgo consensus.ConsensusAlgoType, maxTxPerBlock uint32, leaderFailoverTimeout time.Duration) *acceptanceNetwork {
 
 	testLogger.Info("===========================================================================")
 	testLogger.Info("creating acceptance test network", log.String("consensus", consensusAlgo.String()), log.Int("num-nodes", numNodes))
@@ -63,6 +64,11 @@ func NewAcceptanceTestNetwork(ctx context.Context, numNodes int, testLogger log.
 			consensusAlgo,
 			maxTxPerBlock,
 		)
+		if leaderFailoverTimeout > 0 {
+			cfg.SetBool(config.BENCHMARK_CONSENSUS_LEADER_FAILOVER_ENABLED, true)
+			cfg.SetDuration(config.BENCHMARK_CONSENSUS_LEADER_FAILOVER_TIMEOUT, leaderFailoverTimeout)
+			cfg.SetUint32(config.CONSENSUS_REQUIRED_QUORUM_PERCENTAGE, 66) // the failed leader can't vote
+		}
 
 		network.AddNode(keyPair, cfg, nativeProcessorAdapter.NewFakeCompiler())
 	}
@@ -83,7 +89,7 @@ func (n *acceptanceNetwork) Start(ctx context.Context) {
 	n.CreateAndStartNodes(ctx) // needs to start first so that nodes can register their listeners to it
 }
 
-func (n *acceptanceNetwork) WaitForTransactionInNodeState(ctx context.Context, txhash primitives.Sha256, nodeIndex int, ) {
+func (n *acceptanceNetwork) WaitForTransactionInNodeState(ctx context.Context, txhash primitives.Sha256, nodeIndex int) {
 	n.Nodes[nodeIndex].WaitForTransactionInState(ctx, txhash)
 }
 
@@ -118,6 +124,3 @@ func (n *acceptanceNetwork) MockContract(fakeContractInfo *sdk.ContractInfo, cod
 		}
 	}
 }
-
-
-
@@ -28,6 +28,8 @@ type acceptanceTestNetworkBuilder struct {
 	logFilters     []log.Filter
 	maxTxPerBlock  uint32
 	allowedErrors  []string
+
+	leaderFailoverTimeout time.Duration
 }
 
 func Network(f canFail) *acceptanceTestNetworkBuilder {
@@ -70,6 +72,12 @@ func (b *acceptanceTestNetworkBuilder) WithMaxTxPerBlock(maxTxPerBlock uint32) *
 	return b
 }
 
+// benchmark consensus moves on to the next leader when the leader does not commit in time
+func (b *acceptanceTestNetworkBuilder) WithBenchmarkConsensusLeaderFailover(timeout time.Duration) *acceptanceTestNetworkBuilder {
+	b.leaderFailoverTimeout = timeout
+	return b
+}
+
 func (b *acceptanceTestNetworkBuilder) AllowingErrors(allowedErrors ...string) *acceptanceTestNetworkBuilder {
 	b.allowedErrors = append(b.allowedErrors, allowedErrors...)
 	return b
@@ -82,7 +90,7 @@ func (b *acceptanceTestNetworkBuilder) Start(f func(ctx context.Context, network
 		test.WithContext(func(ctx context.Context) {
 			testId := b.testId + "-" + consensusAlgo.String()
 			logger, errorRecorder := b.makeLogger(testId)
-			network := NewAcceptanceTestNetwork(ctx, b.numNodes, logger, consensusAlgo, b.maxTxPerBlock)
+			network := NewAcceptanceTestNetwork(ctx, b.numNodes, logger, consensusAlgo, b.maxTxPerBlock, b.leaderFailoverTimeout)
 
 			defer printTestIdOnFailure(b.f, testId)
 			defer dumpStateOnFailure(b.f, network)
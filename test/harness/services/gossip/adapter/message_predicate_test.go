@@ -61,7 +61,6 @@ func ExampleMessagePredicate_payloadSize() {
 }
 
 func TestExampleLeanHelixMessage(t *testing.T) {
-	t.Skipf("Skipping Lean Helix tests until lean-helix-go submodule is integrated")
 	pred := LeanHelixMessage(consensus.LEAN_HELIX_COMMIT)
 
 	printMessage := func(msgType consensus.LeanHelixMessageType) {
@@ -2,6 +2,7 @@ package adapter
 
 import (
 	"github.com/orbs-network/orbs-network-go/services/gossip/adapter"
+	"github.com/orbs-network/orbs-spec/types/go/primitives"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/consensus"
 	"github.com/orbs-network/orbs-spec/types/go/protocol/gossipmessages"
 )
@@ -16,6 +17,10 @@ func ABenchmarkConsensusMessage(header *gossipmessages.Header) bool {
 	return header.IsTopicBenchmarkConsensus()
 }
 
+func ALeanHelixMessage(header *gossipmessages.Header) bool {
+	return header.IsTopicLeanHelix()
+}
+
 func HasHeader(headerPredicate HeaderPredicate) MessagePredicate {
 	return func(data *adapter.TransportData) bool {
 		header, ok := parseHeader(data)
@@ -24,6 +29,13 @@ func HasHeader(headerPredicate HeaderPredicate) MessagePredicate {
 	}
 }
 
+// a MessagePredicate for capturing all gossip messages sent by the given node
+func MessageFrom(senderPublicKey primitives.Ed25519PublicKey) MessagePredicate {
+	return func(data *adapter.TransportData) bool {
+		return data.SenderPublicKey.Equal(senderPublicKey)
+	}
+}
+
 func Not(predicate MessagePredicate) MessagePredicate {
 	return func(data *adapter.TransportData) bool {
 		return !predicate(data)
@@ -10,6 +10,8 @@ import (
 	"github.com/orbs-network/orbs-network-go/test/contracts"
 	"github.com/stretchr/testify/require"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -22,8 +24,94 @@ func TestContract_Compile(t *testing.T) {
 
 	t.Run("FakeCompiler", compileTest(aFakeCompiler))
 	t.Run("NativeCompiler", compileTest(aNativeCompiler))
+	t.Run("ExecutorCompiler", compileTest(anExecutorCompiler))
 }
 
+func TestExecutorCompiler_CpuLimitStopsEndlessLoopWithoutAffectingOtherCalls(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping compilation of contracts in short mode")
+	}
+
+	h := anExecutorCompilerWithCpuLimit(t, 1)
+	defer h.cleanup()
+
+	// give the test one minute timeout to compile
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	contractInfo, err := h.compiler.Compile(ctx, ENDLESS_LOOP_SOURCE_CODE)
+	require.NoError(t, err, "compile should succeed")
+	ci := contractInfo.InitSingleton(nil)
+
+	t.Log("Endless loop runs in an executor of its own, which exits once the loop runs past the cpu limit")
+
+	loopFailure := make(chan interface{}, 1)
+	go func() {
+		defer func() { loopFailure <- recover() }()
+		reflect.ValueOf(contractInfo.Methods["loop"].Implementation).Call([]reflect.Value{reflect.ValueOf(ci), reflect.ValueOf(sdk.Context(1))})
+	}()
+
+	t.Log("Calls made while the loop is running are not affected by it")
+
+	res := reflect.ValueOf(contractInfo.Methods["echo"].Implementation).Call([]reflect.Value{reflect.ValueOf(ci), reflect.ValueOf(sdk.Context(2)), reflect.ValueOf("hello")})
+	require.Nil(t, res[1].Interface(), "call next to the endless loop should succeed")
+	require.Equal(t, "hello", res[0].Interface().(string), "result of calling echo() should match")
+
+	select {
+	case failure := <-loopFailure:
+		require.IsType(t, &adapter.ExecutorFailure{}, failure, "endless loop should fail as an executor failure")
+	case <-ctx.Done():
+		require.Fail(t, "endless loop should be stopped by the cpu limit")
+	}
+}
+
+const ENDLESS_LOOP_SOURCE_CODE = `
+package main
+
+import (
+	"github.com/orbs-network/orbs-contract-sdk/go/sdk"
+)
+
+var CONTRACT = sdk.ContractInfo{
+	Name:       "EndlessLoop",
+	Permission: sdk.PERMISSION_SCOPE_SERVICE,
+	Methods: map[string]sdk.MethodInfo{
+		METHOD_LOOP.Name: METHOD_LOOP,
+		METHOD_ECHO.Name: METHOD_ECHO,
+	},
+	InitSingleton: newContract,
+}
+
+func newContract(base *sdk.BaseContract) sdk.ContractInstance {
+	return &contract{base}
+}
+
+type contract struct{ *sdk.BaseContract }
+
+var METHOD_LOOP = sdk.MethodInfo{
+	Name:           "loop",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).loop,
+}
+
+func (c *contract) loop(ctx sdk.Context) error {
+	for {
+	}
+}
+
+var METHOD_ECHO = sdk.MethodInfo{
+	Name:           "echo",
+	External:       true,
+	Access:         sdk.ACCESS_SCOPE_READ_ONLY,
+	Implementation: (*contract).echo,
+}
+
+func (c *contract) echo(ctx sdk.Context, value string) (string, error) {
+	return value, nil
+}
+`
+
 func compileTest(newHarness func(t *testing.T) *compilerContractHarness) func(*testing.T) {
 	return func(t *testing.T) {
 		h := newHarness(t)
@@ -76,6 +164,31 @@ func aNativeCompiler(t *testing.T) *compilerContractHarness {
 	}
 }
 
+func anExecutorCompiler(t *testing.T) *compilerContractHarness {
+	return anExecutorCompilerWithCpuLimit(t, 0)
+}
+
+func anExecutorCompilerWithCpuLimit(t *testing.T, cpuLimitInSeconds uint32) *compilerContractHarness {
+	tmpDir := test.CreateTempDirForTest(t)
+	executorPath := filepath.Join(tmpDir, "orbs-contract-executor")
+	out, err := exec.Command("go", "build", "-o", executorPath, "github.com/orbs-network/orbs-network-go/services/processor/native/executor/main").CombinedOutput()
+	require.NoError(t, err, "contract executor build should succeed: %s", out)
+
+	cfg := &hardcodedConfig{
+		artifactPath:      tmpDir,
+		executorPath:      executorPath,
+		cpuLimitInSeconds: cpuLimitInSeconds,
+	}
+	log := log.GetLogger().WithOutput(log.NewFormattingOutput(os.Stdout, log.NewHumanReadableFormatter()))
+	compiler := adapter.NewExecutorCompiler(cfg, log)
+	return &compilerContractHarness{
+		compiler: compiler,
+		cleanup: func() {
+			os.RemoveAll(tmpDir)
+		},
+	}
+}
+
 func aFakeCompiler(t *testing.T) *compilerContractHarness {
 	compiler := NewFakeCompiler()
 	code := string(contracts.NativeSourceCodeForCounter(contracts.MOCK_COUNTER_CONTRACT_START_FROM))
@@ -87,9 +200,23 @@ func aFakeCompiler(t *testing.T) *compilerContractHarness {
 }
 
 type hardcodedConfig struct {
-	artifactPath string
+	artifactPath      string
+	executorPath      string
+	cpuLimitInSeconds uint32
 }
 
 func (c *hardcodedConfig) ProcessorArtifactPath() string {
 	return c.artifactPath
 }
+
+func (c *hardcodedConfig) ProcessorNativeExecutorPath() string {
+	return c.executorPath
+}
+
+func (c *hardcodedConfig) ProcessorNativeExecutorCallMemoryLimitInBytes() uint32 {
+	return 256 * 1024 * 1024
+}
+
+func (c *hardcodedConfig) ProcessorNativeExecutorCallCpuLimitInSeconds() uint32 {
+	return c.cpuLimitInSeconds
+}